@@ -0,0 +1,64 @@
+package saruta
+
+import (
+	"fmt"
+	"slices"
+)
+
+type registeredAlias struct {
+	pattern string
+	aliases []string
+}
+
+// Alias registers additional patterns that serve the routes of pattern.
+//
+// Every method registered for pattern is also served under each alias with
+// the same handler and middleware, so the patterns form one logical route:
+// req.Pattern is pattern whichever of them matched. Aliases must capture the
+// same parameter names as pattern. Validation is deferred until Compile.
+func (r *Router) Alias(pattern string, aliases ...string) {
+	r.checkFrozen("Alias")
+	prefixed := make([]string, len(aliases))
+	for i, alias := range aliases {
+		prefixed[i] = r.prefix + alias
+	}
+	r.state.aliases = append(r.state.aliases, registeredAlias{
+		pattern: r.prefix + pattern,
+		aliases: prefixed,
+	})
+}
+
+// compileAliases validates registered aliases and returns them keyed by the
+// canonical pattern.
+func compileAliases(aliases []registeredAlias) (map[string][]compiledAlias, error) {
+	if len(aliases) == 0 {
+		return nil, nil
+	}
+	out := make(map[string][]compiledAlias, len(aliases))
+	for _, ra := range aliases {
+		cp, err := compilePattern(ra.pattern)
+		if err != nil {
+			return nil, err
+		}
+		want := cp.paramNames()
+		slices.Sort(want)
+		for _, alias := range ra.aliases {
+			acp, err := compilePattern(alias)
+			if err != nil {
+				return nil, err
+			}
+			got := acp.paramNames()
+			slices.Sort(got)
+			if !slices.Equal(got, want) {
+				return nil, fmt.Errorf("invalid alias %s for %s: parameters %v do not match %v", alias, ra.pattern, got, want)
+			}
+			out[ra.pattern] = append(out[ra.pattern], compiledAlias{pattern: alias, cp: acp})
+		}
+	}
+	return out, nil
+}
+
+type compiledAlias struct {
+	pattern string
+	cp      compiledPattern
+}
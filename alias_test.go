@@ -0,0 +1,65 @@
+package saruta
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRouterAlias(t *testing.T) {
+	r := New()
+	r.Get("/users/{id}", func(w http.ResponseWriter, req *http.Request) {
+		_, _ = w.Write([]byte("user=" + req.PathValue("id")))
+	})
+	r.Delete("/users/{id}", func(w http.ResponseWriter, req *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+	r.Alias("/users/{id}", "/u/{id}", "/members/{id}")
+	r.MustCompile()
+
+	for _, path := range []string{"/users/7", "/u/7", "/members/7"} {
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		if got, want := rec.Body.String(), "user=7"; got != want {
+			t.Fatalf("%s body = %q, want %q", path, got, want)
+		}
+	}
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodDelete, "/u/7", nil))
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("DELETE alias status = %d, want %d", rec.Code, http.StatusNoContent)
+	}
+
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/u/7", nil))
+	if got, want := rec.Header().Get("Allow"), "DELETE, GET"; got != want {
+		t.Fatalf("Allow = %q, want %q", got, want)
+	}
+}
+
+func TestRouterAliasCompileErrors(t *testing.T) {
+	h := func(w http.ResponseWriter, req *http.Request) {}
+
+	r := New()
+	r.Get("/users/{id}", h)
+	r.Alias("/users/{id}", "/u/{name}")
+	if err := r.Compile(); err == nil {
+		t.Fatalf("expected param mismatch error")
+	}
+
+	r = New()
+	r.Get("/users/{id}", h)
+	r.Alias("/people/{id}", "/p/{id}")
+	if err := r.Compile(); err == nil {
+		t.Fatalf("expected missing canonical route error")
+	}
+
+	r = New()
+	r.Get("/users/{id}", h)
+	r.Get("/u/{id}", h)
+	r.Alias("/users/{id}", "/u/{id}")
+	if err := r.Compile(); err == nil {
+		t.Fatalf("expected duplicate route error")
+	}
+}
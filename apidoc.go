@@ -0,0 +1,134 @@
+//go:build !tinygo
+
+package saruta
+
+import (
+	"encoding/json"
+	"maps"
+	"reflect"
+	"slices"
+	"strconv"
+)
+
+// MetaAPIDoc holds the APIDoc of a route. It is set by Doc.
+const MetaAPIDoc = "api_doc"
+
+// APIDoc documents a route for generated API descriptions, the route debug
+// page and OPTIONS documentation responses.
+//
+// Request and Responses hold values whose Go types describe the bodies,
+// typically zero values such as CreateUserRequest{}; generators inspect them
+// with reflect. In JSON (route exports, reports) they appear as type names.
+type APIDoc struct {
+	Summary     string
+	Description string
+	Tags        []string
+	Request     any
+	Responses   map[int]any
+}
+
+// DocOption configures an APIDoc.
+type DocOption func(*APIDoc)
+
+// DocDescription sets the long description of a route.
+func DocDescription(s string) DocOption {
+	return func(d *APIDoc) { d.Description = s }
+}
+
+// DocTags adds tags used to group routes in generated documentation.
+func DocTags(tags ...string) DocOption {
+	return func(d *APIDoc) { d.Tags = append(d.Tags, tags...) }
+}
+
+// DocRequest sets the request body schema to the type of v.
+func DocRequest(v any) DocOption {
+	return func(d *APIDoc) { d.Request = v }
+}
+
+// DocResponse sets the response body schema for status to the type of v.
+// A nil v documents a response without a body.
+func DocResponse(status int, v any) DocOption {
+	return func(d *APIDoc) {
+		if d.Responses == nil {
+			d.Responses = make(map[int]any)
+		}
+		d.Responses[status] = v
+	}
+}
+
+// Doc returns a derived router whose subsequently registered routes carry
+// documentation, keeping it next to the registration:
+//
+//	r.Doc("Create a user",
+//		saruta.DocTags("users"),
+//		saruta.DocRequest(CreateUserRequest{}),
+//		saruta.DocResponse(http.StatusCreated, User{}),
+//	).Post("/users", createUser)
+//
+// Called on a group, it documents every route of the group; a Doc call on
+// the route then adds to it: tags and responses accumulate, and a non-empty
+// summary replaces the group's. The summary is also stored as MetaDoc for
+// OPTIONS documentation responses.
+func (r *Router) Doc(summary string, opts ...DocOption) *Router {
+	var d APIDoc
+	if parent, ok := r.meta[MetaAPIDoc].(APIDoc); ok {
+		d = parent
+		d.Tags = slices.Clone(parent.Tags)
+		d.Responses = maps.Clone(parent.Responses)
+	}
+	if summary != "" {
+		d.Summary = summary
+	}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(&d)
+		}
+	}
+	derived := r.WithMeta(MetaAPIDoc, d)
+	if d.Summary != "" {
+		derived = derived.WithMeta(MetaDoc, d.Summary)
+	}
+	return derived
+}
+
+// RouteDoc returns the APIDoc of route, if it has one.
+func RouteDoc(route RouteInfo) (APIDoc, bool) {
+	d, ok := route.Meta[MetaAPIDoc].(APIDoc)
+	return d, ok
+}
+
+// MarshalJSON encodes d with schemas replaced by their type names.
+func (d APIDoc) MarshalJSON() ([]byte, error) {
+	out := struct {
+		Summary     string            `json:"summary,omitempty"`
+		Description string            `json:"description,omitempty"`
+		Tags        []string          `json:"tags,omitempty"`
+		Request     string            `json:"request,omitempty"`
+		Responses   map[string]string `json:"responses,omitempty"`
+	}{
+		Summary:     d.Summary,
+		Description: d.Description,
+		Tags:        d.Tags,
+		Request:     schemaName(d.Request),
+	}
+	if len(d.Responses) > 0 {
+		out.Responses = make(map[string]string, len(d.Responses))
+		for status, v := range d.Responses {
+			out.Responses[strconv.Itoa(status)] = schemaName(v)
+		}
+	}
+	return json.Marshal(out)
+}
+
+// schemaName returns the Go type name of v without pointer indirections, or
+// "" for nil.
+func schemaName(v any) string {
+	if v == nil {
+		return ""
+	}
+	t := reflect.TypeOf(v)
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t.String()
+}
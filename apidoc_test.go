@@ -0,0 +1,63 @@
+//go:build !tinygo
+
+package saruta
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+)
+
+type docTestUser struct{ ID int }
+
+type docTestCreateUser struct{ Name string }
+
+func TestRouterDoc(t *testing.T) {
+	noop := func(w http.ResponseWriter, req *http.Request) {}
+	r := New(WithAutoOptions())
+	users := r.Doc("", DocTags("users"), DocResponse(http.StatusUnauthorized, nil))
+	users.Doc("Create a user",
+		DocDescription("Creates a user and returns it."),
+		DocTags("admin"),
+		DocRequest(&docTestCreateUser{}),
+		DocResponse(http.StatusCreated, docTestUser{}),
+	).OptionsDoc().Post("/users", noop)
+	users.Doc("List users", DocResponse(http.StatusOK, []docTestUser{})).Get("/users", noop)
+	r.MustCompile()
+
+	var create, list APIDoc
+	for _, rt := range r.Routes() {
+		d, ok := RouteDoc(rt)
+		switch {
+		case rt.Method == http.MethodPost && ok:
+			create = d
+		case rt.Method == http.MethodGet && rt.Pattern == "/users" && ok:
+			list = d
+		}
+	}
+	if create.Summary != "Create a user" || !slices.Equal(create.Tags, []string{"users", "admin"}) || len(create.Responses) != 2 {
+		t.Fatalf("create doc = %+v", create)
+	}
+	if list.Summary != "List users" || !slices.Equal(list.Tags, []string{"users"}) || len(list.Responses) != 2 {
+		t.Fatalf("list doc = %+v (group tags and responses must not leak between routes)", list)
+	}
+
+	b, err := json.Marshal(create)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"summary":"Create a user","description":"Creates a user and returns it.","tags":["users","admin"],"request":"saruta.docTestCreateUser","responses":{"201":"saruta.docTestUser","401":""}}`
+	if string(b) != want {
+		t.Fatalf("JSON = %s, want %s", b, want)
+	}
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodOptions, "/users", nil))
+	if !strings.Contains(rec.Body.String(), `"doc":"Create a user"`) {
+		t.Fatalf("OPTIONS doc = %s", rec.Body.String())
+	}
+
+}
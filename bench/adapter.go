@@ -0,0 +1,43 @@
+// Package bench compares saruta with other routers, both as go test
+// benchmarks and through Run, which produces a JSON report for CI regression
+// gates.
+package bench
+
+import (
+	"net/http"
+	"strconv"
+)
+
+type routerAdapter interface {
+	Name() string
+	BuildStatic(path string) (http.Handler, error)
+	BuildParam(path string) (http.Handler, error)
+	BuildManyStatic(prefix string, n int) (http.Handler, string, error)
+}
+
+var adapters []routerAdapter
+
+func registerAdapter(a routerAdapter) {
+	adapters = append(adapters, a)
+}
+
+type discardResponseWriter struct {
+	header http.Header
+}
+
+func (w *discardResponseWriter) Header() http.Header {
+	if w.header == nil {
+		w.header = make(http.Header)
+	}
+	return w.header
+}
+
+func (w *discardResponseWriter) Write(p []byte) (int, error) {
+	return len(p), nil
+}
+
+func (w *discardResponseWriter) WriteHeader(statusCode int) {}
+
+func itemPath(prefix string, i int) string {
+	return prefix + "/" + strconv.Itoa(i)
+}
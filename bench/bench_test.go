@@ -4,39 +4,12 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/httptest"
-	"strconv"
+	"runtime"
+	"slices"
 	"testing"
-)
-
-type routerAdapter interface {
-	Name() string
-	BuildStatic(path string) (http.Handler, error)
-	BuildParam(path string) (http.Handler, error)
-	BuildManyStatic(prefix string, n int) (http.Handler, string, error)
-}
-
-var adapters []routerAdapter
-
-func registerAdapter(a routerAdapter) {
-	adapters = append(adapters, a)
-}
-
-type discardResponseWriter struct {
-	header http.Header
-}
 
-func (w *discardResponseWriter) Header() http.Header {
-	if w.header == nil {
-		w.header = make(http.Header)
-	}
-	return w.header
-}
-
-func (w *discardResponseWriter) Write(p []byte) (int, error) {
-	return len(p), nil
-}
-
-func (w *discardResponseWriter) WriteHeader(statusCode int) {}
+	"github.com/catatsuy/saruta"
+)
 
 func BenchmarkStaticLookup(b *testing.B) {
 	for _, a := range adapters {
@@ -120,6 +93,25 @@ func BenchmarkLookupScale(b *testing.B) {
 	}
 }
 
-func itemPath(prefix string, i int) string {
-	return prefix + "/" + strconv.Itoa(i)
+// BenchmarkCompile measures saruta's Compile for generated route catalogs.
+// The procs=1 runs are the serial baseline; larger tables compile patterns
+// and build the tree on all procs.
+func BenchmarkCompile(b *testing.B) {
+	for _, routeCount := range []int{1000, 10000, 50000} {
+		r := saruta.New()
+		for i := range routeCount {
+			r.Get(fmt.Sprintf("/catalog/%d/items/{id}/variant-%d", i%500, i), func(w http.ResponseWriter, req *http.Request) {})
+		}
+		for _, procs := range slices.Compact([]int{1, runtime.GOMAXPROCS(0)}) {
+			b.Run(fmt.Sprintf("routes=%d/procs=%d", routeCount, procs), func(b *testing.B) {
+				defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(procs))
+				b.ReportAllocs()
+				for b.Loop() {
+					if err := r.Compile(); err != nil {
+						b.Fatal(err)
+					}
+				}
+			})
+		}
+	}
 }
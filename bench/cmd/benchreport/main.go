@@ -0,0 +1,85 @@
+// Command benchreport runs the saruta lookup benchmarks and writes a JSON
+// report. With -baseline it compares against a previous report and exits
+// with status 1 on regressions, for use as a CI gate:
+//
+//	go run ./cmd/benchreport -o current.json -baseline baseline.json -max-slowdown 0.15
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/catatsuy/saruta/bench"
+)
+
+func main() {
+	var (
+		routers     = flag.String("routers", "", "comma-separated routers to measure (default all)")
+		scenarios   = flag.String("scenarios", "", "comma-separated scenarios to measure (default all: "+strings.Join(bench.Scenarios(), ",")+")")
+		iterations  = flag.Int("n", bench.DefaultIterations, "lookups per scenario")
+		output      = flag.String("o", "", "write the report to this file instead of stdout")
+		baseline    = flag.String("baseline", "", "baseline report to compare against")
+		maxSlowdown = flag.Float64("max-slowdown", 0.1, "allowed ns/op growth over the baseline (0.1 = 10%)")
+	)
+	flag.Parse()
+
+	if err := run(*routers, *scenarios, *iterations, *output, *baseline, *maxSlowdown); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func run(routers, scenarios string, iterations int, output, baseline string, maxSlowdown float64) error {
+	report, err := bench.Run(bench.Config{
+		Routers:    splitList(routers),
+		Scenarios:  splitList(scenarios),
+		Iterations: iterations,
+	})
+	if err != nil {
+		return err
+	}
+
+	var w io.Writer = os.Stdout
+	if output != "" {
+		f, err := os.Create(output)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+	if err := report.WriteJSON(w); err != nil {
+		return err
+	}
+
+	if baseline == "" {
+		return nil
+	}
+	f, err := os.Open(baseline)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	base, err := bench.ReadReport(f)
+	if err != nil {
+		return fmt.Errorf("read baseline: %w", err)
+	}
+	regressions := bench.Compare(base, report, maxSlowdown)
+	for _, r := range regressions {
+		fmt.Fprintln(os.Stderr, "regression:", r)
+	}
+	if len(regressions) > 0 {
+		return fmt.Errorf("%d regression(s) against %s", len(regressions), baseline)
+	}
+	return nil
+}
+
+func splitList(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(s, ",")
+}
@@ -2,11 +2,10 @@ module github.com/catatsuy/saruta/bench
 
 go 1.25
 
-require github.com/catatsuy/saruta v0.0.0
-
 require (
-	github.com/go-chi/chi/v5 v5.2.5 // indirect
-	github.com/julienschmidt/httprouter v1.3.0 // indirect
+	github.com/catatsuy/saruta v0.0.0
+	github.com/go-chi/chi/v5 v5.2.5
+	github.com/julienschmidt/httprouter v1.3.0
 )
 
 replace github.com/catatsuy/saruta => ..
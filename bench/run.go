@@ -0,0 +1,197 @@
+package bench
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"slices"
+	"testing"
+	"time"
+)
+
+// DefaultIterations is the number of lookups per scenario used by Run when
+// Config.Iterations is zero.
+const DefaultIterations = 1_000_000
+
+// Config selects what Run measures.
+type Config struct {
+	// Routers lists adapter names ("saruta", "servemux", ...). Empty means all
+	// registered adapters.
+	Routers []string
+	// Scenarios lists scenario names (see Scenarios). Empty means all.
+	Scenarios []string
+	// Iterations is the number of lookups timed per scenario.
+	Iterations int
+}
+
+// Result is the measurement of one router in one scenario.
+type Result struct {
+	Router      string  `json:"router"`
+	Scenario    string  `json:"scenario"`
+	Iterations  int     `json:"iterations"`
+	NsPerOp     float64 `json:"ns_per_op"`
+	AllocsPerOp float64 `json:"allocs_per_op"`
+}
+
+// Report is the output of Run.
+type Report struct {
+	GoVersion string   `json:"go_version"`
+	GOOS      string   `json:"goos"`
+	GOARCH    string   `json:"goarch"`
+	Results   []Result `json:"results"`
+}
+
+// WriteJSON writes the report as indented JSON.
+func (r Report) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
+
+// ReadReport decodes a report written by WriteJSON.
+func ReadReport(rd io.Reader) (Report, error) {
+	var r Report
+	err := json.NewDecoder(rd).Decode(&r)
+	return r, err
+}
+
+type scenario struct {
+	name  string
+	build func(a routerAdapter) (http.Handler, string, error)
+}
+
+func staticScenario(name, path string) scenario {
+	return scenario{name: name, build: func(a routerAdapter) (http.Handler, string, error) {
+		h, err := a.BuildStatic(path)
+		return h, path, err
+	}}
+}
+
+func scaleScenario(n int) scenario {
+	return scenario{name: fmt.Sprintf("scale=%d", n), build: func(a routerAdapter) (http.Handler, string, error) {
+		return a.BuildManyStatic("/items", n)
+	}}
+}
+
+var scenarios = []scenario{
+	staticScenario("static", "/health"),
+	{name: "param", build: func(a routerAdapter) (http.Handler, string, error) {
+		h, err := a.BuildParam("/users/{id}")
+		return h, "/users/12345", err
+	}},
+	staticScenario("deep", "/a/b/c/d/e/f/g"),
+	scaleScenario(100),
+	scaleScenario(1000),
+	scaleScenario(10000),
+}
+
+// Scenarios returns the names of the scenarios Run knows, mirroring the go
+// test benchmarks.
+func Scenarios() []string {
+	names := make([]string, len(scenarios))
+	for i, s := range scenarios {
+		names[i] = s.name
+	}
+	return names
+}
+
+// Run measures lookup latency and allocations for every selected router and
+// scenario. It returns an error for unknown router or scenario names and for
+// routers that fail to build.
+func Run(cfg Config) (Report, error) {
+	if cfg.Iterations <= 0 {
+		cfg.Iterations = DefaultIterations
+	}
+	for _, name := range cfg.Routers {
+		if !slices.ContainsFunc(adapters, func(a routerAdapter) bool { return a.Name() == name }) {
+			return Report{}, fmt.Errorf("bench: unknown router %q", name)
+		}
+	}
+	for _, name := range cfg.Scenarios {
+		if !slices.ContainsFunc(scenarios, func(s scenario) bool { return s.name == name }) {
+			return Report{}, fmt.Errorf("bench: unknown scenario %q", name)
+		}
+	}
+
+	report := Report{GoVersion: runtime.Version(), GOOS: runtime.GOOS, GOARCH: runtime.GOARCH}
+	for _, s := range scenarios {
+		if len(cfg.Scenarios) > 0 && !slices.Contains(cfg.Scenarios, s.name) {
+			continue
+		}
+		for _, a := range adapters {
+			if len(cfg.Routers) > 0 && !slices.Contains(cfg.Routers, a.Name()) {
+				continue
+			}
+			h, path, err := s.build(a)
+			if err != nil {
+				return Report{}, fmt.Errorf("bench: build %s for %s: %w", a.Name(), s.name, err)
+			}
+			report.Results = append(report.Results, measure(a.Name(), s.name, h, path, cfg.Iterations))
+		}
+	}
+	return report, nil
+}
+
+func measure(router, scenario string, h http.Handler, path string, n int) Result {
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	w := &discardResponseWriter{}
+	h.ServeHTTP(w, req)
+
+	start := time.Now()
+	for i := 0; i < n; i++ {
+		h.ServeHTTP(w, req)
+	}
+	elapsed := time.Since(start)
+
+	allocs := testing.AllocsPerRun(min(n, 1000), func() {
+		h.ServeHTTP(w, req)
+	})
+	return Result{
+		Router:      router,
+		Scenario:    scenario,
+		Iterations:  n,
+		NsPerOp:     float64(elapsed.Nanoseconds()) / float64(n),
+		AllocsPerOp: allocs,
+	}
+}
+
+// Regression describes a result that got worse than its baseline.
+type Regression struct {
+	Router   string  `json:"router"`
+	Scenario string  `json:"scenario"`
+	Metric   string  `json:"metric"` // "ns_per_op" or "allocs_per_op"
+	Baseline float64 `json:"baseline"`
+	Current  float64 `json:"current"`
+}
+
+func (r Regression) String() string {
+	return fmt.Sprintf("%s/%s %s: %.2f -> %.2f", r.Router, r.Scenario, r.Metric, r.Baseline, r.Current)
+}
+
+// Compare reports results in current that regressed against baseline: ns/op
+// grew by more than maxSlowdown (0.1 means 10%) or allocs/op grew at all.
+// Results missing from either report are ignored.
+func Compare(baseline, current Report, maxSlowdown float64) []Regression {
+	type key struct{ router, scenario string }
+	base := make(map[key]Result, len(baseline.Results))
+	for _, r := range baseline.Results {
+		base[key{r.Router, r.Scenario}] = r
+	}
+	var out []Regression
+	for _, cur := range current.Results {
+		b, ok := base[key{cur.Router, cur.Scenario}]
+		if !ok {
+			continue
+		}
+		if cur.NsPerOp > b.NsPerOp*(1+maxSlowdown) {
+			out = append(out, Regression{Router: cur.Router, Scenario: cur.Scenario, Metric: "ns_per_op", Baseline: b.NsPerOp, Current: cur.NsPerOp})
+		}
+		if cur.AllocsPerOp > b.AllocsPerOp {
+			out = append(out, Regression{Router: cur.Router, Scenario: cur.Scenario, Metric: "allocs_per_op", Baseline: b.AllocsPerOp, Current: cur.AllocsPerOp})
+		}
+	}
+	return out
+}
@@ -0,0 +1,67 @@
+package bench
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestRun(t *testing.T) {
+	report, err := Run(Config{Routers: []string{"saruta"}, Scenarios: []string{"static", "param"}, Iterations: 100})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Results) != 2 {
+		t.Fatalf("results = %+v, want 2", report.Results)
+	}
+	for _, r := range report.Results {
+		if r.Router != "saruta" || r.Iterations != 100 || r.NsPerOp <= 0 {
+			t.Fatalf("unexpected result %+v", r)
+		}
+		if r.AllocsPerOp != 0 {
+			t.Fatalf("%s allocs/op = %v, want 0", r.Scenario, r.AllocsPerOp)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := report.WriteJSON(&buf); err != nil {
+		t.Fatal(err)
+	}
+	decoded, err := ReadReport(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(decoded.Results) != 2 || decoded.Results[0] != report.Results[0] {
+		t.Fatalf("round trip = %+v", decoded.Results)
+	}
+
+	if _, err := Run(Config{Routers: []string{"nope"}}); err == nil {
+		t.Fatal("expected error for unknown router")
+	}
+	if _, err := Run(Config{Scenarios: []string{"nope"}}); err == nil {
+		t.Fatal("expected error for unknown scenario")
+	}
+}
+
+func TestCompare(t *testing.T) {
+	baseline := Report{Results: []Result{
+		{Router: "saruta", Scenario: "static", NsPerOp: 100, AllocsPerOp: 0},
+		{Router: "saruta", Scenario: "param", NsPerOp: 100, AllocsPerOp: 0},
+		{Router: "saruta", Scenario: "deep", NsPerOp: 100, AllocsPerOp: 0},
+	}}
+	current := Report{Results: []Result{
+		{Router: "saruta", Scenario: "static", NsPerOp: 109, AllocsPerOp: 0},
+		{Router: "saruta", Scenario: "param", NsPerOp: 120, AllocsPerOp: 0},
+		{Router: "saruta", Scenario: "deep", NsPerOp: 90, AllocsPerOp: 1},
+		{Router: "saruta", Scenario: "scale=100", NsPerOp: 1000, AllocsPerOp: 5},
+	}}
+	got := Compare(baseline, current, 0.1)
+	if len(got) != 2 {
+		t.Fatalf("regressions = %v, want 2", got)
+	}
+	if got[0].Scenario != "param" || got[0].Metric != "ns_per_op" {
+		t.Fatalf("regressions[0] = %v", got[0])
+	}
+	if got[1].Scenario != "deep" || got[1].Metric != "allocs_per_op" {
+		t.Fatalf("regressions[1] = %v", got[1])
+	}
+}
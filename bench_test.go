@@ -4,6 +4,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"strconv"
+	"strings"
 	"testing"
 )
 
@@ -143,3 +144,101 @@ func BenchmarkRouterLookupScale(b *testing.B) {
 		})
 	}
 }
+
+// wordPrefix is a word-at-a-time strings.HasPrefix, kept as the baseline
+// showing why matchPath does not use one.
+func wordPrefix(s, prefix string) bool {
+	n := len(prefix)
+	if len(s) < n {
+		return false
+	}
+	i := 0
+	for ; i+8 <= n; i += 8 {
+		if load64(s, i) != load64(prefix, i) {
+			return false
+		}
+	}
+	return s[i:n] == prefix[i:]
+}
+
+// bytePrefixLen is the byte-loop longestCommonPrefix it replaced.
+func bytePrefixLen(a, b string) int {
+	n := min(len(b), len(a))
+	i := 0
+	for i < n && a[i] == b[i] {
+		i++
+	}
+	return i
+}
+
+func BenchmarkLabelPrefix(b *testing.B) {
+	for _, n := range []int{4, 16, 48, 128} {
+		label := strings.Repeat("abcdefgh", n/8+1)[:n]
+		path := label + "/rest"
+		b.Run("strings.HasPrefix/"+strconv.Itoa(n), func(b *testing.B) {
+			for b.Loop() {
+				if !strings.HasPrefix(path, label) {
+					b.Fatal("no match")
+				}
+			}
+		})
+		b.Run("words/"+strconv.Itoa(n), func(b *testing.B) {
+			for b.Loop() {
+				if !wordPrefix(path, label) {
+					b.Fatal("no match")
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkLongestCommonPrefix(b *testing.B) {
+	for _, n := range []int{4, 16, 48, 128} {
+		a := strings.Repeat("abcdefgh", n/8+1)[:n]
+		x, y := a+"x", a+"y"
+		b.Run("bytes/"+strconv.Itoa(n), func(b *testing.B) {
+			for b.Loop() {
+				if bytePrefixLen(x, y) != n {
+					b.Fatal("wrong length")
+				}
+			}
+		})
+		b.Run("words/"+strconv.Itoa(n), func(b *testing.B) {
+			for b.Loop() {
+				if longestCommonPrefix(x, y) != n {
+					b.Fatal("wrong length")
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkRouterLongStatic(b *testing.B) {
+	r := New()
+	r.Get("/api/v1/organizations/settings/notifications/preferences", func(w http.ResponseWriter, req *http.Request) {})
+	r.Get("/api/v1/organizations/settings/notifications/{channel}", func(w http.ResponseWriter, req *http.Request) {})
+	r.MustCompile()
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/organizations/settings/notifications/preferences", nil)
+	w := &discardResponseWriter{}
+	b.ReportAllocs()
+	for b.Loop() {
+		r.ServeHTTP(w, req)
+	}
+}
+
+func BenchmarkRouterMatchCache(b *testing.B) {
+	for _, n := range []int{0, 64} {
+		b.Run("cache="+strconv.Itoa(n), func(b *testing.B) {
+			r := New(WithMatchCache(n))
+			r.Get("/api/v1/orgs/{org}/repos/{repo}/issues/{number}/comments", func(w http.ResponseWriter, req *http.Request) {})
+			r.Get("/api/v1/orgs/{org}/repos/{repo}/issues/{number}/events", func(w http.ResponseWriter, req *http.Request) {})
+			r.MustCompile()
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/orgs/acme/repos/saruta/issues/42/comments", nil)
+			w := &discardResponseWriter{}
+			b.ReportAllocs()
+			for b.Loop() {
+				r.ServeHTTP(w, req)
+			}
+		})
+	}
+}
@@ -0,0 +1,196 @@
+//go:build !tinygo
+
+package saruta
+
+import (
+	"encoding"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strconv"
+	"sync"
+)
+
+// BindError reports a request value that Bind could not convert. Its message
+// is safe to return to clients in a 400 response.
+type BindError struct {
+	// Source is "path", "query" or "header".
+	Source string
+	Name   string
+	Value  string
+	Err    error
+}
+
+func (e *BindError) Error() string {
+	return fmt.Sprintf("%s parameter %q: invalid value %q", e.Source, e.Name, e.Value)
+}
+
+func (e *BindError) Unwrap() error {
+	return e.Err
+}
+
+// Bind populates a T (a struct) from the request, following field tags:
+//
+//	type listParams struct {
+//		Org   string   `path:"org"`
+//		Page  int      `query:"page"`
+//		Tags  []string `query:"tag"`
+//		Trace string   `header:"X-Trace-Id"`
+//	}
+//	p, err := saruta.Bind[listParams](req)
+//
+// Supported field types are strings, bools, integers, floats, types
+// implementing encoding.TextUnmarshaler, and slices of those (filled from
+// repeated query values or headers). Absent values leave the field zero.
+// Conversion failures are returned as *BindError; a T that is not a struct or
+// has an unsupported tagged field panics, as that is a programming error.
+func Bind[T any](req *http.Request) (T, error) {
+	var v T
+	err := bindInto(req, reflect.ValueOf(&v).Elem())
+	return v, err
+}
+
+// bindInto fills the tagged fields of the struct rv.
+func bindInto(req *http.Request, rv reflect.Value) error {
+	for _, f := range bindPlanFor(rv.Type()) {
+		var values []string
+		switch f.source {
+		case "path":
+			if s := Param(req, f.name); s != "" {
+				values = []string{s}
+			}
+		case "query":
+			values = req.URL.Query()[f.name]
+		case "header":
+			values = req.Header.Values(f.name)
+		}
+		if len(values) == 0 {
+			continue
+		}
+		if err := setField(rv.FieldByIndex(f.index), values); err != nil {
+			return &BindError{Source: f.source, Name: f.name, Value: values[0], Err: err}
+		}
+	}
+	return nil
+}
+
+// ValidateBind reports path tags of T that name no parameter of pattern, so
+// a misspelled tag fails at startup or in tests instead of binding nothing.
+func ValidateBind[T any](pattern string) error {
+	cp, err := compilePattern(pattern)
+	if err != nil {
+		return err
+	}
+	params := make(map[string]bool)
+	for _, name := range cp.paramNames() {
+		params[name] = true
+	}
+	for _, f := range bindPlanFor(reflect.TypeFor[T]()) {
+		if f.source == "path" && !params[f.name] {
+			return fmt.Errorf("bind %s: pattern %s has no parameter %q", reflect.TypeFor[T](), pattern, f.name)
+		}
+	}
+	return nil
+}
+
+type bindField struct {
+	source string
+	name   string
+	index  []int
+}
+
+var bindPlans sync.Map // reflect.Type -> []bindField
+
+func bindPlanFor(t reflect.Type) []bindField {
+	if plan, ok := bindPlans.Load(t); ok {
+		return plan.([]bindField)
+	}
+	if t.Kind() != reflect.Struct {
+		panic(fmt.Sprintf("saruta: Bind target %s is not a struct", t))
+	}
+	var plan []bindField
+	for _, sf := range reflect.VisibleFields(t) {
+		if !sf.IsExported() {
+			continue
+		}
+		for _, source := range []string{"path", "query", "header"} {
+			name, ok := sf.Tag.Lookup(source)
+			if !ok {
+				continue
+			}
+			if !bindable(sf.Type) {
+				panic(fmt.Sprintf("saruta: Bind field %s.%s has unsupported type %s", t, sf.Name, sf.Type))
+			}
+			plan = append(plan, bindField{source: source, name: name, index: sf.Index})
+		}
+	}
+	bindPlans.Store(t, plan)
+	return plan
+}
+
+var textUnmarshalerType = reflect.TypeFor[encoding.TextUnmarshaler]()
+
+func bindable(t reflect.Type) bool {
+	if reflect.PointerTo(t).Implements(textUnmarshalerType) {
+		return true
+	}
+	switch t.Kind() {
+	case reflect.String, reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	case reflect.Slice:
+		return t.Elem().Kind() != reflect.Slice && bindable(t.Elem())
+	}
+	return false
+}
+
+func setField(v reflect.Value, values []string) error {
+	if v.Kind() == reflect.Slice && !v.Addr().Type().Implements(textUnmarshalerType) {
+		s := reflect.MakeSlice(v.Type(), len(values), len(values))
+		for i, value := range values {
+			if err := setScalar(s.Index(i), value); err != nil {
+				return err
+			}
+		}
+		v.Set(s)
+		return nil
+	}
+	return setScalar(v, values[0])
+}
+
+func setScalar(v reflect.Value, s string) error {
+	if u, ok := v.Addr().Interface().(encoding.TextUnmarshaler); ok {
+		return u.UnmarshalText([]byte(s))
+	}
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(s)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(s, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(s, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(s, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetFloat(f)
+	}
+	return nil
+}
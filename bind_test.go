@@ -0,0 +1,93 @@
+//go:build !tinygo
+
+package saruta
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+type bindTestParams struct {
+	Org    string       `path:"org"`
+	ID     int64        `path:"id"`
+	Page   int          `query:"page"`
+	Ratio  float64      `query:"ratio"`
+	Tags   []string     `query:"tag"`
+	Debug  bool         `query:"debug"`
+	Trace  string       `header:"X-Trace-Id"`
+	Addr   netip.Addr   `header:"X-Addr"`
+	Ports  []uint16     `query:"port"`
+	Ignore string       `json:"ignore"`
+	Nets   []netip.Addr `query:"net"`
+	hidden string       `query:"hidden"`
+}
+
+func TestBind(t *testing.T) {
+	var got bindTestParams
+	var bindErr error
+	r := New()
+	r.Get("/orgs/{org}/items/{id}", func(w http.ResponseWriter, req *http.Request) {
+		got, bindErr = Bind[bindTestParams](req)
+	})
+	r.MustCompile()
+
+	req := httptest.NewRequest(http.MethodGet, "/orgs/acme/items/42?page=3&ratio=0.5&tag=a&tag=b&debug=true&port=80&port=443&net=10.0.0.1&hidden=x", nil)
+	req.Header.Set("X-Trace-Id", "abc")
+	req.Header.Set("X-Addr", "192.0.2.1")
+	r.ServeHTTP(httptest.NewRecorder(), req)
+	if bindErr != nil {
+		t.Fatal(bindErr)
+	}
+	want := bindTestParams{
+		Org: "acme", ID: 42, Page: 3, Ratio: 0.5, Tags: []string{"a", "b"}, Debug: true,
+		Trace: "abc", Addr: netip.MustParseAddr("192.0.2.1"), Ports: []uint16{80, 443},
+		Nets: []netip.Addr{netip.MustParseAddr("10.0.0.1")},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("Bind = %+v, want %+v", got, want)
+	}
+
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/orgs/acme/items/x", nil))
+	var be *BindError
+	if !errors.As(bindErr, &be) || be.Source != "path" || be.Name != "id" || be.Value != "x" {
+		t.Fatalf("err = %v", bindErr)
+	}
+	if !errors.Is(bindErr, strconv.ErrSyntax) {
+		t.Fatalf("err does not wrap strconv.ErrSyntax: %v", bindErr)
+	}
+	if bindErr.Error() != `path parameter "id": invalid value "x"` {
+		t.Fatalf("message = %q", bindErr.Error())
+	}
+
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/orgs/acme/items/1?port=70000", nil))
+	if !errors.As(bindErr, &be) || be.Source != "query" || be.Name != "port" {
+		t.Fatalf("overflow err = %v", bindErr)
+	}
+}
+
+func TestBindPanicsOnUnsupportedType(t *testing.T) {
+	type bad struct {
+		M map[string]string `query:"m"`
+	}
+	defer func() {
+		if recover() == nil {
+			t.Fatalf("expected panic")
+		}
+	}()
+	Bind[bad](httptest.NewRequest(http.MethodGet, "/", nil))
+}
+
+func TestValidateBind(t *testing.T) {
+	if err := ValidateBind[bindTestParams]("/orgs/{org}/items/{id:[0-9]+}"); err != nil {
+		t.Fatal(err)
+	}
+	err := ValidateBind[bindTestParams]("/orgs/{org}/items/{item}")
+	if err == nil || err.Error() != `bind saruta.bindTestParams: pattern /orgs/{org}/items/{item} has no parameter "id"` {
+		t.Fatalf("err = %v", err)
+	}
+}
@@ -0,0 +1,179 @@
+//go:build chi
+
+// Package chimigrate copies the routes of a chi router into a saruta router,
+// so that an application can switch routers without rewriting its route
+// table or its handlers.
+//
+// It lives in its own module, behind the chi build tag, so that the chi
+// dependency stays out of the root module:
+//
+//	go build -tags chi
+package chimigrate
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/catatsuy/saruta"
+	"github.com/go-chi/chi/v5"
+)
+
+// wildcardParam is the name of the saruta catch-all a trailing chi "*"
+// becomes. Handlers keep reading it as chi.URLParam(req, "*").
+const wildcardParam = "wildcard"
+
+// UntranslatableError reports a chi route that has no saruta equivalent.
+type UntranslatableError struct {
+	Method  string
+	Pattern string // chi pattern
+	Err     error
+}
+
+func (e *UntranslatableError) Error() string {
+	return fmt.Sprintf("chimigrate: %s %s: %v", e.Method, e.Pattern, e.Err)
+}
+
+func (e *UntranslatableError) Unwrap() error {
+	return e.Err
+}
+
+// FromChi walks mux, including mounted subrouters, and registers every route
+// on a new saruta router created with opts. Each handler keeps its chi
+// middleware stack, in chi's order, and runs with a chi route context, so
+// chi.URLParam and chi.RouteContext(ctx).RoutePattern() work unchanged.
+//
+// Patterns are translated as follows: {name} and {name:regexp} keep their
+// form, with regexp anchors dropped, and a trailing "/*" becomes a catch-all.
+// A route whose pattern saruta cannot express, such as a regexp outside
+// saruta's constraint syntax or a "*" inside a segment, is skipped and
+// reported as an *UntranslatableError; the returned error joins all of them,
+// and the router holds the other routes.
+//
+// The router is not compiled. chi's NotFound and MethodNotAllowed handlers
+// are not copied.
+func FromChi(mux chi.Routes, opts ...saruta.Option) (*saruta.Router, error) {
+	type route struct {
+		method, chiPattern, pattern string
+		h                           http.Handler
+	}
+	var routes []route
+	var errs []error
+	err := chi.Walk(mux, func(method, chiPattern string, h http.Handler, mws ...func(http.Handler) http.Handler) error {
+		pattern, names, err := translatePattern(chiPattern)
+		if err != nil {
+			errs = append(errs, &UntranslatableError{Method: method, Pattern: chiPattern, Err: err})
+			return nil
+		}
+		for i := len(mws) - 1; i >= 0; i-- {
+			h = mws[i](h)
+		}
+		routes = append(routes, route{
+			method:     method,
+			chiPattern: chiPattern,
+			pattern:    pattern,
+			h:          withRouteContext(method, chiPattern, names, h),
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	slices.SortFunc(errs, func(a, b error) int {
+		ea, eb := a.(*UntranslatableError), b.(*UntranslatableError)
+		return cmp.Or(strings.Compare(ea.Pattern, eb.Pattern), strings.Compare(ea.Method, eb.Method))
+	})
+	r := saruta.New(opts...)
+	for _, rt := range routes {
+		r.Handle(rt.method, rt.pattern, rt.h)
+	}
+	return r, errors.Join(errs...)
+}
+
+// translatePattern returns the saruta form of a chi pattern and the chi
+// names of its parameters, in order. The result is checked by compiling it
+// on its own, so that an invalid pattern is reported here instead of failing
+// the Compile of the whole router.
+func translatePattern(pattern string) (string, []string, error) {
+	var b strings.Builder
+	var names []string
+	for i := 0; i < len(pattern); i++ {
+		switch c := pattern[i]; c {
+		case '{':
+			end := closingBrace(pattern, i)
+			if end < 0 {
+				return "", nil, fmt.Errorf("unterminated parameter")
+			}
+			name, expr, hasExpr := strings.Cut(pattern[i+1:end], ":")
+			names = append(names, name)
+			b.WriteString("{" + name)
+			if hasExpr {
+				expr = strings.TrimSuffix(strings.TrimPrefix(expr, "^"), "$")
+				b.WriteString(":" + expr)
+			}
+			b.WriteByte('}')
+			i = end
+		case '*':
+			if i != len(pattern)-1 {
+				return "", nil, fmt.Errorf("wildcard must be the last character")
+			}
+			if i == 0 || pattern[i-1] != '/' {
+				return "", nil, fmt.Errorf("wildcard inside a segment")
+			}
+			names = append(names, "*")
+			b.WriteString("{" + wildcardParam + "...}")
+		default:
+			b.WriteByte(c)
+		}
+	}
+
+	translated := b.String()
+	check := saruta.New()
+	check.Handle(http.MethodGet, translated, http.NotFoundHandler())
+	if err := check.Compile(); err != nil {
+		return "", nil, err
+	}
+	return translated, names, nil
+}
+
+// closingBrace returns the index of the brace closing the one at start, or
+// -1. chi allows braces inside a parameter's regexp, as in {id:[0-9]{3}}.
+func closingBrace(pattern string, start int) int {
+	depth := 0
+	for i := start; i < len(pattern); i++ {
+		switch pattern[i] {
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// withRouteContext runs h with a chi route context holding the values of
+// the matched saruta route under their chi names.
+func withRouteContext(method, chiPattern string, names []string, h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		rctx := chi.NewRouteContext()
+		rctx.RouteMethod = method
+		rctx.RoutePatterns = append(rctx.RoutePatterns, chiPattern)
+		for _, name := range names {
+			param := name
+			if name == "*" {
+				param = wildcardParam
+			}
+			rctx.URLParams.Add(name, saruta.Param(req, param))
+		}
+		ctx := context.WithValue(req.Context(), chi.RouteCtxKey, rctx)
+		h.ServeHTTP(w, req.WithContext(ctx))
+	})
+}
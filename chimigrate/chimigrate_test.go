@@ -0,0 +1,141 @@
+//go:build chi
+
+package chimigrate
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	"github.com/catatsuy/saruta"
+	"github.com/go-chi/chi/v5"
+)
+
+func echo(w http.ResponseWriter, req *http.Request) {
+	rctx := chi.RouteContext(req.Context())
+	w.Write([]byte(rctx.RoutePattern() + " id=" + chi.URLParam(req, "id") + " *=" + chi.URLParam(req, "*")))
+}
+
+func TestFromChi(t *testing.T) {
+	mux := chi.NewRouter()
+	mux.Use(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			w.Header().Add("X-Mw", "outer")
+			next.ServeHTTP(w, req)
+		})
+	})
+	mux.Get("/users/{id:^[0-9]+$}", echo)
+	mux.Post("/users", echo)
+	mux.Get("/files/*", echo)
+	mux.Route("/api", func(r chi.Router) {
+		r.With(func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				w.Header().Add("X-Mw", "inner")
+				next.ServeHTTP(w, req)
+			})
+		}).Get("/items/{id}.json", echo)
+	})
+
+	r, err := FromChi(mux)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.MustCompile()
+
+	tests := []struct {
+		method, path string
+		code         int
+		body         string
+		mw           []string
+	}{
+		{http.MethodGet, "/users/42", 200, "/users/{id:^[0-9]+$} id=42 *=", []string{"outer"}},
+		{http.MethodGet, "/users/abc", 404, "", nil},
+		{http.MethodPost, "/users", 200, "/users id= *=", []string{"outer"}},
+		{http.MethodGet, "/files/a/b.txt", 200, "/files/* id= *=a/b.txt", []string{"outer"}},
+		{http.MethodGet, "/api/items/7.json", 200, "/api/items/{id}.json id=7 *=", []string{"outer", "inner"}},
+	}
+	for _, tc := range tests {
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, httptest.NewRequest(tc.method, tc.path, nil))
+		if rec.Code != tc.code {
+			t.Errorf("%s %s: status %d, want %d", tc.method, tc.path, rec.Code, tc.code)
+			continue
+		}
+		if tc.code != 200 {
+			continue
+		}
+		if rec.Body.String() != tc.body {
+			t.Errorf("%s %s: body %q, want %q", tc.method, tc.path, rec.Body, tc.body)
+		}
+		if got := rec.Header().Values("X-Mw"); !slices.Equal(got, tc.mw) {
+			t.Errorf("%s %s: middleware %v, want %v", tc.method, tc.path, got, tc.mw)
+		}
+	}
+}
+
+func TestFromChiWithoutPathValues(t *testing.T) {
+	mux := chi.NewRouter()
+	mux.Get("/users/{id}", echo)
+	r, err := FromChi(mux, saruta.WithoutPathValues())
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.MustCompile()
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/users/42", nil))
+	if want := "/users/{id} id=42 *="; rec.Body.String() != want {
+		t.Errorf("body %q, want %q", rec.Body, want)
+	}
+}
+
+func TestFromChiReportsUntranslatable(t *testing.T) {
+	mux := chi.NewRouter()
+	mux.Get("/ok/{id}", echo)
+	mux.Get("/path/{rest:.+}", echo)
+	mux.Get("/static*", echo)
+
+	r, err := FromChi(mux)
+	var untranslatable []string
+	for _, e := range err.(interface{ Unwrap() []error }).Unwrap() {
+		var ue *UntranslatableError
+		if !errors.As(e, &ue) {
+			t.Fatalf("error %v is not an *UntranslatableError", e)
+		}
+		untranslatable = append(untranslatable, ue.Pattern)
+	}
+	if want := []string{"/path/{rest:.+}", "/static*"}; !slices.Equal(untranslatable, want) {
+		t.Errorf("untranslatable = %v, want %v", untranslatable, want)
+	}
+
+	routes := r.Routes()
+	if len(routes) != 1 || routes[0].Pattern != "/ok/{id}" {
+		t.Errorf("routes = %+v", routes)
+	}
+	r.MustCompile()
+}
+
+func TestTranslatePattern(t *testing.T) {
+	tests := []struct {
+		chi, want string
+	}{
+		{"/", "/"},
+		{"/users/{id}", "/users/{id}"},
+		{"/users/{id:[0-9]+}", "/users/{id:[0-9]+}"},
+		{"/users/{id:^[a-z]+$}", "/users/{id:[a-z]+}"},
+		{"/img/{name}.{ext}", "/img/{name}.{ext}"},
+		{"/files/*", "/files/{wildcard...}"},
+	}
+	for _, tc := range tests {
+		got, _, err := translatePattern(tc.chi)
+		if err != nil || got != tc.want {
+			t.Errorf("translatePattern(%q) = %q, %v, want %q", tc.chi, got, err, tc.want)
+		}
+	}
+	for _, p := range []string{"/a/*/b", "/files*", "/{id", "/{id:[0-9]{3}}"} {
+		if got, _, err := translatePattern(p); err == nil {
+			t.Errorf("translatePattern(%q) = %q, want an error", p, got)
+		}
+	}
+}
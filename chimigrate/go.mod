@@ -0,0 +1,10 @@
+module github.com/catatsuy/saruta/chimigrate
+
+go 1.25
+
+require (
+	github.com/catatsuy/saruta v0.0.0
+	github.com/go-chi/chi/v5 v5.2.5
+)
+
+replace github.com/catatsuy/saruta => ..
@@ -0,0 +1,95 @@
+package saruta
+
+import (
+	"net/http"
+	"slices"
+)
+
+// Clone returns an independent copy of the registered route set: routes,
+// mounts, aliases, rewrites, sub-routers (cloned recursively), error
+// handlers, options and the middleware, metadata and prefix of r itself.
+// Registering on either router afterwards does not affect the other.
+//
+// The clone starts uncompiled and unfrozen, with fresh near-miss counters and
+// drain tracking, so a shared base definition can be specialized per test or
+// per server (admin vs public) and compiled on its own. Handlers the router
+// built for itself (StaticFS, MountDebug, MountDebugVars, MountRouteDebug)
+// serve from the clone: a StaticFS miss uses the clone's NotFound handler.
+func (r *Router) Clone() *Router {
+	return &Router{
+		state:      r.state.clone(map[*routerState]*routerState{}),
+		middleware: slices.Clone(r.middleware),
+		meta:       r.meta,
+		prefix:     r.prefix,
+	}
+}
+
+// clone copies s; seen maps already cloned states so a sub-router mounted
+// twice is cloned once.
+func (s *routerState) clone(seen map[*routerState]*routerState) *routerState {
+	if c, ok := seen[s]; ok {
+		return c
+	}
+	c := &routerState{
+		notFound:          s.notFound,
+		methodNotAllowed:  s.methodNotAllowed,
+		errorScopes:       slices.Clone(s.errorScopes),
+		routes:            slices.Clone(s.routes),
+		mounts:            slices.Clone(s.mounts),
+		aliases:           slices.Clone(s.aliases),
+		rewrites:          slices.Clone(s.rewrites),
+		subRouters:        slices.Clone(s.subRouters),
+		noise:             s.noise,
+		panicOnCompileErr: s.panicOnCompileErr,
+		copyParams:        s.copyParams,
+		autoOptions:       s.autoOptions,
+		errorMiddleware:   s.errorMiddleware,
+		drain:             &drainTracker{levels: make(map[int]*drainLevel)},
+		onCompile:         slices.Clone(s.onCompile),
+		onRegister:        slices.Clone(s.onRegister),
+		basePath:          s.basePath,
+		suggest:           s.suggest,
+		matchCache:        s.matchCache,
+		noPathValues:      s.noPathValues,
+		no405:             s.no405,
+	}
+	seen[s] = c
+	if s.nearMiss != nil {
+		c.nearMiss = &nearMissCounters{}
+	}
+	if s.vars != nil {
+		c.vars = newVarCounters()
+	}
+	for i, rt := range c.routes {
+		c.routes[i].middleware = slices.Clone(rt.middleware)
+		if rt.rebind != nil {
+			c.routes[i].handler = rt.rebind(&Router{state: c})
+		}
+	}
+	for i, es := range c.errorScopes {
+		c.errorScopes[i].middleware = slices.Clone(es.middleware)
+	}
+	for i, a := range c.aliases {
+		c.aliases[i].aliases = slices.Clone(a.aliases)
+	}
+	for i, sr := range c.subRouters {
+		c.subRouters[i].middleware = slices.Clone(sr.middleware)
+		if sr.router != nil {
+			c.subRouters[i].router = &Router{
+				state:      sr.router.state.clone(seen),
+				middleware: slices.Clone(sr.router.middleware),
+				meta:       sr.router.meta,
+				prefix:     sr.router.prefix,
+			}
+		}
+	}
+	return c
+}
+
+// handleBound registers method and pattern with the handler bind builds for
+// r. Clone calls bind again with the clone, so a handler that reads r (its
+// error handlers, compiled routes or counters) serves from the clone there.
+func (r *Router) handleBound(method, pattern string, bind func(*Router) http.Handler) {
+	r.Handle(method, pattern, bind(r))
+	r.state.routes[len(r.state.routes)-1].rebind = bind
+}
@@ -0,0 +1,118 @@
+package saruta
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+)
+
+func TestRouterClone(t *testing.T) {
+	body := func(s string) http.HandlerFunc {
+		return func(w http.ResponseWriter, req *http.Request) { w.Write([]byte(s)) }
+	}
+	sub := New()
+	sub.Get("/items", body("items"))
+
+	base := New(WithAutoOptions())
+	base.Use(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			w.Header().Set("X-Base", "1")
+			next.ServeHTTP(w, req)
+		})
+	})
+	base.Get("/health", body("ok"))
+	base.MountRouter("/shop", sub)
+	base.NotFound(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		http.Error(w, "custom", http.StatusNotFound)
+	}))
+
+	admin := base.Clone()
+	admin.Get("/admin", body("admin"))
+	admin.Remove(http.MethodGet, "/health")
+	base.Get("/public", body("public"))
+	sub.Get("/late", body("late"))
+
+	base.MustCompile()
+	admin.MustCompile()
+
+	serve := func(r *Router, method, path string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, httptest.NewRequest(method, path, nil))
+		return rec
+	}
+	for _, tc := range []struct {
+		r    *Router
+		path string
+		code int
+	}{
+		{r: base, path: "/health", code: http.StatusOK},
+		{r: base, path: "/public", code: http.StatusOK},
+		{r: base, path: "/admin", code: http.StatusNotFound},
+		{r: base, path: "/shop/late", code: http.StatusOK},
+		{r: admin, path: "/admin", code: http.StatusOK},
+		{r: admin, path: "/health", code: http.StatusNotFound},
+		{r: admin, path: "/public", code: http.StatusNotFound},
+		{r: admin, path: "/shop/items", code: http.StatusOK},
+		{r: admin, path: "/shop/late", code: http.StatusNotFound},
+	} {
+		if rec := serve(tc.r, http.MethodGet, tc.path); rec.Code != tc.code {
+			t.Fatalf("%s: status=%d, want %d", tc.path, rec.Code, tc.code)
+		}
+	}
+
+	rec := serve(admin, http.MethodGet, "/admin")
+	if rec.Header().Get("X-Base") != "1" {
+		t.Fatalf("clone lost middleware")
+	}
+	if rec := serve(admin, http.MethodGet, "/missing"); rec.Body.String() != "custom\n" {
+		t.Fatalf("clone lost NotFound handler: %q", rec.Body.String())
+	}
+	if rec := serve(admin, http.MethodOptions, "/admin"); rec.Code != http.StatusNoContent {
+		t.Fatalf("clone lost options: OPTIONS status=%d", rec.Code)
+	}
+}
+
+func TestRouterCloneUnfrozen(t *testing.T) {
+	r := New()
+	r.Get("/", func(w http.ResponseWriter, req *http.Request) {})
+	r.MustCompile()
+	r.Freeze()
+
+	c := r.Clone()
+	if c.Frozen() {
+		t.Fatalf("clone of a frozen router is frozen")
+	}
+	if c.Compiled() != nil {
+		t.Fatalf("clone is compiled")
+	}
+	c.Get("/extra", func(w http.ResponseWriter, req *http.Request) {})
+	c.MustCompile()
+}
+
+func TestRouterCloneStaticFSNotFound(t *testing.T) {
+	base := New()
+	base.StaticFS("/assets", fstest.MapFS{"app.css": {Data: []byte("body{}")}})
+	c := base.Clone()
+	c.NotFound(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	}))
+	base.MustCompile()
+	c.MustCompile()
+
+	for _, tc := range []struct {
+		r    *Router
+		path string
+		code int
+	}{
+		{base, "/assets/missing.css", http.StatusNotFound},
+		{c, "/assets/missing.css", http.StatusTeapot},
+		{c, "/assets/app.css", http.StatusOK},
+	} {
+		rec := httptest.NewRecorder()
+		tc.r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tc.path, nil))
+		if rec.Code != tc.code {
+			t.Errorf("GET %s: status %d, want %d", tc.path, rec.Code, tc.code)
+		}
+	}
+}
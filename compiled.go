@@ -0,0 +1,143 @@
+package saruta
+
+import "net/http"
+
+// CompiledRouter is the matching engine of a compiled Router, detached from
+// http.Request so that servers not built on net/http (fasthttp shims, Lambda
+// adapters) can route with it. It is immutable and safe for concurrent use.
+type CompiledRouter struct {
+	c *compiledState
+}
+
+// Compiled returns the tree built by the most recent successful Compile, or
+// nil if r has never been compiled. Later Compile calls do not change the
+// returned value; call Compiled again to pick them up.
+func (r *Router) Compiled() *CompiledRouter {
+	c := r.state.current.Load()
+	if c == nil {
+		return nil
+	}
+	return &CompiledRouter{c: c}
+}
+
+// Lookup matches method + path against the current tree without building an
+// http.Request, for custom servers, RPC gateways and offline tooling that
+// embed the router. It reports whether a route or mount handles the request;
+// captured values and the pattern are stored in params, which may be nil.
+// With a reused ParamBuffer a successful lookup does not allocate.
+//
+// Lookup is CompiledRouter.Lookup on the latest Compile; use that directly
+// to tell a 405 from a 404 or a mount from a route. Like ServeHTTP, Lookup
+// panics if r has not been compiled.
+func (r *Router) Lookup(method, path string, params *ParamBuffer) (http.Handler, bool) {
+	c := r.state.current.Load()
+	if c == nil {
+		panic("saruta: router is not compiled; call Compile or MustCompile before lookup")
+	}
+	cr := CompiledRouter{c: c}
+	h, _ := cr.Lookup(method, path, params)
+	return h, h != nil
+}
+
+// Flags describes the outcome of Lookup.
+type Flags uint8
+
+const (
+	// FlagMethodNotAllowed means the path matched a route that does not accept
+	// the method. The handler is nil and ParamBuffer.Allow lists the methods.
+	FlagMethodNotAllowed Flags = 1 << iota
+	// FlagMount means the handler is a mounted handler.
+	FlagMount
+	// FlagRewritten means a Rewrite rule applied; ParamBuffer.Path holds the
+	// rewritten path.
+	FlagRewritten
+)
+
+// ParamBuffer receives the result of Lookup. It can be reused across lookups;
+// Lookup resets it first. Values are substrings of the looked-up path.
+type ParamBuffer struct {
+	// Pattern is the registered pattern of the matched route.
+	Pattern string
+	// Path is the path used for matching, after rewrites.
+	Path string
+	// Allow is the Allow header value when FlagMethodNotAllowed is set.
+	Allow string
+
+	params paramStore
+}
+
+// Reset clears p. Storage for routes with more than eight path values is
+// returned to a pool.
+func (p *ParamBuffer) Reset() {
+	p.params.release()
+	*p = ParamBuffer{}
+}
+
+// Len returns the number of captured path values.
+func (p *ParamBuffer) Len() int {
+	return p.params.len()
+}
+
+// At returns the name and value of the i-th captured path value.
+func (p *ParamBuffer) At(i int) (name, value string) {
+	pp := p.params.at(i)
+	return pp.name, pp.value
+}
+
+// Get returns the value captured for name, or "" if there is none.
+func (p *ParamBuffer) Get(name string) string {
+	return p.params.get(name)
+}
+
+// Lookup routes method + path the way ServeHTTP does and returns the handler
+// to run, with captured values stored in ps (which may be nil). Rewrites and
+// mounts are applied; the noise filter, automatic OPTIONS responses and the
+// NotFound / MethodNotAllowed handlers are left to the caller.
+//
+// A nil handler without flags means no route matched. The returned handler
+// expects path values and req.Pattern to be set from ps, as ServeHTTP does.
+func (cr *CompiledRouter) Lookup(method, path string, ps *ParamBuffer) (http.Handler, Flags) {
+	if ps == nil {
+		ps = &ParamBuffer{}
+	} else {
+		ps.Reset()
+	}
+	if path == "" || path[0] != '/' {
+		return nil, 0
+	}
+	var flags Flags
+	c := cr.c
+	if c.rewriteRoot != nil {
+		if next, ok := rewriteTarget(c.rewriteRoot, path); ok {
+			path = next
+			flags |= FlagRewritten
+		}
+	}
+	ps.Path = path
+
+	if c.mountsFirst {
+		if m := c.root.findMount(path); m != nil && m.precedence == MountBeforeRoutes {
+			return m.handler, flags | FlagMount
+		}
+	}
+	if matched, ok := c.matchRoute(path); ok && matched.leaf.handlers.len() > 0 {
+		if h, ok := matched.leaf.handlers.get(method); ok {
+			ps.Pattern = matched.leaf.pattern
+			ps.params = matched.params
+			return h, flags
+		}
+		matched.params.release()
+		if !c.no405 {
+			if m := c.root.findMount(path); m != nil && m.precedence != MountAfterRoutes {
+				return m.handler, flags | FlagMount
+			}
+			ps.Pattern = matched.leaf.pattern
+			ps.Allow = matched.leaf.handlers.allow[0]
+			return nil, flags | FlagMethodNotAllowed
+		}
+	}
+	if m := c.root.findMount(path); m != nil {
+		return m.handler, flags | FlagMount
+	}
+	return nil, flags
+}
@@ -0,0 +1,121 @@
+package saruta
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCompiledRouterLookup(t *testing.T) {
+	r := New()
+	r.Get("/users/{id:[0-9]+}/files/{name}", func(w http.ResponseWriter, req *http.Request) {
+		w.Write([]byte("file"))
+	})
+	r.Post("/users", func(w http.ResponseWriter, req *http.Request) {})
+	r.Rewrite("/u/{id}/f/{name}", "/users/{id}/files/{name}")
+	r.Mount("/legacy", http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Write([]byte("legacy"))
+	}))
+
+	if r.Compiled() != nil {
+		t.Fatalf("Compiled before Compile should be nil")
+	}
+	r.MustCompile()
+	cr := r.Compiled()
+
+	var ps ParamBuffer
+	h, flags := cr.Lookup(http.MethodGet, "/users/42/files/a.txt", &ps)
+	if h == nil || flags != 0 {
+		t.Fatalf("Lookup matched=%v flags=%b", h != nil, flags)
+	}
+	if ps.Pattern != "/users/{id:[0-9]+}/files/{name}" || ps.Len() != 2 || ps.Get("id") != "42" || ps.Get("name") != "a.txt" {
+		t.Fatalf("params = %+v", ps)
+	}
+	if name, value := ps.At(1); name != "name" || value != "a.txt" {
+		t.Fatalf("At(1) = %q, %q", name, value)
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	if rec.Body.String() != "file" {
+		t.Fatalf("handler body = %q", rec.Body.String())
+	}
+
+	h, flags = cr.Lookup(http.MethodGet, "/u/7/f/b", &ps)
+	if h == nil || flags != FlagRewritten || ps.Path != "/users/7/files/b" || ps.Get("id") != "7" {
+		t.Fatalf("rewrite: matched=%v flags=%b params=%+v", h != nil, flags, ps)
+	}
+
+	h, flags = cr.Lookup(http.MethodGet, "/users", &ps)
+	if h != nil || flags != FlagMethodNotAllowed || ps.Allow != "POST" || ps.Pattern != "/users" {
+		t.Fatalf("405: matched=%v flags=%b params=%+v", h != nil, flags, ps)
+	}
+
+	h, flags = cr.Lookup(http.MethodGet, "/legacy/x", &ps)
+	if h == nil || flags != FlagMount {
+		t.Fatalf("mount: matched=%v flags=%b", h != nil, flags)
+	}
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/legacy/x", nil))
+	if rec.Body.String() != "legacy" {
+		t.Fatalf("mount body = %q", rec.Body.String())
+	}
+
+	h, flags = cr.Lookup(http.MethodGet, "/missing", &ps)
+	if h != nil || flags != 0 || ps.Len() != 0 {
+		t.Fatalf("404: matched=%v flags=%b params=%+v", h != nil, flags, ps)
+	}
+
+	if h, _ := cr.Lookup(http.MethodGet, "/users/1/files/x", nil); h == nil {
+		t.Fatalf("Lookup with nil ParamBuffer should match")
+	}
+
+	// A recompile does not change a CompiledRouter obtained earlier.
+	r.Remove(http.MethodPost, "/users")
+	r.MustCompile()
+	if _, flags := cr.Lookup(http.MethodGet, "/users", &ps); flags != FlagMethodNotAllowed {
+		t.Fatalf("old snapshot flags=%b, want method not allowed", flags)
+	}
+	if _, flags := r.Compiled().Lookup(http.MethodGet, "/users", &ps); flags != 0 {
+		t.Fatalf("new snapshot flags=%b, want not found", flags)
+	}
+}
+
+func TestCompiledRouterLookupNoAlloc(t *testing.T) {
+	r := New()
+	r.Get("/users/{id}", func(w http.ResponseWriter, req *http.Request) {})
+	r.MustCompile()
+	cr := r.Compiled()
+
+	var ps ParamBuffer
+	allocs := testing.AllocsPerRun(100, func() {
+		cr.Lookup(http.MethodGet, "/users/42", &ps)
+	})
+	if allocs != 0 {
+		t.Fatalf("Lookup allocs = %v, want 0", allocs)
+	}
+}
+
+func TestRouterLookup(t *testing.T) {
+	r := New()
+	r.Get("/users/{id}", func(w http.ResponseWriter, req *http.Request) {})
+	r.MustCompile()
+
+	var ps ParamBuffer
+	h, ok := r.Lookup(http.MethodGet, "/users/42", &ps)
+	if !ok || h == nil || ps.Pattern != "/users/{id}" || ps.Get("id") != "42" {
+		t.Fatalf("Lookup = %v, %v, %+v", h, ok, ps)
+	}
+	if h, ok := r.Lookup(http.MethodPost, "/users/42", nil); ok || h != nil {
+		t.Fatalf("POST Lookup = %v, %v, want no handler", h, ok)
+	}
+	if _, ok := r.Lookup(http.MethodGet, "/missing", &ps); ok {
+		t.Fatal("Lookup matched /missing")
+	}
+
+	allocs := testing.AllocsPerRun(100, func() {
+		r.Lookup(http.MethodGet, "/users/42", &ps)
+	})
+	if allocs != 0 {
+		t.Fatalf("Lookup allocs = %v, want 0", allocs)
+	}
+}
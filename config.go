@@ -0,0 +1,64 @@
+package saruta
+
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+)
+
+// Config holds a configuration snapshot that can be swapped atomically while
+// the router is serving.
+type Config[T any] struct {
+	p atomic.Pointer[T]
+}
+
+// NewConfig returns a Config holding v.
+func NewConfig[T any](v *T) *Config[T] {
+	c := &Config[T]{}
+	c.p.Store(v)
+	return c
+}
+
+// Load returns the current snapshot.
+func (c *Config[T]) Load() *T {
+	return c.p.Load()
+}
+
+// Store atomically replaces the snapshot. Requests already in flight keep
+// the snapshot they started with.
+func (c *Config[T]) Store(v *T) {
+	c.p.Store(v)
+}
+
+type configKey[T any] struct{}
+
+func (c *Config[T]) middleware() Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			ctx := context.WithValue(req.Context(), configKey[T]{}, c.Load())
+			next.ServeHTTP(w, req.WithContext(ctx))
+		})
+	}
+}
+
+// GroupConfigSource is implemented by *Config.
+type GroupConfigSource interface {
+	middleware() Middleware
+}
+
+// WithConfig returns a derived router (like With) whose routes see the
+// current snapshot of cfg via GroupConfig.
+//
+// Each request loads the snapshot once when it enters the route, so a
+// concurrent Store never changes the configuration seen mid-request. Nested
+// groups may attach a different *Config of the same type to override it.
+func (r *Router) WithConfig(cfg GroupConfigSource) *Router {
+	return r.With(cfg.middleware())
+}
+
+// GroupConfig returns the configuration snapshot of type T attached to the
+// matched route's group with WithConfig, or nil.
+func GroupConfig[T any](req *http.Request) *T {
+	v, _ := req.Context().Value(configKey[T]{}).(*T)
+	return v
+}
@@ -0,0 +1,48 @@
+package saruta
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type canaryConfig struct {
+	Variant string
+}
+
+func TestRouterGroupConfig(t *testing.T) {
+	stable := NewConfig(&canaryConfig{Variant: "stable"})
+	beta := NewConfig(&canaryConfig{Variant: "beta"})
+	h := func(w http.ResponseWriter, req *http.Request) {
+		if cfg := GroupConfig[canaryConfig](req); cfg != nil {
+			_, _ = w.Write([]byte(cfg.Variant))
+			return
+		}
+		_, _ = w.Write([]byte("none"))
+	}
+
+	r := New()
+	r.Get("/plain", h)
+	r.WithConfig(stable).Route("/api", func(api *Router) {
+		api.Get("/items", h)
+		api.WithConfig(beta).Get("/beta", h)
+	})
+	r.MustCompile()
+
+	serve := func(path string) string {
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		return rec.Body.String()
+	}
+
+	for path, want := range map[string]string{"/plain": "none", "/api/items": "stable", "/api/beta": "beta"} {
+		if got := serve(path); got != want {
+			t.Fatalf("%s = %q, want %q", path, got, want)
+		}
+	}
+
+	stable.Store(&canaryConfig{Variant: "canary"})
+	if got := serve("/api/items"); got != "canary" {
+		t.Fatalf("after swap = %q, want canary", got)
+	}
+}
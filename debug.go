@@ -0,0 +1,178 @@
+//go:build !tinygo
+
+package saruta
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/netip"
+	"runtime"
+	"runtime/debug"
+	"runtime/pprof"
+	"time"
+)
+
+// MountDebug registers runtime diagnostics endpoints under prefix:
+//
+//	GET {prefix}/goroutines  goroutine dump (text)
+//	GET {prefix}/gc          GC and memory statistics (JSON)
+//	GET {prefix}/buildinfo   module build information (text)
+//	GET {prefix}/router      the router's route table and counters (JSON)
+//	GET {prefix}/explain     TraceMatch for ?path=...&method=... (JSON)
+//	GET {prefix}/tree        DumpTree output (text)
+//	GET {prefix}/ui          route explorer page built on the three above
+//
+// The endpoints expose internals, so they are guarded by mw, for example an
+// IP filter from the middleware subpackage:
+//
+//	r.MountDebug("/debug", middleware.IPFilter("127.0.0.0/8", "10.0.0.0/8"))
+//
+// Without mw they answer only loopback clients and refuse others with 403.
+// Behind a reverse proxy every client may look local, so pass a guard there.
+func (r *Router) MountDebug(prefix string, mw ...Middleware) {
+	if len(mw) == 0 {
+		mw = []Middleware{loopbackOnly}
+	}
+	d := r.With(mw...)
+	d.Get(prefix+"/goroutines", func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		_ = pprof.Lookup("goroutine").WriteTo(w, 2)
+	})
+	d.Get(prefix+"/gc", func(w http.ResponseWriter, req *http.Request) {
+		writeDebugJSON(w, readGCReport())
+	})
+	d.Get(prefix+"/buildinfo", func(w http.ResponseWriter, req *http.Request) {
+		info, ok := debug.ReadBuildInfo()
+		if !ok {
+			http.Error(w, "build info unavailable", http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		_, _ = w.Write([]byte(info.String()))
+	})
+	d.handleBound(http.MethodGet, prefix+"/router", func(r *Router) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			writeDebugJSON(w, r.debugReport())
+		})
+	})
+	d.handleBound(http.MethodGet, prefix+"/explain", func(r *Router) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			q := req.URL.Query()
+			method := q.Get("method")
+			if method == "" {
+				method = http.MethodGet
+			}
+			writeDebugJSON(w, TraceMatch(r, q.Get("path"), method))
+		})
+	})
+	d.handleBound(http.MethodGet, prefix+"/tree", func(r *Router) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			var buf bytes.Buffer
+			if err := r.DumpTree(&buf); err != nil {
+				http.Error(w, err.Error(), http.StatusServiceUnavailable)
+				return
+			}
+			w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+			_, _ = w.Write(buf.Bytes())
+		})
+	})
+	d.Get(prefix+"/ui", func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Header().Set("Content-Security-Policy", "default-src 'self'; script-src 'unsafe-inline'; style-src 'unsafe-inline'")
+		_, _ = w.Write(debugUI)
+	})
+}
+
+//go:embed debug_ui.html
+var debugUI []byte
+
+type gcReport struct {
+	NumGC          int64         `json:"num_gc"`
+	LastGC         time.Time     `json:"last_gc"`
+	PauseTotal     time.Duration `json:"pause_total_ns"`
+	Goroutines     int           `json:"goroutines"`
+	HeapAlloc      uint64        `json:"heap_alloc"`
+	HeapInuse      uint64        `json:"heap_inuse"`
+	HeapObjects    uint64        `json:"heap_objects"`
+	Sys            uint64        `json:"sys"`
+	NextGC         uint64        `json:"next_gc"`
+	GCCPUFraction  float64       `json:"gc_cpu_fraction"`
+	TotalAllocated uint64        `json:"total_alloc"`
+}
+
+func readGCReport() gcReport {
+	var stats debug.GCStats
+	debug.ReadGCStats(&stats)
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+	return gcReport{
+		NumGC:          stats.NumGC,
+		LastGC:         stats.LastGC,
+		PauseTotal:     stats.PauseTotal,
+		Goroutines:     runtime.NumGoroutine(),
+		HeapAlloc:      mem.HeapAlloc,
+		HeapInuse:      mem.HeapInuse,
+		HeapObjects:    mem.HeapObjects,
+		Sys:            mem.Sys,
+		NextGC:         mem.NextGC,
+		GCCPUFraction:  mem.GCCPUFraction,
+		TotalAllocated: mem.TotalAlloc,
+	}
+}
+
+type debugRoute struct {
+	Method  string   `json:"method"`
+	Pattern string   `json:"pattern"`
+	Aliases []string `json:"aliases,omitempty"`
+	Meta    Meta     `json:"meta,omitempty"`
+}
+
+type debugReport struct {
+	Compiled   bool             `json:"compiled"`
+	Routes     []debugRoute     `json:"routes"`
+	Warnings   []CompileWarning `json:"warnings,omitempty"`
+	NearMisses NearMissStats    `json:"near_misses"`
+}
+
+// debugReport describes the compiled state serving requests, which unlike
+// the registration state can be read while other goroutines register routes.
+func (r *Router) debugReport() debugReport {
+	out := debugReport{NearMisses: r.NearMisses()}
+	c := r.state.current.Load()
+	if c == nil {
+		return out
+	}
+	routes := routeInfos(c.reg)
+	out.Compiled = true
+	out.Routes = make([]debugRoute, 0, len(routes))
+	out.Warnings = c.report.Warnings
+	for _, rt := range routes {
+		out.Routes = append(out.Routes, debugRoute{Method: rt.Method, Pattern: rt.Pattern, Aliases: rt.Aliases, Meta: rt.Meta})
+	}
+	return out
+}
+
+// loopbackOnly is the guard MountDebug uses when none is given.
+func loopbackOnly(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		host, _, err := net.SplitHostPort(req.RemoteAddr)
+		if err != nil {
+			host = req.RemoteAddr
+		}
+		if addr, err := netip.ParseAddr(host); err != nil || !addr.Unmap().IsLoopback() {
+			writeProblem(w, http.StatusForbidden, "debug endpoints are only served to loopback clients")
+			return
+		}
+		next.ServeHTTP(w, req)
+	})
+}
+
+func writeDebugJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	_ = enc.Encode(v)
+}
@@ -0,0 +1,106 @@
+//go:build !tinygo
+
+package saruta
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRouterMountDebug(t *testing.T) {
+	onlyLocal := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			if !strings.HasPrefix(req.RemoteAddr, "127.0.0.1:") {
+				http.Error(w, "forbidden", http.StatusForbidden)
+				return
+			}
+			next.ServeHTTP(w, req)
+		})
+	}
+
+	r := New()
+	r.Get("/users/{id}", func(w http.ResponseWriter, req *http.Request) {})
+	r.MountDebug("/debug", onlyLocal)
+	r.MustCompile()
+
+	serve := func(path, remote string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.RemoteAddr = remote
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+		return rec
+	}
+
+	if rec := serve("/debug/goroutines", "10.0.0.1:1234"); rec.Code != http.StatusForbidden {
+		t.Fatalf("remote status = %d, want %d", rec.Code, http.StatusForbidden)
+	}
+	if rec := serve("/debug/goroutines", "127.0.0.1:1234"); !strings.Contains(rec.Body.String(), "goroutine") {
+		t.Fatalf("goroutine dump = %q", rec.Body.String())
+	}
+
+	var gc gcReport
+	if err := json.Unmarshal(serve("/debug/gc", "127.0.0.1:1").Body.Bytes(), &gc); err != nil {
+		t.Fatal(err)
+	}
+	if gc.Goroutines == 0 || gc.Sys == 0 {
+		t.Fatalf("gc report = %+v", gc)
+	}
+
+	var report debugReport
+	if err := json.Unmarshal(serve("/debug/router", "127.0.0.1:1").Body.Bytes(), &report); err != nil {
+		t.Fatal(err)
+	}
+	if !report.Compiled || len(report.Routes) != 8 {
+		t.Fatalf("router report = %+v", report)
+	}
+
+	var trace MatchTrace
+	if err := json.Unmarshal(serve("/debug/explain?path=/users/1", "127.0.0.1:1").Body.Bytes(), &trace); err != nil {
+		t.Fatal(err)
+	}
+	if trace.Result != "matched" || trace.Pattern != "/users/{id}" || trace.Method != http.MethodGet {
+		t.Fatalf("explain = %+v", trace)
+	}
+
+	if tree := serve("/debug/tree", "127.0.0.1:1").Body.String(); !strings.Contains(tree, "{id} [GET] /users/{id}") {
+		t.Fatalf("tree = %q", tree)
+	}
+	ui := serve("/debug/ui", "127.0.0.1:1")
+	if ct := ui.Header().Get("Content-Type"); ct != "text/html; charset=utf-8" {
+		t.Fatalf("ui Content-Type = %q", ct)
+	}
+	for _, endpoint := range []string{`fetch("router")`, `fetch("tree")`, `fetch("explain?"`} {
+		if !strings.Contains(ui.Body.String(), endpoint) {
+			t.Fatalf("ui page does not call %s", endpoint)
+		}
+	}
+	if rec := serve("/debug/ui", "10.0.0.1:1"); rec.Code != http.StatusForbidden {
+		t.Fatalf("remote ui status = %d, want %d", rec.Code, http.StatusForbidden)
+	}
+}
+
+func TestRouterMountDebugDefaultsToLoopback(t *testing.T) {
+	r := New()
+	r.MountDebug("/debug")
+	r.MustCompile()
+
+	for remote, want := range map[string]int{
+		"127.0.0.1:1234":   http.StatusOK,
+		"[::1]:1234":       http.StatusOK,
+		"10.0.0.1:1234":    http.StatusForbidden,
+		"192.0.2.1:1234":   http.StatusForbidden,
+		"[2001:db8::1]:80": http.StatusForbidden,
+		"":                 http.StatusForbidden,
+	} {
+		req := httptest.NewRequest(http.MethodGet, "/debug/router", nil)
+		req.RemoteAddr = remote
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+		if rec.Code != want {
+			t.Errorf("remote %q: status = %d, want %d", remote, rec.Code, want)
+		}
+	}
+}
@@ -0,0 +1,63 @@
+//go:build !tinygo
+
+package saruta
+
+import (
+	"encoding/json"
+	"expvar"
+	"net/http"
+	"sort"
+)
+
+// MountDebugVars registers GET pattern (typically "/debug/vars") serving the
+// variables published with expvar, as expvar.Handler does, plus a "saruta"
+// variable with the router's counters:
+//
+//	{"matches": 1042, "not_found": 17, "method_not_allowed": 2,
+//	 "routes": {"GET /users/{id}": 1000, "POST /users": 42}}
+//
+// Counting starts with the next Compile and costs one atomic increment per
+// request; routers that never call MountDebugVars do not count. Route hits
+// are keyed by method and pattern (aliases count toward their route) and
+// survive recompiles.
+//
+// The variables include the process command line and memory statistics, so
+// guard the endpoint with mw as for MountDebug. Without mw it answers only
+// loopback clients.
+func (r *Router) MountDebugVars(pattern string, mw ...Middleware) {
+	r.checkFrozen("MountDebugVars")
+	if r.state.vars == nil {
+		r.state.vars = newVarCounters()
+	}
+	if len(mw) == 0 {
+		mw = []Middleware{loopbackOnly}
+	}
+	r.With(mw...).handleBound(http.MethodGet, pattern, func(r *Router) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			w.Header().Set("Content-Type", "application/json; charset=utf-8")
+			out := make(map[string]json.RawMessage)
+			expvar.Do(func(kv expvar.KeyValue) {
+				out[kv.Key] = json.RawMessage(kv.Value.String())
+			})
+			if b, err := json.Marshal(r.state.vars.snapshot()); err == nil {
+				out["saruta"] = b
+			}
+			keys := make([]string, 0, len(out))
+			for k := range out {
+				keys = append(keys, k)
+			}
+			sort.Strings(keys)
+			_, _ = w.Write([]byte("{\n"))
+			for i, k := range keys {
+				if i > 0 {
+					_, _ = w.Write([]byte(",\n"))
+				}
+				name, _ := json.Marshal(k)
+				_, _ = w.Write(name)
+				_, _ = w.Write([]byte(": "))
+				_, _ = w.Write(out[k])
+			}
+			_, _ = w.Write([]byte("\n}\n"))
+		})
+	})
+}
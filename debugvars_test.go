@@ -0,0 +1,84 @@
+//go:build !tinygo
+
+package saruta
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRouterMountDebugVars(t *testing.T) {
+	r := New()
+	r.Get("/users/{id}", func(w http.ResponseWriter, req *http.Request) {})
+	r.Alias("/users/{id}", "/u/{id}")
+	r.Post("/users", func(w http.ResponseWriter, req *http.Request) {})
+	r.MountDebugVars("/debug/vars")
+	r.MustCompile()
+
+	for _, tc := range []struct{ method, path string }{
+		{http.MethodGet, "/users/1"},
+		{http.MethodGet, "/u/2"},
+		{http.MethodPost, "/users"},
+		{http.MethodGet, "/missing"},
+		{http.MethodDelete, "/users"},
+	} {
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(tc.method, tc.path, nil))
+	}
+	r.MustCompile() // counters survive recompiles
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, loopbackRequest("/debug/vars"))
+	var out struct {
+		Memstats json.RawMessage `json:"memstats"`
+		Saruta   routerVars      `json:"saruta"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &out); err != nil {
+		t.Fatalf("%v: %s", err, rec.Body.String())
+	}
+	if len(out.Memstats) == 0 {
+		t.Fatal("expvar memstats missing")
+	}
+	got := out.Saruta
+	// The /debug/vars request itself is counted before the handler runs.
+	if got.Matches != 4 || got.NotFound != 1 || got.MethodNotAllowed != 1 {
+		t.Fatalf("counters = %+v", got)
+	}
+	if got.Routes["GET /users/{id}"] != 2 || got.Routes["POST /users"] != 1 || got.Routes["GET /debug/vars"] != 1 {
+		t.Fatalf("route hits = %v", got.Routes)
+	}
+}
+
+func TestRouterMountDebugVarsClone(t *testing.T) {
+	base := New()
+	base.Get("/ping", func(w http.ResponseWriter, req *http.Request) {})
+	base.MountDebugVars("/debug/vars")
+	c := base.Clone()
+	c.MustCompile()
+
+	c.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/ping", nil))
+	rec := httptest.NewRecorder()
+	c.ServeHTTP(rec, loopbackRequest("/debug/vars"))
+	var out struct {
+		Saruta routerVars `json:"saruta"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &out); err != nil {
+		t.Fatalf("%v: %s", err, rec.Body.String())
+	}
+	if out.Saruta.Routes["GET /ping"] != 1 {
+		t.Fatalf("clone route hits = %v", out.Saruta.Routes)
+	}
+
+	rec = httptest.NewRecorder()
+	c.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/debug/vars", nil))
+	if rec.Code != http.StatusForbidden {
+		t.Fatalf("remote client: status = %d, want 403", rec.Code)
+	}
+}
+
+func loopbackRequest(target string) *http.Request {
+	req := httptest.NewRequest(http.MethodGet, target, nil)
+	req.RemoteAddr = "127.0.0.1:1234"
+	return req
+}
@@ -0,0 +1,75 @@
+package saruta
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Deprecation describes a deprecated route. See Deprecated.
+type Deprecation struct {
+	// Since is when the route was deprecated. Zero sends "Deprecation: true".
+	Since time.Time `json:"since,omitzero"`
+	// Sunset is when the route is expected to stop working. Zero omits the
+	// Sunset header.
+	Sunset time.Time `json:"sunset,omitzero"`
+	// Link points to migration documentation or the successor endpoint.
+	Link string `json:"link,omitempty"`
+}
+
+// String summarizes d for reports, e.g. "deprecated; sunset 2027-01-31".
+func (d Deprecation) String() string {
+	parts := []string{"deprecated"}
+	if !d.Since.IsZero() {
+		parts = append(parts, "since "+d.Since.UTC().Format(time.DateOnly))
+	}
+	if !d.Sunset.IsZero() {
+		parts = append(parts, "sunset "+d.Sunset.UTC().Format(time.DateOnly))
+	}
+	if d.Link != "" {
+		parts = append(parts, "see "+d.Link)
+	}
+	return strings.Join(parts, "; ")
+}
+
+// Deprecated returns a derived router whose routes are marked deprecated:
+//
+//	old := r.Deprecated(saruta.Deprecation{
+//		Sunset: time.Date(2027, 1, 31, 0, 0, 0, 0, time.UTC),
+//		Link:   "https://example.com/docs/migrate-v2",
+//	})
+//	old.Get("/v1/users", listUsersV1)
+//
+// Responses of those routes carry Deprecation (RFC 9745), Sunset (RFC 8594)
+// and Link rel="deprecation" headers, unless the handler overrides them. d
+// is stored under MetaDeprecated, so Routes, Walk, the governance report and
+// MountDebug list the route as deprecated.
+func (r *Router) Deprecated(d Deprecation) *Router {
+	return r.WithMeta(MetaDeprecated, d)
+}
+
+func (d Deprecation) wrap(next http.Handler) http.Handler {
+	deprecation := "true"
+	if !d.Since.IsZero() {
+		deprecation = "@" + strconv.FormatInt(d.Since.Unix(), 10)
+	}
+	var sunset, link string
+	if !d.Sunset.IsZero() {
+		sunset = d.Sunset.UTC().Format(http.TimeFormat)
+	}
+	if d.Link != "" {
+		link = "<" + d.Link + `>; rel="deprecation"`
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		h := w.Header()
+		h.Set("Deprecation", deprecation)
+		if sunset != "" {
+			h.Set("Sunset", sunset)
+		}
+		if link != "" {
+			h.Add("Link", link)
+		}
+		next.ServeHTTP(w, req)
+	})
+}
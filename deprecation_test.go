@@ -0,0 +1,56 @@
+package saruta
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRouterDeprecated(t *testing.T) {
+	h := func(w http.ResponseWriter, req *http.Request) { _, _ = w.Write([]byte("ok")) }
+	r := New()
+	r.Deprecated(Deprecation{
+		Since:  time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+		Sunset: time.Date(2027, 1, 31, 0, 0, 0, 0, time.UTC),
+		Link:   "https://example.com/migrate",
+	}).Get("/v1/users", h)
+	r.Deprecated(Deprecation{}).Get("/v1/groups", h)
+	r.Get("/v2/users", h)
+	r.MustCompile()
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v1/users", nil))
+	for name, want := range map[string]string{
+		"Deprecation": "@1767225600",
+		"Sunset":      "Sun, 31 Jan 2027 00:00:00 GMT",
+		"Link":        `<https://example.com/migrate>; rel="deprecation"`,
+	} {
+		if got := rec.Header().Get(name); got != want {
+			t.Fatalf("%s = %q, want %q", name, got, want)
+		}
+	}
+
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v1/groups", nil))
+	if rec.Header().Get("Deprecation") != "true" || rec.Header().Get("Sunset") != "" || rec.Header().Get("Link") != "" {
+		t.Fatalf("headers = %v", rec.Header())
+	}
+
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v2/users", nil))
+	if rec.Header().Get("Deprecation") != "" {
+		t.Fatalf("non-deprecated route has Deprecation header")
+	}
+
+	report := BuildGovernanceReport(r)
+	if report.Summary.Deprecated != 2 {
+		t.Fatalf("deprecated count = %d, want 2", report.Summary.Deprecated)
+	}
+	for _, e := range report.Routes {
+		if e.Pattern == "/v1/users" && !strings.Contains(e.Deprecated, "sunset 2027-01-31") {
+			t.Fatalf("governance entry = %q", e.Deprecated)
+		}
+	}
+}
@@ -0,0 +1,132 @@
+package saruta
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"sync"
+)
+
+// MetaDrainPriority holds the drain priority (int) set by WithDrainPriority.
+const MetaDrainPriority = "drain_priority"
+
+// WithDrainPriority returns a derived router whose routes are tracked in
+// flight and drained by Drain at priority p.
+//
+// Higher priorities drain first. Requests at priority 0 or above are left to
+// finish; requests below 0 (long polls, streams) have their context canceled
+// when Drain reaches their level.
+func (r *Router) WithDrainPriority(p int) *Router {
+	return r.WithMeta(MetaDrainPriority, p)
+}
+
+// Drain waits for in-flight requests on routes registered with
+// WithDrainPriority, one priority level at a time from the highest down, and
+// cancels the request contexts of negative levels when their turn comes.
+// Requests arriving at a level that has been canceled start with a canceled
+// context.
+//
+// If ctx ends first, the contexts of all remaining requests are canceled and
+// ctx.Err() is returned. Routes without a drain priority are not tracked;
+// http.Server.Shutdown waits for them. A typical setup starts Drain when the
+// server shuts down:
+//
+//	srv.RegisterOnShutdown(func() { go r.Drain(shutdownCtx) })
+func (r *Router) Drain(ctx context.Context) error {
+	t := r.state.drain
+	t.mu.Lock()
+	levels := make([]*drainLevel, 0, len(t.levels))
+	for _, l := range t.levels {
+		levels = append(levels, l)
+	}
+	t.mu.Unlock()
+	slices.SortFunc(levels, func(a, b *drainLevel) int { return b.priority - a.priority })
+
+	for _, l := range levels {
+		if l.priority < 0 {
+			l.cancel()
+		}
+		if err := l.wait(ctx); err != nil {
+			for _, l := range levels {
+				l.cancel()
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// drainTracker keeps one level per priority across compiles, so requests
+// served by an older tree are still drained. mu guards levels, which Compile
+// may extend while Drain runs.
+type drainTracker struct {
+	mu     sync.Mutex
+	levels map[int]*drainLevel
+}
+
+type drainLevel struct {
+	priority int
+	ctx      context.Context
+	cancel   context.CancelFunc
+
+	mu     sync.Mutex
+	active int
+	idle   chan struct{}
+}
+
+func (t *drainTracker) wrap(priority int, next http.Handler) http.Handler {
+	t.mu.Lock()
+	l, ok := t.levels[priority]
+	if !ok {
+		ctx, cancel := context.WithCancel(context.Background())
+		l = &drainLevel{priority: priority, ctx: ctx, cancel: cancel}
+		t.levels[priority] = l
+	}
+	t.mu.Unlock()
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		l.mu.Lock()
+		l.active++
+		l.mu.Unlock()
+		defer l.done()
+
+		ctx, cancel := context.WithCancel(req.Context())
+		defer cancel()
+		if l.ctx.Err() != nil {
+			cancel()
+		} else {
+			stop := context.AfterFunc(l.ctx, cancel)
+			defer stop()
+		}
+		next.ServeHTTP(w, req.WithContext(ctx))
+	})
+}
+
+func (l *drainLevel) done() {
+	l.mu.Lock()
+	l.active--
+	if l.active == 0 && l.idle != nil {
+		close(l.idle)
+		l.idle = nil
+	}
+	l.mu.Unlock()
+}
+
+func (l *drainLevel) wait(ctx context.Context) error {
+	l.mu.Lock()
+	if l.active == 0 {
+		l.mu.Unlock()
+		return nil
+	}
+	if l.idle == nil {
+		l.idle = make(chan struct{})
+	}
+	idle := l.idle
+	l.mu.Unlock()
+
+	select {
+	case <-idle:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,101 @@
+package saruta
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestRouterDrainPriorities(t *testing.T) {
+	started := make(chan string, 2)
+	releasePayment := make(chan struct{})
+	pollCanceled := make(chan struct{})
+
+	r := New()
+	r.WithDrainPriority(10).Post("/payments", func(w http.ResponseWriter, req *http.Request) {
+		started <- "payment"
+		<-releasePayment
+	})
+	r.WithDrainPriority(-1).Get("/poll", func(w http.ResponseWriter, req *http.Request) {
+		started <- "poll"
+		<-req.Context().Done()
+		close(pollCanceled)
+	})
+	r.MustCompile()
+
+	go r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/payments", nil))
+	go r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/poll", nil))
+	<-started
+	<-started
+
+	drained := make(chan error, 1)
+	go func() { drained <- r.Drain(context.Background()) }()
+
+	select {
+	case <-pollCanceled:
+		t.Fatal("long poll canceled before payment finished")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	close(releasePayment)
+	select {
+	case <-pollCanceled:
+	case <-time.After(time.Second):
+		t.Fatal("long poll not canceled after payment finished")
+	}
+	if err := <-drained; err != nil {
+		t.Fatalf("Drain error = %v", err)
+	}
+
+	var ctxErr error
+	r2 := New()
+	r2.WithDrainPriority(-1).Get("/late", func(w http.ResponseWriter, req *http.Request) {
+		ctxErr = req.Context().Err()
+	})
+	r2.MustCompile()
+	if err := r2.Drain(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	r2.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/late", nil))
+	if ctxErr == nil {
+		t.Fatal("request after drain should start canceled")
+	}
+}
+
+func TestRouterDrainDeadline(t *testing.T) {
+	started := make(chan struct{})
+	canceled := make(chan struct{})
+
+	r := New()
+	r.WithDrainPriority(5).Post("/payments", func(w http.ResponseWriter, req *http.Request) {
+		close(started)
+		<-req.Context().Done()
+		close(canceled)
+	})
+	r.MustCompile()
+
+	go r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/payments", nil))
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := r.Drain(ctx); err != context.DeadlineExceeded {
+		t.Fatalf("Drain error = %v, want %v", err, context.DeadlineExceeded)
+	}
+	select {
+	case <-canceled:
+	case <-time.After(time.Second):
+		t.Fatal("request not canceled after drain deadline")
+	}
+}
+
+func TestRouterDrainWithoutPriorities(t *testing.T) {
+	r := New()
+	r.Get("/", func(w http.ResponseWriter, req *http.Request) {})
+	r.MustCompile()
+	if err := r.Drain(context.Background()); err != nil {
+		t.Fatalf("Drain error = %v", err)
+	}
+}
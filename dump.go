@@ -0,0 +1,58 @@
+package saruta
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// DumpTree writes the compiled radix tree, one edge per line, indented by
+// depth. Each line shows the edge (a quoted static label, or a {param} /
+// {name...} edge that consumes one "/"-prefixed segment / the rest of the
+// path) followed by the methods registered at the node, its pattern, and any
+// mount or rewrite stored there:
+//
+//	"users" [GET POST] /users
+//	  "/me" [GET] /users/me
+//	  {id:[0-9]+} [GET] /users/{id:[0-9]+}
+//
+// Static edges are listed before the param and catch-all edges of a node,
+// which is also the order in which matching tries them.
+func (r *Router) DumpTree(w io.Writer) error {
+	c := r.state.current.Load()
+	if c == nil {
+		return errors.New("saruta: router is not compiled")
+	}
+	bw := bufio.NewWriter(w)
+	dumpNode(bw, c.root, c.root.node(0), "(root)", 0)
+	return bw.Flush()
+}
+
+func dumpNode(w *bufio.Writer, t *radixTree, n *radixNode, edge string, depth int) {
+	w.WriteString(strings.Repeat("  ", depth))
+	w.WriteString(edge)
+	leaf := t.leaf(n)
+	if leaf.handlers.len() > 0 {
+		methods := leaf.handlers.methods()
+		fmt.Fprintf(w, " [%s] %s", strings.Join(methods, " "), leaf.pattern)
+	}
+	if leaf.mount != nil {
+		w.WriteString(" (mount)")
+	}
+	if leaf.rewrite != nil {
+		w.WriteString(" (rewrite)")
+	}
+	w.WriteString("\n")
+
+	for _, e := range t.staticEdges(n) {
+		dumpNode(w, t, t.node(e.next), fmt.Sprintf("%q", t.label(e)), depth+1)
+	}
+	if pe := t.paramChild(n); pe != nil {
+		dumpNode(w, t, t.node(pe.next), pe.label(), depth+1)
+	}
+	if pe := t.catchAllChild(n); pe != nil {
+		dumpNode(w, t, t.node(pe.next), pe.catchAllLabel(), depth+1)
+	}
+}
@@ -0,0 +1,40 @@
+package saruta
+
+import (
+	"bytes"
+	"net/http"
+	"testing"
+)
+
+func TestRouterDumpTree(t *testing.T) {
+	noop := func(w http.ResponseWriter, req *http.Request) {}
+	r := New()
+	if err := r.DumpTree(&bytes.Buffer{}); err == nil {
+		t.Fatal("expected error before Compile")
+	}
+
+	r.Get("/users", noop)
+	r.Post("/users", noop)
+	r.Get("/users/me", noop)
+	r.Get("/users/{id:[0-9]+}", noop)
+	r.Get("/files/{path...}", noop)
+	r.Mount("/legacy", http.NotFoundHandler())
+	r.MustCompile()
+
+	var buf bytes.Buffer
+	if err := r.DumpTree(&buf); err != nil {
+		t.Fatal(err)
+	}
+	want := `(root)
+  "/"
+    "files"
+      {path...} [GET] /files/{path...}
+    "legacy" (mount)
+    "users" [GET POST] /users
+      "/me" [GET] /users/me
+      {id:[0-9]+} [GET] /users/{id:[0-9]+}
+`
+	if buf.String() != want {
+		t.Fatalf("DumpTree =\n%s\nwant\n%s", buf.String(), want)
+	}
+}
@@ -0,0 +1,41 @@
+package saruta
+
+import (
+	"net/http"
+	"slices"
+)
+
+// MetaEarlyHints holds the Link header values ([]string) set by
+// WithEarlyHints.
+const MetaEarlyHints = "early_hints"
+
+// WithEarlyHints returns a derived router whose routes answer with a 103
+// Early Hints response carrying links as Link headers before the handler
+// runs, so that browsers start fetching the page's assets while the handler
+// is still working:
+//
+//	pages := r.WithEarlyHints(
+//		"</app.css>; rel=preload; as=style",
+//		"</app.js>; rel=modulepreload",
+//	)
+//
+// Links accumulate over nested calls. They stay in the header map, so the
+// final response carries them as well. HTTP/1.0 clients, which cannot
+// receive informational responses, get no hints.
+func (r *Router) WithEarlyHints(links ...string) *Router {
+	inherited, _ := r.meta[MetaEarlyHints].([]string)
+	return r.WithMeta(MetaEarlyHints, append(slices.Clip(inherited), links...))
+}
+
+func sendEarlyHints(links []string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.ProtoAtLeast(1, 1) {
+			h := w.Header()
+			for _, link := range links {
+				h.Add("Link", link)
+			}
+			w.WriteHeader(http.StatusEarlyHints)
+		}
+		next.ServeHTTP(w, req)
+	})
+}
@@ -0,0 +1,80 @@
+package saruta
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/http/httptrace"
+	"net/textproto"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestWithEarlyHints(t *testing.T) {
+	r := New()
+	pages := r.WithEarlyHints("</app.css>; rel=preload; as=style")
+	pages.WithEarlyHints("</app.js>; rel=modulepreload").Get("/app", func(w http.ResponseWriter, req *http.Request) {
+		w.Write([]byte("app"))
+	})
+	pages.WithMaxBodySize(1).Post("/upload", func(w http.ResponseWriter, req *http.Request) {})
+	r.Get("/plain", func(w http.ResponseWriter, req *http.Request) {})
+	r.MustCompile()
+	srv := httptest.NewServer(r)
+	defer srv.Close()
+
+	get := func(method, path, body string) (hints [][]string, res *http.Response) {
+		t.Helper()
+		trace := &httptrace.ClientTrace{
+			Got1xxResponse: func(code int, header textproto.MIMEHeader) error {
+				if code == http.StatusEarlyHints {
+					hints = append(hints, header.Values("Link"))
+				}
+				return nil
+			},
+		}
+		ctx := httptrace.WithClientTrace(context.Background(), trace)
+		req, _ := http.NewRequestWithContext(ctx, method, srv.URL+path, strings.NewReader(body))
+		res, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		res.Body.Close()
+		return hints, res
+	}
+
+	hints, res := get(http.MethodGet, "/app", "")
+	want := []string{"</app.css>; rel=preload; as=style", "</app.js>; rel=modulepreload"}
+	if len(hints) != 1 || !slices.Equal(hints[0], want) {
+		t.Errorf("hints = %q, want one 103 with %q", hints, want)
+	}
+	if res.StatusCode != http.StatusOK || !slices.Equal(res.Header.Values("Link"), want) {
+		t.Errorf("final response %d, Link %q", res.StatusCode, res.Header.Values("Link"))
+	}
+
+	if hints, res := get(http.MethodPost, "/upload", "too large"); len(hints) != 0 || res.StatusCode != http.StatusRequestEntityTooLarge {
+		t.Errorf("rejected upload: hints %q, status %d", hints, res.StatusCode)
+	}
+	if hints, _ := get(http.MethodGet, "/plain", ""); len(hints) != 0 {
+		t.Errorf("route without hints sent %q", hints)
+	}
+
+	for _, info := range r.Routes() {
+		if info.Pattern == "/app" && !slices.Equal(info.Meta[MetaEarlyHints].([]string), want) {
+			t.Errorf("meta = %v", info.Meta[MetaEarlyHints])
+		}
+	}
+}
+
+func TestEarlyHintsSkipHTTP10(t *testing.T) {
+	r := New()
+	r.WithEarlyHints("</a.css>; rel=preload").Get("/", func(w http.ResponseWriter, req *http.Request) {})
+	r.MustCompile()
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Proto, req.ProtoMajor, req.ProtoMinor = "HTTP/1.0", 1, 0
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	if rec.Header().Get("Link") != "" {
+		t.Errorf("Link = %q for an HTTP/1.0 request", rec.Header().Get("Link"))
+	}
+}
@@ -0,0 +1,176 @@
+//go:build !tinygo
+
+package saruta
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// Edge rule kinds.
+const (
+	// EdgeExact matches Path exactly.
+	EdgeExact = "exact"
+	// EdgePrefix matches Path and everything below it (mounts).
+	EdgePrefix = "prefix"
+	// EdgePattern matches Regex; Path is its static prefix, for edges that
+	// only support prefix rules.
+	EdgePattern = "pattern"
+)
+
+// EdgeRule is one path shape that reaches the origin, for CDN or WAF
+// configuration.
+type EdgeRule struct {
+	Kind  string `json:"kind"`
+	Path  string `json:"path"`
+	Regex string `json:"regex,omitempty"`
+	// Methods lists the accepted methods; it is empty for mounts and rewrites,
+	// which accept any method.
+	Methods []string `json:"methods,omitempty"`
+}
+
+// BuildEdgeRules derives edge rules from the compiled tree of r: an exact rule
+// per static route, a pattern rule (an anchored regular expression) per route
+// with parameters, and a prefix rule per mount. Rewrite sources are included
+// as well, since they are valid request shapes. Rules are sorted by path.
+//
+// Parameter regular expressions describe the accepted shape; the origin still
+// applies its own matching. BuildEdgeRules returns an error if r is not
+// compiled.
+func BuildEdgeRules(r *Router) ([]EdgeRule, error) {
+	c := r.state.current.Load()
+	if c == nil {
+		return nil, errors.New("saruta: router is not compiled")
+	}
+	var rules []EdgeRule
+	collectEdgeRules(&rules, c.root, c.root.node(0), "", "", true)
+	if c.rewriteRoot != nil {
+		collectEdgeRules(&rules, c.rewriteRoot, c.rewriteRoot.node(0), "", "", true)
+	}
+	slices.SortStableFunc(rules, func(a, b EdgeRule) int {
+		if c := strings.Compare(a.Path, b.Path); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Regex, b.Regex)
+	})
+	return rules, nil
+}
+
+// WriteEdgeRules writes the edge rules of r to w as JSON or CSV.
+func WriteEdgeRules(w io.Writer, r *Router, format ReportFormat) error {
+	rules, err := BuildEdgeRules(r)
+	if err != nil {
+		return err
+	}
+	switch format {
+	case ReportJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(rules)
+	case ReportCSV:
+		cw := csv.NewWriter(w)
+		if err := cw.Write([]string{"kind", "path", "regex", "methods"}); err != nil {
+			return err
+		}
+		for _, rule := range rules {
+			if err := cw.Write([]string{rule.Kind, rule.Path, rule.Regex, strings.Join(rule.Methods, " ")}); err != nil {
+				return err
+			}
+		}
+		cw.Flush()
+		return cw.Error()
+	default:
+		return fmt.Errorf("unknown report format %d", format)
+	}
+}
+
+// collectEdgeRules walks n, where lit is the static path up to the first
+// parameter, re the regular expression of the whole path so far and static
+// whether no parameter was crossed yet.
+func collectEdgeRules(rules *[]EdgeRule, t *radixTree, n *radixNode, lit, re string, static bool) {
+	path := lit
+	if path == "" {
+		path = "/"
+	}
+	leaf := t.leaf(n)
+	if leaf.handlers.len() > 0 || leaf.rewrite != nil {
+		var methods []string
+		if leaf.handlers.len() > 0 {
+			methods = leaf.handlers.methods()
+		}
+		if static {
+			*rules = append(*rules, EdgeRule{Kind: EdgeExact, Path: path, Methods: methods})
+		} else {
+			*rules = append(*rules, EdgeRule{Kind: EdgePattern, Path: path, Regex: "^" + re + "$", Methods: methods})
+		}
+	}
+	if leaf.mount != nil && static {
+		*rules = append(*rules, EdgeRule{Kind: EdgePrefix, Path: path})
+	}
+
+	for _, e := range t.staticEdges(n) {
+		label := t.label(e)
+		next := lit
+		if static {
+			next += label
+		}
+		collectEdgeRules(rules, t, t.node(e.next), next, re+regexp.QuoteMeta(label), static)
+	}
+	if static {
+		lit += "/"
+	}
+	if pe := t.paramChild(n); pe != nil {
+		collectEdgeRules(rules, t, t.node(pe.next), lit, re+"/"+pe.regex(), false)
+	}
+	if pe := t.catchAllChild(n); pe != nil {
+		collectEdgeRules(rules, t, t.node(pe.next), lit, re+"/"+pe.catchAllRegex(), false)
+	}
+}
+
+func (pe *radixParamEdge) regex() string {
+	if pe.tmpl == nil {
+		return regexp.QuoteMeta(pe.prefix) + exprRegex(pe.expr, false) + regexp.QuoteMeta(pe.suffix)
+	}
+	var b strings.Builder
+	for i, p := range pe.tmpl.params {
+		b.WriteString(regexp.QuoteMeta(pe.tmpl.literals[i]))
+		b.WriteString(exprRegex(p.expr, false))
+	}
+	b.WriteString(regexp.QuoteMeta(pe.tmpl.literals[len(pe.tmpl.literals)-1]))
+	return b.String()
+}
+
+func (pe *radixParamEdge) catchAllRegex() string {
+	if !pe.repeated {
+		return ".*"
+	}
+	seg := exprRegex(pe.expr, true)
+	return seg + "(?:/" + seg + ")*"
+}
+
+// exprRegex translates a parameter constraint into a regular expression for
+// one segment value. nonEmpty forces at least one byte.
+func exprRegex(expr string, nonEmpty bool) string {
+	class, quant := "[^/]", "*"
+	switch {
+	case expr == "":
+	case strings.HasPrefix(expr, `\d`):
+		class, quant = "[0-9]", strings.TrimPrefix(expr, `\d`)
+	default:
+		end := strings.IndexByte(expr, ']')
+		class, quant = expr[:end+1], expr[end+1:]
+		if quant == "" {
+			quant = "+"
+		}
+	}
+	if quant == "" || nonEmpty {
+		quant = "+"
+	}
+	return class + quant
+}
@@ -0,0 +1,84 @@
+//go:build !tinygo
+
+package saruta
+
+import (
+	"bytes"
+	"net/http"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestBuildEdgeRules(t *testing.T) {
+	noop := func(w http.ResponseWriter, req *http.Request) {}
+	r := New()
+	if _, err := BuildEdgeRules(r); err == nil {
+		t.Fatal("expected error before Compile")
+	}
+	r.Get("/", noop)
+	r.Get("/health", noop)
+	r.Post("/health", noop)
+	r.Get("/users/{id:[0-9]+}", noop)
+	r.Get("/image/{id:[a-z0-9]+}.{ext:[a-z]+}", noop)
+	r.Get("/files/{path...}", noop)
+	r.Get("/tags/{tag+:[a-z]+}", noop)
+	r.Rewrite("/people/{id}", "/users/{id}")
+	r.Mount("/legacy", http.NotFoundHandler())
+	r.MustCompile()
+
+	rules, err := BuildEdgeRules(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []EdgeRule{
+		{Kind: EdgeExact, Path: "/", Methods: []string{"GET"}},
+		{Kind: EdgePattern, Path: "/files/", Regex: `^/files/.*$`, Methods: []string{"GET"}},
+		{Kind: EdgeExact, Path: "/health", Methods: []string{"GET", "POST"}},
+		{Kind: EdgePattern, Path: "/image/", Regex: `^/image/[a-z0-9]+\.[a-z]+$`, Methods: []string{"GET"}},
+		{Kind: EdgePrefix, Path: "/legacy"},
+		{Kind: EdgePattern, Path: "/people/", Regex: `^/people/[^/]*$`},
+		{Kind: EdgePattern, Path: "/tags/", Regex: `^/tags/[a-z]+(?:/[a-z]+)*$`, Methods: []string{"GET"}},
+		{Kind: EdgePattern, Path: "/users/", Regex: `^/users/[0-9]+$`, Methods: []string{"GET"}},
+	}
+	if len(rules) != len(want) {
+		t.Fatalf("rules = %+v, want %d rules", rules, len(want))
+	}
+	for i := range want {
+		got := rules[i]
+		if got.Kind != want[i].Kind || got.Path != want[i].Path || got.Regex != want[i].Regex || strings.Join(got.Methods, ",") != strings.Join(want[i].Methods, ",") {
+			t.Fatalf("rule %d = %+v, want %+v", i, got, want[i])
+		}
+	}
+
+	for _, rule := range rules {
+		if rule.Kind != EdgePattern {
+			continue
+		}
+		re := regexp.MustCompile(rule.Regex)
+		for path, match := range map[string]bool{
+			"/users/42":      true,
+			"/users/abc":     false,
+			"/image/a1.png":  true,
+			"/tags/a/b":      true,
+			"/tags/a//b":     false,
+			"/files/x/y.txt": true,
+		} {
+			_, _, ok := r.Match(http.MethodGet, path)
+			if strings.HasPrefix(path, rule.Path) && re.MatchString(path) != match {
+				t.Fatalf("%s on %s = %v, want %v", rule.Regex, path, !match, match)
+			}
+			if re.MatchString(path) && !ok {
+				t.Fatalf("%s accepts %s, which the router rejects", rule.Regex, path)
+			}
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := WriteEdgeRules(&buf, r, ReportCSV); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(buf.String(), "kind,path,regex,methods\nexact,/,,GET\n") {
+		t.Fatalf("csv = %q", buf.String())
+	}
+}
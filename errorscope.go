@@ -0,0 +1,131 @@
+package saruta
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// registeredErrorScope holds the NotFound / MethodNotAllowed handlers set on
+// a group with a prefix.
+type registeredErrorScope struct {
+	prefix           string
+	notFound         http.Handler
+	methodNotAllowed http.Handler
+	middleware       []middlewareEntry
+}
+
+// errorScope is a compiled registeredErrorScope. A request falls into the
+// scope when the leading segments of its path match segments; parameter
+// segments match any non-empty segment.
+type errorScope struct {
+	segments         []segment
+	notFound         http.Handler
+	methodNotAllowed http.Handler
+	// routeNotFound is notFound without the WithMiddlewareOnErrors chain.
+	routeNotFound http.Handler
+}
+
+// setErrorScope records h for the group prefix of r. set stores the handler
+// in the matching field of the scope.
+func (r *Router) setErrorScope(set func(*registeredErrorScope)) {
+	i := slices.IndexFunc(r.state.errorScopes, func(s registeredErrorScope) bool {
+		return s.prefix == r.prefix
+	})
+	if i < 0 {
+		r.state.errorScopes = append(r.state.errorScopes, registeredErrorScope{prefix: r.prefix})
+		i = len(r.state.errorScopes) - 1
+	}
+	s := &r.state.errorScopes[i]
+	s.middleware = append([]middlewareEntry(nil), r.middleware...)
+	set(s)
+}
+
+// compileErrorScopes validates the registered scopes and orders them most
+// specific first, so the first match for a path is the deepest group.
+func (s *routerState) compileErrorScopes(global, globalMethod http.Handler) ([]errorScope, error) {
+	if len(s.errorScopes) == 0 {
+		return nil, nil
+	}
+	scopes := make([]errorScope, 0, len(s.errorScopes))
+	for _, rs := range s.errorScopes {
+		prefix, err := normalizePrefix(rs.prefix)
+		if err != nil {
+			return nil, fmt.Errorf("invalid error handler scope: %w", err)
+		}
+		if prefix == "" {
+			continue
+		}
+		cp, err := compilePattern(prefix)
+		if err != nil {
+			return nil, fmt.Errorf("invalid error handler scope: %w", err)
+		}
+		sc := errorScope{
+			segments:         cp.segments,
+			notFound:         rs.notFound,
+			methodNotAllowed: rs.methodNotAllowed,
+			routeNotFound:    rs.notFound,
+		}
+		if sc.notFound == nil {
+			sc.notFound, sc.routeNotFound = global, s.notFound
+		}
+		if sc.methodNotAllowed == nil {
+			sc.methodNotAllowed = globalMethod
+		}
+		if mws := s.errorEntries(rs.middleware); len(mws) > 0 {
+			if rs.notFound != nil {
+				sc.notFound = chainMiddlewares(rs.notFound, mws, RouteInfo{})
+			}
+			if rs.methodNotAllowed != nil {
+				sc.methodNotAllowed = chainMiddlewares(rs.methodNotAllowed, mws, RouteInfo{})
+			}
+		}
+		scopes = append(scopes, sc)
+	}
+	slices.SortStableFunc(scopes, func(a, b errorScope) int {
+		return len(b.segments) - len(a.segments)
+	})
+	return scopes, nil
+}
+
+// errorScope returns the most specific scope containing the request path, or
+// nil. c may be nil.
+func (c *compiledState) errorScope(req *http.Request) *errorScope {
+	if c == nil || len(c.errorScopes) == 0 || req == nil || req.URL == nil {
+		return nil
+	}
+	for i := range c.errorScopes {
+		if c.errorScopes[i].contains(req.URL.Path) {
+			return &c.errorScopes[i]
+		}
+	}
+	return nil
+}
+
+func (sc *errorScope) contains(path string) bool {
+	rest := path
+	for _, seg := range sc.segments {
+		if rest == "" || rest[0] != '/' {
+			return false
+		}
+		rest = rest[1:]
+		part := rest
+		if i := strings.IndexByte(rest, '/'); i >= 0 {
+			part, rest = rest[:i], rest[i:]
+		} else {
+			rest = ""
+		}
+		switch seg.kind {
+		case segmentStatic:
+			if part != seg.literal {
+				return false
+			}
+		default:
+			if part == "" {
+				return false
+			}
+		}
+	}
+	return true
+}
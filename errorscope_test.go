@@ -0,0 +1,74 @@
+package saruta
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRouterScopedErrorHandlers(t *testing.T) {
+	text := func(body string, code int) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			http.Error(w, body, code)
+		})
+	}
+
+	r := New()
+	r.NotFound(text("html 404", http.StatusNotFound))
+	r.Get("/", func(w http.ResponseWriter, req *http.Request) {})
+	r.Route("/api", func(api *Router) {
+		api.NotFound(text("api 404", http.StatusNotFound))
+		api.MethodNotAllowed(text("api 405", http.StatusMethodNotAllowed))
+		api.Get("/users/{id}", func(w http.ResponseWriter, req *http.Request) {})
+		api.Route("/orgs/{org}", func(org *Router) {
+			org.NotFound(text("org 404", http.StatusNotFound))
+		})
+	})
+	r.MustCompile()
+
+	tests := []struct {
+		method, path string
+		code         int
+		body         string
+	}{
+		{http.MethodGet, "/missing", http.StatusNotFound, "html 404\n"},
+		{http.MethodGet, "/apix", http.StatusNotFound, "html 404\n"},
+		{http.MethodGet, "/api", http.StatusNotFound, "api 404\n"},
+		{http.MethodGet, "/api/missing", http.StatusNotFound, "api 404\n"},
+		{http.MethodPost, "/api/users/1", http.StatusMethodNotAllowed, "api 405\n"},
+		{http.MethodPost, "/", http.StatusMethodNotAllowed, "Method Not Allowed\n"},
+		{http.MethodGet, "/api/orgs/acme/missing", http.StatusNotFound, "org 404\n"},
+		{http.MethodGet, "/api/orgs//missing", http.StatusNotFound, "api 404\n"},
+	}
+	for _, tt := range tests {
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, httptest.NewRequest(tt.method, tt.path, nil))
+		if rec.Code != tt.code || rec.Body.String() != tt.body {
+			t.Errorf("%s %s = %d %q, want %d %q", tt.method, tt.path, rec.Code, rec.Body.String(), tt.code, tt.body)
+		}
+	}
+}
+
+func TestRouterScopedErrorHandlersMiddleware(t *testing.T) {
+	r := New(WithMiddlewareOnErrors())
+	api := r.WithPrefix("/api")
+	api.Use(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			w.Header().Set("X-Scope", "api")
+			next.ServeHTTP(w, req)
+		})
+	})
+	api.NotFound(http.NotFoundHandler())
+	r.MustCompile()
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/missing", nil))
+	if rec.Code != http.StatusNotFound || rec.Header().Get("X-Scope") != "api" {
+		t.Fatalf("got %d X-Scope=%q", rec.Code, rec.Header().Get("X-Scope"))
+	}
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/missing", nil))
+	if rec.Header().Get("X-Scope") != "" {
+		t.Fatalf("group middleware ran outside the group")
+	}
+}
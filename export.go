@@ -0,0 +1,135 @@
+package saruta
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// ManifestRoute is one route or mount in a route manifest.
+type ManifestRoute struct {
+	Method  string   `json:"method,omitempty"`
+	Pattern string   `json:"pattern"`
+	Name    string   `json:"name,omitempty"`
+	Params  []string `json:"params,omitempty"`
+	Aliases []string `json:"aliases,omitempty"`
+	Mount   bool     `json:"mount,omitempty"`
+	Meta    Meta     `json:"meta,omitempty"`
+}
+
+// RouteManifest is the machine-readable route table written by ExportRoutes.
+type RouteManifest struct {
+	Routes []ManifestRoute `json:"routes"`
+}
+
+// BuildRouteManifest collects the registered routes and mounts of r, in the
+// order of Walk.
+func BuildRouteManifest(r *Router) RouteManifest {
+	m := RouteManifest{Routes: []ManifestRoute{}}
+	_ = r.Walk(func(rt RouteInfo) error {
+		m.Routes = append(m.Routes, ManifestRoute{
+			Method:  rt.Method,
+			Pattern: rt.Pattern,
+			Name:    metaString(rt.Meta, MetaName),
+			Params:  rt.Params,
+			Aliases: rt.Aliases,
+			Mount:   rt.Mount,
+			Meta:    rt.Meta,
+		})
+		return nil
+	})
+	return m
+}
+
+// ExportRoutes writes the route manifest of r (method, pattern, name,
+// parameters, aliases and metadata of every route, plus mounts) to w as
+// ReportJSON or ReportYAML, for API gateways, WAF rule generation and
+// documentation pipelines. Like Routes, it reflects registration and can be
+// called before Compile.
+//
+// The YAML output uses block style for the manifest and JSON (flow) style
+// for metadata values, so any JSON-encodable value round-trips.
+func (r *Router) ExportRoutes(w io.Writer, format ReportFormat) error {
+	m := BuildRouteManifest(r)
+	switch format {
+	case ReportJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(m)
+	case ReportYAML:
+		return writeManifestYAML(w, m)
+	default:
+		return fmt.Errorf("unsupported route export format %d", format)
+	}
+}
+
+func writeManifestYAML(w io.Writer, m RouteManifest) error {
+	bw := bufio.NewWriter(w)
+	if len(m.Routes) == 0 {
+		bw.WriteString("routes: []\n")
+		return bw.Flush()
+	}
+	bw.WriteString("routes:\n")
+	for _, rt := range m.Routes {
+		indent := "  - "
+		field := func(key string, v any) {
+			b, _ := json.Marshal(v) // strings, string slices and bools
+			bw.WriteString(indent + key + ": ")
+			bw.Write(b)
+			bw.WriteByte('\n')
+			indent = "    "
+		}
+		if rt.Method != "" {
+			field("method", rt.Method)
+		}
+		field("pattern", rt.Pattern)
+		if rt.Name != "" {
+			field("name", rt.Name)
+		}
+		if len(rt.Params) > 0 {
+			field("params", rt.Params)
+		}
+		if len(rt.Aliases) > 0 {
+			field("aliases", rt.Aliases)
+		}
+		if rt.Mount {
+			field("mount", true)
+		}
+		if len(rt.Meta) == 0 {
+			continue
+		}
+		bw.WriteString("    meta:\n")
+		for _, k := range slices.Sorted(maps.Keys(rt.Meta)) {
+			b, err := json.Marshal(rt.Meta[k])
+			if err != nil {
+				return fmt.Errorf("route %s %s: meta %q: %w", rt.Method, rt.Pattern, k, err)
+			}
+			bw.WriteString("      " + yamlKey(k) + ": ")
+			bw.Write(b)
+			bw.WriteByte('\n')
+		}
+	}
+	return bw.Flush()
+}
+
+// yamlKey returns k unquoted when it is a plain identifier-like key, and as
+// a JSON (double-quoted YAML) string otherwise.
+func yamlKey(k string) string {
+	plain := k != "" && (k[0] == '_' || k[0] >= 'a' && k[0] <= 'z' || k[0] >= 'A' && k[0] <= 'Z') &&
+		!strings.ContainsFunc(k, func(c rune) bool {
+			return !(c == '_' || c == '-' || c == '.' || c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z')
+		})
+	switch strings.ToLower(k) {
+	case "true", "false", "null", "yes", "no", "on", "off", "y", "n":
+		plain = false
+	}
+	if plain {
+		return k
+	}
+	b, _ := json.Marshal(k)
+	return string(b)
+}
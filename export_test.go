@@ -0,0 +1,72 @@
+package saruta
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"testing"
+)
+
+func exportTestRouter() *Router {
+	noop := func(w http.ResponseWriter, req *http.Request) {}
+	r := New()
+	r.WithMeta(MetaName, "users.show").WithMeta(MetaOwner, "accounts").WithMeta("rate limit", 10).Get("/users/{id}", noop)
+	r.WithMeta("scopes", []string{"users:write"}).Post("/users", noop)
+	r.Mount("/static", http.NotFoundHandler())
+	return r
+}
+
+func TestRouterExportRoutesJSON(t *testing.T) {
+	var buf bytes.Buffer
+	if err := exportTestRouter().ExportRoutes(&buf, ReportJSON); err != nil {
+		t.Fatal(err)
+	}
+	var m RouteManifest
+	if err := json.Unmarshal(buf.Bytes(), &m); err != nil {
+		t.Fatal(err)
+	}
+	if len(m.Routes) != 3 {
+		t.Fatalf("routes = %+v", m.Routes)
+	}
+	show := m.Routes[2]
+	if show.Method != http.MethodGet || show.Pattern != "/users/{id}" || show.Name != "users.show" || len(show.Params) != 1 || show.Meta[MetaOwner] != "accounts" {
+		t.Fatalf("GET /users/{id} = %+v", show)
+	}
+	if !m.Routes[0].Mount || m.Routes[0].Pattern != "/static" {
+		t.Fatalf("mount = %+v", m.Routes[0])
+	}
+}
+
+func TestRouterExportRoutesYAML(t *testing.T) {
+	var buf bytes.Buffer
+	if err := exportTestRouter().ExportRoutes(&buf, ReportYAML); err != nil {
+		t.Fatal(err)
+	}
+	want := `routes:
+  - pattern: "/static"
+    mount: true
+  - method: "POST"
+    pattern: "/users"
+    meta:
+      scopes: ["users:write"]
+  - method: "GET"
+    pattern: "/users/{id}"
+    name: "users.show"
+    params: ["id"]
+    meta:
+      name: "users.show"
+      owner: "accounts"
+      "rate limit": 10
+`
+	if got := buf.String(); got != want {
+		t.Fatalf("YAML =\n%s\nwant\n%s", got, want)
+	}
+
+	buf.Reset()
+	if err := New().ExportRoutes(&buf, ReportYAML); err != nil || buf.String() != "routes: []\n" {
+		t.Fatalf("empty YAML = %q, %v", buf.String(), err)
+	}
+	if err := New().ExportRoutes(&buf, ReportCSV); err == nil {
+		t.Fatal("expected error for CSV")
+	}
+}
@@ -0,0 +1,37 @@
+package saruta
+
+import "net/http"
+
+// wrapRouteFeatures applies the built-in per-route behaviors configured via
+// route metadata. The wrappers run after router middleware, right before the
+// route handler.
+func wrapRouteFeatures(st *routerState, rt registeredRoute, h http.Handler) http.Handler {
+	// Innermost, so that hints only go out for requests the wrappers below
+	// let through.
+	if links, ok := rt.meta[MetaEarlyHints].([]string); ok && len(links) > 0 {
+		h = sendEarlyHints(links, h)
+	}
+	if p, ok := rt.meta[MetaDrainPriority].(int); ok {
+		h = st.drain.wrap(p, h)
+	}
+	if d, ok := rt.meta[MetaDeprecated].(Deprecation); ok {
+		h = d.wrap(h)
+	}
+	return h
+}
+
+// wrapRouteGuards applies the per-route checks that must hold before router
+// middleware runs, so that middleware never sees a request the route would
+// refuse. The wrappers go around the whole middleware chain.
+func wrapRouteGuards(rt registeredRoute, h http.Handler) http.Handler {
+	// Outside the chain, so that middleware reading the body is capped too.
+	if n, ok := rt.meta[MetaMaxBodySize].(int64); ok {
+		h = limitBody(n, h)
+	}
+	// Outermost: a request on the wrong protocol is refused before anything
+	// else, its body included, is looked at.
+	if p, ok := rt.meta[MetaProtocols].(ProtocolPolicy); ok {
+		h = enforceProtocols(p, h)
+	}
+	return h
+}
@@ -0,0 +1,113 @@
+package saruta
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// ReportFormat selects the output format of WriteGovernanceReport,
+// WriteEdgeRules and ExportRoutes.
+type ReportFormat int
+
+const (
+	ReportJSON ReportFormat = iota
+	ReportCSV
+	// ReportYAML is supported by ExportRoutes only.
+	ReportYAML
+)
+
+// GovernanceEntry is one route in a governance report.
+type GovernanceEntry struct {
+	Method     string   `json:"method"`
+	Pattern    string   `json:"pattern"`
+	Aliases    []string `json:"aliases,omitempty"`
+	Owner      string   `json:"owner"`
+	Auth       string   `json:"auth"`
+	Deprecated string   `json:"deprecated,omitempty"`
+	Meta       Meta     `json:"meta,omitempty"`
+}
+
+// GovernanceSummary counts routes lacking governance metadata.
+type GovernanceSummary struct {
+	Total      int `json:"total"`
+	Unowned    int `json:"unowned"`
+	NoAuth     int `json:"no_auth"`
+	Deprecated int `json:"deprecated"`
+}
+
+// GovernanceReport combines route metadata (owner, auth, deprecation) into one document.
+type GovernanceReport struct {
+	Routes  []GovernanceEntry `json:"routes"`
+	Summary GovernanceSummary `json:"summary"`
+}
+
+// BuildGovernanceReport collects the governance report for r.
+func BuildGovernanceReport(r *Router) GovernanceReport {
+	routes := r.Routes()
+	report := GovernanceReport{Routes: make([]GovernanceEntry, 0, len(routes))}
+	for _, rt := range routes {
+		e := GovernanceEntry{
+			Method:     rt.Method,
+			Pattern:    rt.Pattern,
+			Aliases:    rt.Aliases,
+			Owner:      metaString(rt.Meta, MetaOwner),
+			Auth:       metaString(rt.Meta, MetaAuth),
+			Deprecated: metaString(rt.Meta, MetaDeprecated),
+			Meta:       rt.Meta,
+		}
+		report.Summary.Total++
+		if e.Owner == "" {
+			report.Summary.Unowned++
+		}
+		if e.Auth == "" {
+			report.Summary.NoAuth++
+		}
+		if e.Deprecated != "" {
+			report.Summary.Deprecated++
+		}
+		report.Routes = append(report.Routes, e)
+	}
+	return report
+}
+
+// WriteGovernanceReport writes the governance report for r to w.
+//
+// It is intended to be called from CI (for example in a test) to audit route
+// ownership and authentication without scraping source code.
+func WriteGovernanceReport(w io.Writer, r *Router, format ReportFormat) error {
+	report := BuildGovernanceReport(r)
+	switch format {
+	case ReportJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(report)
+	case ReportCSV:
+		cw := csv.NewWriter(w)
+		if err := cw.Write([]string{"method", "pattern", "aliases", "owner", "auth", "deprecated"}); err != nil {
+			return err
+		}
+		for _, e := range report.Routes {
+			if err := cw.Write([]string{e.Method, e.Pattern, strings.Join(e.Aliases, " "), e.Owner, e.Auth, e.Deprecated}); err != nil {
+				return err
+			}
+		}
+		cw.Flush()
+		return cw.Error()
+	default:
+		return fmt.Errorf("unknown report format %d", format)
+	}
+}
+
+func metaString(m Meta, key string) string {
+	v, ok := m[key]
+	if !ok || v == nil {
+		return ""
+	}
+	if s, ok := v.(string); ok {
+		return s
+	}
+	return fmt.Sprint(v)
+}
@@ -0,0 +1,65 @@
+package saruta
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"testing"
+)
+
+func TestWriteGovernanceReport(t *testing.T) {
+	h := func(w http.ResponseWriter, req *http.Request) {}
+	r := New()
+	r.Get("/health", h)
+	api := r.WithMeta(MetaOwner, "team-users").WithMeta(MetaAuth, "bearer")
+	api.Get("/users/{id}", h)
+	api.WithMeta(MetaDeprecated, "2026-12-31").Delete("/users/{id}", h)
+	r.Alias("/users/{id}", "/u/{id}")
+
+	var buf bytes.Buffer
+	if err := WriteGovernanceReport(&buf, r, ReportJSON); err != nil {
+		t.Fatal(err)
+	}
+	var report GovernanceReport
+	if err := json.Unmarshal(buf.Bytes(), &report); err != nil {
+		t.Fatal(err)
+	}
+	want := GovernanceSummary{Total: 3, Unowned: 1, NoAuth: 1, Deprecated: 1}
+	if report.Summary != want {
+		t.Fatalf("summary = %+v, want %+v", report.Summary, want)
+	}
+	if got := report.Routes[1]; got.Method != http.MethodDelete || got.Owner != "team-users" || got.Deprecated != "2026-12-31" || len(got.Aliases) != 1 {
+		t.Fatalf("routes[1] = %+v", got)
+	}
+
+	buf.Reset()
+	if err := WriteGovernanceReport(&buf, r, ReportCSV); err != nil {
+		t.Fatal(err)
+	}
+	wantCSV := "method,pattern,aliases,owner,auth,deprecated\n" +
+		"GET,/health,,,,\n" +
+		"DELETE,/users/{id},/u/{id},team-users,bearer,2026-12-31\n" +
+		"GET,/users/{id},/u/{id},team-users,bearer,\n"
+	if got := buf.String(); got != wantCSV {
+		t.Fatalf("csv = %q, want %q", got, wantCSV)
+	}
+}
+
+func TestRouterWithMetaDoesNotLeak(t *testing.T) {
+	h := func(w http.ResponseWriter, req *http.Request) {}
+	r := New()
+	a := r.WithMeta(MetaOwner, "a")
+	b := a.WithMeta(MetaOwner, "b")
+	a.Get("/a", h)
+	b.Get("/b", h)
+	r.Get("/c", h)
+
+	routes := r.Routes()
+	got := []string{metaString(routes[0].Meta, MetaOwner), metaString(routes[1].Meta, MetaOwner), metaString(routes[2].Meta, MetaOwner)}
+	want := []string{"a", "b", ""}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("owners = %#v, want %#v", got, want)
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package saruta
+
+import (
+	"mime"
+	"net/http"
+	"strings"
+)
+
+// DefaultGraphQLMaxBodySize is the request body cap used by GraphQL unless
+// WithGraphQLMaxBodySize is given.
+const DefaultGraphQLMaxBodySize int64 = 1 << 20
+
+type graphQLConfig struct {
+	maxBodySize   int64
+	subscriptions http.Handler
+}
+
+// GraphQLOption configures a GraphQL endpoint.
+type GraphQLOption func(*graphQLConfig)
+
+// WithGraphQLMaxBodySize sets the request body cap for POST requests.
+func WithGraphQLMaxBodySize(n int64) GraphQLOption {
+	return func(c *graphQLConfig) {
+		c.maxBodySize = n
+	}
+}
+
+// WithGraphQLSubscriptions routes WebSocket upgrade requests to h instead of
+// the main GraphQL handler.
+func WithGraphQLSubscriptions(h http.Handler) GraphQLOption {
+	return func(c *graphQLConfig) {
+		c.subscriptions = h
+	}
+}
+
+// GraphQL registers h as a GraphQL endpoint at pattern.
+//
+// The router handles the transport concerns and leaves query execution to h:
+//   - POST requires an application/json or application/graphql body and is
+//     capped at DefaultGraphQLMaxBodySize (see WithGraphQLMaxBodySize).
+//   - GET requires a query or extensions (persisted query) parameter.
+//   - GET requests upgrading to WebSocket pass through untouched, to the
+//     handler from WithGraphQLSubscriptions when one is set.
+//
+// Other methods get 405. Rejecting mutations sent over GET is left to h, since
+// it requires parsing the document.
+func (r *Router) GraphQL(pattern string, h http.Handler, opts ...GraphQLOption) {
+	cfg := graphQLConfig{maxBodySize: DefaultGraphQLMaxBodySize}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	subscriptions := cfg.subscriptions
+	if subscriptions == nil {
+		subscriptions = h
+	}
+
+	r.Handle(http.MethodGet, pattern, http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if isWebSocketUpgrade(req) {
+			subscriptions.ServeHTTP(w, req)
+			return
+		}
+		q := req.URL.Query()
+		if !q.Has("query") && !q.Has("extensions") {
+			writeProblem(w, http.StatusBadRequest, "missing query or extensions parameter")
+			return
+		}
+		h.ServeHTTP(w, req)
+	}))
+	r.WithMaxBodySize(cfg.maxBodySize).Handle(http.MethodPost, pattern, http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		mediaType, _, err := mime.ParseMediaType(req.Header.Get("Content-Type"))
+		if err != nil || (mediaType != "application/json" && mediaType != "application/graphql") {
+			writeProblem(w, http.StatusUnsupportedMediaType, "content type must be application/json or application/graphql")
+			return
+		}
+		h.ServeHTTP(w, req)
+	}))
+}
+
+func isWebSocketUpgrade(req *http.Request) bool {
+	if !strings.EqualFold(req.Header.Get("Upgrade"), "websocket") {
+		return false
+	}
+	for _, v := range req.Header.Values("Connection") {
+		for token := range strings.SplitSeq(v, ",") {
+			if strings.EqualFold(strings.TrimSpace(token), "upgrade") {
+				return true
+			}
+		}
+	}
+	return false
+}
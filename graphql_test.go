@@ -0,0 +1,65 @@
+package saruta
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRouterGraphQL(t *testing.T) {
+	gql := http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Body != nil {
+			if _, err := io.ReadAll(req.Body); err != nil {
+				w.WriteHeader(http.StatusRequestEntityTooLarge)
+				return
+			}
+		}
+		io.WriteString(w, "gql")
+	})
+	subs := http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		io.WriteString(w, "subs")
+	})
+
+	r := New()
+	r.GraphQL("/graphql", gql, WithGraphQLMaxBodySize(16), WithGraphQLSubscriptions(subs))
+	r.MustCompile()
+
+	for _, tc := range []struct {
+		name   string
+		method string
+		target string
+		ctype  string
+		body   string
+		header map[string]string
+		code   int
+		want   string
+	}{
+		{name: "post json", method: http.MethodPost, target: "/graphql", ctype: "application/json; charset=utf-8", body: `{"query":"{a}"}`, code: http.StatusOK, want: "gql"},
+		{name: "post graphql", method: http.MethodPost, target: "/graphql", ctype: "application/graphql", body: "{a}", code: http.StatusOK, want: "gql"},
+		{name: "post bad type", method: http.MethodPost, target: "/graphql", ctype: "text/plain", body: "{a}", code: http.StatusUnsupportedMediaType},
+		{name: "post too large", method: http.MethodPost, target: "/graphql", ctype: "application/json", body: strings.Repeat("x", 32), code: http.StatusRequestEntityTooLarge},
+		{name: "get query", method: http.MethodGet, target: "/graphql?query=%7Ba%7D", code: http.StatusOK, want: "gql"},
+		{name: "get persisted", method: http.MethodGet, target: "/graphql?extensions=%7B%7D", code: http.StatusOK, want: "gql"},
+		{name: "get empty", method: http.MethodGet, target: "/graphql", code: http.StatusBadRequest},
+		{name: "websocket", method: http.MethodGet, target: "/graphql", header: map[string]string{"Connection": "keep-alive, Upgrade", "Upgrade": "websocket"}, code: http.StatusOK, want: "subs"},
+		{name: "put", method: http.MethodPut, target: "/graphql", code: http.StatusMethodNotAllowed},
+	} {
+		req := httptest.NewRequest(tc.method, tc.target, strings.NewReader(tc.body))
+		if tc.ctype != "" {
+			req.Header.Set("Content-Type", tc.ctype)
+		}
+		for k, v := range tc.header {
+			req.Header.Set(k, v)
+		}
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+		if rec.Code != tc.code {
+			t.Fatalf("%s: status = %d, want %d", tc.name, rec.Code, tc.code)
+		}
+		if tc.want != "" && rec.Body.String() != tc.want {
+			t.Fatalf("%s: body = %q, want %q", tc.name, rec.Body.String(), tc.want)
+		}
+	}
+}
@@ -0,0 +1,41 @@
+//go:build grpcgateway
+
+// Package grpcgateway mounts a grpc-gateway runtime.ServeMux on a saruta
+// router.
+//
+// It lives in its own module, behind the grpcgateway build tag, so that the
+// grpc-gateway dependency stays out of the root module:
+//
+//	go build -tags grpcgateway
+package grpcgateway
+
+import (
+	"net/http"
+
+	"github.com/catatsuy/saruta"
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+)
+
+// Mount delegates prefix to gw. The gateway matches the paths from its
+// google.api.http annotations, so it receives the original request path:
+// mount "/v1" for a service annotated with "/v1/users/{id}", and do not
+// strip the prefix.
+//
+// The response writer is passed through untouched, which server-streaming
+// methods need to flush each message and WebSocket proxies wrapped around
+// the gateway need to hijack the connection. Router middleware does not run
+// for mounts, so no wrapper can hide http.Flusher or http.Hijacker.
+//
+// The mount takes precedence over 405 responses (MountBeforeMethodNotAllowed),
+// so that a saruta route sharing a path with a gateway method does not answer
+// the gateway's requests with 405; opts override it.
+func Mount(r *saruta.Router, prefix string, gw *runtime.ServeMux, opts ...saruta.MountOption) {
+	MountHandler(r, prefix, gw, opts...)
+}
+
+// MountHandler is Mount for a gateway wrapped in another handler, such as a
+// WebSocket proxy or CORS middleware.
+func MountHandler(r *saruta.Router, prefix string, h http.Handler, opts ...saruta.MountOption) {
+	opts = append([]saruta.MountOption{saruta.WithMountPrecedence(saruta.MountBeforeMethodNotAllowed)}, opts...)
+	r.Mount(prefix, h, opts...)
+}
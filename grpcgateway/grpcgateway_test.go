@@ -0,0 +1,187 @@
+//go:build grpcgateway
+
+package grpcgateway
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/catatsuy/saruta"
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+func newServer(t *testing.T, gw *runtime.ServeMux) *httptest.Server {
+	t.Helper()
+	r := saruta.New()
+	r.Get("/v1/status", func(w http.ResponseWriter, req *http.Request) {
+		w.Write([]byte("saruta status"))
+	})
+	Mount(r, "/v1", gw)
+	r.MustCompile()
+	srv := httptest.NewServer(r)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func get(t *testing.T, method, url string) (int, string) {
+	t.Helper()
+	req, _ := http.NewRequest(method, url, nil)
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+	body, _ := io.ReadAll(res.Body)
+	return res.StatusCode, string(body)
+}
+
+func TestMountPassesFullPath(t *testing.T) {
+	gw := runtime.NewServeMux()
+	gw.HandlePath(http.MethodGet, "/v1/users/{id}", func(w http.ResponseWriter, req *http.Request, params map[string]string) {
+		w.Write([]byte(req.URL.Path + " id=" + params["id"]))
+	})
+	gw.HandlePath(http.MethodPost, "/v1/status", func(w http.ResponseWriter, req *http.Request, params map[string]string) {
+		w.Write([]byte("gateway status"))
+	})
+	srv := newServer(t, gw)
+
+	tests := []struct {
+		method, path string
+		code         int
+		body         string
+	}{
+		{http.MethodGet, "/v1/users/7", 200, "/v1/users/7 id=7"},
+		{http.MethodGet, "/v1/status", 200, "saruta status"},
+		{http.MethodPost, "/v1/status", 200, "gateway status"},
+		{http.MethodGet, "/v1/missing", 404, ""},
+		{http.MethodGet, "/v2/users/7", 404, ""},
+	}
+	for _, tc := range tests {
+		code, body := get(t, tc.method, srv.URL+tc.path)
+		if code != tc.code {
+			t.Errorf("%s %s: status %d, want %d", tc.method, tc.path, code, tc.code)
+			continue
+		}
+		if tc.code == 200 && body != tc.body {
+			t.Errorf("%s %s: body %q, want %q", tc.method, tc.path, body, tc.body)
+		}
+	}
+}
+
+func TestMountStreamsServerMessages(t *testing.T) {
+	gw := runtime.NewServeMux()
+	next := make(chan string, 1)
+	gw.HandlePath(http.MethodGet, "/v1/events", func(w http.ResponseWriter, req *http.Request, params map[string]string) {
+		ctx := runtime.NewServerMetadataContext(req.Context(), runtime.ServerMetadata{})
+		_, marshaler := runtime.MarshalerForRequest(gw, req)
+		runtime.ForwardResponseStream(ctx, gw, marshaler, w, req, func() (proto.Message, error) {
+			s, ok := <-next
+			if !ok {
+				return nil, io.EOF
+			}
+			return wrapperspb.String(s), nil
+		})
+	})
+	srv := newServer(t, gw)
+
+	// The response headers go out with the first message, so queue it
+	// before sending the request.
+	next <- "one"
+	res, err := http.Get(srv.URL + "/v1/events")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+	lines := bufio.NewScanner(res.Body)
+	// Each message must reach the client while the stream is still open,
+	// which requires the gateway to flush through the router.
+	for i, msg := range []string{"one", "two"} {
+		if i > 0 {
+			next <- msg
+		}
+		if !lines.Scan() {
+			t.Fatalf("stream ended before %q: %v", msg, lines.Err())
+		}
+		if !strings.Contains(lines.Text(), msg) {
+			t.Errorf("line %q, want %q", lines.Text(), msg)
+		}
+	}
+	close(next)
+	if lines.Scan() {
+		t.Errorf("unexpected line %q", lines.Text())
+	}
+}
+
+func TestMountHandlerHijacksUpgrades(t *testing.T) {
+	gw := runtime.NewServeMux()
+	// upgrade stands in for a WebSocket proxy wrapped around the gateway.
+	upgrade := http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Header.Get("Upgrade") == "" {
+			gw.ServeHTTP(w, req)
+			return
+		}
+		conn, rw, err := http.NewResponseController(w).Hijack()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		defer conn.Close()
+		rw.WriteString("HTTP/1.1 101 Switching Protocols\r\nUpgrade: echo\r\nConnection: Upgrade\r\n\r\n")
+		rw.Flush()
+		line, _ := rw.ReadString('\n')
+		rw.WriteString("echo " + req.URL.Path + " " + line)
+		rw.Flush()
+	})
+	r := saruta.New()
+	MountHandler(r, "/v1", upgrade)
+	r.MustCompile()
+	srv := httptest.NewServer(r)
+	defer srv.Close()
+
+	conn, err := net.Dial("tcp", srv.Listener.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	io.WriteString(conn, "GET /v1/ws HTTP/1.1\r\nHost: test\r\nUpgrade: echo\r\nConnection: Upgrade\r\n\r\n")
+	br := bufio.NewReader(conn)
+	res, err := http.ReadResponse(br, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.StatusCode != http.StatusSwitchingProtocols {
+		t.Fatalf("status %d, want 101", res.StatusCode)
+	}
+	io.WriteString(conn, "ping\n")
+	line, err := br.ReadString('\n')
+	if err != nil {
+		t.Fatal(err)
+	}
+	if line != "echo /v1/ws ping\n" {
+		t.Errorf("got %q", line)
+	}
+}
+
+func TestMountPrecedenceOverride(t *testing.T) {
+	gw := runtime.NewServeMux()
+	gw.HandlePath(http.MethodPost, "/v1/status", func(w http.ResponseWriter, req *http.Request, params map[string]string) {
+		w.Write([]byte("gateway status"))
+	})
+	r := saruta.New()
+	r.Get("/v1/status", func(w http.ResponseWriter, req *http.Request) {})
+	Mount(r, "/v1", gw, saruta.WithMountPrecedence(saruta.MountAfterRoutes))
+	r.MustCompile()
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/v1/status", nil))
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("status %d, want 405", rec.Code)
+	}
+}
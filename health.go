@@ -0,0 +1,86 @@
+package saruta
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// HealthCheck is a named dependency check aggregated by Health.
+type HealthCheck struct {
+	Name  string
+	Check func(ctx context.Context) error
+}
+
+type healthResponse struct {
+	Status string                       `json:"status"`
+	Checks map[string]healthCheckResult `json:"checks,omitempty"`
+}
+
+type healthCheckResult struct {
+	Status    string  `json:"status"`
+	LatencyMS float64 `json:"latency_ms"`
+	Error     string  `json:"error,omitempty"`
+}
+
+// Health registers a GET and HEAD endpoint at pattern that runs checks
+// concurrently with the request context and answers with a JSON summary:
+//
+//	{"status":"ok","checks":{"db":{"status":"ok","latency_ms":1.3}}}
+//
+// The status is 200 when every check returns nil and 503 otherwise, with the
+// failing checks' errors included. Register liveness and readiness as separate
+// endpoints, typically with no checks for liveness:
+//
+//	r.Health("/livez")
+//	r.Health("/readyz", saruta.HealthCheck{Name: "db", Check: db.PingContext})
+//
+// Bound check duration with a deadline on the request context (for example
+// with middleware.Timeout); Health does not add one.
+func (r *Router) Health(pattern string, checks ...HealthCheck) {
+	h := healthHandler(checks)
+	r.Get(pattern, h)
+	r.Head(pattern, h)
+}
+
+func healthHandler(checks []HealthCheck) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		resp := healthResponse{Status: "ok"}
+		if len(checks) > 0 {
+			results := make([]healthCheckResult, len(checks))
+			var wg sync.WaitGroup
+			for i, c := range checks {
+				wg.Go(func() {
+					start := time.Now()
+					err := c.Check(req.Context())
+					res := healthCheckResult{Status: "ok", LatencyMS: float64(time.Since(start).Microseconds()) / 1000}
+					if err != nil {
+						res.Status = "fail"
+						res.Error = err.Error()
+					}
+					results[i] = res
+				})
+			}
+			wg.Wait()
+			resp.Checks = make(map[string]healthCheckResult, len(checks))
+			for i, c := range checks {
+				resp.Checks[c.Name] = results[i]
+				if results[i].Status != "ok" {
+					resp.Status = "fail"
+				}
+			}
+		}
+		status := http.StatusOK
+		if resp.Status != "ok" {
+			status = http.StatusServiceUnavailable
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "no-store")
+		w.WriteHeader(status)
+		if req.Method != http.MethodHead {
+			_ = json.NewEncoder(w).Encode(resp)
+		}
+	}
+}
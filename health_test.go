@@ -0,0 +1,67 @@
+package saruta
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRouterHealth(t *testing.T) {
+	dbErr := errors.New("connection refused")
+	var dbDown bool
+	r := New()
+	r.Health("/livez")
+	r.Health("/readyz",
+		HealthCheck{Name: "cache", Check: func(ctx context.Context) error { return nil }},
+		HealthCheck{Name: "db", Check: func(ctx context.Context) error {
+			if dbDown {
+				return dbErr
+			}
+			return nil
+		}},
+	)
+	r.MustCompile()
+
+	get := func(method, path string) (*httptest.ResponseRecorder, healthResponse) {
+		t.Helper()
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, httptest.NewRequest(method, path, nil))
+		var body healthResponse
+		if method == http.MethodGet {
+			if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+				t.Fatalf("%s: %v", path, err)
+			}
+		}
+		return rec, body
+	}
+
+	rec, body := get(http.MethodGet, "/livez")
+	if rec.Code != http.StatusOK || body.Status != "ok" || len(body.Checks) != 0 {
+		t.Fatalf("livez: %d %+v", rec.Code, body)
+	}
+
+	rec, body = get(http.MethodGet, "/readyz")
+	if rec.Code != http.StatusOK || body.Status != "ok" || body.Checks["db"].Status != "ok" || body.Checks["cache"].Status != "ok" {
+		t.Fatalf("readyz: %d %+v", rec.Code, body)
+	}
+
+	dbDown = true
+	rec, body = get(http.MethodGet, "/readyz")
+	if rec.Code != http.StatusServiceUnavailable || body.Status != "fail" {
+		t.Fatalf("readyz with db down: %d %+v", rec.Code, body)
+	}
+	if db := body.Checks["db"]; db.Status != "fail" || db.Error != "connection refused" {
+		t.Fatalf("db check = %+v", db)
+	}
+	if body.Checks["cache"].Status != "ok" {
+		t.Fatalf("cache check = %+v", body.Checks["cache"])
+	}
+
+	rec, _ = get(http.MethodHead, "/readyz")
+	if rec.Code != http.StatusServiceUnavailable || rec.Body.Len() != 0 {
+		t.Fatalf("HEAD readyz: %d %q", rec.Code, rec.Body.String())
+	}
+}
@@ -0,0 +1,34 @@
+package saruta
+
+import "maps"
+
+// OnRegister registers fn to be called for every subsequent Handle and Mount
+// call on r or on routers derived from it (With, WithMeta, Group, Route), so
+// plugins such as documentation generators or authorization registries can
+// observe the route set as it is built.
+//
+// fn receives the route as Routes would report it: Pattern includes group
+// prefixes, Meta is a copy of the metadata in effect, and for mounts Mount is
+// true and Method is empty. Params is nil if the pattern is invalid; the
+// error itself is still reported by Compile. Hooks run synchronously, in
+// registration order.
+func (r *Router) OnRegister(fn func(RouteInfo)) {
+	r.checkFrozen("OnRegister")
+	r.state.onRegister = append(r.state.onRegister, fn)
+}
+
+func (r *Router) notifyRegister(info RouteInfo) {
+	if len(r.state.onRegister) == 0 {
+		return
+	}
+	if !info.Mount {
+		if cp, err := compilePattern(info.Pattern); err == nil {
+			info.Params = cp.paramNames()
+		}
+	}
+	for _, fn := range r.state.onRegister {
+		info := info
+		info.Meta = maps.Clone(info.Meta)
+		fn(info)
+	}
+}
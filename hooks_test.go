@@ -0,0 +1,38 @@
+package saruta
+
+import (
+	"net/http"
+	"slices"
+	"testing"
+)
+
+func TestRouterOnRegister(t *testing.T) {
+	noop := func(w http.ResponseWriter, req *http.Request) {}
+	r := New()
+	r.Get("/before", noop)
+
+	var got []RouteInfo
+	r.OnRegister(func(info RouteInfo) { got = append(got, info) })
+	r.Route("/api", func(api *Router) {
+		api.WithMeta(MetaOwner, "billing").Get("/invoices/{id}", noop)
+	})
+	r.Mount("/static", http.NotFoundHandler())
+
+	if len(got) != 2 {
+		t.Fatalf("got %d registrations, want 2: %+v", len(got), got)
+	}
+	rt := got[0]
+	if rt.Method != http.MethodGet || rt.Pattern != "/api/invoices/{id}" || rt.Mount {
+		t.Fatalf("route = %+v", rt)
+	}
+	if !slices.Equal(rt.Params, []string{"id"}) || rt.Meta[MetaOwner] != "billing" {
+		t.Fatalf("route params/meta = %v %v", rt.Params, rt.Meta)
+	}
+	rt.Meta[MetaOwner] = "changed"
+	if r.Routes()[0].Meta[MetaOwner] != "billing" {
+		t.Fatal("hook could modify route metadata")
+	}
+	if mt := got[1]; !mt.Mount || mt.Pattern != "/static" || mt.Method != "" {
+		t.Fatalf("mount = %+v", mt)
+	}
+}
@@ -0,0 +1,6 @@
+// Package wasmsmoke holds a js/wasm smoke test: the router answering fetch
+// requests handed over from JavaScript, as a service worker would. It has no
+// code of its own; run it under Node with
+//
+//	GOOS=js GOARCH=wasm go test -exec="$(go env GOROOT)/lib/wasm/go_js_wasm_exec" ./internal/wasmsmoke
+package wasmsmoke
@@ -0,0 +1,66 @@
+//go:build js && wasm
+
+package wasmsmoke
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"syscall/js"
+	"testing"
+
+	"github.com/catatsuy/saruta"
+)
+
+func newRouter() *saruta.Router {
+	r := saruta.New()
+	r.Get("/users/{id}", func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		w.Write([]byte("user " + req.PathValue("id") + " " + req.URL.Query().Get("tab")))
+	})
+	r.Route("/assets", func(assets *saruta.Router) {
+		assets.Get("/{path...}", func(w http.ResponseWriter, req *http.Request) {
+			w.Write([]byte("asset " + saruta.Param(req, "path")))
+		})
+	})
+	r.MustCompile()
+	return r
+}
+
+// TestServiceWorkerFetch routes requests that JavaScript passes to an
+// exported Go function, the way a service worker's fetch listener would.
+func TestServiceWorkerFetch(t *testing.T) {
+	r := newRouter()
+	route := js.FuncOf(func(this js.Value, args []js.Value) any {
+		req := httptest.NewRequest(args[0].String(), args[1].String(), nil)
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+		return map[string]any{"status": rec.Code, "body": rec.Body.String()}
+	})
+	defer route.Release()
+
+	fetch := js.Global().Get("Function").New("route", "method", "url", "return route(method, url)")
+	tests := []struct {
+		method, url string
+		status      int
+		body        string
+	}{
+		{"GET", "https://app.example/users/42?tab=posts", 200, "user 42 posts"},
+		{"GET", "https://app.example/assets/css/app.css", 200, "asset css/app.css"},
+		{"POST", "https://app.example/users/42", 405, "Method Not Allowed\n"},
+		{"GET", "https://app.example/missing", 404, "404 page not found\n"},
+	}
+	for _, tc := range tests {
+		res := fetch.Invoke(route, tc.method, tc.url)
+		if status, body := res.Get("status").Int(), res.Get("body").String(); status != tc.status || body != tc.body {
+			t.Errorf("%s %s = %d %q, want %d %q", tc.method, tc.url, status, body, tc.status, tc.body)
+		}
+	}
+}
+
+func TestCompiledLookup(t *testing.T) {
+	var ps saruta.ParamBuffer
+	h, flags := newRouter().Compiled().Lookup(http.MethodGet, "/users/7", &ps)
+	if h == nil || flags != 0 || ps.Get("id") != "7" {
+		t.Errorf("Lookup = %v, %v, id %q", h != nil, flags, ps.Get("id"))
+	}
+}
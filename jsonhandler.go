@@ -0,0 +1,107 @@
+//go:build !tinygo
+
+package saruta
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"reflect"
+)
+
+// StatusError carries the HTTP status JSON answers with when a handler
+// function fails. Errors of other types become 500 responses without their
+// message.
+type StatusError struct {
+	Status int
+	Err    error
+}
+
+func (e *StatusError) Error() string {
+	if e.Err == nil {
+		return http.StatusText(e.Status)
+	}
+	return e.Err.Error()
+}
+
+func (e *StatusError) Unwrap() error {
+	return e.Err
+}
+
+// JSON adapts a typed function into a handler:
+//
+//	r.Post("/users", saruta.JSON(func(ctx context.Context, in createUser) (user, error) { ... }))
+//
+// The request body, if any, is decoded into I (it must be JSON, else 415);
+// when I is a struct, fields tagged for Bind are then filled from path values,
+// query values and headers. The result is encoded as a 200 application/json
+// response. Failures are answered with problem+json: 400 for malformed input,
+// 413 for bodies over a MaxBytesReader cap (see WithMaxBodySize), the status
+// of a *StatusError returned by fn, and 500 for any other error.
+func JSON[I, O any](fn func(context.Context, I) (O, error)) http.HandlerFunc {
+	bindIn := reflect.TypeFor[I]().Kind() == reflect.Struct
+	if bindIn {
+		bindPlanFor(reflect.TypeFor[I]()) // panic on unsupported tags now, not per request
+	}
+	return func(w http.ResponseWriter, req *http.Request) {
+		var in I
+		if req.Body != nil && req.Body != http.NoBody {
+			if err := decodeJSONBody(req, &in); err != nil {
+				writeJSONError(w, err)
+				return
+			}
+		}
+		if bindIn {
+			if err := bindInto(req, reflect.ValueOf(&in).Elem()); err != nil {
+				writeJSONError(w, err)
+				return
+			}
+		}
+		out, err := fn(req.Context(), in)
+		if err != nil {
+			writeJSONError(w, err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(out)
+	}
+}
+
+func decodeJSONBody(req *http.Request, v any) error {
+	if ct := req.Header.Get("Content-Type"); ct != "" {
+		mt, _, _ := mime.ParseMediaType(ct)
+		if mt != "application/json" {
+			return &StatusError{Status: http.StatusUnsupportedMediaType, Err: errors.New("request body must be application/json")}
+		}
+	}
+	err := json.NewDecoder(req.Body).Decode(v)
+	if err == nil || errors.Is(err, io.EOF) {
+		return nil
+	}
+	var mbe *http.MaxBytesError
+	if errors.As(err, &mbe) {
+		return &StatusError{Status: http.StatusRequestEntityTooLarge, Err: err}
+	}
+	return &StatusError{Status: http.StatusBadRequest, Err: errors.New("malformed JSON body")}
+}
+
+func writeJSONError(w http.ResponseWriter, err error) {
+	var se *StatusError
+	var be *BindError
+	var pe *ParamError
+	switch {
+	case errors.As(err, &se) && se.Status < 500:
+		writeProblem(w, se.Status, se.Error())
+	case errors.As(err, &se):
+		writeProblem(w, se.Status, "")
+	case errors.As(err, &be):
+		writeProblem(w, http.StatusBadRequest, be.Error())
+	case errors.As(err, &pe):
+		writeProblem(w, http.StatusBadRequest, pe.Error())
+	default:
+		writeProblem(w, http.StatusInternalServerError, "")
+	}
+}
@@ -0,0 +1,84 @@
+//go:build !tinygo
+
+package saruta
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type jsonTestIn struct {
+	Org  string `path:"org"`
+	Name string `json:"name"`
+}
+
+type jsonTestOut struct {
+	Org  string `json:"org"`
+	Name string `json:"name"`
+}
+
+func TestJSONHandler(t *testing.T) {
+	r := New()
+	r.WithMaxBodySize(64).Post("/orgs/{org}/users", JSON(func(ctx context.Context, in jsonTestIn) (jsonTestOut, error) {
+		switch in.Name {
+		case "taken":
+			return jsonTestOut{}, &StatusError{Status: http.StatusConflict, Err: errors.New("name already taken")}
+		case "boom":
+			return jsonTestOut{}, errors.New("database password is hunter2")
+		}
+		return jsonTestOut{Org: in.Org, Name: in.Name}, nil
+	}))
+	r.Get("/ping", JSON(func(ctx context.Context, _ struct{}) (string, error) { return "pong", nil }))
+	r.MustCompile()
+
+	do := func(method, path, ct, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		if ct != "" {
+			req.Header.Set("Content-Type", ct)
+		}
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := do(http.MethodPost, "/orgs/acme/users", "application/json", `{"name":"ann"}`)
+	var out jsonTestOut
+	if rec.Code != http.StatusOK || rec.Header().Get("Content-Type") != "application/json" {
+		t.Fatalf("status=%d content-type=%q", rec.Code, rec.Header().Get("Content-Type"))
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &out); err != nil || out != (jsonTestOut{Org: "acme", Name: "ann"}) {
+		t.Fatalf("out = %+v, %v", out, err)
+	}
+
+	if rec := do(http.MethodGet, "/ping", "", ""); rec.Code != http.StatusOK || rec.Body.String() != "\"pong\"\n" {
+		t.Fatalf("GET /ping status=%d body=%q", rec.Code, rec.Body.String())
+	}
+
+	for _, tc := range []struct {
+		ct, body string
+		code     int
+		detail   string
+	}{
+		{ct: "text/plain", body: `{"name":"a"}`, code: http.StatusUnsupportedMediaType},
+		{ct: "application/json", body: `{"name":`, code: http.StatusBadRequest, detail: "malformed JSON body"},
+		{ct: "application/json", body: `{"name":"` + strings.Repeat("x", 100) + `"}`, code: http.StatusRequestEntityTooLarge},
+		{ct: "application/json", body: `{"name":"taken"}`, code: http.StatusConflict, detail: "name already taken"},
+		{ct: "application/json", body: `{"name":"boom"}`, code: http.StatusInternalServerError},
+	} {
+		rec := do(http.MethodPost, "/orgs/acme/users", tc.ct, tc.body)
+		if rec.Code != tc.code || rec.Header().Get("Content-Type") != "application/problem+json" {
+			t.Fatalf("%s: status=%d content-type=%q, want %d", tc.body, rec.Code, rec.Header().Get("Content-Type"), tc.code)
+		}
+		if strings.Contains(rec.Body.String(), "hunter2") {
+			t.Fatalf("500 leaked the error message: %s", rec.Body.String())
+		}
+		if tc.detail != "" && !strings.Contains(rec.Body.String(), tc.detail) {
+			t.Fatalf("%s: body %s lacks %q", tc.body, rec.Body.String(), tc.detail)
+		}
+	}
+}
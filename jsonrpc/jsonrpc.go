@@ -0,0 +1,183 @@
+// Package jsonrpc dispatches JSON-RPC 2.0 requests to typed Go functions
+// behind a single saruta POST route.
+//
+//	s := jsonrpc.NewServer()
+//	jsonrpc.Register(s, "sum", func(ctx context.Context, p []int) (int, error) { ... })
+//	jsonrpc.Handle(r, "/rpc", s)
+//
+// Batches are supported; notifications (requests without an id) are executed
+// but never answered.
+package jsonrpc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/catatsuy/saruta"
+)
+
+// Error codes defined by the JSON-RPC 2.0 specification.
+const (
+	CodeParseError     = -32700
+	CodeInvalidRequest = -32600
+	CodeMethodNotFound = -32601
+	CodeInvalidParams  = -32602
+	CodeInternalError  = -32603
+)
+
+// Error is a JSON-RPC error object. Methods may return an *Error to control
+// the code and data sent to the client; any other error is reported as
+// CodeInternalError with its message.
+type Error struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Data    any    `json:"data,omitempty"`
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("jsonrpc: %d %s", e.Code, e.Message)
+}
+
+type method func(ctx context.Context, params json.RawMessage) (any, error)
+
+// Server holds the registered methods. Register all methods before serving;
+// Server is not safe for concurrent registration.
+type Server struct {
+	methods map[string]method
+}
+
+// NewServer returns an empty Server.
+func NewServer() *Server {
+	return &Server{methods: make(map[string]method)}
+}
+
+// Register adds fn as the JSON-RPC method name. Request params are decoded
+// into P; a decoding failure is answered with CodeInvalidParams. Absent params
+// leave P at its zero value.
+func Register[P, R any](s *Server, name string, fn func(ctx context.Context, params P) (R, error)) {
+	s.methods[name] = func(ctx context.Context, raw json.RawMessage) (any, error) {
+		var p P
+		if len(raw) > 0 {
+			if err := json.Unmarshal(raw, &p); err != nil {
+				return nil, &Error{Code: CodeInvalidParams, Message: err.Error()}
+			}
+		}
+		return fn(ctx, p)
+	}
+}
+
+// Handle registers s on r as a POST route at pattern. Router middleware and
+// route features such as WithMaxBodySize apply as for any other route.
+func Handle(r *saruta.Router, pattern string, s *Server) {
+	r.Handle(http.MethodPost, pattern, s)
+}
+
+type request struct {
+	JSONRPC string          `json:"jsonrpc"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params"`
+	ID      json.RawMessage `json:"id"`
+}
+
+type response struct {
+	JSONRPC string          `json:"jsonrpc"`
+	Result  any             `json:"result,omitempty"`
+	Error   *Error          `json:"error,omitempty"`
+	ID      json.RawMessage `json:"id"`
+}
+
+var nullID = json.RawMessage("null")
+
+// ServeHTTP decodes a single request or a batch and writes the responses.
+func (s *Server) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		var maxErr *http.MaxBytesError
+		if errors.As(err, &maxErr) {
+			http.Error(w, fmt.Sprintf("request body exceeds %d bytes", maxErr.Limit), http.StatusRequestEntityTooLarge)
+			return
+		}
+		http.Error(w, "failed to read request body", http.StatusBadRequest)
+		return
+	}
+	body = bytes.TrimSpace(body)
+
+	if len(body) > 0 && body[0] == '[' {
+		var batch []json.RawMessage
+		if err := json.Unmarshal(body, &batch); err != nil {
+			writeJSON(w, errorResponse(nullID, CodeParseError, err.Error()))
+			return
+		}
+		if len(batch) == 0 {
+			writeJSON(w, errorResponse(nullID, CodeInvalidRequest, "empty batch"))
+			return
+		}
+		out := make([]*response, 0, len(batch))
+		for _, raw := range batch {
+			if resp := s.call(req.Context(), raw); resp != nil {
+				out = append(out, resp)
+			}
+		}
+		if len(out) == 0 {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		writeJSON(w, out)
+		return
+	}
+
+	if !json.Valid(body) {
+		writeJSON(w, errorResponse(nullID, CodeParseError, "invalid JSON"))
+		return
+	}
+	resp := s.call(req.Context(), body)
+	if resp == nil {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	writeJSON(w, resp)
+}
+
+// call runs one request and returns its response, or nil for notifications.
+func (s *Server) call(ctx context.Context, raw json.RawMessage) *response {
+	var rq request
+	if err := json.Unmarshal(raw, &rq); err != nil || rq.JSONRPC != "2.0" || rq.Method == "" {
+		return errorResponse(nullID, CodeInvalidRequest, "invalid request")
+	}
+	fn, ok := s.methods[rq.Method]
+	if !ok {
+		if rq.ID == nil {
+			return nil
+		}
+		return errorResponse(rq.ID, CodeMethodNotFound, fmt.Sprintf("method %q not found", rq.Method))
+	}
+	result, err := fn(ctx, rq.Params)
+	if rq.ID == nil {
+		return nil
+	}
+	if err != nil {
+		var rpcErr *Error
+		if !errors.As(err, &rpcErr) {
+			rpcErr = &Error{Code: CodeInternalError, Message: err.Error()}
+		}
+		return &response{JSONRPC: "2.0", Error: rpcErr, ID: rq.ID}
+	}
+	if result == nil {
+		result = json.RawMessage("null")
+	}
+	return &response{JSONRPC: "2.0", Result: result, ID: rq.ID}
+}
+
+func errorResponse(id json.RawMessage, code int, msg string) *response {
+	return &response{JSONRPC: "2.0", Error: &Error{Code: code, Message: msg}, ID: id}
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(v)
+}
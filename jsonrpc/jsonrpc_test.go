@@ -0,0 +1,87 @@
+package jsonrpc_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/catatsuy/saruta"
+	"github.com/catatsuy/saruta/jsonrpc"
+)
+
+func newRouter() *saruta.Router {
+	s := jsonrpc.NewServer()
+	jsonrpc.Register(s, "sum", func(ctx context.Context, p []int) (int, error) {
+		total := 0
+		for _, v := range p {
+			total += v
+		}
+		return total, nil
+	})
+	jsonrpc.Register(s, "fail", func(ctx context.Context, p struct{}) (any, error) {
+		return nil, &jsonrpc.Error{Code: 42, Message: "custom"}
+	})
+	jsonrpc.Register(s, "boom", func(ctx context.Context, p struct{}) (any, error) {
+		return nil, errors.New("boom")
+	})
+
+	r := saruta.New()
+	jsonrpc.Handle(r, "/rpc", s)
+	r.MustCompile()
+	return r
+}
+
+func TestServer(t *testing.T) {
+	r := newRouter()
+	for _, tc := range []struct {
+		name string
+		body string
+		code int
+		want string
+	}{
+		{name: "call", body: `{"jsonrpc":"2.0","method":"sum","params":[1,2,3],"id":1}`, code: http.StatusOK, want: `{"jsonrpc":"2.0","result":6,"id":1}`},
+		{name: "string id", body: `{"jsonrpc":"2.0","method":"sum","params":[],"id":"a"}`, code: http.StatusOK, want: `{"jsonrpc":"2.0","result":0,"id":"a"}`},
+		{name: "invalid params", body: `{"jsonrpc":"2.0","method":"sum","params":{"x":1},"id":1}`, code: http.StatusOK, want: `"code":-32602`},
+		{name: "custom error", body: `{"jsonrpc":"2.0","method":"fail","id":1}`, code: http.StatusOK, want: `{"jsonrpc":"2.0","error":{"code":42,"message":"custom"},"id":1}`},
+		{name: "internal error", body: `{"jsonrpc":"2.0","method":"boom","id":1}`, code: http.StatusOK, want: `{"jsonrpc":"2.0","error":{"code":-32603,"message":"boom"},"id":1}`},
+		{name: "not found", body: `{"jsonrpc":"2.0","method":"nope","id":1}`, code: http.StatusOK, want: `"code":-32601`},
+		{name: "invalid request", body: `{"method":"sum","id":1}`, code: http.StatusOK, want: `"code":-32600`},
+		{name: "parse error", body: `{"jsonrpc":`, code: http.StatusOK, want: `"code":-32700`},
+		{name: "notification", body: `{"jsonrpc":"2.0","method":"sum","params":[1]}`, code: http.StatusNoContent},
+		{name: "batch", body: `[{"jsonrpc":"2.0","method":"sum","params":[1],"id":1},{"jsonrpc":"2.0","method":"sum","params":[2]},{"jsonrpc":"2.0","method":"sum","params":[3],"id":2}]`, code: http.StatusOK, want: `[{"jsonrpc":"2.0","result":1,"id":1},{"jsonrpc":"2.0","result":3,"id":2}]`},
+		{name: "notification batch", body: `[{"jsonrpc":"2.0","method":"sum"}]`, code: http.StatusNoContent},
+		{name: "empty batch", body: `[]`, code: http.StatusOK, want: `"code":-32600`},
+	} {
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/rpc", strings.NewReader(tc.body)))
+		if rec.Code != tc.code {
+			t.Fatalf("%s: status = %d, want %d", tc.name, rec.Code, tc.code)
+		}
+		if !strings.Contains(rec.Body.String(), tc.want) {
+			t.Fatalf("%s: body = %s, want %s", tc.name, rec.Body.String(), tc.want)
+		}
+	}
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/rpc", nil))
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("GET status = %d, want %d", rec.Code, http.StatusMethodNotAllowed)
+	}
+}
+
+func TestServerBodyLimit(t *testing.T) {
+	s := jsonrpc.NewServer()
+	r := saruta.New()
+	jsonrpc.Handle(r.WithMaxBodySize(8), "/rpc", s)
+	r.MustCompile()
+
+	req := httptest.NewRequest(http.MethodPost, "/rpc", strings.NewReader(`{"jsonrpc":"2.0","method":"x","id":1}`))
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	if rec.Code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusRequestEntityTooLarge)
+	}
+}
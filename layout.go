@@ -0,0 +1,137 @@
+package saruta
+
+import "strings"
+
+// compactEdgeLimit is the largest number of static edges a node looks up by
+// scanning firsts; nodes with more edges get a 256-entry index.
+const compactEdgeLimit = 8
+
+// radixArena is the radixTree being laid out, plus the state the copy needs.
+type radixArena struct {
+	radixTree
+	// matchers dedupes constraint matchers while copying parameter edges.
+	matchers matcherInterner
+	// labelAt maps each distinct static edge label to its offset in labels,
+	// so a label repeated across the tree, such as "/settings" below every
+	// {id}, is stored once instead of once per compressed chain.
+	labelAt map[string]int
+}
+
+// layoutRadix flattens the sorted tree rooted at root into a radixTree, one
+// slice per kind of element, sized up front. Nodes are laid out depth first,
+// so a match mostly walks forward through memory, and links become indexes,
+// so that only parameter edges and leaves, the parts holding strings,
+// matchers and handlers, are left for the garbage collector to scan.
+func layoutRadix(root *radixBuildNode) *radixTree {
+	var a radixArena
+	var nodes, edges, params, leaves, tables, indexes int
+	var labels strings.Builder
+	a.labelAt = make(map[string]int)
+	var count func(n *radixBuildNode)
+	count = func(n *radixBuildNode) {
+		nodes++
+		edges += len(n.staticEdges)
+		for _, e := range n.staticEdges {
+			if _, ok := a.labelAt[e.label]; !ok {
+				a.labelAt[e.label] = labels.Len()
+				labels.WriteString(e.label)
+			}
+		}
+		if !n.leaf.empty() {
+			leaves++
+		}
+		if n.leaf.handlers != nil {
+			tables++
+		}
+		if len(n.staticEdges) > compactEdgeLimit {
+			indexes++
+		}
+		for _, e := range n.staticEdges {
+			count(e.next)
+		}
+		for _, next := range []*radixBuildNode{n.paramNext, n.catchAllNext} {
+			if next != nil {
+				params++
+				count(next)
+			}
+		}
+	}
+	count(root)
+	a.labels = labels.String()
+	a.nodes = make([]radixNode, 0, nodes)
+	a.edges = make([]radixStaticEdge, 0, edges)
+	a.firsts = make([]byte, 0, edges)
+	a.params = make([]radixParamEdge, 0, params)
+	a.leaves = make([]radixLeaf, 1, leaves+1)
+	a.tables = make([]methodTable, 0, tables)
+	a.indexes = make([][256]uint16, 0, indexes)
+	a.matchers = make(matcherInterner)
+	a.copy(root)
+	return &a.radixTree
+}
+
+// copy appends src and its subtree, returning the index of src. A node's
+// edges are reserved before its children are copied, so they stay
+// contiguous.
+func (a *radixArena) copy(src *radixBuildNode) int32 {
+	i := int32(len(a.nodes))
+	a.nodes = append(a.nodes, radixNode{index: -1, param: -1, catchAll: -1})
+	n := radixNode{
+		edges:    int32(len(a.edges)),
+		numEdges: int32(len(src.staticEdges)),
+		index:    -1,
+		param:    -1,
+		catchAll: -1,
+	}
+	if !src.leaf.empty() {
+		leaf := src.leaf
+		if leaf.handlers != nil {
+			a.tables = append(a.tables, *leaf.handlers)
+			leaf.handlers = &a.tables[len(a.tables)-1]
+		}
+		n.leaf = int32(len(a.leaves))
+		a.leaves = append(a.leaves, leaf)
+	}
+
+	for _, e := range src.staticEdges {
+		off := a.labelAt[e.label]
+		a.edges = append(a.edges, radixStaticEdge{labelStart: int32(off), labelEnd: int32(off + len(e.label))})
+		a.firsts = append(a.firsts, e.label[0])
+	}
+	if len(src.staticEdges) > compactEdgeLimit {
+		n.index = int32(len(a.indexes))
+		a.indexes = append(a.indexes, [256]uint16{})
+		index := &a.indexes[n.index]
+		for j, e := range src.staticEdges {
+			if e.label != "" {
+				index[e.label[0]] = uint16(j + 1)
+			}
+		}
+	}
+
+	for j, e := range src.staticEdges {
+		next := a.copy(e.next)
+		a.edges[n.edges+int32(j)].next = next
+	}
+	n.param = a.copyParamEdge(src.paramChild, src.paramNext)
+	n.catchAll = a.copyParamEdge(src.catchAllChild, src.catchAllNext)
+	a.nodes[i] = n
+	return i
+}
+
+func (a *radixArena) copyParamEdge(src *radixParamEdge, next *radixBuildNode) int32 {
+	if src == nil {
+		return -1
+	}
+	i := int32(len(a.params))
+	a.params = append(a.params, *src)
+	pe := &a.params[i]
+	pe.matcher = a.matchers.intern(pe.matcher)
+	if pe.tmpl != nil {
+		for j := range pe.tmpl.params {
+			pe.tmpl.params[j].matcher = a.matchers.intern(pe.tmpl.params[j].matcher)
+		}
+	}
+	a.params[i].next = a.copy(next)
+	return i
+}
@@ -0,0 +1,149 @@
+package saruta
+
+import (
+	"net/http"
+	"runtime"
+	"strconv"
+	"testing"
+	"unsafe"
+)
+
+func TestLayoutRadix(t *testing.T) {
+	r := New()
+	paths := map[string]string{
+		"/ax/{id}/edit": "/ax/7/edit",
+		"/ax/{id}/view": "/ax/7/view",
+	}
+	for c := 'a'; c <= 'p'; c++ {
+		paths["/"+string(c)+"x/{id}"] = "/" + string(c) + "x/7"
+	}
+	for p := range paths {
+		r.Get(p, func(w http.ResponseWriter, req *http.Request) {})
+	}
+	r.MustCompile()
+
+	for p, path := range paths {
+		route, params, ok := r.Match(http.MethodGet, path)
+		if !ok || route.Pattern != p || params["id"] != "7" {
+			t.Errorf("Match(%s) = %q %v %v", path, route.Pattern, params, ok)
+		}
+	}
+	for _, path := range []string{"/qx/7", "/ax/7/delete", "/x"} {
+		if _, _, ok := r.Match(http.MethodGet, path); ok {
+			t.Errorf("Match(%s) matched", path)
+		}
+	}
+
+	// The root has one edge "/" to a node with 16 edges (indexed); the
+	// parameter node of /ax/{id} has one edge "/" to two edges (scanned).
+	tree := r.state.current.Load().root
+	wide := tree.node(tree.staticEdges(tree.node(0))[0].next)
+	if wide.numEdges != 16 || wide.index < 0 {
+		t.Fatalf("wide node: %d edges, index %d", wide.numEdges, wide.index)
+	}
+	ax := tree.node(tree.staticEdgeFor(wide, 'a').next)
+	idNode := tree.node(tree.paramChild(ax).next)
+	narrow := tree.node(tree.staticEdges(idNode)[0].next)
+	if firsts := string(tree.firsts[narrow.edges : narrow.edges+narrow.numEdges]); firsts != "ev" || narrow.index >= 0 {
+		t.Fatalf("narrow node: firsts %q, index %d", firsts, narrow.index)
+	}
+}
+
+func TestLayoutRadixInternsMatchers(t *testing.T) {
+	r := New()
+	h := func(w http.ResponseWriter, req *http.Request) {}
+	r.Get("/users/{id:[0-9]+}", h)
+	r.Get("/orders/{id:\\d+}", h)
+	r.Get("/files/{name:[a-z]+}.{n:[0-9]+}", h)
+	r.Get("/tags/{tag+:[0-9]+}", h)
+	r.Get("/slugs/{slug:[0-9]*}", h)
+	r.MustCompile()
+
+	var matchers []segmentMatcher
+	tree := r.state.current.Load().root
+	var walk func(n *radixNode)
+	walk = func(n *radixNode) {
+		for _, e := range tree.staticEdges(n) {
+			walk(tree.node(e.next))
+		}
+		if pe := tree.paramChild(n); pe != nil {
+			for _, p := range pe.tmpl.params {
+				matchers = append(matchers, p.matcher)
+			}
+			walk(tree.node(pe.next))
+		}
+		if pe := tree.catchAllChild(n); pe != nil {
+			matchers = append(matchers, pe.matcher.(*repeatedMatcher).each)
+			walk(tree.node(pe.next))
+		}
+	}
+	walk(tree.node(0))
+
+	distinct := make(map[segmentMatcher]bool)
+	for _, m := range matchers {
+		distinct[m] = true
+	}
+	// [0-9]+ and \d+ share one table; [a-z]+ and [0-9]* differ.
+	if len(matchers) != 6 || len(distinct) != 3 {
+		t.Fatalf("%d matchers, %d distinct; want 6 and 3", len(matchers), len(distinct))
+	}
+	for path, want := range map[string]bool{"/users/12": true, "/orders/7": true, "/files/a.1": true, "/tags/1/2": true, "/slugs/": true, "/users/x": false, "/tags/1/x": false} {
+		if _, _, ok := r.Match(http.MethodGet, path); ok != want {
+			t.Errorf("Match(%s) = %v, want %v", path, ok, want)
+		}
+	}
+}
+
+func TestLayoutRadixSharesLabels(t *testing.T) {
+	r := New()
+	h := func(w http.ResponseWriter, req *http.Request) {}
+	for _, p := range []string{"/users/{id}/settings/profile", "/orgs/{id}/settings/profile", "/teams/{id}/settings/profile"} {
+		r.Get(p, h)
+	}
+	r.MustCompile()
+
+	var labels []string
+	tree := r.state.current.Load().root
+	var walk func(n *radixNode)
+	walk = func(n *radixNode) {
+		for _, e := range tree.staticEdges(n) {
+			if label := tree.label(e); label == "/settings/profile" {
+				labels = append(labels, label)
+			}
+			walk(tree.node(e.next))
+		}
+		if pe := tree.paramChild(n); pe != nil {
+			walk(tree.node(pe.next))
+		}
+	}
+	walk(tree.node(0))
+	if len(labels) != 3 {
+		t.Fatalf("found %d /settings/profile edges, want 3", len(labels))
+	}
+	for _, l := range labels[1:] {
+		if unsafe.StringData(l) != unsafe.StringData(labels[0]) {
+			t.Fatal("duplicate labels do not share storage")
+		}
+	}
+	if _, _, ok := r.Match(http.MethodGet, "/orgs/1/settings/profile"); !ok {
+		t.Fatal("no match after interning")
+	}
+}
+
+// BenchmarkLayoutGC measures a full collection while only a large compiled
+// tree is live.
+func BenchmarkLayoutGC(b *testing.B) {
+	r := New()
+	h := func(w http.ResponseWriter, req *http.Request) {}
+	for i := range 20000 {
+		r.Get("/api/v"+strconv.Itoa(i%7)+"/orgs/{org}/repos/r"+strconv.Itoa(i)+"/issues/{number}", h)
+	}
+	r.MustCompile()
+	root := r.state.current.Load().root
+	r = nil
+	runtime.GC()
+	for b.Loop() {
+		runtime.GC()
+	}
+	runtime.KeepAlive(root)
+}
@@ -0,0 +1,68 @@
+package saruta
+
+import (
+	"context"
+	"net/http"
+	"sync"
+)
+
+// WithoutPathValues makes ServeHTTP skip req.SetPathValue for matched
+// routes. Setting path values costs a map allocation per request plus a
+// hash insert per parameter; without them the router keeps the matched values
+// in a pooled structure it attaches to the request context, and handlers read
+// them with Param. Attaching takes a copy of the request, about as costly as
+// the map for few parameters, so benchmark the routes before switching.
+//
+// The trade-off: req.PathValue returns "" for every parameter. The helpers of
+// this package (Param, Params, PathValues, ParamInt and friends, Bind,
+// redirects, StaticFS) and middleware.Coalesce work in both modes, but other
+// code calling req.PathValue does not. WithCopyParams has no effect.
+func WithoutPathValues() Option {
+	return func(r *Router) {
+		r.state.noPathValues = true
+	}
+}
+
+// Param returns the value of the path parameter name of the matched route,
+// or "" if there is none. It is req.PathValue, extended to routers created
+// with WithoutPathValues, for which it reads the values the router attached
+// to the request while matching, without allocating.
+//
+// Under WithoutPathValues the attached values are pooled and reused once
+// ServeHTTP returns: call Param during the request, not from goroutines that
+// outlive it. The strings it returned stay valid.
+func Param(req *http.Request, name string) string {
+	if v := req.PathValue(name); v != "" || req.Pattern == "" {
+		return v
+	}
+	if mp, ok := req.Context().Value(matchedParamsKey{}).(*matchedParams); ok {
+		return mp.params.get(name)
+	}
+	return ""
+}
+
+type matchedParamsKey struct{}
+
+// matchedParams holds the path values of a request served by a router
+// created with WithoutPathValues. It is taken from matchedParamsPool for the
+// duration of the handler.
+type matchedParams struct {
+	params paramStore
+}
+
+var matchedParamsPool = sync.Pool{
+	New: func() any {
+		return new(matchedParams)
+	},
+}
+
+// serveMatchedParams attaches params to req and serves it with h. It takes
+// over params, releasing them when h returns.
+func serveMatchedParams(w http.ResponseWriter, req *http.Request, h http.Handler, params *paramStore) {
+	mp := matchedParamsPool.Get().(*matchedParams)
+	mp.params = *params
+	h.ServeHTTP(w, req.WithContext(context.WithValue(req.Context(), matchedParamsKey{}, mp)))
+	mp.params.release()
+	mp.params = paramStore{}
+	matchedParamsPool.Put(mp)
+}
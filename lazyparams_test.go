@@ -0,0 +1,106 @@
+package saruta
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+)
+
+func TestWithoutPathValues(t *testing.T) {
+	var got []string
+	r := New(WithoutPathValues())
+	r.Get("/users/{id}/files/{name:[a-z]+}.{ext}/v{version}/{rest...}", func(w http.ResponseWriter, req *http.Request) {
+		if v := req.PathValue("id"); v != "" {
+			t.Errorf("PathValue(id) = %q, want empty", v)
+		}
+		for name, value := range Params(req) {
+			got = append(got, name+"="+value)
+		}
+		id, err := ParamInt(req, "id")
+		if err != nil || id != 42 {
+			t.Errorf("ParamInt = %d, %v", id, err)
+		}
+		if v := Param(req, "missing"); v != "" {
+			t.Errorf("Param(missing) = %q", v)
+		}
+	})
+	r.Get("/items/{id}", func(w http.ResponseWriter, req *http.Request) {
+		got = append(got, "item="+Param(req, "id"))
+	})
+	r.Alias("/items/{id}", "/legacy/items/{id}/show")
+	r.Rewrite("/old/{id}", "/items/{id}")
+	r.MustCompile()
+
+	for _, path := range []string{"/users/42/files/report.tar.gz/v2/a/b", "/legacy/items/7/show", "/old/9"} {
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("%s: status %d", path, rec.Code)
+		}
+	}
+	want := []string{"id=42", "name=report", "ext=tar.gz", "version=2", "rest=a/b", "item=7", "item=9"}
+	if !slices.Equal(got, want) {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+}
+
+func TestParamAllocs(t *testing.T) {
+	r := New(WithoutPathValues())
+	var allocs float64
+	r.Get("/orgs/{org}/repos/{name}.{ext}", func(w http.ResponseWriter, req *http.Request) {
+		allocs = testing.AllocsPerRun(100, func() {
+			if Param(req, "org") != "acme" || Param(req, "ext") != "git" {
+				t.Fatal("wrong value")
+			}
+		})
+	})
+	r.MustCompile()
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/orgs/acme/repos/saruta.git", nil))
+	if allocs != 0 {
+		t.Fatalf("Param allocated %v times", allocs)
+	}
+}
+
+func TestParamAfterPathChange(t *testing.T) {
+	r := New(WithoutPathValues())
+	r.Use(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			req.URL.Path = "/elsewhere"
+			next.ServeHTTP(w, req)
+		})
+	})
+	r.Get("/users/{id}", func(w http.ResponseWriter, req *http.Request) {
+		_, _ = w.Write([]byte(Param(req, "id")))
+	})
+	r.MustCompile()
+
+	for _, id := range []string{"7", "8"} {
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/users/"+id, nil))
+		if rec.Body.String() != id {
+			t.Fatalf("Param = %q, want %s", rec.Body, id)
+		}
+	}
+}
+
+func BenchmarkRouterPathValues(b *testing.B) {
+	for _, opts := range [][]Option{nil, {WithoutPathValues()}} {
+		r := New(opts...)
+		r.Get("/orgs/{org}/repos/{repo}/issues/{number}", func(w http.ResponseWriter, req *http.Request) {
+			_ = Param(req, "org")
+			_ = Param(req, "number")
+		})
+		r.MustCompile()
+		req := httptest.NewRequest(http.MethodGet, "/orgs/acme/repos/saruta/issues/42", nil)
+		w := &discardResponseWriter{}
+		b.Run(fmt.Sprintf("WithoutPathValues=%v", opts != nil), func(b *testing.B) {
+			b.ReportAllocs()
+			for b.Loop() {
+				fresh := *req // a new request per iteration, as a server would have
+				r.ServeHTTP(w, &fresh)
+			}
+		})
+	}
+}
@@ -0,0 +1,122 @@
+package saruta
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// MetaMaxBodySize holds the request body cap (int64 bytes) set by WithMaxBodySize.
+const MetaMaxBodySize = "max_body_size"
+
+// WithMaxBodySize returns a derived router whose routes reject request bodies
+// larger than n bytes.
+//
+// Requests declaring a larger Content-Length get 413 with a problem+json body
+// before router middleware and the handler run. Bodies without a declared
+// length (chunked) are wrapped with http.MaxBytesReader around the whole
+// middleware chain, so reads past the cap fail with *http.MaxBytesError, in
+// middleware as in the handler; if the chain then answers with an error, the
+// response is replaced by the same 413, instead of whatever generic 400 or
+// 500 the handler produced.
+func (r *Router) WithMaxBodySize(n int64) *Router {
+	return r.WithMeta(MetaMaxBodySize, n)
+}
+
+// LimitBody returns middleware capping request bodies at n bytes, the way
+// WithMaxBodySize caps the bodies of its routes, for handlers outside the
+// router or a default cap on every route; see middleware.BodyLimit, which
+// leaves routes with their own cap alone. n of zero or less disables the
+// cap.
+func LimitBody(n int64) Middleware {
+	return func(next http.Handler) http.Handler {
+		if n <= 0 {
+			return next
+		}
+		return limitBody(n, next)
+	}
+}
+
+func limitBody(n int64, next http.Handler) http.Handler {
+	detail := fmt.Sprintf("request body exceeds %d bytes", n)
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.ContentLength > n {
+			writeProblem(w, http.StatusRequestEntityTooLarge, detail)
+			return
+		}
+		if req.Body == nil || req.Body == http.NoBody {
+			next.ServeHTTP(w, req)
+			return
+		}
+		body := &limitedBody{ReadCloser: http.MaxBytesReader(w, req.Body, n)}
+		req.Body = body
+		next.ServeHTTP(&limitedWriter{ResponseWriter: w, body: body, detail: detail}, req)
+	})
+}
+
+// limitedBody records whether a read failed on the cap.
+type limitedBody struct {
+	io.ReadCloser
+	exceeded bool
+}
+
+func (b *limitedBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	var mbe *http.MaxBytesError
+	if errors.As(err, &mbe) {
+		b.exceeded = true
+	}
+	return n, err
+}
+
+// limitedWriter replaces an error response with 413 once the body cap has
+// been hit.
+type limitedWriter struct {
+	http.ResponseWriter
+	body        *limitedBody
+	detail      string
+	wroteHeader bool
+	replaced    bool
+}
+
+func (w *limitedWriter) WriteHeader(code int) {
+	if w.wroteHeader {
+		return
+	}
+	if code < 200 {
+		w.ResponseWriter.WriteHeader(code)
+		return
+	}
+	w.wroteHeader = true
+	if w.body.exceeded && code >= 400 {
+		w.replaced = true
+		h := w.ResponseWriter.Header()
+		h.Del("Content-Length")
+		h.Del("Content-Encoding")
+		writeProblem(w.ResponseWriter, http.StatusRequestEntityTooLarge, w.detail)
+		return
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *limitedWriter) Write(p []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	if w.replaced {
+		return len(p), nil
+	}
+	return w.ResponseWriter.Write(p)
+}
+
+func (w *limitedWriter) Flush() {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	_ = http.NewResponseController(w.ResponseWriter).Flush()
+}
+
+func (w *limitedWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
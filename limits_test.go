@@ -0,0 +1,136 @@
+package saruta
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRouterWithMaxBodySize(t *testing.T) {
+	var readErr error
+	r := New()
+	r.WithMaxBodySize(4).Post("/small", func(w http.ResponseWriter, req *http.Request) {
+		_, readErr = io.ReadAll(req.Body)
+	})
+	r.Post("/large", func(w http.ResponseWriter, req *http.Request) {
+		_, readErr = io.ReadAll(req.Body)
+	})
+	r.MustCompile()
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/small", strings.NewReader("too large")))
+	if rec.Code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusRequestEntityTooLarge)
+	}
+	if got, want := rec.Header().Get("Content-Type"), "application/problem+json"; got != want {
+		t.Fatalf("Content-Type = %q, want %q", got, want)
+	}
+	var problem struct {
+		Status int    `json:"status"`
+		Detail string `json:"detail"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &problem); err != nil {
+		t.Fatal(err)
+	}
+	if problem.Status != http.StatusRequestEntityTooLarge || problem.Detail == "" {
+		t.Fatalf("problem = %+v", problem)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/small", io.NopCloser(strings.NewReader("chunked body")))
+	req.ContentLength = -1
+	r.ServeHTTP(httptest.NewRecorder(), req)
+	var maxErr *http.MaxBytesError
+	if !errors.As(readErr, &maxErr) {
+		t.Fatalf("read error = %v, want *http.MaxBytesError", readErr)
+	}
+
+	readErr = nil
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/large", strings.NewReader("too large")))
+	if rec.Code != http.StatusOK || readErr != nil {
+		t.Fatalf("uncapped status = %d err = %v", rec.Code, readErr)
+	}
+
+	// A handler answering the failed read with an error gets the same 413.
+	r = New()
+	r.WithMaxBodySize(4).Post("/small", func(w http.ResponseWriter, req *http.Request) {
+		if _, err := io.ReadAll(req.Body); err != nil {
+			http.Error(w, "bad request", http.StatusBadRequest)
+		}
+	})
+	r.MustCompile()
+	req = httptest.NewRequest(http.MethodPost, "/small", io.NopCloser(strings.NewReader("chunked body")))
+	req.ContentLength = -1
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	if rec.Code != http.StatusRequestEntityTooLarge || rec.Header().Get("Content-Type") != "application/problem+json" {
+		t.Fatalf("chunked status = %d, Content-Type = %q", rec.Code, rec.Header().Get("Content-Type"))
+	}
+	problem.Detail = ""
+	if err := json.Unmarshal(rec.Body.Bytes(), &problem); err != nil || problem.Detail != "request body exceeds 4 bytes" {
+		t.Fatalf("chunked problem = %+v, %v (body %q)", problem, err, rec.Body)
+	}
+}
+
+func TestLimitBody(t *testing.T) {
+	h := LimitBody(4)(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if _, err := io.ReadAll(req.Body); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	}))
+	for _, tc := range []struct {
+		body    string
+		chunked bool
+		want    int
+	}{
+		{body: "abcd", want: http.StatusOK},
+		{body: "abcde", want: http.StatusRequestEntityTooLarge},
+		{body: "abcde", chunked: true, want: http.StatusRequestEntityTooLarge},
+	} {
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tc.body))
+		if tc.chunked {
+			req.ContentLength = -1
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		if rec.Code != tc.want {
+			t.Errorf("%q chunked=%v: status = %d, want %d", tc.body, tc.chunked, rec.Code, tc.want)
+		}
+		if tc.want != http.StatusOK && rec.Header().Get("Content-Type") != "application/problem+json" {
+			t.Errorf("%q chunked=%v: Content-Type = %q", tc.body, tc.chunked, rec.Header().Get("Content-Type"))
+		}
+	}
+	if got := LimitBody(0)(http.NotFoundHandler()); got == nil {
+		t.Fatal("LimitBody(0) returned nil")
+	}
+}
+
+func TestRouterWithMaxBodySizeCapsMiddleware(t *testing.T) {
+	handled := false
+	r := New()
+	r.Use(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			if _, err := io.ReadAll(req.Body); err != nil {
+				http.Error(w, "bad request", http.StatusBadRequest)
+				return
+			}
+			next.ServeHTTP(w, req)
+		})
+	})
+	r.WithMaxBodySize(10).Post("/small", func(w http.ResponseWriter, req *http.Request) {
+		handled = true
+	})
+	r.MustCompile()
+
+	req := httptest.NewRequest(http.MethodPost, "/small", io.NopCloser(strings.NewReader(strings.Repeat("x", 1000))))
+	req.ContentLength = -1
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	if rec.Code != http.StatusRequestEntityTooLarge || handled {
+		t.Fatalf("status = %d, handled = %v, want 413 before the handler", rec.Code, handled)
+	}
+}
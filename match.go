@@ -0,0 +1,69 @@
+package saruta
+
+import (
+	"maps"
+)
+
+// Match reports which route would serve a method + path request, and the
+// path values it would capture, without building an http.Request or running
+// any handler or middleware. Rewrites are applied first, as in ServeHTTP.
+//
+// ok is false when the path matches no route or the route does not accept
+// method (ServeHTTP would answer 404, 405 or hand the request to a mount).
+// Match panics if the router is not compiled.
+func (r *Router) Match(method, path string) (route RouteInfo, params map[string]string, ok bool) {
+	c := r.state.current.Load()
+	if c == nil {
+		panic("saruta: router is not compiled; call Compile or MustCompile before matching")
+	}
+	if path == "" || path[0] != '/' {
+		return RouteInfo{}, nil, false
+	}
+	if c.rewriteRoot != nil {
+		if next, ok := rewriteTarget(c.rewriteRoot, path); ok {
+			path = next
+		}
+	}
+	matched, ok := c.matchRoute(path)
+	if !ok {
+		return RouteInfo{}, nil, false
+	}
+	defer matched.params.release()
+	info, ok := matched.leaf.routes[method]
+	if !ok {
+		return RouteInfo{}, nil, false
+	}
+	route = *info
+	route.Meta = maps.Clone(info.Meta)
+	params = make(map[string]string, matched.params.len())
+	for i := range matched.params.len() {
+		p := matched.params.at(i)
+		params[p.name] = p.value
+	}
+	return route, params, true
+}
+
+// AllowedMethods returns the sorted methods routed for path, after rewrites,
+// or nil if no route matches it. Mounts are not considered. Like Match, it
+// panics if the router has not been compiled.
+//
+// CORS middleware uses it to answer preflight requests from the route table.
+func (r *Router) AllowedMethods(path string) []string {
+	c := r.state.current.Load()
+	if c == nil {
+		panic("saruta: router is not compiled; call Compile or MustCompile before matching")
+	}
+	if path == "" || path[0] != '/' {
+		return nil
+	}
+	if c.rewriteRoot != nil {
+		if next, ok := rewriteTarget(c.rewriteRoot, path); ok {
+			path = next
+		}
+	}
+	matched, ok := c.matchRoute(path)
+	if !ok || matched.leaf.handlers.len() == 0 {
+		return nil
+	}
+	return matched.leaf.handlers.methods()
+}
@@ -0,0 +1,83 @@
+package saruta
+
+import (
+	"net/http"
+	"slices"
+	"testing"
+)
+
+func TestRouterMatch(t *testing.T) {
+	noop := func(w http.ResponseWriter, req *http.Request) {}
+	r := New()
+	r.Get("/users/{id:[0-9]+}", noop)
+	r.WithMeta(MetaOwner, "files").Get("/files/{path...}", noop)
+	r.Alias("/users/{id:[0-9]+}", "/u/{id:[0-9]+}")
+	r.Rewrite("/people/{id}", "/users/{id}")
+	r.Mount("/legacy", http.NotFoundHandler())
+	r.MustCompile()
+
+	for _, tc := range []struct {
+		method  string
+		path    string
+		ok      bool
+		pattern string
+		params  map[string]string
+	}{
+		{method: "GET", path: "/users/42", ok: true, pattern: "/users/{id:[0-9]+}", params: map[string]string{"id": "42"}},
+		{method: "GET", path: "/u/7", ok: true, pattern: "/users/{id:[0-9]+}", params: map[string]string{"id": "7"}},
+		{method: "GET", path: "/people/9", ok: true, pattern: "/users/{id:[0-9]+}", params: map[string]string{"id": "9"}},
+		{method: "GET", path: "/files/a/b.txt", ok: true, pattern: "/files/{path...}", params: map[string]string{"path": "a/b.txt"}},
+		{method: "POST", path: "/users/42", ok: false},
+		{method: "GET", path: "/users/abc", ok: false},
+		{method: "GET", path: "/legacy/x", ok: false},
+		{method: "GET", path: "relative", ok: false},
+	} {
+		route, params, ok := r.Match(tc.method, tc.path)
+		if ok != tc.ok {
+			t.Fatalf("Match(%s, %s) ok = %v, want %v", tc.method, tc.path, ok, tc.ok)
+		}
+		if !ok {
+			continue
+		}
+		if route.Pattern != tc.pattern || route.Method != tc.method {
+			t.Fatalf("Match(%s, %s) route = %s %s, want %s %s", tc.method, tc.path, route.Method, route.Pattern, tc.method, tc.pattern)
+		}
+		if len(params) != len(tc.params) {
+			t.Fatalf("Match(%s, %s) params = %v, want %v", tc.method, tc.path, params, tc.params)
+		}
+		for k, v := range tc.params {
+			if params[k] != v {
+				t.Fatalf("Match(%s, %s) params = %v, want %v", tc.method, tc.path, params, tc.params)
+			}
+		}
+	}
+
+	route, _, _ := r.Match("GET", "/files/x")
+	if route.Meta[MetaOwner] != "files" {
+		t.Fatalf("meta = %v", route.Meta)
+	}
+	route.Meta[MetaOwner] = "changed"
+	if route, _, _ := r.Match("GET", "/files/x"); route.Meta[MetaOwner] != "files" {
+		t.Fatal("Match returned shared metadata")
+	}
+}
+
+func TestRouterAllowedMethods(t *testing.T) {
+	noop := func(w http.ResponseWriter, req *http.Request) {}
+	r := New()
+	r.Get("/users/{id}", noop)
+	r.Delete("/users/{id}", noop)
+	r.Put("/users/{id}", noop)
+	r.Rewrite("/u/{id}", "/users/{id}")
+	r.MustCompile()
+
+	want := []string{http.MethodDelete, http.MethodGet, http.MethodPut}
+	for _, path := range []string{"/users/1", "/u/1"} {
+		if got := r.AllowedMethods(path); !slices.Equal(got, want) {
+			t.Fatalf("AllowedMethods(%q) = %v, want %v", path, got, want)
+		}
+	}
+	if got := r.AllowedMethods("/missing"); got != nil {
+		t.Fatalf("AllowedMethods(/missing) = %v, want nil", got)
+	}
+}
@@ -0,0 +1,165 @@
+package saruta
+
+import (
+	"strings"
+	"sync"
+	"unsafe"
+)
+
+// WithMatchCache keeps the tree matches of the n most recently requested
+// paths, so that requests for a hot path skip the tree walk: a hit costs a
+// map lookup under a mutex. Only paths that match a route are cached, and
+// Compile starts with an empty cache.
+//
+// It pays off for services where a handful of URLs take most of the traffic
+// and the tree is deep or parameter-heavy; with many distinct paths the
+// cache mostly churns, and under high parallelism the mutex can cost more
+// than the walk it saves. Measure before enabling it.
+func WithMatchCache(n int) Option {
+	return func(r *Router) {
+		r.state.matchCache = max(n, 0)
+	}
+}
+
+// matchCache is an LRU of path → leaf and parameter offsets. Entries live in
+// a fixed slice linked into a recency list, so hits do not allocate.
+type matchCache struct {
+	mu      sync.Mutex
+	index   map[string]int32
+	entries []matchCacheEntry
+	head    int32 // most recently used; -1 when empty
+	tail    int32 // least recently used
+}
+
+type matchCacheEntry struct {
+	path       string
+	leaf       *radixLeaf
+	params     []paramOffset
+	prev, next int32
+}
+
+// paramOffset locates a path value in the cached path. Values are
+// substrings of the matched path, so they are rebuilt from the offsets on
+// every hit and reference the current request's path; a match with a value
+// that lies outside the path is not cached.
+type paramOffset struct {
+	name       string
+	start, end int
+}
+
+func newMatchCache(n int) *matchCache {
+	return &matchCache{
+		index:   make(map[string]int32, n),
+		entries: make([]matchCacheEntry, 0, n),
+		head:    -1,
+		tail:    -1,
+	}
+}
+
+// matchRoute is radixTree.matchRoute through the cache, when there is one.
+func (c *compiledState) matchRoute(path string) (routeMatch, bool) {
+	mc := c.cache
+	if mc == nil {
+		return c.root.matchRoute(path)
+	}
+	var m routeMatch
+	if mc.get(path, &m) {
+		return m, true
+	}
+	m, ok := c.root.matchRoute(path)
+	if ok {
+		mc.add(path, &m)
+	}
+	return m, ok
+}
+
+func (mc *matchCache) get(path string, m *routeMatch) bool {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+	i, ok := mc.index[path]
+	if !ok {
+		return false
+	}
+	mc.moveToFront(i)
+	e := &mc.entries[i]
+	m.leaf = e.leaf
+	for _, p := range e.params {
+		m.params.push(pathParam{name: p.name, value: path[p.start:p.end]})
+	}
+	return true
+}
+
+func (mc *matchCache) add(path string, m *routeMatch) {
+	params := make([]paramOffset, m.params.len())
+	base := uintptr(unsafe.Pointer(unsafe.StringData(path)))
+	for i := range params {
+		p := m.params.at(i)
+		params[i].name = p.name
+		if p.value == "" {
+			continue
+		}
+		ptr := uintptr(unsafe.Pointer(unsafe.StringData(p.value)))
+		if len(p.value) > len(path) || ptr < base || ptr-base > uintptr(len(path)-len(p.value)) {
+			return
+		}
+		start := int(ptr - base)
+		params[i].start, params[i].end = start, start+len(p.value)
+	}
+	// Copy the key so that the cache does not pin the request's URL.
+	path = strings.Clone(path)
+
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+	if _, ok := mc.index[path]; ok {
+		return // added by a concurrent request
+	}
+	var i int32
+	if len(mc.entries) < cap(mc.entries) {
+		i = int32(len(mc.entries))
+		mc.entries = append(mc.entries, matchCacheEntry{prev: -1, next: -1})
+	} else {
+		i = mc.tail
+		mc.unlink(i)
+		delete(mc.index, mc.entries[i].path)
+	}
+	mc.entries[i].path = path
+	mc.entries[i].leaf = m.leaf
+	mc.entries[i].params = params
+	mc.index[path] = i
+	mc.pushFront(i)
+}
+
+func (mc *matchCache) moveToFront(i int32) {
+	if mc.head == i {
+		return
+	}
+	mc.unlink(i)
+	mc.pushFront(i)
+}
+
+func (mc *matchCache) unlink(i int32) {
+	e := &mc.entries[i]
+	if e.prev >= 0 {
+		mc.entries[e.prev].next = e.next
+	} else {
+		mc.head = e.next
+	}
+	if e.next >= 0 {
+		mc.entries[e.next].prev = e.prev
+	} else {
+		mc.tail = e.prev
+	}
+	e.prev, e.next = -1, -1
+}
+
+func (mc *matchCache) pushFront(i int32) {
+	e := &mc.entries[i]
+	e.prev, e.next = -1, mc.head
+	if mc.head >= 0 {
+		mc.entries[mc.head].prev = i
+	}
+	mc.head = i
+	if mc.tail < 0 {
+		mc.tail = i
+	}
+}
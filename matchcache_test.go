@@ -0,0 +1,141 @@
+package saruta
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestMatchCache(t *testing.T) {
+	var got []string
+	r := New(WithMatchCache(2))
+	r.Get("/users/{id}", func(w http.ResponseWriter, req *http.Request) {
+		got = append(got, "user "+req.PathValue("id"))
+	})
+	r.Get("/files/{dir}/{name...}", func(w http.ResponseWriter, req *http.Request) {
+		got = append(got, "file "+req.PathValue("dir")+" "+req.PathValue("name"))
+	})
+	r.MustCompile()
+
+	serve := func(path string) int {
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		return rec.Code
+	}
+	cached := func() []string {
+		mc := r.state.current.Load().cache
+		var paths []string
+		for i := mc.head; i >= 0; i = mc.entries[i].next {
+			paths = append(paths, mc.entries[i].path)
+		}
+		return paths
+	}
+
+	for _, path := range []string{"/users/1", "/users/1", "/files/a/b/c", "/files/a/b/c", "/files/x/"} {
+		serve(path)
+	}
+	want := []string{"user 1", "user 1", "file a b/c", "file a b/c", "file x "}
+	if !slices.Equal(got, want) {
+		t.Fatalf("handled %q, want %q", got, want)
+	}
+	if c := cached(); !slices.Equal(c, []string{"/files/x/", "/files/a/b/c"}) {
+		t.Fatalf("cache = %q, want /users/1 evicted", c)
+	}
+	serve("/files/a/b/c")
+	if c := cached(); !slices.Equal(c, []string{"/files/a/b/c", "/files/x/"}) {
+		t.Fatalf("cache = %q after hit", c)
+	}
+	if serve("/nope") != http.StatusNotFound || len(cached()) != 2 || cached()[0] != "/files/a/b/c" {
+		t.Fatalf("404 changed the cache: %q", cached())
+	}
+
+	// Compile starts over, so new routes take precedence over cached matches.
+	r.Get("/files/static/readme", func(w http.ResponseWriter, req *http.Request) { got = append(got, "readme") })
+	r.MustCompile()
+	if len(cached()) != 0 {
+		t.Fatalf("cache after Compile = %q", cached())
+	}
+	got = nil
+	serve("/files/static/readme")
+	if !slices.Equal(got, []string{"readme"}) {
+		t.Fatalf("handled %q after Compile", got)
+	}
+}
+
+func TestMatchCacheSkipsValuesOutsidePath(t *testing.T) {
+	mc := newMatchCache(2)
+	leaf := &radixLeaf{}
+	full := strings.Clone("/users/42/extra")
+	path := full[:len("/users/42")]
+	for _, value := range []string{
+		strings.Clone("42"), // a copy, not a substring
+		full[7:],            // starts in the path but runs past its end
+		full[:len(full)-1],  // starts at the path but is longer
+	} {
+		m := routeMatch{leaf: leaf}
+		m.params.push(pathParam{name: "id", value: value})
+		mc.add(path, &m)
+		m.params.release()
+	}
+	if len(mc.index) != 0 {
+		t.Fatalf("cached %d matches with values outside the path", len(mc.index))
+	}
+
+	m := routeMatch{leaf: leaf}
+	m.params.push(pathParam{name: "id", value: path[len(path)-2:]})
+	mc.add(path, &m)
+	m.params.release()
+	var hit routeMatch
+	if !mc.get(path, &hit) || hit.params.at(0).value != "42" {
+		t.Fatal("substring match was not cached")
+	}
+	hit.params.release()
+}
+
+func TestMatchCacheLookupAllocs(t *testing.T) {
+	r := New(WithMatchCache(8))
+	r.Get("/orgs/{org}/repos/{repo}", func(w http.ResponseWriter, req *http.Request) {})
+	r.MustCompile()
+
+	var ps ParamBuffer
+	r.Lookup(http.MethodGet, "/orgs/acme/repos/saruta", &ps)
+	allocs := testing.AllocsPerRun(100, func() {
+		ps.Reset()
+		if _, ok := r.Lookup(http.MethodGet, "/orgs/acme/repos/saruta", &ps); !ok {
+			t.Fatal("no match")
+		}
+	})
+	if allocs != 0 {
+		t.Fatalf("cache hit allocated %v times", allocs)
+	}
+	if ps.Get("org") != "acme" || ps.Get("repo") != "saruta" {
+		t.Fatalf("params %q %q", ps.Get("org"), ps.Get("repo"))
+	}
+}
+
+func TestMatchCacheConcurrent(t *testing.T) {
+	r := New(WithMatchCache(4))
+	r.Get("/items/{id}", func(w http.ResponseWriter, req *http.Request) {
+		w.Write([]byte(req.PathValue("id")))
+	})
+	r.MustCompile()
+
+	var wg sync.WaitGroup
+	for g := range 8 {
+		wg.Go(func() {
+			for i := range 200 {
+				id := string(rune('a' + (g+i)%6))
+				rec := httptest.NewRecorder()
+				r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/items/"+id, nil))
+				if rec.Body.String() != id {
+					t.Errorf("/items/%s: got %q", id, rec.Body.String())
+					return
+				}
+			}
+		})
+	}
+	wg.Wait()
+}
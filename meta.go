@@ -0,0 +1,119 @@
+package saruta
+
+import (
+	"maps"
+	"slices"
+	"strings"
+)
+
+// Meta holds metadata attached to routes with WithMeta.
+//
+// Values should be JSON-encodable so that reports and exports can include them.
+type Meta map[string]any
+
+// Well-known metadata keys.
+const (
+	MetaOwner      = "owner"
+	MetaAuth       = "auth"
+	MetaDeprecated = "deprecated"
+	// MetaName is a stable route name such as "users.show", exported by
+	// ExportRoutes.
+	MetaName = "name"
+)
+
+// RouteInfo describes a registered route.
+//
+// For mounts (reported by Walk only) Mount is true, Pattern is the mount
+// prefix and Method is empty.
+type RouteInfo struct {
+	Method  string
+	Pattern string
+	Aliases []string
+	Params  []string
+	Meta    Meta
+	Mount   bool
+}
+
+func newRouteInfo(rt registeredRoute, cp compiledPattern, aliases []compiledAlias) *RouteInfo {
+	info := &RouteInfo{
+		Method:  rt.method,
+		Pattern: rt.pattern,
+		Params:  cp.paramNames(),
+		Meta:    rt.meta,
+	}
+	for _, a := range aliases {
+		info.Aliases = append(info.Aliases, a.pattern)
+	}
+	return info
+}
+
+// WithMeta returns a derived router whose subsequently registered routes carry
+// key=value in their metadata, in addition to any metadata inherited from r.
+func (r *Router) WithMeta(key string, value any) *Router {
+	d := r.With()
+	d.meta = maps.Clone(r.meta)
+	if d.meta == nil {
+		d.meta = make(Meta, 1)
+	}
+	d.meta[key] = value
+	return d
+}
+
+// Routes returns the registered routes sorted by pattern and method.
+//
+// Routes reflects registration (including routes merged with MountRouter), so
+// it can be called before Compile. Params is nil for routes with an invalid
+// pattern.
+func (r *Router) Routes() []RouteInfo {
+	reg, _ := r.state.collect()
+	return routeInfos(reg)
+}
+
+// routeInfos returns the routes of reg sorted by pattern and method.
+func routeInfos(reg registrations) []RouteInfo {
+	aliases := make(map[string][]compiledAlias, len(reg.aliases))
+	for _, ra := range reg.aliases {
+		for _, a := range ra.aliases {
+			aliases[ra.pattern] = append(aliases[ra.pattern], compiledAlias{pattern: a})
+		}
+	}
+	out := make([]RouteInfo, 0, len(reg.routes))
+	for _, rt := range reg.routes {
+		cp, _ := compilePattern(rt.pattern)
+		info := newRouteInfo(rt, cp, aliases[rt.pattern])
+		info.Meta = maps.Clone(info.Meta)
+		out = append(out, *info)
+	}
+	sortRouteInfos(out)
+	return out
+}
+
+// Walk calls fn for every registered route and mount, including those added
+// through groups, prefixes and MountRouter, in the order of Routes with each
+// mount placed by its prefix. Walk stops at the first error from fn and
+// returns it.
+//
+// Like Routes, Walk reflects registration and can be called before Compile.
+func (r *Router) Walk(fn func(route RouteInfo) error) error {
+	routes := r.Routes()
+	reg, _ := r.state.collect()
+	for _, mt := range reg.mounts {
+		routes = append(routes, RouteInfo{Pattern: mt.prefix, Mount: true})
+	}
+	sortRouteInfos(routes)
+	for _, route := range routes {
+		if err := fn(route); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func sortRouteInfos(routes []RouteInfo) {
+	slices.SortStableFunc(routes, func(a, b RouteInfo) int {
+		if c := strings.Compare(a.Pattern, b.Pattern); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Method, b.Method)
+	})
+}
@@ -0,0 +1,64 @@
+package saruta
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+)
+
+func TestRouterWalk(t *testing.T) {
+	noop := func(w http.ResponseWriter, req *http.Request) {}
+
+	sub := New()
+	sub.Get("/{id}", noop)
+
+	r := New()
+	r.Post("/users", noop)
+	r.Get("/users", noop)
+	r.Route("/api", func(api *Router) {
+		api.WithMeta(MetaOwner, "team-a").Get("/items", noop)
+	})
+	r.MountRouter("/orders", sub)
+	r.Mount("/legacy", http.NotFoundHandler())
+
+	type visit struct {
+		method, pattern string
+		mount           bool
+	}
+	var got []visit
+	err := r.Walk(func(route RouteInfo) error {
+		got = append(got, visit{route.Method, route.Pattern, route.Mount})
+		if route.Pattern == "/api/items" && route.Meta[MetaOwner] != "team-a" {
+			t.Fatalf("meta = %v", route.Meta)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []visit{
+		{"GET", "/api/items", false},
+		{"", "/legacy", true},
+		{"GET", "/orders/{id}", false},
+		{"GET", "/users", false},
+		{"POST", "/users", false},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("visits = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("visit %d = %v, want %v", i, got[i], want[i])
+		}
+	}
+
+	stop := errors.New("stop")
+	n := 0
+	err = r.Walk(func(route RouteInfo) error {
+		n++
+		return stop
+	})
+	if !errors.Is(err, stop) || n != 1 {
+		t.Fatalf("Walk error = %v after %d visits, want stop after 1", err, n)
+	}
+}
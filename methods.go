@@ -0,0 +1,130 @@
+package saruta
+
+import (
+	"net/http"
+	"slices"
+)
+
+// standardMethods are the methods stored in the array of a methodTable, in
+// index order.
+var standardMethods = [...]string{
+	http.MethodGet,
+	http.MethodPost,
+	http.MethodPut,
+	http.MethodPatch,
+	http.MethodDelete,
+	http.MethodHead,
+	http.MethodOptions,
+	http.MethodConnect,
+	http.MethodTrace,
+}
+
+// methodIndex returns the index of method in standardMethods, or -1 for a
+// custom method. The switch compiles to length and byte comparisons, which is
+// cheaper than hashing the method for a map lookup.
+func methodIndex(method string) int {
+	switch method {
+	case http.MethodGet:
+		return 0
+	case http.MethodPost:
+		return 1
+	case http.MethodPut:
+		return 2
+	case http.MethodPatch:
+		return 3
+	case http.MethodDelete:
+		return 4
+	case http.MethodHead:
+		return 5
+	case http.MethodOptions:
+		return 6
+	case http.MethodConnect:
+		return 7
+	case http.MethodTrace:
+		return 8
+	}
+	return -1
+}
+
+// methodTable holds the handlers of a leaf in the compiled tree: standard
+// methods in an array indexed by methodIndex, custom methods in a map. A nil
+// *methodTable is an empty table.
+type methodTable struct {
+	std    [len(standardMethods)]http.Handler
+	custom map[string]http.Handler
+	n      int
+	// allow and allowOptions are the Allow header values of 405 responses,
+	// without and with an automatic OPTIONS, joined once at Compile.
+	allow, allowOptions []string
+}
+
+// newMethodTable returns the table for handlers, or nil if there are none.
+func newMethodTable(handlers map[string]http.Handler) *methodTable {
+	if len(handlers) == 0 {
+		return nil
+	}
+	t := &methodTable{n: len(handlers)}
+	for method, h := range handlers {
+		if i := methodIndex(method); i >= 0 {
+			t.std[i] = h
+			continue
+		}
+		if t.custom == nil {
+			t.custom = make(map[string]http.Handler)
+		}
+		t.custom[method] = h
+	}
+	t.allow = []string{allowHeaderValue(t)}
+	t.allowOptions = []string{allowHeaderWithOptions(t)}
+	return t
+}
+
+// allowHeader returns the Allow header value for a 405 response, to be
+// assigned to the header map directly: Header.Set would allocate a new slice
+// per response. The slice is shared and must not be modified.
+func (t *methodTable) allowHeader(autoOptions bool) []string {
+	if t == nil {
+		return nil
+	}
+	if autoOptions {
+		return t.allowOptions[:1:1]
+	}
+	return t.allow[:1:1]
+}
+
+func (t *methodTable) get(method string) (http.Handler, bool) {
+	if t == nil {
+		return nil, false
+	}
+	if i := methodIndex(method); i >= 0 {
+		h := t.std[i]
+		return h, h != nil
+	}
+	h, ok := t.custom[method]
+	return h, ok
+}
+
+func (t *methodTable) len() int {
+	if t == nil {
+		return 0
+	}
+	return t.n
+}
+
+// methods returns the registered methods in sorted order.
+func (t *methodTable) methods() []string {
+	if t == nil {
+		return nil
+	}
+	methods := make([]string, 0, t.n+1)
+	for i, h := range t.std {
+		if h != nil {
+			methods = append(methods, standardMethods[i])
+		}
+	}
+	for method := range t.custom {
+		methods = append(methods, method)
+	}
+	slices.Sort(methods)
+	return methods
+}
@@ -0,0 +1,69 @@
+package saruta
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+)
+
+func TestMethodTable(t *testing.T) {
+	h := func(name string) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) { w.Write([]byte(name)) })
+	}
+	tbl := newMethodTable(map[string]http.Handler{
+		http.MethodGet:    h("get"),
+		http.MethodDelete: h("delete"),
+		"PURGE":           h("purge"),
+	})
+	if tbl.len() != 3 {
+		t.Fatalf("len = %d, want 3", tbl.len())
+	}
+	for _, method := range []string{http.MethodGet, http.MethodDelete, "PURGE"} {
+		if _, ok := tbl.get(method); !ok {
+			t.Errorf("get(%s) not found", method)
+		}
+	}
+	for _, method := range []string{http.MethodPost, "get", "PROPFIND"} {
+		if _, ok := tbl.get(method); ok {
+			t.Errorf("get(%s) found", method)
+		}
+	}
+	if got, want := tbl.methods(), []string{"DELETE", "GET", "PURGE"}; !slices.Equal(got, want) {
+		t.Errorf("methods = %q, want %q", got, want)
+	}
+
+	var empty *methodTable
+	if _, ok := empty.get(http.MethodGet); ok || empty.len() != 0 || empty.methods() != nil {
+		t.Error("nil table is not empty")
+	}
+	if newMethodTable(nil) != nil {
+		t.Error("newMethodTable(nil) != nil")
+	}
+	for i, method := range standardMethods {
+		if methodIndex(method) != i {
+			t.Errorf("methodIndex(%s) = %d, want %d", method, methodIndex(method), i)
+		}
+	}
+}
+
+func TestRouterCustomMethods(t *testing.T) {
+	r := New()
+	r.Get("/items/{id}", func(w http.ResponseWriter, req *http.Request) { w.Write([]byte("get")) })
+	r.HandleFunc("PURGE", "/items/{id}", func(w http.ResponseWriter, req *http.Request) { w.Write([]byte("purge")) })
+	r.MustCompile()
+
+	for method, want := range map[string]string{http.MethodGet: "get", "PURGE": "purge"} {
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, httptest.NewRequest(method, "/items/1", nil))
+		if rec.Code != http.StatusOK || rec.Body.String() != want {
+			t.Errorf("%s: status %d body %q", method, rec.Code, rec.Body.String())
+		}
+	}
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/items/1", nil))
+	if rec.Code != http.StatusMethodNotAllowed || rec.Header().Get("Allow") != "GET, PURGE" {
+		t.Fatalf("POST: status %d Allow %q", rec.Code, rec.Header().Get("Allow"))
+	}
+}
@@ -8,9 +8,77 @@ import "net/http"
 // A -> B -> handler.
 type Middleware func(http.Handler) http.Handler
 
-func chainMiddlewares(h http.Handler, mws []Middleware) http.Handler {
+// RouteMiddleware is middleware that knows the route it wraps. Compile calls
+// it once per route with the route's pattern, method, parameter names and
+// metadata, so per-route decisions (auth scopes, rate-limit buckets) are made
+// up front and cost nothing per request:
+//
+//	r.UseRoute(func(route saruta.RouteInfo, next http.Handler) http.Handler {
+//		scope, _ := route.Meta["scope"].(string)
+//		if scope == "" {
+//			return next
+//		}
+//		return requireScope(scope, next)
+//	})
+//
+// Returning next unchanged skips the route. Route middleware is ordered with
+// plain middleware in registration order.
+type RouteMiddleware func(route RouteInfo, next http.Handler) http.Handler
+
+// middlewareEntry is one element of a middleware chain; exactly one of mw and
+// route is set. onErrors marks middleware registered with UseWithErrors.
+type middlewareEntry struct {
+	mw       Middleware
+	route    RouteMiddleware
+	onErrors bool
+}
+
+func plainEntries(mws []Middleware) []middlewareEntry {
+	out := make([]middlewareEntry, len(mws))
+	for i, mw := range mws {
+		out[i] = middlewareEntry{mw: mw}
+	}
+	return out
+}
+
+func routeEntries(mws []RouteMiddleware) []middlewareEntry {
+	out := make([]middlewareEntry, len(mws))
+	for i, mw := range mws {
+		out[i] = middlewareEntry{route: mw}
+	}
+	return out
+}
+
+// chainRoute wraps h with the middleware of rt. streaming.go replaces it to
+// check and guard streaming routes; the reflect that needs is left out of
+// TinyGo builds.
+var chainRoute = func(rt registeredRoute, h http.Handler, route RouteInfo) (http.Handler, error) {
+	return chainMiddlewares(h, rt.middleware, route), nil
+}
+
+// errorEntries returns the middleware of mws that wraps the NotFound and
+// MethodNotAllowed handlers: all of it with WithMiddlewareOnErrors, otherwise
+// only the entries registered with UseWithErrors.
+func (s *routerState) errorEntries(mws []middlewareEntry) []middlewareEntry {
+	if s.errorMiddleware {
+		return mws
+	}
+	var out []middlewareEntry
+	for _, m := range mws {
+		if m.onErrors {
+			out = append(out, m)
+		}
+	}
+	return out
+}
+
+func chainMiddlewares(h http.Handler, mws []middlewareEntry, route RouteInfo) http.Handler {
 	for i := len(mws) - 1; i >= 0; i-- {
-		h = mws[i](h)
+		if m := mws[i]; m.route != nil {
+			h = m.route(route, h)
+		} else {
+			h = m.mw(h)
+		}
 	}
 	return h
 }
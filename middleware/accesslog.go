@@ -0,0 +1,123 @@
+package middleware
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// AccessLogFormat selects the line format of an AccessLogger.
+type AccessLogFormat int
+
+const (
+	// CommonLogFormat is the NCSA Common Log Format:
+	//	host ident authuser [date] "request" status bytes
+	CommonLogFormat AccessLogFormat = iota
+	// CombinedLogFormat appends "referer" "user-agent" to CommonLogFormat.
+	CombinedLogFormat
+)
+
+// AccessLogFlushInterval bounds how long a line may sit in an AccessLogger's
+// buffer before it is written out.
+const AccessLogFlushInterval = time.Second
+
+// AccessLogger writes Apache/Nginx-compatible access log lines to an
+// io.Writer. Lines are buffered and flushed when the buffer fills or
+// AccessLogFlushInterval after the first unflushed line; call Flush on
+// shutdown to write out the rest.
+type AccessLogger struct {
+	format AccessLogFormat
+
+	mu    sync.Mutex
+	bw    *bufio.Writer
+	timer *time.Timer
+}
+
+// NewAccessLogger returns an AccessLogger writing to w.
+func NewAccessLogger(w io.Writer, format AccessLogFormat) *AccessLogger {
+	return &AccessLogger{format: format, bw: bufio.NewWriter(w)}
+}
+
+// Middleware logs one line per request.
+func (l *AccessLogger) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		start := time.Now()
+		sw := &statusWriter{ResponseWriter: w}
+		next.ServeHTTP(sw, req)
+		l.write(l.line(req, start, sw.Status(), sw.bytes))
+	})
+}
+
+// Flush writes buffered lines to the underlying writer.
+func (l *AccessLogger) Flush() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.timer != nil {
+		l.timer.Stop()
+		l.timer = nil
+	}
+	return l.bw.Flush()
+}
+
+func (l *AccessLogger) write(line []byte) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	_, _ = l.bw.Write(line)
+	if l.bw.Buffered() > 0 && l.timer == nil {
+		l.timer = time.AfterFunc(AccessLogFlushInterval, func() { _ = l.Flush() })
+	}
+}
+
+func (l *AccessLogger) line(req *http.Request, start time.Time, status int, size int64) []byte {
+	host, _, err := net.SplitHostPort(req.RemoteAddr)
+	if err != nil {
+		host = req.RemoteAddr
+	}
+	user := "-"
+	if u, _, ok := req.BasicAuth(); ok && u != "" {
+		user = u
+	}
+	b := make([]byte, 0, 256)
+	b = append(b, clfField(host)...)
+	b = append(b, " - "...)
+	b = append(b, clfField(user)...)
+	b = append(b, " ["...)
+	b = start.AppendFormat(b, "02/Jan/2006:15:04:05 -0700")
+	b = append(b, `] "`...)
+	b = append(b, clfEscape(req.Method+" "+req.RequestURI+" "+req.Proto)...)
+	b = append(b, `" `...)
+	b = strconv.AppendInt(b, int64(status), 10)
+	b = append(b, ' ')
+	if size > 0 {
+		b = strconv.AppendInt(b, size, 10)
+	} else {
+		b = append(b, '-')
+	}
+	if l.format == CombinedLogFormat {
+		b = append(b, ` "`...)
+		b = append(b, clfEscape(req.Referer())...)
+		b = append(b, `" "`...)
+		b = append(b, clfEscape(req.UserAgent())...)
+		b = append(b, '"')
+	}
+	return append(b, '\n')
+}
+
+var clfEscaper = strings.NewReplacer(`"`, `\"`, `\`, `\\`, "\n", `\n`, "\r", `\r`)
+
+func clfEscape(s string) string {
+	return clfEscaper.Replace(s)
+}
+
+// clfField escapes an unquoted field, which must not contain spaces.
+func clfField(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return strings.ReplaceAll(clfEscape(s), " ", "%20")
+}
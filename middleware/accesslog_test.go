@@ -0,0 +1,50 @@
+package middleware
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+)
+
+func TestAccessLogger(t *testing.T) {
+	var buf bytes.Buffer
+	l := NewAccessLogger(&buf, CombinedLogFormat)
+	h := l.Middleware(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte("hello"))
+	}))
+	req := httptest.NewRequest(http.MethodPost, "/users?x=1", nil)
+	req.RemoteAddr = "192.0.2.1:4321"
+	req.SetBasicAuth("alice", "secret")
+	req.Header.Set("Referer", "https://example.com/")
+	req.Header.Set("User-Agent", `curl/8.0 "quoted"`)
+	h.ServeHTTP(httptest.NewRecorder(), req)
+
+	if buf.Len() != 0 {
+		t.Fatalf("line written before Flush: %q", buf.String())
+	}
+	if err := l.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	want := regexp.MustCompile(`^192\.0\.2\.1 - alice \[\d{2}/\w{3}/\d{4}:\d{2}:\d{2}:\d{2} [+-]\d{4}\] "POST /users\?x=1 HTTP/1\.1" 201 5 "https://example\.com/" "curl/8\.0 \\"quoted\\""\n$`)
+	if !want.Match(buf.Bytes()) {
+		t.Fatalf("line = %q", buf.String())
+	}
+}
+
+func TestAccessLoggerCommon(t *testing.T) {
+	var buf bytes.Buffer
+	l := NewAccessLogger(&buf, CommonLogFormat)
+	h := l.Middleware(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.RemoteAddr = "198.51.100.2:80"
+	h.ServeHTTP(httptest.NewRecorder(), req)
+	_ = l.Flush()
+	if want := regexp.MustCompile(`^198\.51\.100\.2 - - \[[^]]+\] "GET / HTTP/1\.1" 204 -\n$`); !want.Match(buf.Bytes()) {
+		t.Fatalf("line = %q", buf.String())
+	}
+}
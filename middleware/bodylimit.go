@@ -0,0 +1,30 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/catatsuy/saruta"
+)
+
+// BodyLimit caps request bodies at def bytes, except on routes with their
+// own saruta.MetaMaxBodySize (set with WithMaxBodySize), which the router
+// already enforces. Register it with UseRoute; the cap is chosen once per
+// route at Compile:
+//
+//	r.UseRoute(middleware.BodyLimit(1 << 20))
+//	r.WithMaxBodySize(64 << 20).Post("/uploads", upload)
+//
+// Bodies over the cap are answered as for WithMaxBodySize (see
+// saruta.LimitBody): 413 with a problem+json body, before the handler runs
+// when Content-Length declares the size, or in place of the handler's error
+// response when a read hits the cap. A def of zero or less leaves routes
+// without metadata uncapped.
+func BodyLimit(def int64) saruta.RouteMiddleware {
+	limit := saruta.LimitBody(def)
+	return func(route saruta.RouteInfo, next http.Handler) http.Handler {
+		if _, ok := route.Meta[saruta.MetaMaxBodySize].(int64); ok {
+			return next
+		}
+		return limit(next)
+	}
+}
@@ -0,0 +1,58 @@
+package middleware
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/catatsuy/saruta"
+)
+
+func TestBodyLimit(t *testing.T) {
+	echo := func(w http.ResponseWriter, req *http.Request) {
+		b, err := io.ReadAll(req.Body)
+		if err != nil {
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+		_, _ = w.Write(b)
+	}
+	r := saruta.New()
+	r.UseRoute(BodyLimit(4))
+	r.Post("/small", echo)
+	r.WithMaxBodySize(8).Post("/large", echo)
+	r.MustCompile()
+
+	for _, tc := range []struct {
+		path    string
+		body    string
+		chunked bool
+		want    int
+		wantMsg string
+	}{
+		{path: "/small", body: "abcd", want: http.StatusOK, wantMsg: "abcd"},
+		{path: "/small", body: "abcde", want: http.StatusRequestEntityTooLarge, wantMsg: "request body exceeds 4 bytes"},
+		{path: "/small", body: "abcde", chunked: true, want: http.StatusRequestEntityTooLarge, wantMsg: "request body exceeds 4 bytes"},
+		{path: "/large", body: "abcdefgh", chunked: true, want: http.StatusOK, wantMsg: "abcdefgh"},
+		{path: "/large", body: "abcdefghi", chunked: true, want: http.StatusRequestEntityTooLarge, wantMsg: "request body exceeds 8 bytes"},
+	} {
+		req := httptest.NewRequest(http.MethodPost, tc.path, strings.NewReader(tc.body))
+		if tc.chunked {
+			req.ContentLength = -1
+		}
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+		body := rec.Body.String()
+		if rec.Code == http.StatusRequestEntityTooLarge {
+			var problem struct{ Detail string }
+			_ = json.Unmarshal(rec.Body.Bytes(), &problem)
+			body = problem.Detail
+		}
+		if rec.Code != tc.want || body != tc.wantMsg {
+			t.Fatalf("%s %q chunked=%v: %d %q, want %d %q", tc.path, tc.body, tc.chunked, rec.Code, rec.Body.String(), tc.want, tc.wantMsg)
+		}
+	}
+}
@@ -0,0 +1,162 @@
+package middleware
+
+import (
+	"bytes"
+	"maps"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/catatsuy/saruta"
+)
+
+// CoalesceOptions configures Coalesce.
+type CoalesceOptions struct {
+	// VaryHeaders lists request headers whose values are part of the key, so
+	// requests that may get different responses are not merged. It defaults
+	// to Authorization and Cookie; set it to a non-nil empty slice to key on
+	// the URL alone.
+	VaryHeaders []string
+}
+
+// Coalesce merges concurrent identical GET requests: while one request for a
+// key is being served, others with the same key wait for it and receive a
+// copy of its response instead of running the handler again. This protects
+// expensive read endpoints from cache stampedes.
+//
+// The key is the route pattern, its path parameter values, the raw query and
+// the VaryHeaders. Register it with UseRoute or WithRoute, since the
+// parameter names come from the route:
+//
+//	r.WithRoute(middleware.Coalesce(middleware.CoalesceOptions{})).Get("/products/{id}", product)
+//
+// Responses are buffered, so it is unsuitable for streaming handlers. A
+// response that sets a cookie or is marked Cache-Control: private belongs to
+// one client and is not shared: the waiting requests run the handler
+// themselves. If the first request's handler panics, the waiting requests get
+// 500. A waiting request whose context is canceled returns without a
+// response.
+func Coalesce(opts CoalesceOptions) saruta.RouteMiddleware {
+	vary := opts.VaryHeaders
+	if vary == nil {
+		vary = []string{"Authorization", "Cookie"}
+	}
+	return func(route saruta.RouteInfo, next http.Handler) http.Handler {
+		g := &flightGroup{calls: make(map[string]*flightCall)}
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			if req.Method != http.MethodGet {
+				next.ServeHTTP(w, req)
+				return
+			}
+			var key strings.Builder
+			key.WriteString(route.Pattern)
+			for _, name := range route.Params {
+				key.WriteByte(0)
+				key.WriteString(saruta.Param(req, name))
+			}
+			key.WriteByte(0)
+			key.WriteString(req.URL.RawQuery)
+			for _, h := range vary {
+				key.WriteByte(0)
+				key.WriteString(strings.Join(req.Header.Values(h), "\x01"))
+			}
+			g.do(key.String(), w, req, next)
+		})
+	}
+}
+
+type flightGroup struct {
+	mu    sync.Mutex
+	calls map[string]*flightCall
+}
+
+type flightCall struct {
+	done   chan struct{}
+	header http.Header
+	status int
+	body   bytes.Buffer
+	failed bool
+	shared bool // the response may be copied to other clients
+}
+
+func (g *flightGroup) do(key string, w http.ResponseWriter, req *http.Request, next http.Handler) {
+	g.mu.Lock()
+	if c, ok := g.calls[key]; ok {
+		g.mu.Unlock()
+		select {
+		case <-c.done:
+		case <-req.Context().Done():
+			return
+		}
+		if !c.failed && !c.shared {
+			next.ServeHTTP(w, req)
+			return
+		}
+		c.writeTo(w)
+		return
+	}
+	c := &flightCall{done: make(chan struct{}), header: make(http.Header)}
+	g.calls[key] = c
+	g.mu.Unlock()
+
+	defer func() {
+		g.mu.Lock()
+		delete(g.calls, key)
+		g.mu.Unlock()
+		close(c.done)
+	}()
+	c.failed = true // until next returns normally
+	next.ServeHTTP(&flightWriter{c: c}, req)
+	c.failed = false
+	c.shared = shareable(c.header)
+	c.writeTo(w)
+}
+
+// shareable reports whether a response with header h can be given to
+// clients other than the one it was made for.
+func shareable(h http.Header) bool {
+	if len(h.Values("Set-Cookie")) > 0 {
+		return false
+	}
+	for _, v := range h.Values("Cache-Control") {
+		for directive := range strings.SplitSeq(v, ",") {
+			name, _, _ := strings.Cut(strings.TrimSpace(directive), "=")
+			if strings.EqualFold(name, "private") {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func (c *flightCall) writeTo(w http.ResponseWriter) {
+	if c.failed {
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	maps.Copy(w.Header(), c.header.Clone())
+	w.WriteHeader(max(c.status, http.StatusOK))
+	_, _ = w.Write(c.body.Bytes())
+}
+
+// flightWriter buffers the response of the leading request.
+type flightWriter struct {
+	c *flightCall
+}
+
+func (w *flightWriter) Header() http.Header {
+	return w.c.header
+}
+
+func (w *flightWriter) WriteHeader(code int) {
+	if w.c.status == 0 && code >= 200 {
+		w.c.status = code
+	}
+}
+
+func (w *flightWriter) Write(p []byte) (int, error) {
+	if w.c.status == 0 {
+		w.c.status = http.StatusOK
+	}
+	return w.c.body.Write(p)
+}
@@ -0,0 +1,160 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/catatsuy/saruta"
+)
+
+func TestCoalesce(t *testing.T) {
+	var runs atomic.Int32
+	var arrived atomic.Int32
+	release := make(chan struct{})
+	r := saruta.New()
+	r.Use(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			arrived.Add(1)
+			next.ServeHTTP(w, req)
+		})
+	})
+	r.WithRoute(Coalesce(CoalesceOptions{})).Get("/products/{id}", func(w http.ResponseWriter, req *http.Request) {
+		runs.Add(1)
+		if req.PathValue("id") == "1" {
+			<-release
+		}
+		w.Header().Set("X-Product", req.PathValue("id"))
+		_, _ = w.Write([]byte("product " + req.PathValue("id")))
+	})
+	r.MustCompile()
+
+	const n = 5
+	recs := make([]*httptest.ResponseRecorder, n)
+	var wg sync.WaitGroup
+	for i := range n {
+		recs[i] = httptest.NewRecorder()
+		wg.Go(func() {
+			r.ServeHTTP(recs[i], httptest.NewRequest(http.MethodGet, "/products/1", nil))
+		})
+	}
+	for arrived.Load() < n {
+		time.Sleep(time.Millisecond)
+	}
+	time.Sleep(20 * time.Millisecond) // let the followers reach the wait
+
+	// A different key is not blocked by the in-flight request.
+	other := httptest.NewRecorder()
+	r.ServeHTTP(other, httptest.NewRequest(http.MethodGet, "/products/2", nil))
+	if other.Body.String() != "product 2" {
+		t.Fatalf("other key: %q", other.Body.String())
+	}
+
+	close(release)
+	wg.Wait()
+	if got := runs.Load(); got != 2 {
+		t.Fatalf("handler ran %d times, want 2 (one per key)", got)
+	}
+	for i, rec := range recs {
+		if rec.Code != http.StatusOK || rec.Body.String() != "product 1" || rec.Header().Get("X-Product") != "1" {
+			t.Fatalf("response %d: %d %q %v", i, rec.Code, rec.Body.String(), rec.Header())
+		}
+	}
+}
+
+func TestCoalesceVaryHeaders(t *testing.T) {
+	var runs atomic.Int32
+	release := make(chan struct{})
+	started := make(chan struct{}, 2)
+	h := Coalesce(CoalesceOptions{})(saruta.RouteInfo{Pattern: "/me"}, http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		runs.Add(1)
+		started <- struct{}{}
+		<-release
+		_, _ = w.Write([]byte(req.Header.Get("Authorization")))
+	}))
+
+	var wg sync.WaitGroup
+	for _, token := range []string{"Bearer a", "Bearer b"} {
+		wg.Go(func() {
+			req := httptest.NewRequest(http.MethodGet, "/me", nil)
+			req.Header.Set("Authorization", token)
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+			if rec.Body.String() != token {
+				t.Errorf("got %q, want %q", rec.Body.String(), token)
+			}
+		})
+	}
+	<-started
+	<-started
+	close(release)
+	wg.Wait()
+	if runs.Load() != 2 {
+		t.Fatalf("handler ran %d times, want 2", runs.Load())
+	}
+}
+
+func TestCoalescePrivateResponses(t *testing.T) {
+	for _, private := range []func(http.Header){
+		func(h http.Header) { h.Add("Set-Cookie", "session=new") },
+		func(h http.Header) { h.Set("Cache-Control", "no-cache, Private") },
+	} {
+		var runs atomic.Int32
+		release := make(chan struct{})
+		started := make(chan struct{}, 1)
+		h := Coalesce(CoalesceOptions{})(saruta.RouteInfo{Pattern: "/login"}, http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			if runs.Add(1) == 1 {
+				started <- struct{}{}
+				<-release
+			}
+			private(w.Header())
+		}))
+
+		const n = 3
+		var wg sync.WaitGroup
+		wg.Go(func() {
+			h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/login", nil))
+		})
+		<-started
+		for range n - 1 {
+			wg.Go(func() {
+				h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/login", nil))
+			})
+		}
+		time.Sleep(20 * time.Millisecond) // let the followers reach the wait
+		close(release)
+		wg.Wait()
+		if got := runs.Load(); got != n {
+			t.Fatalf("handler ran %d times, want %d", got, n)
+		}
+	}
+}
+
+func TestCoalesceWaiterCanceled(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+	started := make(chan struct{})
+	h := Coalesce(CoalesceOptions{})(saruta.RouteInfo{Pattern: "/slow"}, http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		close(started)
+		<-release
+	}))
+	go h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/slow", nil))
+	<-started
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/slow", nil).WithContext(ctx))
+		close(done)
+	}()
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("waiter did not return after its context was canceled")
+	}
+}
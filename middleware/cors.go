@@ -0,0 +1,159 @@
+package middleware
+
+import (
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/catatsuy/saruta"
+)
+
+// RouteMethods reports the methods a path is routed for. *saruta.Router
+// implements it.
+type RouteMethods interface {
+	AllowedMethods(path string) []string
+}
+
+// RouteMatcher reports the route a request would match. *saruta.Router
+// implements it.
+type RouteMatcher interface {
+	Match(method, path string) (route saruta.RouteInfo, params map[string]string, ok bool)
+}
+
+// CORSOptions configures CORS.
+type CORSOptions struct {
+	// Origins lists the allowed origins, e.g. "https://app.example.com".
+	// "*" allows any origin.
+	Origins []string
+	// Methods lists the methods allowed in preflight responses. If empty,
+	// they are taken from Routes, so preflights reflect the route table.
+	Methods []string
+	// Headers lists the request headers allowed in preflight responses. If
+	// empty, the headers requested by the browser are allowed.
+	Headers []string
+	// ExposedHeaders lists response headers readable by scripts.
+	ExposedHeaders []string
+	// Credentials allows cookies and HTTP authentication for the listed
+	// origins. It cannot be combined with "*" in Origins: CORS panics rather
+	// than let every site make credentialed requests.
+	Credentials bool
+	// MaxAge is how long browsers may cache preflight responses. Zero omits
+	// Access-Control-Max-Age. A route with saruta.MetaCORSMaxAge (set by
+	// WithCORSMaxAge) overrides it for its preflights, which then also carry
+	// "Cache-Control: public, max-age=N", as automatic OPTIONS responses do.
+	MaxAge time.Duration
+	// Routes supplies the methods of each path when Methods is empty. If nil
+	// and the wrapped handler implements RouteMethods (as *saruta.Router
+	// does), it is used. Without either, GET, HEAD and POST are allowed. If
+	// it also implements RouteMatcher, preflights read the route's metadata.
+	Routes RouteMethods
+}
+
+// CORS adds Cross-Origin Resource Sharing headers for allowed origins and
+// answers preflight requests (OPTIONS with Access-Control-Request-Method)
+// with 204 itself.
+//
+// To answer preflights for every path, wrap the router rather than
+// registering CORS with Use, since saruta does not run route middleware for
+// OPTIONS requests to paths without an OPTIONS route:
+//
+//	handler := middleware.CORS(middleware.CORSOptions{Origins: []string{"https://app.example.com"}})(r)
+//
+// Preflights for paths no route matches are passed on to the wrapped handler
+// (typically answering 404). Requests without an allowed Origin are passed
+// on unchanged. CORS panics if opts sets Credentials with a "*" origin.
+func CORS(opts CORSOptions) func(http.Handler) http.Handler {
+	anyOrigin := slices.Contains(opts.Origins, "*")
+	if anyOrigin && opts.Credentials {
+		panic(`middleware: CORS Credentials cannot be used with the "*" origin`)
+	}
+	methods := strings.Join(opts.Methods, ", ")
+	headers := strings.Join(opts.Headers, ", ")
+	exposed := strings.Join(opts.ExposedHeaders, ", ")
+	var maxAge string
+	if opts.MaxAge > 0 {
+		maxAge = strconv.FormatInt(int64(opts.MaxAge/time.Second), 10)
+	}
+	allowed := func(origin string) bool {
+		return anyOrigin || slices.Contains(opts.Origins, origin)
+	}
+
+	return func(next http.Handler) http.Handler {
+		routes := opts.Routes
+		if routes == nil {
+			routes, _ = next.(RouteMethods)
+		}
+		matcher, _ := routes.(RouteMatcher)
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			origin := req.Header.Get("Origin")
+			h := w.Header()
+			h.Add("Vary", "Origin")
+			if origin == "" || !allowed(origin) {
+				next.ServeHTTP(w, req)
+				return
+			}
+			if anyOrigin {
+				h.Set("Access-Control-Allow-Origin", "*")
+			} else {
+				h.Set("Access-Control-Allow-Origin", origin)
+			}
+			if opts.Credentials {
+				h.Set("Access-Control-Allow-Credentials", "true")
+			}
+
+			if req.Method != http.MethodOptions || req.Header.Get("Access-Control-Request-Method") == "" {
+				if exposed != "" {
+					h.Set("Access-Control-Expose-Headers", exposed)
+				}
+				next.ServeHTTP(w, req)
+				return
+			}
+
+			allowMethods := methods
+			if allowMethods == "" {
+				if routes == nil {
+					allowMethods = "GET, HEAD, POST"
+				} else {
+					m := routes.AllowedMethods(req.URL.Path)
+					if m == nil {
+						next.ServeHTTP(w, req)
+						return
+					}
+					allowMethods = strings.Join(m, ", ")
+				}
+			}
+			h.Add("Vary", "Access-Control-Request-Method")
+			h.Add("Vary", "Access-Control-Request-Headers")
+			h.Set("Access-Control-Allow-Methods", allowMethods)
+			if headers != "" {
+				h.Set("Access-Control-Allow-Headers", headers)
+			} else if reqHeaders := req.Header.Get("Access-Control-Request-Headers"); reqHeaders != "" {
+				h.Set("Access-Control-Allow-Headers", reqHeaders)
+			}
+			if d, ok := routeMaxAge(matcher, req); ok {
+				secs := strconv.FormatInt(int64(d/time.Second), 10)
+				h.Set("Access-Control-Max-Age", secs)
+				h.Set("Cache-Control", "public, max-age="+secs)
+			} else if maxAge != "" {
+				h.Set("Access-Control-Max-Age", maxAge)
+			}
+			w.WriteHeader(http.StatusNoContent)
+		})
+	}
+}
+
+// routeMaxAge returns the saruta.MetaCORSMaxAge of the route the preflight
+// req asks about.
+func routeMaxAge(matcher RouteMatcher, req *http.Request) (time.Duration, bool) {
+	if matcher == nil {
+		return 0, false
+	}
+	route, _, ok := matcher.Match(req.Header.Get("Access-Control-Request-Method"), req.URL.Path)
+	if !ok {
+		return 0, false
+	}
+	d, ok := route.Meta[saruta.MetaCORSMaxAge].(time.Duration)
+	return d, ok && d >= 0
+}
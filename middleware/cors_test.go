@@ -0,0 +1,131 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/catatsuy/saruta"
+)
+
+func TestCORS(t *testing.T) {
+	r := saruta.New()
+	r.Get("/users/{id}", func(w http.ResponseWriter, req *http.Request) {
+		_, _ = w.Write([]byte("user"))
+	})
+	r.Delete("/users/{id}", func(w http.ResponseWriter, req *http.Request) {})
+	r.MustCompile()
+	h := CORS(CORSOptions{
+		Origins:        []string{"https://app.example.com"},
+		ExposedHeaders: []string{"X-Request-Id"},
+		MaxAge:         10 * time.Minute,
+	})(r)
+
+	serve := func(method, path, origin string, header map[string]string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, nil)
+		if origin != "" {
+			req.Header.Set("Origin", origin)
+		}
+		for k, v := range header {
+			req.Header.Set(k, v)
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := serve(http.MethodGet, "/users/1", "https://app.example.com", nil)
+	if rec.Body.String() != "user" || rec.Header().Get("Access-Control-Allow-Origin") != "https://app.example.com" ||
+		rec.Header().Get("Access-Control-Expose-Headers") != "X-Request-Id" {
+		t.Fatalf("simple request: %q %v", rec.Body.String(), rec.Header())
+	}
+
+	rec = serve(http.MethodGet, "/users/1", "https://evil.example", nil)
+	if rec.Header().Get("Access-Control-Allow-Origin") != "" || rec.Body.String() != "user" {
+		t.Fatalf("disallowed origin: %v", rec.Header())
+	}
+
+	rec = serve(http.MethodOptions, "/users/1", "https://app.example.com", map[string]string{
+		"Access-Control-Request-Method":  http.MethodDelete,
+		"Access-Control-Request-Headers": "Authorization",
+	})
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("preflight status = %d", rec.Code)
+	}
+	for name, want := range map[string]string{
+		"Access-Control-Allow-Origin":  "https://app.example.com",
+		"Access-Control-Allow-Methods": "DELETE, GET",
+		"Access-Control-Allow-Headers": "Authorization",
+		"Access-Control-Max-Age":       "600",
+	} {
+		if got := rec.Header().Get(name); got != want {
+			t.Fatalf("preflight %s = %q, want %q", name, got, want)
+		}
+	}
+
+	rec = serve(http.MethodOptions, "/missing", "https://app.example.com", map[string]string{
+		"Access-Control-Request-Method": http.MethodGet,
+	})
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("preflight for unknown path: status %d, want 404", rec.Code)
+	}
+}
+
+func TestCORSWildcard(t *testing.T) {
+	next := http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {})
+	h := CORS(CORSOptions{Origins: []string{"*"}, Methods: []string{"GET", "PATCH"}})(next)
+	req := httptest.NewRequest(http.MethodOptions, "/", nil)
+	req.Header.Set("Origin", "https://a.example")
+	req.Header.Set("Access-Control-Request-Method", "PATCH")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if got := rec.Header().Get("Access-Control-Allow-Origin"); got != "*" {
+		t.Fatalf("origin = %q, want *", got)
+	}
+	if got := rec.Header().Get("Access-Control-Allow-Credentials"); got != "" {
+		t.Fatalf("credentials = %q, want none", got)
+	}
+	if got := rec.Header().Get("Access-Control-Allow-Methods"); got != "GET, PATCH" {
+		t.Fatalf("methods = %q", got)
+	}
+}
+
+func TestCORSWildcardCredentials(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatal("CORS with Credentials and a \"*\" origin did not panic")
+		}
+	}()
+	CORS(CORSOptions{Origins: []string{"https://a.example", "*"}, Credentials: true})
+}
+
+func TestCORSRouteMaxAge(t *testing.T) {
+	r := saruta.New()
+	r.Get("/users", func(w http.ResponseWriter, req *http.Request) {})
+	r.WithCORSMaxAge(time.Hour).Post("/users", func(w http.ResponseWriter, req *http.Request) {})
+	r.MustCompile()
+	h := CORS(CORSOptions{Origins: []string{"*"}, MaxAge: time.Minute})(r)
+
+	for _, tc := range []struct {
+		method, maxAge, cacheControl string
+	}{
+		{method: http.MethodGet, maxAge: "60"},
+		{method: http.MethodPost, maxAge: "3600", cacheControl: "public, max-age=3600"},
+	} {
+		req := httptest.NewRequest(http.MethodOptions, "/users", nil)
+		req.Header.Set("Origin", "https://a.example")
+		req.Header.Set("Access-Control-Request-Method", tc.method)
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		if rec.Code != http.StatusNoContent {
+			t.Fatalf("%s preflight status = %d", tc.method, rec.Code)
+		}
+		if got := rec.Header().Get("Access-Control-Max-Age"); got != tc.maxAge {
+			t.Errorf("%s preflight max-age = %q, want %q", tc.method, got, tc.maxAge)
+		}
+		if got := rec.Header().Get("Cache-Control"); got != tc.cacheControl {
+			t.Errorf("%s preflight Cache-Control = %q, want %q", tc.method, got, tc.cacheControl)
+		}
+	}
+}
@@ -0,0 +1,27 @@
+// Package middleware provides net/http middleware for use with saruta.
+//
+// Most middleware has the func(http.Handler) http.Handler shape, so it can
+// be passed to saruta's Use, With and MountDebug as well as used with any
+// other net/http router. Middleware that configures itself per route from
+// route metadata, such as BodyLimit, is a saruta.RouteMiddleware for UseRoute
+// and WithRoute.
+//
+// # Correlation
+//
+// Middleware in this package reports requests through a shared contract:
+//
+//   - the request ID is read with GetRequestID(req.Context()) and is set by
+//     RequestID. Recoverer, Logger and OnServerError also find it when they
+//     are registered before RequestID, from the response header RequestID
+//     sets;
+//   - the route pattern is read from req.Pattern, which saruta sets on the
+//     request before route middleware runs (it is empty for 404/405 and mounts).
+//
+// Register the middleware with saruta's UseWithErrors so that it also wraps
+// the NotFound and MethodNotAllowed handlers:
+//
+//	r.UseWithErrors(middleware.RequestID, middleware.Recoverer, middleware.OnServerError(report))
+//
+// Panic reports, error handlers, 5xx hooks and logs then carry the same
+// request ID and route pattern whatever the order of the middleware.
+package middleware
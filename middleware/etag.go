@@ -0,0 +1,184 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"mime"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// DefaultETagMaxBuffer is the ETagOptions.MaxBuffer used when it is zero.
+const DefaultETagMaxBuffer = 1 << 20
+
+// ETagOptions configures ETag.
+type ETagOptions struct {
+	// Weak produces weak validators (W/"...") for responses that are
+	// semantically but not byte-for-byte equivalent, e.g. when compressed
+	// later by a proxy.
+	Weak bool
+	// ContentTypes restricts ETags to responses with one of these media
+	// types (e.g. "application/json", or "text/*" for all text types).
+	// Empty means any type.
+	ContentTypes []string
+	// MaxBuffer is the largest body buffered for hashing. Larger responses,
+	// and responses flushed by the handler, are streamed without an ETag.
+	MaxBuffer int
+}
+
+// ETag buffers successful GET responses, sets an ETag header derived from a
+// SHA-256 of the body, and answers 304 Not Modified when the request's
+// If-None-Match matches it.
+//
+// Responses with a status other than 200, or that already carry an ETag, are
+// passed through. So are HEAD requests: their body is empty, and a tag of the
+// empty body would not match the one GET sends. Scope it to routes with With or Group:
+//
+//	r.With(middleware.ETag(middleware.ETagOptions{ContentTypes: []string{"application/json"}})).Get("/catalog", catalog)
+func ETag(opts ETagOptions) func(http.Handler) http.Handler {
+	if opts.MaxBuffer == 0 {
+		opts.MaxBuffer = DefaultETagMaxBuffer
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			if req.Method != http.MethodGet {
+				next.ServeHTTP(w, req)
+				return
+			}
+			ew := &etagWriter{ResponseWriter: w, opts: &opts}
+			next.ServeHTTP(ew, req)
+			ew.finish(req)
+		})
+	}
+}
+
+// etagWriter buffers the response until finish, falling back to streaming
+// (passthrough) when the response cannot be tagged.
+type etagWriter struct {
+	http.ResponseWriter
+	opts        *ETagOptions
+	buf         bytes.Buffer
+	status      int
+	passthrough bool
+}
+
+func (w *etagWriter) WriteHeader(code int) {
+	if w.passthrough || w.status != 0 {
+		if w.passthrough {
+			w.ResponseWriter.WriteHeader(code)
+		}
+		return
+	}
+	if code < 200 {
+		w.ResponseWriter.WriteHeader(code)
+		return
+	}
+	w.status = code
+	if code != http.StatusOK || w.Header().Get("ETag") != "" {
+		w.startPassthrough()
+	}
+}
+
+func (w *etagWriter) Write(p []byte) (int, error) {
+	if w.status == 0 {
+		w.WriteHeader(http.StatusOK)
+	}
+	if w.passthrough {
+		return w.ResponseWriter.Write(p)
+	}
+	if w.buf.Len()+len(p) > w.opts.MaxBuffer {
+		w.startPassthrough()
+		return w.ResponseWriter.Write(p)
+	}
+	return w.buf.Write(p)
+}
+
+func (w *etagWriter) Flush() {
+	if w.status == 0 {
+		w.WriteHeader(http.StatusOK)
+	}
+	if !w.passthrough {
+		w.startPassthrough()
+	}
+	_ = http.NewResponseController(w.ResponseWriter).Flush()
+}
+
+func (w *etagWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+func (w *etagWriter) startPassthrough() {
+	w.passthrough = true
+	w.ResponseWriter.WriteHeader(w.status)
+	if w.buf.Len() > 0 {
+		_, _ = w.ResponseWriter.Write(w.buf.Bytes())
+		w.buf.Reset()
+	}
+}
+
+func (w *etagWriter) finish(req *http.Request) {
+	if w.passthrough {
+		return
+	}
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	body := w.buf.Bytes()
+	h := w.Header()
+	ct := h.Get("Content-Type")
+	if ct == "" && len(body) > 0 {
+		ct = http.DetectContentType(body)
+	}
+	if !matchContentType(w.opts.ContentTypes, ct) {
+		w.startPassthrough()
+		return
+	}
+	sum := sha256.Sum256(body)
+	tag := `"` + hex.EncodeToString(sum[:16]) + `"`
+	if w.opts.Weak {
+		tag = "W/" + tag
+	}
+	h.Set("ETag", tag)
+	if etagMatch(req.Header.Get("If-None-Match"), tag) {
+		h.Del("Content-Type")
+		h.Del("Content-Length")
+		w.ResponseWriter.WriteHeader(http.StatusNotModified)
+		return
+	}
+	h.Set("Content-Length", strconv.Itoa(len(body)))
+	w.startPassthrough()
+}
+
+func matchContentType(allowed []string, ct string) bool {
+	if len(allowed) == 0 {
+		return true
+	}
+	mt, _, err := mime.ParseMediaType(ct)
+	if err != nil {
+		return false
+	}
+	return slices.ContainsFunc(allowed, func(a string) bool {
+		if prefix, ok := strings.CutSuffix(a, "/*"); ok {
+			return strings.HasPrefix(mt, prefix+"/")
+		}
+		return a == mt
+	})
+}
+
+// etagMatch implements the weak comparison If-None-Match requires.
+func etagMatch(header, tag string) bool {
+	if header == "" {
+		return false
+	}
+	tag = strings.TrimPrefix(tag, "W/")
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == tag {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,91 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestETag(t *testing.T) {
+	body := `{"items":[1,2,3]}`
+	h := ETag(ETagOptions{ContentTypes: []string{"application/json"}})(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		switch req.URL.Path {
+		case "/json":
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(body))
+		case "/text":
+			_, _ = w.Write([]byte("plain text"))
+		case "/missing":
+			http.NotFound(w, req)
+		}
+	}))
+	serve := func(path, inm string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		if inm != "" {
+			req.Header.Set("If-None-Match", inm)
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := serve("/json", "")
+	tag := rec.Header().Get("ETag")
+	if rec.Code != http.StatusOK || rec.Body.String() != body || !strings.HasPrefix(tag, `"`) || rec.Header().Get("Content-Length") != "17" {
+		t.Fatalf("first response: %d %q %v", rec.Code, rec.Body.String(), rec.Header())
+	}
+
+	rec = serve("/json", `"other", `+tag)
+	if rec.Code != http.StatusNotModified || rec.Body.Len() != 0 || rec.Header().Get("ETag") != tag {
+		t.Fatalf("conditional response: %d %q %v", rec.Code, rec.Body.String(), rec.Header())
+	}
+	if rec := serve("/json", "W/"+tag); rec.Code != http.StatusNotModified {
+		t.Fatalf("weak If-None-Match: status %d", rec.Code)
+	}
+	if rec := serve("/json", `"stale"`); rec.Code != http.StatusOK {
+		t.Fatalf("stale If-None-Match: status %d", rec.Code)
+	}
+
+	rec = serve("/text", "")
+	if rec.Header().Get("ETag") != "" || rec.Body.String() != "plain text" {
+		t.Fatalf("content type filter: %v %q", rec.Header(), rec.Body.String())
+	}
+	rec = serve("/missing", "*")
+	if rec.Code != http.StatusNotFound || rec.Header().Get("ETag") != "" {
+		t.Fatalf("404: %d %v", rec.Code, rec.Header())
+	}
+}
+
+func TestETagStreamingPassthrough(t *testing.T) {
+	h := ETag(ETagOptions{Weak: true, MaxBuffer: 4})(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		_, _ = w.Write([]byte("abc"))
+		_, _ = w.Write([]byte("defg"))
+	}))
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	if rec.Body.String() != "abcdefg" || rec.Header().Get("ETag") != "" {
+		t.Fatalf("got %q %v", rec.Body.String(), rec.Header())
+	}
+
+	h = ETag(ETagOptions{Weak: true})(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		_, _ = w.Write([]byte("abc"))
+	}))
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	if !strings.HasPrefix(rec.Header().Get("ETag"), `W/"`) {
+		t.Fatalf("weak tag = %q", rec.Header().Get("ETag"))
+	}
+}
+
+func TestETagHead(t *testing.T) {
+	h := ETag(ETagOptions{})(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		http.ServeContent(w, req, "hello.txt", time.Time{}, strings.NewReader("hello world"))
+	}))
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodHead, "/", nil))
+	if rec.Code != http.StatusOK || rec.Header().Get("ETag") != "" || rec.Header().Get("Content-Length") != "11" {
+		t.Fatalf("HEAD: %d %v", rec.Code, rec.Header())
+	}
+}
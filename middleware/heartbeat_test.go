@@ -0,0 +1,33 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHeartbeat(t *testing.T) {
+	var reached bool
+	h := Heartbeat("/ping")(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		reached = true
+		w.WriteHeader(http.StatusTeapot)
+	}))
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/ping", nil))
+	if rec.Code != http.StatusOK || rec.Body.String() != "." || reached {
+		t.Fatalf("ping: %d %q reached=%v", rec.Code, rec.Body.String(), reached)
+	}
+
+	for _, req := range []*http.Request{
+		httptest.NewRequest(http.MethodPost, "/ping", nil),
+		httptest.NewRequest(http.MethodGet, "/ping/x", nil),
+	} {
+		reached = false
+		rec = httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		if rec.Code != http.StatusTeapot || !reached {
+			t.Fatalf("%s %s: %d reached=%v", req.Method, req.URL.Path, rec.Code, reached)
+		}
+	}
+}
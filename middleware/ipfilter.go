@@ -0,0 +1,61 @@
+package middleware
+
+import (
+	"net"
+	"net/http"
+	"net/netip"
+)
+
+// IPFilter allows requests whose client address (req.RemoteAddr) is inside one
+// of the given CIDR prefixes and answers 403 Forbidden otherwise.
+//
+// Plain addresses such as "127.0.0.1" are accepted as single-host prefixes.
+// IPFilter panics if an entry cannot be parsed. Behind a reverse proxy, run a
+// trusted RealIP middleware first so RemoteAddr holds the client address.
+func IPFilter(allowed ...string) func(http.Handler) http.Handler {
+	prefixes := parsePrefixes("IPFilter", allowed)
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			addr, ok := remoteAddr(req.RemoteAddr)
+			if ok {
+				for _, p := range prefixes {
+					if p.Contains(addr) {
+						next.ServeHTTP(w, req)
+						return
+					}
+				}
+			}
+			http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+		})
+	}
+}
+
+// parsePrefixes parses CIDR prefixes and plain addresses, panicking on
+// invalid entries of the named middleware.
+func parsePrefixes(name string, entries []string) []netip.Prefix {
+	prefixes := make([]netip.Prefix, 0, len(entries))
+	for _, s := range entries {
+		p, err := netip.ParsePrefix(s)
+		if err != nil {
+			addr, aerr := netip.ParseAddr(s)
+			if aerr != nil {
+				panic("middleware: invalid " + name + " entry " + s + ": " + err.Error())
+			}
+			p = netip.PrefixFrom(addr, addr.BitLen())
+		}
+		prefixes = append(prefixes, p.Masked())
+	}
+	return prefixes
+}
+
+func remoteAddr(remote string) (netip.Addr, bool) {
+	host, _, err := net.SplitHostPort(remote)
+	if err != nil {
+		host = remote
+	}
+	addr, err := netip.ParseAddr(host)
+	if err != nil {
+		return netip.Addr{}, false
+	}
+	return addr.Unmap(), true
+}
@@ -0,0 +1,42 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestIPFilter(t *testing.T) {
+	h := IPFilter("10.0.0.0/8", "192.168.1.5", "::1")(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	for _, tc := range []struct {
+		remote string
+		want   int
+	}{
+		{remote: "10.1.2.3:1234", want: http.StatusNoContent},
+		{remote: "192.168.1.5:80", want: http.StatusNoContent},
+		{remote: "192.168.1.6:80", want: http.StatusForbidden},
+		{remote: "[::1]:8080", want: http.StatusNoContent},
+		{remote: "[::ffff:10.0.0.1]:8080", want: http.StatusNoContent},
+		{remote: "garbage", want: http.StatusForbidden},
+	} {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.RemoteAddr = tc.remote
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		if rec.Code != tc.want {
+			t.Fatalf("%s status = %d, want %d", tc.remote, rec.Code, tc.want)
+		}
+	}
+}
+
+func TestIPFilterPanicsOnInvalidEntry(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatalf("expected panic")
+		}
+	}()
+	IPFilter("not-an-ip")
+}
@@ -0,0 +1,214 @@
+package middleware
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// JWKS fetches and caches the public keys published at a JSON Web Key Set
+// URL. Its Keyfunc method plugs into JWTOptions.
+//
+// Keys are refetched when older than TTL, and when a token names an unknown
+// kid. Fetches, failed ones included, happen at most once per MinRefresh, so
+// forged kids cannot flood the issuer, and concurrent requests share one
+// fetch, which runs without holding up requests for cached keys.
+type JWKS struct {
+	URL        string
+	Client     *http.Client  // http.DefaultClient if nil
+	TTL        time.Duration // one hour if zero
+	MinRefresh time.Duration // one minute if zero
+	Timeout    time.Duration // limit for one fetch; ten seconds if zero
+
+	mu        sync.Mutex
+	keys      map[string]any
+	fetched   time.Time // last successful fetch
+	attempted time.Time // last fetch, successful or not
+	err       error     // error of the last fetch
+	refresh   *jwksRefresh
+}
+
+// jwksRefresh is a fetch in flight.
+type jwksRefresh struct {
+	done chan struct{}
+	err  error
+}
+
+// NewJWKS returns a JWKS for url with default settings.
+func NewJWKS(url string) *JWKS {
+	return &JWKS{URL: url}
+}
+
+// Keyfunc returns the key for h.Kid, fetching the key set as needed. ctx
+// bounds only the wait for a fetch; the fetch itself is shared and limited
+// by Timeout.
+func (j *JWKS) Keyfunc(ctx context.Context, h JWTHeader) (any, error) {
+	ttl := j.TTL
+	if ttl == 0 {
+		ttl = time.Hour
+	}
+	minRefresh := j.MinRefresh
+	if minRefresh == 0 {
+		minRefresh = time.Minute
+	}
+
+	j.mu.Lock()
+	key, ok := j.keys[h.Kid]
+	if (ok && time.Since(j.fetched) < ttl) || (!j.attempted.IsZero() && time.Since(j.attempted) < minRefresh) {
+		err := j.err
+		j.mu.Unlock()
+		return j.result(h.Kid, key, ok, err)
+	}
+	rf := j.refresh
+	if rf == nil {
+		rf = &jwksRefresh{done: make(chan struct{})}
+		j.refresh, j.attempted = rf, time.Now()
+		go j.fetchKeys(rf)
+	}
+	j.mu.Unlock()
+
+	select {
+	case <-rf.done:
+	case <-ctx.Done():
+		if ok {
+			return key, nil
+		}
+		return nil, ctx.Err()
+	}
+	j.mu.Lock()
+	if k, found := j.keys[h.Kid]; found {
+		key, ok = k, true
+	}
+	j.mu.Unlock()
+	// A failed fetch leaves the cached keys in place, so they keep being
+	// served while the issuer is down.
+	return j.result(h.Kid, key, ok, rf.err)
+}
+
+func (j *JWKS) result(kid string, key any, ok bool, err error) (any, error) {
+	switch {
+	case ok:
+		return key, nil
+	case err != nil:
+		return nil, err
+	}
+	return nil, fmt.Errorf("jwks: unknown key id %q", kid)
+}
+
+// fetchKeys runs the fetch rf stands for and records its result.
+func (j *JWKS) fetchKeys(rf *jwksRefresh) {
+	timeout := j.Timeout
+	if timeout == 0 {
+		timeout = 10 * time.Second
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	keys, err := j.fetch(ctx)
+	cancel()
+
+	j.mu.Lock()
+	if err == nil {
+		j.keys, j.fetched = keys, time.Now()
+	}
+	j.err, j.refresh = err, nil
+	j.mu.Unlock()
+	rf.err = err
+	close(rf.done)
+}
+
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Crv string `json:"crv"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+func (j *JWKS) fetch(ctx context.Context) (map[string]any, error) {
+	client := j.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, j.URL, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("jwks: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("jwks: %s returned %s", j.URL, resp.Status)
+	}
+	var set struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&set); err != nil {
+		return nil, fmt.Errorf("jwks: %w", err)
+	}
+	keys := make(map[string]any, len(set.Keys))
+	for _, k := range set.Keys {
+		if key, err := k.publicKey(); err == nil {
+			keys[k.Kid] = key
+		}
+	}
+	return keys, nil
+}
+
+func (k jsonWebKey) publicKey() (any, error) {
+	b64 := base64.RawURLEncoding
+	switch k.Kty {
+	case "RSA":
+		n, err := b64.DecodeString(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := b64.DecodeString(k.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := b64.DecodeString(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := b64.DecodeString(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		return ecdsa.ParseUncompressedPublicKey(curve, append(append([]byte{4}, x...), y...))
+	case "OKP":
+		if k.Crv != "Ed25519" {
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := b64.DecodeString(k.X)
+		if err != nil || len(x) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("invalid Ed25519 key")
+		}
+		return ed25519.PublicKey(x), nil
+	}
+	return nil, fmt.Errorf("unsupported key type %q", k.Kty)
+}
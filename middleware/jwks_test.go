@@ -0,0 +1,120 @@
+package middleware
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestJWKS(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	point, err := ecKey.PublicKey.Bytes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	b64 := base64.RawURLEncoding.EncodeToString
+	var fetches atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		fetches.Add(1)
+		_ = json.NewEncoder(w).Encode(map[string]any{"keys": []map[string]string{
+			{"kty": "RSA", "kid": "rsa-1", "n": b64(rsaKey.N.Bytes()), "e": b64(big.NewInt(int64(rsaKey.E)).Bytes())},
+			{"kty": "EC", "kid": "ec-1", "crv": "P-256", "x": b64(point[1:33]), "y": b64(point[33:])},
+		}})
+	}))
+	defer srv.Close()
+
+	jwks := NewJWKS(srv.URL)
+	ctx := context.Background()
+	key, err := jwks.Keyfunc(ctx, JWTHeader{Kid: "rsa-1"})
+	if err != nil || !key.(*rsa.PublicKey).Equal(&rsaKey.PublicKey) {
+		t.Fatalf("rsa key = %v, %v", key, err)
+	}
+	key, err = jwks.Keyfunc(ctx, JWTHeader{Kid: "ec-1"})
+	if err != nil || !key.(*ecdsa.PublicKey).Equal(&ecKey.PublicKey) {
+		t.Fatalf("ec key = %v, %v", key, err)
+	}
+	if _, err := jwks.Keyfunc(ctx, JWTHeader{Kid: "unknown"}); err == nil {
+		t.Fatal("expected error for unknown kid")
+	}
+	if n := fetches.Load(); n != 1 {
+		t.Fatalf("fetched %d times, want 1 (cached, refresh rate limited)", n)
+	}
+
+	token := signJWT(t, "RS256", "rsa-1", rsaKey, map[string]any{"sub": "bob"})
+	claims, err := verifyJWT(ctx, token, &JWTOptions{Keyfunc: jwks.Keyfunc})
+	if err != nil || claims.Subject() != "bob" {
+		t.Fatalf("verify = %v, %v", claims, err)
+	}
+}
+
+func TestJWKSFetchFailures(t *testing.T) {
+	var fetches atomic.Int32
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		fetches.Add(1)
+		<-release
+		http.Error(w, "down", http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+	jwks := NewJWKS(srv.URL)
+
+	// Concurrent lookups share one fetch, and a caller that gives up does
+	// not wait for it.
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := jwks.Keyfunc(ctx, JWTHeader{Kid: "a"}); err != context.DeadlineExceeded {
+		t.Fatalf("canceled lookup err = %v, want %v", err, context.DeadlineExceeded)
+	}
+	var wg sync.WaitGroup
+	for range 3 {
+		wg.Go(func() {
+			if _, err := jwks.Keyfunc(context.Background(), JWTHeader{Kid: "a"}); err == nil {
+				t.Error("expected error while the issuer is down")
+			}
+		})
+	}
+	time.Sleep(20 * time.Millisecond) // let the lookups reach the wait
+	close(release)
+	wg.Wait()
+
+	// The failed fetch counts against MinRefresh.
+	if _, err := jwks.Keyfunc(context.Background(), JWTHeader{Kid: "b"}); err == nil {
+		t.Fatal("expected the last fetch error")
+	}
+	if n := fetches.Load(); n != 1 {
+		t.Fatalf("fetched %d times, want 1", n)
+	}
+}
+
+func TestJWKSTimeout(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		<-req.Context().Done()
+	}))
+	defer srv.Close()
+	jwks := &JWKS{URL: srv.URL, Timeout: 20 * time.Millisecond}
+	start := time.Now()
+	if _, err := jwks.Keyfunc(context.Background(), JWTHeader{Kid: "a"}); err == nil {
+		t.Fatal("expected a timeout error")
+	}
+	if d := time.Since(start); d > time.Second {
+		t.Fatalf("fetch took %v, want it cut off by Timeout", d)
+	}
+}
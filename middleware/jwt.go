@@ -0,0 +1,274 @@
+package middleware
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/rsa"
+	_ "crypto/sha256" // SHA-256 for HS256, RS256, PS256, ES256
+	_ "crypto/sha512" // SHA-384 and SHA-512
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/catatsuy/saruta"
+)
+
+// MetaScopes holds the scopes ([]string) a route requires from JWT. Set it
+// with r.WithMeta(middleware.MetaScopes, []string{"users:read"}).
+const MetaScopes = "scopes"
+
+// JWTHeader is the decoded JOSE header of a token, passed to a Keyfunc.
+type JWTHeader struct {
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+	Typ string `json:"typ"`
+}
+
+// Claims are the payload claims of a verified token.
+type Claims map[string]any
+
+// Subject returns the "sub" claim.
+func (c Claims) Subject() string {
+	s, _ := c["sub"].(string)
+	return s
+}
+
+// Scopes returns the scopes granted by the "scope" claim (space-separated,
+// RFC 8693) or the "scp" claim (array).
+func (c Claims) Scopes() []string {
+	if s, ok := c["scope"].(string); ok {
+		return strings.Fields(s)
+	}
+	var scopes []string
+	if list, ok := c["scp"].([]any); ok {
+		for _, v := range list {
+			if s, ok := v.(string); ok {
+				scopes = append(scopes, s)
+			}
+		}
+	}
+	return scopes
+}
+
+// JWTOptions configures JWT.
+type JWTOptions struct {
+	// Keyfunc returns the verification key for a token: []byte for HS256/384/512,
+	// *rsa.PublicKey for RS* and PS*, *ecdsa.PublicKey for ES*, and
+	// ed25519.PublicKey for EdDSA. The key type must match the token's alg.
+	// (*JWKS).Keyfunc fetches keys from a JWKS endpoint.
+	Keyfunc func(ctx context.Context, h JWTHeader) (any, error)
+	// Issuer and Audience, when set, must match the "iss" and "aud" claims.
+	Issuer   string
+	Audience string
+	// Leeway tolerates clock skew when checking "exp" and "nbf".
+	Leeway time.Duration
+}
+
+type claimsKey struct{}
+
+// GetClaims returns the claims stored by JWT, or nil.
+func GetClaims(ctx context.Context) Claims {
+	c, _ := ctx.Value(claimsKey{}).(Claims)
+	return c
+}
+
+// JWT requires a valid "Authorization: Bearer" token on every route it wraps
+// and stores its claims in the request context (see GetClaims). Register it
+// with UseRoute or WithRoute; routes with MetaScopes additionally require
+// every listed scope:
+//
+//	api := r.WithRoute(middleware.JWT(middleware.JWTOptions{Keyfunc: jwks.Keyfunc, Issuer: issuer}))
+//	api.WithMeta(middleware.MetaScopes, []string{"users:write"}).Post("/users", createUser)
+//
+// Missing or invalid tokens get 401 and tokens lacking a scope get 403, both
+// with a WWW-Authenticate header as in RFC 6750.
+func JWT(opts JWTOptions) saruta.RouteMiddleware {
+	if opts.Keyfunc == nil {
+		panic("middleware: JWT requires a Keyfunc")
+	}
+	return func(route saruta.RouteInfo, next http.Handler) http.Handler {
+		required, _ := route.Meta[MetaScopes].([]string)
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			token, ok := bearerToken(req)
+			if !ok {
+				w.Header().Set("WWW-Authenticate", `Bearer`)
+				http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+				return
+			}
+			claims, err := verifyJWT(req.Context(), token, &opts)
+			if err != nil {
+				w.Header().Set("WWW-Authenticate", `Bearer error="invalid_token"`)
+				http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+				return
+			}
+			if len(required) > 0 {
+				granted := claims.Scopes()
+				for _, s := range required {
+					if !slices.Contains(granted, s) {
+						w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer error="insufficient_scope", scope=%q`, strings.Join(required, " ")))
+						http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+						return
+					}
+				}
+			}
+			next.ServeHTTP(w, req.WithContext(context.WithValue(req.Context(), claimsKey{}, claims)))
+		})
+	}
+}
+
+func bearerToken(req *http.Request) (string, bool) {
+	scheme, token, ok := strings.Cut(req.Header.Get("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return "", false
+	}
+	token = strings.TrimSpace(token)
+	return token, token != ""
+}
+
+var errInvalidToken = errors.New("invalid token")
+
+func verifyJWT(ctx context.Context, token string, opts *JWTOptions) (Claims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, errInvalidToken
+	}
+	var h JWTHeader
+	if err := decodeSegment(parts[0], &h); err != nil {
+		return nil, err
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, errInvalidToken
+	}
+	key, err := opts.Keyfunc(ctx, h)
+	if err != nil {
+		return nil, err
+	}
+	if err := verifySignature(h.Alg, key, parts[0]+"."+parts[1], sig); err != nil {
+		return nil, err
+	}
+	var claims Claims
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return nil, err
+	}
+	if err := checkClaims(claims, opts); err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
+
+func decodeSegment(seg string, v any) error {
+	b, err := base64.RawURLEncoding.DecodeString(seg)
+	if err != nil {
+		return errInvalidToken
+	}
+	if err := json.Unmarshal(b, v); err != nil {
+		return errInvalidToken
+	}
+	return nil
+}
+
+func verifySignature(alg string, key any, signed string, sig []byte) error {
+	if alg == "EdDSA" {
+		k, ok := key.(ed25519.PublicKey)
+		if !ok || !ed25519.Verify(k, []byte(signed), sig) {
+			return errInvalidToken
+		}
+		return nil
+	}
+	var hash crypto.Hash
+	if len(alg) == 5 {
+		switch alg[2:] {
+		case "256":
+			hash = crypto.SHA256
+		case "384":
+			hash = crypto.SHA384
+		case "512":
+			hash = crypto.SHA512
+		}
+	}
+	if hash == 0 {
+		return fmt.Errorf("unsupported alg %q", alg)
+	}
+	if alg[:2] == "HS" {
+		k, ok := key.([]byte)
+		if !ok {
+			return errInvalidToken
+		}
+		mac := hmac.New(hash.New, k)
+		mac.Write([]byte(signed))
+		if !hmac.Equal(mac.Sum(nil), sig) {
+			return errInvalidToken
+		}
+		return nil
+	}
+	hh := hash.New()
+	hh.Write([]byte(signed))
+	digest := hh.Sum(nil)
+	switch alg[:2] {
+	case "RS", "PS":
+		k, ok := key.(*rsa.PublicKey)
+		if !ok {
+			return errInvalidToken
+		}
+		if alg[0] == 'R' {
+			if rsa.VerifyPKCS1v15(k, hash, digest, sig) != nil {
+				return errInvalidToken
+			}
+			return nil
+		}
+		if rsa.VerifyPSS(k, hash, digest, sig, nil) != nil {
+			return errInvalidToken
+		}
+		return nil
+	case "ES":
+		k, ok := key.(*ecdsa.PublicKey)
+		if !ok || len(sig)%2 != 0 {
+			return errInvalidToken
+		}
+		r := new(big.Int).SetBytes(sig[:len(sig)/2])
+		s := new(big.Int).SetBytes(sig[len(sig)/2:])
+		if !ecdsa.Verify(k, digest, r, s) {
+			return errInvalidToken
+		}
+		return nil
+	}
+	return fmt.Errorf("unsupported alg %q", alg)
+}
+
+func checkClaims(c Claims, opts *JWTOptions) error {
+	now := time.Now()
+	if exp, ok := c["exp"].(float64); ok && now.After(time.Unix(int64(exp), 0).Add(opts.Leeway)) {
+		return errors.New("token expired")
+	}
+	if nbf, ok := c["nbf"].(float64); ok && now.Add(opts.Leeway).Before(time.Unix(int64(nbf), 0)) {
+		return errors.New("token not yet valid")
+	}
+	if opts.Issuer != "" && c["iss"] != opts.Issuer {
+		return errors.New("unexpected issuer")
+	}
+	if opts.Audience != "" {
+		switch aud := c["aud"].(type) {
+		case string:
+			if aud != opts.Audience {
+				return errors.New("unexpected audience")
+			}
+		case []any:
+			if !slices.Contains(aud, any(opts.Audience)) {
+				return errors.New("unexpected audience")
+			}
+		default:
+			return errors.New("unexpected audience")
+		}
+	}
+	return nil
+}
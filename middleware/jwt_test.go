@@ -0,0 +1,136 @@
+package middleware
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/catatsuy/saruta"
+)
+
+func signJWT(t *testing.T, alg, kid string, key any, claims map[string]any) string {
+	t.Helper()
+	enc := func(v any) string {
+		b, err := json.Marshal(v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return base64.RawURLEncoding.EncodeToString(b)
+	}
+	signed := enc(map[string]string{"alg": alg, "kid": kid, "typ": "JWT"}) + "." + enc(claims)
+	digest := sha256.Sum256([]byte(signed))
+	var sig []byte
+	var err error
+	switch k := key.(type) {
+	case []byte:
+		mac := hmac.New(sha256.New, k)
+		mac.Write([]byte(signed))
+		sig = mac.Sum(nil)
+	case *rsa.PrivateKey:
+		sig, err = rsa.SignPKCS1v15(rand.Reader, k, crypto.SHA256, digest[:])
+	case *ecdsa.PrivateKey:
+		r, s, serr := ecdsa.Sign(rand.Reader, k, digest[:])
+		err = serr
+		sig = append(r.FillBytes(make([]byte, 32)), s.FillBytes(make([]byte, 32))...)
+	case ed25519.PrivateKey:
+		sig = ed25519.Sign(k, []byte(signed))
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signed + "." + base64.RawURLEncoding.EncodeToString(sig)
+}
+
+func TestJWT(t *testing.T) {
+	secret := []byte("test-secret")
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	edPub, edKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys := map[string]any{"hs": secret, "rs": &rsaKey.PublicKey, "es": &ecKey.PublicKey, "ed": edPub}
+
+	r := saruta.New()
+	api := r.WithRoute(JWT(JWTOptions{
+		Keyfunc: func(ctx context.Context, h JWTHeader) (any, error) { return keys[h.Kid], nil },
+		Issuer:  "https://issuer.example",
+	}))
+	api.Get("/me", func(w http.ResponseWriter, req *http.Request) {
+		_, _ = w.Write([]byte(GetClaims(req.Context()).Subject()))
+	})
+	api.WithMeta(MetaScopes, []string{"users:write"}).Post("/users", func(w http.ResponseWriter, req *http.Request) {})
+	r.MustCompile()
+
+	exp := float64(time.Now().Add(time.Hour).Unix())
+	valid := map[string]any{"sub": "alice", "iss": "https://issuer.example", "exp": exp, "scope": "users:read"}
+	serve := func(method, path, token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, nil)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+		return rec
+	}
+
+	for _, tc := range []struct {
+		alg, kid string
+		key      any
+	}{
+		{"HS256", "hs", secret},
+		{"RS256", "rs", rsaKey},
+		{"ES256", "es", ecKey},
+		{"EdDSA", "ed", edKey},
+	} {
+		rec := serve(http.MethodGet, "/me", signJWT(t, tc.alg, tc.kid, tc.key, valid))
+		if rec.Code != http.StatusOK || rec.Body.String() != "alice" {
+			t.Fatalf("%s: %d %q", tc.alg, rec.Code, rec.Body.String())
+		}
+	}
+
+	if rec := serve(http.MethodGet, "/me", ""); rec.Code != http.StatusUnauthorized || rec.Header().Get("WWW-Authenticate") != "Bearer" {
+		t.Fatalf("no token: %d %v", rec.Code, rec.Header())
+	}
+	for name, token := range map[string]string{
+		"expired":      signJWT(t, "HS256", "hs", secret, map[string]any{"iss": "https://issuer.example", "exp": float64(time.Now().Add(-time.Hour).Unix())}),
+		"wrong issuer": signJWT(t, "HS256", "hs", secret, map[string]any{"iss": "https://evil.example", "exp": exp}),
+		"bad secret":   signJWT(t, "HS256", "hs", []byte("other"), valid),
+		// An HMAC token verified against an RSA public key must not pass.
+		"alg confusion": signJWT(t, "HS256", "rs", rsaKey.PublicKey.N.Bytes(), valid),
+		"garbage":       "a.b.c",
+	} {
+		rec := serve(http.MethodGet, "/me", token)
+		if rec.Code != http.StatusUnauthorized || !strings.Contains(rec.Header().Get("WWW-Authenticate"), "invalid_token") {
+			t.Fatalf("%s: %d %v", name, rec.Code, rec.Header())
+		}
+	}
+
+	rec := serve(http.MethodPost, "/users", signJWT(t, "HS256", "hs", secret, valid))
+	if rec.Code != http.StatusForbidden || !strings.Contains(rec.Header().Get("WWW-Authenticate"), `scope="users:write"`) {
+		t.Fatalf("missing scope: %d %v", rec.Code, rec.Header())
+	}
+	writer := map[string]any{"iss": "https://issuer.example", "exp": exp, "scp": []string{"users:read", "users:write"}}
+	if rec := serve(http.MethodPost, "/users", signJWT(t, "HS256", "hs", secret, writer)); rec.Code != http.StatusOK {
+		t.Fatalf("with scope: %d", rec.Code)
+	}
+}
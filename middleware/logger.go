@@ -0,0 +1,47 @@
+package middleware
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+// Logger logs one record per request to l (slog.Default if nil) with the
+// method, path, matched route pattern, status, response bytes, duration and
+// the request ID (see RequestID), if any. Responses with a 5xx status are
+// logged at error level, others at info level.
+//
+// The pattern is req.Pattern as set by saruta for the matched route; it is
+// empty for 404/405 responses and mounts. Logger only sees 404/405 responses
+// when registered with saruta's UseWithErrors or WithMiddlewareOnErrors. The response writer passed
+// on still supports http.Flusher, http.Hijacker and http.ResponseController.
+func Logger(l *slog.Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			logger := l
+			if logger == nil {
+				logger = slog.Default()
+			}
+			start := time.Now()
+			sw := &statusWriter{ResponseWriter: w}
+			next.ServeHTTP(sw, req)
+
+			level := slog.LevelInfo
+			if sw.Status() >= 500 {
+				level = slog.LevelError
+			}
+			attrs := []slog.Attr{
+				slog.String("method", req.Method),
+				slog.String("path", req.URL.Path),
+				slog.String("pattern", req.Pattern),
+				slog.Int("status", sw.Status()),
+				slog.Int64("bytes", sw.bytes),
+				slog.Duration("duration", time.Since(start)),
+			}
+			if id := requestIDOf(w, req); id != "" {
+				attrs = append(attrs, slog.String("request_id", id))
+			}
+			logger.LogAttrs(req.Context(), level, "request", attrs...)
+		})
+	}
+}
@@ -0,0 +1,74 @@
+package middleware
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/catatsuy/saruta"
+)
+
+func TestLogger(t *testing.T) {
+	var buf bytes.Buffer
+	r := saruta.New()
+	r.Use(Logger(slog.New(slog.NewJSONHandler(&buf, nil))))
+	r.Get("/users/{id}", func(w http.ResponseWriter, req *http.Request) {
+		if _, ok := w.(http.Flusher); !ok {
+			t.Error("writer lost http.Flusher")
+		}
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte("hello"))
+	})
+	r.MustCompile()
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/users/7", nil))
+
+	var rec struct {
+		Level, Msg, Method, Path, Pattern string
+		Status                            int
+		Bytes                             int64
+	}
+	if err := json.Unmarshal(buf.Bytes(), &rec); err != nil {
+		t.Fatalf("%v: %s", err, buf.String())
+	}
+	if rec.Level != "INFO" || rec.Method != "GET" || rec.Path != "/users/7" || rec.Pattern != "/users/{id}" || rec.Status != 201 || rec.Bytes != 5 {
+		t.Fatalf("record = %+v", rec)
+	}
+}
+
+func TestLoggerErrorLevel(t *testing.T) {
+	var buf bytes.Buffer
+	h := Logger(slog.New(slog.NewJSONHandler(&buf, nil)))(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		http.Error(w, "fail", http.StatusBadGateway)
+	}))
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	if !bytes.Contains(buf.Bytes(), []byte(`"level":"ERROR"`)) || !bytes.Contains(buf.Bytes(), []byte(`"status":502`)) {
+		t.Fatalf("log = %s", buf.String())
+	}
+}
+
+type hijackRecorder struct {
+	*httptest.ResponseRecorder
+	hijacked bool
+}
+
+func (h *hijackRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h.hijacked = true
+	return nil, nil, nil
+}
+
+func TestStatusWriterHijack(t *testing.T) {
+	rec := &hijackRecorder{ResponseRecorder: httptest.NewRecorder()}
+	sw := &statusWriter{ResponseWriter: rec}
+	if _, _, err := sw.Hijack(); err != nil || !rec.hijacked {
+		t.Fatalf("Hijack not forwarded: %v", err)
+	}
+	sw = &statusWriter{ResponseWriter: httptest.NewRecorder()}
+	if _, _, err := sw.Hijack(); err == nil {
+		t.Fatal("expected error for writer without Hijacker")
+	}
+}
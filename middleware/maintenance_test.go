@@ -0,0 +1,72 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestMaintenance(t *testing.T) {
+	var sw MaintenanceSwitch
+	next := http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		_, _ = w.Write([]byte("ok"))
+	})
+	h := Maintenance(MaintenanceOptions{
+		Enabled:    sw.Enabled,
+		RetryAfter: 90500 * time.Millisecond,
+		Allow:      []string{"/healthz", "/status/"},
+	})(next)
+
+	serve := func(path string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		return rec
+	}
+
+	if rec := serve("/users"); rec.Code != http.StatusOK {
+		t.Fatalf("off: status = %d, want 200", rec.Code)
+	}
+
+	sw.Enable()
+	rec := serve("/users")
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("on: status = %d, want 503", rec.Code)
+	}
+	if got := rec.Header().Get("Retry-After"); got != "91" {
+		t.Fatalf("Retry-After = %q, want 91", got)
+	}
+	for _, p := range []string{"/healthz", "/status", "/status/db"} {
+		if rec := serve(p); rec.Code != http.StatusOK {
+			t.Errorf("%s: status = %d, want 200", p, rec.Code)
+		}
+	}
+	if rec := serve("/healthzx"); rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("/healthzx: status = %d, want 503", rec.Code)
+	}
+
+	sw.Disable()
+	if rec := serve("/users"); rec.Code != http.StatusOK {
+		t.Fatalf("disabled again: status = %d, want 200", rec.Code)
+	}
+}
+
+func TestMaintenanceCustomHandler(t *testing.T) {
+	h := Maintenance(MaintenanceOptions{
+		Enabled: func() bool { return true },
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusOK)
+			_, _ = w.Write([]byte(`{"error":"maintenance"}`))
+		}),
+	})(http.NotFoundHandler())
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	if rec.Code != http.StatusServiceUnavailable || rec.Body.String() != `{"error":"maintenance"}` {
+		t.Fatalf("got %d %q", rec.Code, rec.Body.String())
+	}
+	if rec.Header().Get("Retry-After") != "" {
+		t.Fatalf("unexpected Retry-After %q", rec.Header().Get("Retry-After"))
+	}
+}
@@ -0,0 +1,211 @@
+package middleware
+
+import (
+	"cmp"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/catatsuy/saruta"
+)
+
+// DefaultDurationBuckets are the request duration histogram buckets, in
+// seconds, used when MetricsOptions.DurationBuckets is nil.
+var DefaultDurationBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// DefaultSizeBuckets are the response size histogram buckets, in bytes, used
+// when MetricsOptions.SizeBuckets is nil.
+var DefaultSizeBuckets = []float64{100, 1e3, 1e4, 1e5, 1e6, 1e7}
+
+// MetricsOptions configures NewMetrics.
+type MetricsOptions struct {
+	// Namespace prefixes every metric name, e.g. "myapp" gives
+	// myapp_http_requests_total.
+	Namespace       string
+	DurationBuckets []float64
+	SizeBuckets     []float64
+}
+
+// Metrics collects per-route HTTP metrics and serves them in the Prometheus
+// text exposition format:
+//
+//   - http_requests_total{method,route,status} (counter)
+//   - http_request_duration_seconds{method,route} (histogram)
+//   - http_response_size_bytes{method,route} (histogram)
+//   - http_requests_in_flight (gauge)
+//
+// The route label is the matched pattern (req.Pattern), never the raw path, so
+// cardinality stays bounded by the route table. Requests without a pattern
+// (404, 405, mounts) are labeled "unmatched", and methods outside the
+// standard set are labeled "OTHER".
+type Metrics struct {
+	prefix          string
+	durationBuckets []float64
+	sizeBuckets     []float64
+
+	inFlight atomic.Int64
+	mu       sync.Mutex
+	requests map[requestLabels]uint64
+	duration map[routeLabels]*histogram
+	size     map[routeLabels]*histogram
+}
+
+type routeLabels struct{ method, route string }
+
+type requestLabels struct {
+	routeLabels
+	status int
+}
+
+type histogram struct {
+	counts []uint64 // per bucket, not cumulative
+	sum    float64
+	count  uint64
+}
+
+func (h *histogram) observe(buckets []float64, v float64) {
+	if i, _ := slices.BinarySearch(buckets, v); i < len(buckets) {
+		h.counts[i]++
+	}
+	h.sum += v
+	h.count++
+}
+
+// NewMetrics returns an empty Metrics.
+func NewMetrics(opts MetricsOptions) *Metrics {
+	m := &Metrics{
+		durationBuckets: opts.DurationBuckets,
+		sizeBuckets:     opts.SizeBuckets,
+		requests:        make(map[requestLabels]uint64),
+		duration:        make(map[routeLabels]*histogram),
+		size:            make(map[routeLabels]*histogram),
+	}
+	if m.durationBuckets == nil {
+		m.durationBuckets = DefaultDurationBuckets
+	}
+	if m.sizeBuckets == nil {
+		m.sizeBuckets = DefaultSizeBuckets
+	}
+	if opts.Namespace != "" {
+		m.prefix = opts.Namespace + "_"
+	}
+	return m
+}
+
+// Middleware records metrics for each request. Register it with Use (and
+// saruta.WithMiddlewareOnErrors to count 404 / 405 responses).
+func (m *Metrics) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		m.inFlight.Add(1)
+		defer m.inFlight.Add(-1)
+		start := time.Now()
+		sw := &statusWriter{ResponseWriter: w}
+		next.ServeHTTP(sw, req)
+		m.record(req, sw.Status(), sw.bytes, time.Since(start))
+	})
+}
+
+func (m *Metrics) record(req *http.Request, status int, size int64, d time.Duration) {
+	rl := routeLabels{method: metricMethod(req.Method), route: cmp.Or(req.Pattern, "unmatched")}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.requests[requestLabels{routeLabels: rl, status: status}]++
+	dh := m.duration[rl]
+	if dh == nil {
+		dh = &histogram{counts: make([]uint64, len(m.durationBuckets))}
+		m.duration[rl] = dh
+	}
+	dh.observe(m.durationBuckets, d.Seconds())
+	sh := m.size[rl]
+	if sh == nil {
+		sh = &histogram{counts: make([]uint64, len(m.sizeBuckets))}
+		m.size[rl] = sh
+	}
+	sh.observe(m.sizeBuckets, float64(size))
+}
+
+func metricMethod(method string) string {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch,
+		http.MethodDelete, http.MethodConnect, http.MethodOptions, http.MethodTrace:
+		return method
+	}
+	return "OTHER"
+}
+
+// Handler serves the collected metrics in the Prometheus text format.
+func (m *Metrics) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		m.WriteTo(w)
+	})
+}
+
+// Mount registers Handler for GET requests at pattern on r, typically
+// "/metrics".
+func (m *Metrics) Mount(r *saruta.Router, pattern string) {
+	r.Get(pattern, m.Handler().ServeHTTP)
+}
+
+// WriteTo writes the metrics in the Prometheus text format.
+func (m *Metrics) WriteTo(w io.Writer) (int64, error) {
+	var b strings.Builder
+	p := m.prefix
+
+	m.mu.Lock()
+	requests := make([]requestLabels, 0, len(m.requests))
+	for k := range m.requests {
+		requests = append(requests, k)
+	}
+	slices.SortFunc(requests, func(a, b requestLabels) int {
+		return cmp.Or(compareRoute(a.routeLabels, b.routeLabels), cmp.Compare(a.status, b.status))
+	})
+	fmt.Fprintf(&b, "# HELP %shttp_requests_total Requests by method, route pattern and status.\n# TYPE %[1]shttp_requests_total counter\n", p)
+	for _, k := range requests {
+		fmt.Fprintf(&b, "%shttp_requests_total{%s,status=\"%d\"} %d\n", p, k.labels(), k.status, m.requests[k])
+	}
+	writeHistograms(&b, p+"http_request_duration_seconds", "Request duration in seconds by method and route pattern.", m.durationBuckets, m.duration)
+	writeHistograms(&b, p+"http_response_size_bytes", "Response body size in bytes by method and route pattern.", m.sizeBuckets, m.size)
+	m.mu.Unlock()
+
+	fmt.Fprintf(&b, "# HELP %shttp_requests_in_flight Requests currently being served.\n# TYPE %[1]shttp_requests_in_flight gauge\n%[1]shttp_requests_in_flight %d\n", p, m.inFlight.Load())
+	n, err := io.WriteString(w, b.String())
+	return int64(n), err
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func (l routeLabels) labels() string {
+	return `method="` + l.method + `",route="` + labelEscaper.Replace(l.route) + `"`
+}
+
+func compareRoute(a, b routeLabels) int {
+	return cmp.Or(cmp.Compare(a.route, b.route), cmp.Compare(a.method, b.method))
+}
+
+func writeHistograms(b *strings.Builder, name, help string, buckets []float64, hs map[routeLabels]*histogram) {
+	keys := make([]routeLabels, 0, len(hs))
+	for k := range hs {
+		keys = append(keys, k)
+	}
+	slices.SortFunc(keys, compareRoute)
+	fmt.Fprintf(b, "# HELP %s %s\n# TYPE %[1]s histogram\n", name, help)
+	for _, k := range keys {
+		h := hs[k]
+		labels := k.labels()
+		var cum uint64
+		for i, le := range buckets {
+			cum += h.counts[i]
+			fmt.Fprintf(b, "%s_bucket{%s,le=\"%s\"} %d\n", name, labels, strconv.FormatFloat(le, 'g', -1, 64), cum)
+		}
+		fmt.Fprintf(b, "%s_bucket{%s,le=\"+Inf\"} %d\n", name, labels, h.count)
+		fmt.Fprintf(b, "%s_sum{%s} %s\n", name, labels, strconv.FormatFloat(h.sum, 'g', -1, 64))
+		fmt.Fprintf(b, "%s_count{%s} %d\n", name, labels, h.count)
+	}
+}
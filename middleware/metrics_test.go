@@ -0,0 +1,74 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/catatsuy/saruta"
+)
+
+func TestMetrics(t *testing.T) {
+	m := NewMetrics(MetricsOptions{Namespace: "app", DurationBuckets: []float64{0.1, 1}})
+	r := saruta.New(saruta.WithMiddlewareOnErrors())
+	r.Use(m.Middleware)
+	r.Get("/users/{id}", func(w http.ResponseWriter, req *http.Request) {
+		_, _ = w.Write([]byte("hello"))
+	})
+	m.Mount(r, "/metrics")
+	r.MustCompile()
+
+	for _, path := range []string{"/users/1", "/users/2", "/missing"} {
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("PURGE", "/users/1", nil))
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	out := rec.Body.String()
+	if !strings.HasPrefix(rec.Header().Get("Content-Type"), "text/plain; version=0.0.4") {
+		t.Fatalf("content type = %q", rec.Header().Get("Content-Type"))
+	}
+	for _, want := range []string{
+		"# TYPE app_http_requests_total counter\n",
+		`app_http_requests_total{method="GET",route="/users/{id}",status="200"} 2` + "\n",
+		`app_http_requests_total{method="GET",route="unmatched",status="404"} 1` + "\n",
+		`app_http_requests_total{method="OTHER",route="unmatched",status="405"} 1` + "\n",
+		`app_http_request_duration_seconds_bucket{method="GET",route="/users/{id}",le="+Inf"} 2` + "\n",
+		`app_http_request_duration_seconds_count{method="GET",route="/users/{id}"} 2` + "\n",
+		`app_http_response_size_bytes_sum{method="GET",route="/users/{id}"} 10` + "\n",
+		`app_http_response_size_bytes_bucket{method="GET",route="/users/{id}",le="100"} 2` + "\n",
+		// The metrics request itself is in flight while it is served.
+		"app_http_requests_in_flight 1\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("metrics output lacks %q:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "/users/1") {
+		t.Fatalf("raw path leaked into labels:\n%s", out)
+	}
+}
+
+func TestHistogramBuckets(t *testing.T) {
+	m := NewMetrics(MetricsOptions{DurationBuckets: []float64{0.1, 1}})
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	for _, d := range []time.Duration{50 * time.Millisecond, 100 * time.Millisecond, 500 * time.Millisecond, 2 * time.Second} {
+		m.record(req, http.StatusOK, 0, d)
+	}
+	var b strings.Builder
+	if _, err := m.WriteTo(&b); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		`http_request_duration_seconds_bucket{method="GET",route="unmatched",le="0.1"} 2`,
+		`http_request_duration_seconds_bucket{method="GET",route="unmatched",le="1"} 3`,
+		`http_request_duration_seconds_bucket{method="GET",route="unmatched",le="+Inf"} 4`,
+	} {
+		if !strings.Contains(b.String(), want) {
+			t.Fatalf("output lacks %q:\n%s", want, b.String())
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package middleware
+
+import "net/http"
+
+var noCacheHeaders = map[string]string{
+	"Cache-Control": "no-cache, no-store, no-transform, must-revalidate, private, max-age=0",
+	"Pragma":        "no-cache",
+	"Expires":       "Thu, 01 Jan 1970 00:00:00 GMT",
+}
+
+var (
+	// requestValidators are removed from requests so handlers never answer 304.
+	requestValidators = []string{"If-None-Match", "If-Modified-Since", "If-Match", "If-Unmodified-Since", "If-Range"}
+	// responseValidators are removed from responses so clients cannot revalidate.
+	responseValidators = []string{"ETag", "Last-Modified"}
+)
+
+// NoCache prevents browsers and intermediaries from caching responses: it
+// sets Cache-Control, Pragma and Expires, removes conditional request headers
+// and strips ETag and Last-Modified from the response. The anti-caching
+// headers are written last, so they override values set by the handler.
+func NoCache(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		for _, h := range requestValidators {
+			req.Header.Del(h)
+		}
+		next.ServeHTTP(&noCacheWriter{ResponseWriter: w}, req)
+	})
+}
+
+type noCacheWriter struct {
+	http.ResponseWriter
+	wroteHeader bool
+}
+
+func (w *noCacheWriter) WriteHeader(code int) {
+	if !w.wroteHeader {
+		h := w.Header()
+		for _, v := range responseValidators {
+			h.Del(v)
+		}
+		for k, v := range noCacheHeaders {
+			h.Set(k, v)
+		}
+		w.wroteHeader = code >= 200
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *noCacheWriter) Write(p []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	return w.ResponseWriter.Write(p)
+}
+
+func (w *noCacheWriter) Flush() {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	_ = http.NewResponseController(w.ResponseWriter).Flush()
+}
+
+func (w *noCacheWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -0,0 +1,150 @@
+package middleware
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// RateLimit is a token bucket: requests take one token each, tokens refill at
+// Rate per second, and at most Burst tokens accumulate.
+type RateLimit struct {
+	Rate  float64
+	Burst int
+}
+
+// RateLimitStore holds the token buckets of RateLimiter. Implement it on top
+// of a shared database such as Redis to enforce limits across instances.
+type RateLimitStore interface {
+	// Take removes one token from the bucket for key. If none is available it
+	// reports false and how long until one will be.
+	Take(ctx context.Context, key string, limit RateLimit) (ok bool, retryAfter time.Duration, err error)
+}
+
+// RateLimitOptions configures RateLimiter.
+type RateLimitOptions struct {
+	Limit RateLimit
+	// Key returns the bucket key of a request. It defaults to KeyByIP.
+	// Requests with an empty key are not limited.
+	Key func(req *http.Request) string
+	// Store defaults to a new MemoryStore.
+	Store RateLimitStore
+}
+
+// RateLimiter answers 429 Too Many Requests, with a Retry-After header, to
+// requests whose bucket is empty.
+//
+// Errors from the store are not fatal: the request is let through, so an
+// unavailable shared store does not take the service down with it.
+func RateLimiter(opts RateLimitOptions) func(http.Handler) http.Handler {
+	key := opts.Key
+	if key == nil {
+		key = KeyByIP
+	}
+	store := opts.Store
+	if store == nil {
+		store = NewMemoryStore()
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			k := key(req)
+			if k == "" {
+				next.ServeHTTP(w, req)
+				return
+			}
+			ok, retryAfter, err := store.Take(req.Context(), k, opts.Limit)
+			if err != nil || ok {
+				next.ServeHTTP(w, req)
+				return
+			}
+			secs := int64(math.Ceil(retryAfter.Seconds()))
+			w.Header().Set("Retry-After", strconv.FormatInt(max(secs, 1), 10))
+			http.Error(w, http.StatusText(http.StatusTooManyRequests), http.StatusTooManyRequests)
+		})
+	}
+}
+
+// KeyByIP keys requests by client address (req.RemoteAddr without the port).
+// Behind a proxy, run RealIP first.
+func KeyByIP(req *http.Request) string {
+	if addr, ok := remoteAddr(req.RemoteAddr); ok {
+		return addr.String()
+	}
+	return req.RemoteAddr
+}
+
+// KeyByRoute keys requests by the matched route pattern (req.Pattern), so
+// each route has one bucket shared by all clients. Requests without a
+// pattern, such as those handled by mounts, are not limited.
+func KeyByRoute(req *http.Request) string {
+	return req.Pattern
+}
+
+// KeyByRouteAndIP gives each client its own bucket per route.
+func KeyByRouteAndIP(req *http.Request) string {
+	if req.Pattern == "" {
+		return ""
+	}
+	return req.Pattern + " " + KeyByIP(req)
+}
+
+// MemoryStore is an in-process RateLimitStore. Idle buckets are dropped once
+// they have refilled.
+type MemoryStore struct {
+	mu        sync.Mutex
+	buckets   map[string]*tokenBucket
+	lastSweep time.Time
+}
+
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+	full   time.Time // when the bucket will be full again
+}
+
+// NewMemoryStore returns an empty MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{buckets: make(map[string]*tokenBucket), lastSweep: time.Now()}
+}
+
+// Take implements RateLimitStore.
+func (s *MemoryStore) Take(_ context.Context, key string, limit RateLimit) (bool, time.Duration, error) {
+	now := time.Now()
+	burst := float64(max(limit.Burst, 1))
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if now.Sub(s.lastSweep) > time.Minute {
+		for k, b := range s.buckets {
+			if now.After(b.full) {
+				delete(s.buckets, k)
+			}
+		}
+		s.lastSweep = now
+	}
+
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &tokenBucket{tokens: burst, last: now}
+		s.buckets[key] = b
+	} else {
+		b.tokens = min(burst, b.tokens+now.Sub(b.last).Seconds()*limit.Rate)
+		b.last = now
+	}
+	if limit.Rate > 0 {
+		b.full = now.Add(time.Duration((burst - b.tokens + 1) / limit.Rate * float64(time.Second)))
+	} else {
+		b.full = now.AddDate(100, 0, 0) // never refills
+	}
+	if b.tokens < 1 {
+		if limit.Rate <= 0 {
+			return false, time.Duration(math.MaxInt64), nil
+		}
+		return false, time.Duration((1 - b.tokens) / limit.Rate * float64(time.Second)), nil
+	}
+	b.tokens--
+	return true, 0, nil
+}
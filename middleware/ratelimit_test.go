@@ -0,0 +1,91 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/catatsuy/saruta"
+)
+
+func TestRateLimiter(t *testing.T) {
+	h := RateLimiter(RateLimitOptions{Limit: RateLimit{Rate: 0.5, Burst: 2}})(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	serve := func(remote string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.RemoteAddr = remote
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec
+	}
+	for i := range 2 {
+		if rec := serve("192.0.2.1:1000"); rec.Code != http.StatusNoContent {
+			t.Fatalf("request %d: status %d", i, rec.Code)
+		}
+	}
+	rec := serve("192.0.2.1:2000")
+	if rec.Code != http.StatusTooManyRequests || rec.Header().Get("Retry-After") != "2" {
+		t.Fatalf("over limit: %d Retry-After=%q", rec.Code, rec.Header().Get("Retry-After"))
+	}
+	if rec := serve("192.0.2.2:1000"); rec.Code != http.StatusNoContent {
+		t.Fatalf("other client: status %d", rec.Code)
+	}
+}
+
+func TestRateLimiterByRoute(t *testing.T) {
+	r := saruta.New()
+	r.Use(RateLimiter(RateLimitOptions{Limit: RateLimit{Rate: 1, Burst: 1}, Key: KeyByRoute}))
+	r.Get("/a/{id}", func(w http.ResponseWriter, req *http.Request) {})
+	r.Get("/b", func(w http.ResponseWriter, req *http.Request) {})
+	r.MustCompile()
+
+	for _, tc := range []struct {
+		path string
+		want int
+	}{
+		{"/a/1", http.StatusOK},
+		{"/a/2", http.StatusTooManyRequests}, // same pattern, same bucket
+		{"/b", http.StatusOK},
+	} {
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tc.path, nil))
+		if rec.Code != tc.want {
+			t.Fatalf("%s: status %d, want %d", tc.path, rec.Code, tc.want)
+		}
+	}
+}
+
+type failingStore struct{}
+
+func (failingStore) Take(context.Context, string, RateLimit) (bool, time.Duration, error) {
+	return false, 0, errors.New("store down")
+}
+
+func TestRateLimiterStoreErrorFailsOpen(t *testing.T) {
+	h := RateLimiter(RateLimitOptions{Limit: RateLimit{Rate: 1, Burst: 1}, Store: failingStore{}})(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {}))
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", rec.Code)
+	}
+}
+
+func TestMemoryStoreRefill(t *testing.T) {
+	s := NewMemoryStore()
+	limit := RateLimit{Rate: 1000, Burst: 1}
+	ctx := context.Background()
+	if ok, _, _ := s.Take(ctx, "k", limit); !ok {
+		t.Fatal("first take failed")
+	}
+	if ok, retry, _ := s.Take(ctx, "k", limit); ok || retry <= 0 || retry > time.Millisecond {
+		t.Fatalf("second take: ok=%v retry=%v", ok, retry)
+	}
+	time.Sleep(5 * time.Millisecond)
+	if ok, _, _ := s.Take(ctx, "k", limit); !ok {
+		t.Fatal("take after refill failed")
+	}
+}
@@ -0,0 +1,108 @@
+package middleware
+
+import (
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+)
+
+// RealIP replaces req.RemoteAddr with the client address reported by a
+// trusted reverse proxy.
+//
+// trusted lists the CIDR prefixes (or plain addresses) of the proxies in
+// front of the server. Forwarding headers are only read when the connecting
+// peer is trusted; otherwise the request is passed on unchanged, so clients
+// cannot spoof their address. The headers are consulted in order: Forwarded
+// (RFC 7239), X-Forwarded-For, X-Real-IP. For the list headers, entries are
+// walked from the right and the first address not in trusted is the client;
+// if every entry is trusted, the leftmost one is used. A malformed or
+// obfuscated entry ("unknown") stops the walk and leaves RemoteAddr as is.
+//
+// The new RemoteAddr is the bare IP address without a port. RealIP panics if
+// an entry of trusted cannot be parsed.
+func RealIP(trusted ...string) func(http.Handler) http.Handler {
+	prefixes := parsePrefixes("RealIP", trusted)
+	isTrusted := func(addr netip.Addr) bool {
+		for _, p := range prefixes {
+			if p.Contains(addr) {
+				return true
+			}
+		}
+		return false
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			if peer, ok := remoteAddr(req.RemoteAddr); ok && isTrusted(peer) {
+				if client, ok := forwardedClient(req.Header, isTrusted); ok {
+					req.RemoteAddr = client.String()
+				}
+			}
+			next.ServeHTTP(w, req)
+		})
+	}
+}
+
+func forwardedClient(h http.Header, trusted func(netip.Addr) bool) (netip.Addr, bool) {
+	if values := h.Values("Forwarded"); len(values) > 0 {
+		var hops []string
+		for _, v := range values {
+			for _, elem := range strings.Split(v, ",") {
+				hops = append(hops, forwardedFor(elem))
+			}
+		}
+		return rightmostUntrusted(hops, trusted)
+	}
+	if values := h.Values("X-Forwarded-For"); len(values) > 0 {
+		var hops []string
+		for _, v := range values {
+			hops = append(hops, strings.Split(v, ",")...)
+		}
+		return rightmostUntrusted(hops, trusted)
+	}
+	if v := h.Get("X-Real-IP"); v != "" {
+		return parseHop(v)
+	}
+	return netip.Addr{}, false
+}
+
+// forwardedFor returns the for= parameter of one Forwarded element.
+func forwardedFor(elem string) string {
+	for _, pair := range strings.Split(elem, ";") {
+		k, v, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		if ok && strings.EqualFold(k, "for") {
+			return strings.Trim(v, `"`)
+		}
+	}
+	return ""
+}
+
+func rightmostUntrusted(hops []string, trusted func(netip.Addr) bool) (netip.Addr, bool) {
+	var addr netip.Addr
+	for i := len(hops) - 1; i >= 0; i-- {
+		var ok bool
+		addr, ok = parseHop(hops[i])
+		if !ok {
+			return netip.Addr{}, false
+		}
+		if !trusted(addr) {
+			return addr, true
+		}
+	}
+	return addr, addr.IsValid()
+}
+
+// parseHop parses an address as found in forwarding headers: a bare IP,
+// "ip:port", or "[ipv6]:port".
+func parseHop(s string) (netip.Addr, bool) {
+	s = strings.TrimSpace(s)
+	if host, _, err := net.SplitHostPort(s); err == nil {
+		s = host
+	}
+	s = strings.TrimSuffix(strings.TrimPrefix(s, "["), "]")
+	addr, err := netip.ParseAddr(s)
+	if err != nil {
+		return netip.Addr{}, false
+	}
+	return addr.Unmap(), true
+}
@@ -0,0 +1,53 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRealIP(t *testing.T) {
+	var got string
+	h := RealIP("10.0.0.0/8", "::1")(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		got = req.RemoteAddr
+	}))
+
+	for _, tc := range []struct {
+		name   string
+		remote string
+		header map[string]string
+		want   string
+	}{
+		{name: "untrusted peer ignores headers", remote: "203.0.113.9:5000", header: map[string]string{"X-Forwarded-For": "1.2.3.4"}, want: "203.0.113.9:5000"},
+		{name: "no headers", remote: "10.0.0.1:5000", want: "10.0.0.1:5000"},
+		{name: "xff single", remote: "10.0.0.1:5000", header: map[string]string{"X-Forwarded-For": "198.51.100.7"}, want: "198.51.100.7"},
+		{name: "xff skips trusted hops", remote: "10.0.0.1:5000", header: map[string]string{"X-Forwarded-For": "6.6.6.6, 198.51.100.7, 10.2.3.4"}, want: "198.51.100.7"},
+		{name: "xff all trusted", remote: "10.0.0.1:5000", header: map[string]string{"X-Forwarded-For": "10.9.9.9, 10.2.3.4"}, want: "10.9.9.9"},
+		{name: "xff garbage", remote: "10.0.0.1:5000", header: map[string]string{"X-Forwarded-For": "1.2.3.4, nonsense"}, want: "10.0.0.1:5000"},
+		{name: "forwarded wins", remote: "[::1]:5000", header: map[string]string{
+			"Forwarded":       `for=192.0.2.60;proto=https, For="[2001:db8:cafe::17]:4711"`,
+			"X-Forwarded-For": "1.2.3.4",
+		}, want: "2001:db8:cafe::17"},
+		{name: "forwarded unknown", remote: "10.0.0.1:5000", header: map[string]string{"Forwarded": "for=unknown"}, want: "10.0.0.1:5000"},
+		{name: "x-real-ip", remote: "10.0.0.1:5000", header: map[string]string{"X-Real-IP": "198.51.100.8"}, want: "198.51.100.8"},
+	} {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.RemoteAddr = tc.remote
+		for k, v := range tc.header {
+			req.Header.Set(k, v)
+		}
+		h.ServeHTTP(httptest.NewRecorder(), req)
+		if got != tc.want {
+			t.Fatalf("%s: RemoteAddr = %q, want %q", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestRealIPPanicsOnInvalidEntry(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatalf("expected panic")
+		}
+	}()
+	RealIP("10.0.0.0/33")
+}
@@ -0,0 +1,52 @@
+package middleware
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"runtime/debug"
+)
+
+// Recoverer is RecovererWithLogger(nil): it logs panics with slog.Default.
+func Recoverer(next http.Handler) http.Handler {
+	return RecovererWithLogger(nil)(next)
+}
+
+// RecovererWithLogger recovers from panics in later handlers, logs the panic
+// value and stack to l (slog.Default if nil) at error level, and answers 500
+// Internal Server Error.
+//
+// The log record carries the request ID (see RequestID) and the route pattern
+// when available, whether Recoverer is registered before or after RequestID.
+// http.ErrAbortHandler is re-panicked so net/http aborts the response as
+// intended. If the handler had already started the response, the status
+// cannot be changed and the client sees a truncated body.
+func RecovererWithLogger(l *slog.Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			defer func() {
+				v := recover()
+				if v == nil {
+					return
+				}
+				if v == http.ErrAbortHandler {
+					panic(v)
+				}
+				logger := l
+				if logger == nil {
+					logger = slog.Default()
+				}
+				logger.LogAttrs(req.Context(), slog.LevelError, "panic serving request",
+					slog.String("request_id", requestIDOf(w, req)),
+					slog.String("method", req.Method),
+					slog.String("path", req.URL.Path),
+					slog.String("pattern", req.Pattern),
+					slog.String("panic", fmt.Sprint(v)),
+					slog.String("stack", string(debug.Stack())),
+				)
+				http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			}()
+			next.ServeHTTP(w, req)
+		})
+	}
+}
@@ -0,0 +1,66 @@
+package middleware
+
+import (
+	"bytes"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRecoverer(t *testing.T) {
+	var buf bytes.Buffer
+	prev := slog.Default()
+	slog.SetDefault(slog.New(slog.NewTextHandler(&buf, nil)))
+	defer slog.SetDefault(prev)
+
+	h := RequestID(Recoverer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		panic("boom")
+	})))
+	req := httptest.NewRequest(http.MethodGet, "/users/1", nil)
+	req.Header.Set(RequestIDHeader, "req-1")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusInternalServerError {
+		t.Fatalf("status = %d, want 500", rec.Code)
+	}
+	log := buf.String()
+	for _, want := range []string{"panic=boom", "request_id=req-1", "path=/users/1", "recoverer_test.go"} {
+		if !strings.Contains(log, want) {
+			t.Fatalf("log %q does not contain %q", log, want)
+		}
+	}
+}
+
+func TestRecovererWithLogger(t *testing.T) {
+	var buf bytes.Buffer
+	h := RecovererWithLogger(slog.New(slog.NewTextHandler(&buf, nil)))(RequestID(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		panic("boom")
+	})))
+	req := httptest.NewRequest(http.MethodGet, "/users/1", nil)
+	req.Header.Set(RequestIDHeader, "req-1")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusInternalServerError {
+		t.Fatalf("status = %d, want 500", rec.Code)
+	}
+	// Registered before RequestID, the recoverer still logs the ID.
+	if log := buf.String(); !strings.Contains(log, "request_id=req-1") || !strings.Contains(log, "panic=boom") {
+		t.Fatalf("log %q does not carry the panic and request ID", log)
+	}
+}
+
+func TestRecovererAbortHandler(t *testing.T) {
+	h := Recoverer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		panic(http.ErrAbortHandler)
+	}))
+	defer func() {
+		if v := recover(); v != http.ErrAbortHandler {
+			t.Fatalf("recovered %v, want http.ErrAbortHandler", v)
+		}
+	}()
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+}
@@ -0,0 +1,62 @@
+package middleware
+
+import (
+	"context"
+	"crypto/rand"
+	"net/http"
+)
+
+// RequestIDHeader is the header read and written by RequestID.
+var RequestIDHeader = "X-Request-Id"
+
+type requestIDKey struct{}
+
+// RequestID assigns a request ID to every request.
+//
+// An incoming RequestIDHeader is reused when it is a short printable token;
+// otherwise a random ID is generated. The ID is stored in the request context
+// (see GetRequestID) and echoed in the response header. Register it first so
+// that every later middleware, handler and error handler can correlate on it.
+func RequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		id := req.Header.Get(RequestIDHeader)
+		if !validRequestID(id) {
+			id = rand.Text()
+		}
+		w.Header().Set(RequestIDHeader, id)
+		next.ServeHTTP(w, req.WithContext(WithRequestID(req.Context(), id)))
+	})
+}
+
+// WithRequestID returns a copy of ctx carrying id.
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// GetRequestID returns the request ID stored by RequestID, or "".
+func GetRequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// requestIDOf returns the request ID of req, falling back to the ID RequestID
+// echoed in the response header of w, so middleware registered before
+// RequestID still finds it once the inner handlers have run.
+func requestIDOf(w http.ResponseWriter, req *http.Request) string {
+	if id := GetRequestID(req.Context()); id != "" {
+		return id
+	}
+	return w.Header().Get(RequestIDHeader)
+}
+
+func validRequestID(id string) bool {
+	if id == "" || len(id) > 128 {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] < 0x21 || id[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
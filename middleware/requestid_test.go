@@ -0,0 +1,130 @@
+package middleware_test
+
+import (
+	"bytes"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/catatsuy/saruta"
+	"github.com/catatsuy/saruta/middleware"
+)
+
+func TestRequestID(t *testing.T) {
+	var gotID, gotPattern string
+	r := saruta.New()
+	r.Use(middleware.RequestID)
+	r.Get("/users/{id}", func(w http.ResponseWriter, req *http.Request) {
+		gotID = middleware.GetRequestID(req.Context())
+		gotPattern = req.Pattern
+	})
+	r.MustCompile()
+
+	req := httptest.NewRequest(http.MethodGet, "/users/1", nil)
+	req.Header.Set("X-Request-Id", "abc-123")
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	if gotID != "abc-123" || rec.Header().Get("X-Request-Id") != "abc-123" {
+		t.Fatalf("id = %q header = %q, want abc-123", gotID, rec.Header().Get("X-Request-Id"))
+	}
+	if gotPattern != "/users/{id}" {
+		t.Fatalf("pattern = %q, want /users/{id}", gotPattern)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/users/1", nil)
+	req.Header.Set("X-Request-Id", strings.Repeat("x", 200))
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	if gotID == "" || len(gotID) > 128 || rec.Header().Get("X-Request-Id") != gotID {
+		t.Fatalf("generated id = %q header = %q", gotID, rec.Header().Get("X-Request-Id"))
+	}
+}
+
+func TestRequestIDOrderingWithIPFilter(t *testing.T) {
+	r := saruta.New()
+	r.Use(middleware.RequestID, middleware.IPFilter("127.0.0.1"))
+	r.Get("/admin", func(w http.ResponseWriter, req *http.Request) {})
+	r.MustCompile()
+
+	req := httptest.NewRequest(http.MethodGet, "/admin", nil)
+	req.RemoteAddr = "10.0.0.1:1234"
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	if rec.Code != http.StatusForbidden {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusForbidden)
+	}
+	if rec.Header().Get("X-Request-Id") == "" {
+		t.Fatalf("rejected response has no request ID")
+	}
+}
+
+// TestCorrelationOrdering checks that the recoverer, the error handlers and the
+// 5xx hook see the same request ID and pattern whatever the order of the
+// built-in middleware, and without WithMiddlewareOnErrors.
+func TestCorrelationOrdering(t *testing.T) {
+	type report struct{ id, pattern string }
+	var hooked, handled []report
+	hook := middleware.OnServerError(func(req *http.Request, status int) {
+		hooked = append(hooked, report{middleware.GetRequestID(req.Context()), req.Pattern})
+	})
+	var buf bytes.Buffer
+	recoverer := middleware.RecovererWithLogger(slog.New(slog.NewTextHandler(&buf, nil)))
+
+	for _, order := range []struct {
+		name string
+		mw   []saruta.Middleware
+	}{
+		{"RequestID first", []saruta.Middleware{middleware.RequestID, hook, recoverer}},
+		{"RequestID last", []saruta.Middleware{hook, recoverer, middleware.RequestID}},
+		{"RequestID between", []saruta.Middleware{recoverer, middleware.RequestID, hook}},
+	} {
+		t.Run(order.name, func(t *testing.T) {
+			r := saruta.New()
+			r.UseWithErrors(order.mw...)
+			r.Get("/boom/{id}", func(w http.ResponseWriter, req *http.Request) {
+				panic("boom")
+			})
+			r.NotFound(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				handled = append(handled, report{middleware.GetRequestID(req.Context()), req.Pattern})
+				w.WriteHeader(http.StatusNotFound)
+			}))
+			r.MethodNotAllowed(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				handled = append(handled, report{middleware.GetRequestID(req.Context()), req.Pattern})
+				w.WriteHeader(http.StatusMethodNotAllowed)
+			}))
+			r.MustCompile()
+
+			hooked, handled = nil, nil
+			buf.Reset()
+			for _, tc := range []struct {
+				method, path string
+				code         int
+			}{
+				{http.MethodGet, "/boom/1", http.StatusInternalServerError},
+				{http.MethodGet, "/missing", http.StatusNotFound},
+				{http.MethodPost, "/boom/1", http.StatusMethodNotAllowed},
+			} {
+				req := httptest.NewRequest(tc.method, tc.path, nil)
+				req.Header.Set("X-Request-Id", "req-1")
+				rec := httptest.NewRecorder()
+				r.ServeHTTP(rec, req)
+				if rec.Code != tc.code || rec.Header().Get("X-Request-Id") != "req-1" {
+					t.Fatalf("%s %s: status = %d, X-Request-Id = %q", tc.method, tc.path, rec.Code, rec.Header().Get("X-Request-Id"))
+				}
+			}
+
+			if log := buf.String(); !strings.Contains(log, "request_id=req-1") || !strings.Contains(log, "pattern=/boom/{id}") {
+				t.Fatalf("panic log %q lacks the request ID or pattern", log)
+			}
+			if want := []report{{"req-1", "/boom/{id}"}}; !slices.Equal(hooked, want) {
+				t.Fatalf("5xx hook saw %v, want %v", hooked, want)
+			}
+			if want := []report{{"req-1", ""}, {"req-1", ""}}; !slices.Equal(handled, want) {
+				t.Fatalf("error handlers saw %v, want %v", handled, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,47 @@
+package middleware
+
+import "net/http"
+
+// OnServerError calls fn after every response with a 5xx status, including
+// the 500 written by Recoverer. A panic passing through, other than
+// http.ErrAbortHandler, is reported as a 500 and re-panicked, so fn also sees
+// panics when Recoverer is registered before OnServerError.
+//
+// fn receives the request with the same correlation as the other middleware
+// in this package: GetRequestID(req.Context()) returns the request ID and
+// req.Pattern the matched route pattern, whether OnServerError is registered
+// before or after RequestID. fn runs after the response has been written, on
+// the serving goroutine. The response writer passed on still supports
+// http.Flusher, http.Hijacker and http.ResponseController.
+func OnServerError(fn func(req *http.Request, status int)) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			sw := &statusWriter{ResponseWriter: w}
+			panicked := true
+			defer func() {
+				if !panicked {
+					return
+				}
+				v := recover()
+				if v != http.ErrAbortHandler {
+					reportServerError(fn, w, req, http.StatusInternalServerError)
+				}
+				panic(v)
+			}()
+			next.ServeHTTP(sw, req)
+			panicked = false
+			if sw.Status() >= 500 {
+				reportServerError(fn, w, req, sw.Status())
+			}
+		})
+	}
+}
+
+func reportServerError(fn func(*http.Request, int), w http.ResponseWriter, req *http.Request, status int) {
+	if GetRequestID(req.Context()) == "" {
+		if id := w.Header().Get(RequestIDHeader); id != "" {
+			req = req.WithContext(WithRequestID(req.Context(), id))
+		}
+	}
+	fn(req, status)
+}
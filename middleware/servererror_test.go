@@ -0,0 +1,52 @@
+package middleware
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestOnServerError(t *testing.T) {
+	var calls []int
+	var gotID string
+	hook := OnServerError(func(req *http.Request, status int) {
+		calls = append(calls, status)
+		gotID = GetRequestID(req.Context())
+	})
+	h := hook(RequestID(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		switch req.URL.Path {
+		case "/fail":
+			w.WriteHeader(http.StatusBadGateway)
+		case "/missing":
+			http.NotFound(w, req)
+		}
+	})))
+
+	for _, path := range []string{"/ok", "/missing", "/fail"} {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set(RequestIDHeader, "req-1")
+		h.ServeHTTP(httptest.NewRecorder(), req)
+	}
+	if len(calls) != 1 || calls[0] != http.StatusBadGateway {
+		t.Fatalf("hook calls = %v, want [502]", calls)
+	}
+	if gotID != "req-1" {
+		t.Fatalf("request ID = %q, want req-1", gotID)
+	}
+}
+
+func TestOnServerErrorPanic(t *testing.T) {
+	var calls []int
+	h := RecovererWithLogger(slog.New(slog.NewTextHandler(io.Discard, nil)))(OnServerError(func(req *http.Request, status int) {
+		calls = append(calls, status)
+	})(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		panic("boom")
+	})))
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	if rec.Code != http.StatusInternalServerError || len(calls) != 1 || calls[0] != http.StatusInternalServerError {
+		t.Fatalf("status = %d, hook calls = %v", rec.Code, calls)
+	}
+}
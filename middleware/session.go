@@ -0,0 +1,356 @@
+package middleware
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"maps"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SessionStore keeps session values on the server, keyed by the session ID
+// stored in the cookie. Implement it on a shared database to share sessions
+// between instances.
+type SessionStore interface {
+	// Load returns the values of session id, or nil if there is none.
+	Load(ctx context.Context, id string) (map[string]any, error)
+	// Save stores values for id, expiring after ttl.
+	Save(ctx context.Context, id string, values map[string]any, ttl time.Duration) error
+	Delete(ctx context.Context, id string) error
+}
+
+// SessionOptions configures Sessions.
+type SessionOptions struct {
+	// Name is the cookie name; "session" if empty.
+	Name string
+	// SigningKey authenticates the cookie with HMAC-SHA256. Required; use at
+	// least 32 random bytes.
+	SigningKey []byte
+	// EncryptionKey, if set, encrypts cookie-stored values with AES-GCM. It
+	// must be 16, 24 or 32 bytes. Unused with a Store.
+	EncryptionKey []byte
+	// Store keeps values on the server. If nil, values are stored in the
+	// cookie itself, so they must stay small (browsers cap cookies at ~4 KB).
+	Store SessionStore
+	// MaxAge is the session lifetime, renewed on every save; 24h if zero.
+	MaxAge time.Duration
+	// Cookie attributes. Path defaults to "/" and SameSite to Lax; the
+	// cookie is always HttpOnly.
+	Path     string
+	Domain   string
+	Secure   bool
+	SameSite http.SameSite
+}
+
+// Session is the session of one request. Values must be JSON-encodable;
+// after a round trip numbers are float64.
+type Session struct {
+	id        string
+	oldID     string // set by RenewID, deleted from the store on save
+	values    map[string]any
+	changed   bool
+	destroyed bool
+}
+
+// Get returns the value stored under key, or nil.
+func (s *Session) Get(key string) any {
+	return s.values[key]
+}
+
+// Set stores value under key.
+func (s *Session) Set(key string, value any) {
+	if s.values == nil {
+		s.values = make(map[string]any)
+	}
+	s.values[key] = value
+	s.changed = true
+}
+
+// Delete removes key.
+func (s *Session) Delete(key string) {
+	delete(s.values, key)
+	s.changed = true
+}
+
+// Destroy removes all values and expires the cookie.
+func (s *Session) Destroy() {
+	s.values = nil
+	s.destroyed = true
+}
+
+// RenewID moves the session to a new ID, keeping its values. Call it after
+// login to prevent session fixation. It has no effect on cookie-stored
+// sessions, which have no ID.
+func (s *Session) RenewID() {
+	if s.oldID == "" {
+		s.oldID = s.id
+	}
+	s.id = ""
+	s.changed = true
+}
+
+type sessionKey struct{}
+
+// GetSession returns the session stored by Sessions, or nil.
+func GetSession(ctx context.Context) *Session {
+	s, _ := ctx.Value(sessionKey{}).(*Session)
+	return s
+}
+
+// Sessions loads the session named by the request's cookie (a fresh one if
+// the cookie is missing, forged or expired) into the context, see
+// GetSession, and saves it when the handler starts its response, if it was
+// changed. Changes made after the response has started are lost.
+//
+//	r.Use(middleware.Sessions(middleware.SessionOptions{SigningKey: key, Secure: true}))
+//
+// Sessions panics if SigningKey is empty or EncryptionKey has an invalid
+// length.
+func Sessions(opts SessionOptions) func(http.Handler) http.Handler {
+	if len(opts.SigningKey) == 0 {
+		panic("middleware: Sessions requires a SigningKey")
+	}
+	if opts.Name == "" {
+		opts.Name = "session"
+	}
+	if opts.MaxAge == 0 {
+		opts.MaxAge = 24 * time.Hour
+	}
+	if opts.Path == "" {
+		opts.Path = "/"
+	}
+	if opts.SameSite == 0 {
+		opts.SameSite = http.SameSiteLaxMode
+	}
+	var aead cipher.AEAD
+	if len(opts.EncryptionKey) > 0 {
+		block, err := aes.NewCipher(opts.EncryptionKey)
+		if err != nil {
+			panic("middleware: invalid Sessions EncryptionKey: " + err.Error())
+		}
+		aead, _ = cipher.NewGCM(block)
+	}
+	c := &sessionCodec{opts: &opts, aead: aead}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			s := c.load(req)
+			sw := &sessionWriter{ResponseWriter: w, save: func() { c.save(w, req, s) }}
+			next.ServeHTTP(sw, req.WithContext(context.WithValue(req.Context(), sessionKey{}, s)))
+			sw.commit()
+		})
+	}
+}
+
+type sessionCodec struct {
+	opts *SessionOptions
+	aead cipher.AEAD
+}
+
+type cookiePayload struct {
+	Values  map[string]any `json:"v"`
+	Expires int64          `json:"e"`
+}
+
+func (c *sessionCodec) load(req *http.Request) *Session {
+	cookie, err := req.Cookie(c.opts.Name)
+	if err != nil {
+		return &Session{}
+	}
+	data, ok := c.verify(cookie.Value)
+	if !ok {
+		return &Session{}
+	}
+	if c.opts.Store != nil {
+		id := string(data)
+		values, err := c.opts.Store.Load(req.Context(), id)
+		if err != nil || values == nil {
+			return &Session{}
+		}
+		return &Session{id: id, values: values}
+	}
+	if c.aead != nil {
+		ns := c.aead.NonceSize()
+		if len(data) < ns {
+			return &Session{}
+		}
+		if data, err = c.aead.Open(nil, data[:ns], data[ns:], []byte(c.opts.Name)); err != nil {
+			return &Session{}
+		}
+	}
+	var p cookiePayload
+	if json.Unmarshal(data, &p) != nil || time.Now().Unix() > p.Expires {
+		return &Session{}
+	}
+	return &Session{values: p.Values}
+}
+
+func (c *sessionCodec) save(w http.ResponseWriter, req *http.Request, s *Session) {
+	if s.destroyed {
+		if c.opts.Store != nil {
+			for _, id := range []string{s.id, s.oldID} {
+				if id != "" {
+					_ = c.opts.Store.Delete(req.Context(), id)
+				}
+			}
+		}
+		c.setCookie(w, "", -1)
+		return
+	}
+	if !s.changed {
+		return
+	}
+	var data []byte
+	if c.opts.Store != nil {
+		if s.oldID != "" {
+			_ = c.opts.Store.Delete(req.Context(), s.oldID)
+		}
+		if s.id == "" {
+			s.id = rand.Text()
+		}
+		if err := c.opts.Store.Save(req.Context(), s.id, s.values, c.opts.MaxAge); err != nil {
+			return
+		}
+		data = []byte(s.id)
+	} else {
+		var err error
+		data, err = json.Marshal(cookiePayload{Values: s.values, Expires: time.Now().Add(c.opts.MaxAge).Unix()})
+		if err != nil {
+			return
+		}
+		if c.aead != nil {
+			nonce := make([]byte, c.aead.NonceSize())
+			_, _ = rand.Read(nonce)
+			data = c.aead.Seal(nonce, nonce, data, []byte(c.opts.Name))
+		}
+	}
+	c.setCookie(w, c.sign(data), int(c.opts.MaxAge/time.Second))
+}
+
+func (c *sessionCodec) setCookie(w http.ResponseWriter, value string, maxAge int) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     c.opts.Name,
+		Value:    value,
+		Path:     c.opts.Path,
+		Domain:   c.opts.Domain,
+		MaxAge:   maxAge,
+		Secure:   c.opts.Secure,
+		HttpOnly: true,
+		SameSite: c.opts.SameSite,
+	})
+}
+
+func (c *sessionCodec) mac(payload string) []byte {
+	m := hmac.New(sha256.New, c.opts.SigningKey)
+	m.Write([]byte(c.opts.Name + "|" + payload))
+	return m.Sum(nil)
+}
+
+func (c *sessionCodec) sign(data []byte) string {
+	payload := base64.RawURLEncoding.EncodeToString(data)
+	return payload + "." + base64.RawURLEncoding.EncodeToString(c.mac(payload))
+}
+
+func (c *sessionCodec) verify(value string) ([]byte, bool) {
+	payload, sig, ok := strings.Cut(value, ".")
+	if !ok {
+		return nil, false
+	}
+	got, err := base64.RawURLEncoding.DecodeString(sig)
+	if err != nil || !hmac.Equal(got, c.mac(payload)) {
+		return nil, false
+	}
+	data, err := base64.RawURLEncoding.DecodeString(payload)
+	return data, err == nil
+}
+
+// sessionWriter saves the session right before the response header is sent.
+type sessionWriter struct {
+	http.ResponseWriter
+	save      func()
+	committed bool
+}
+
+func (w *sessionWriter) commit() {
+	if !w.committed {
+		w.committed = true
+		w.save()
+	}
+}
+
+func (w *sessionWriter) WriteHeader(code int) {
+	if code >= 200 {
+		w.commit()
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *sessionWriter) Write(p []byte) (int, error) {
+	w.commit()
+	return w.ResponseWriter.Write(p)
+}
+
+func (w *sessionWriter) Flush() {
+	w.commit()
+	_ = http.NewResponseController(w.ResponseWriter).Flush()
+}
+
+func (w *sessionWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// MemorySessionStore is an in-process SessionStore, for tests and
+// single-instance deployments.
+type MemorySessionStore struct {
+	mu       sync.Mutex
+	sessions map[string]memorySession
+}
+
+type memorySession struct {
+	values  map[string]any
+	expires time.Time
+}
+
+// NewMemorySessionStore returns an empty MemorySessionStore.
+func NewMemorySessionStore() *MemorySessionStore {
+	return &MemorySessionStore{sessions: make(map[string]memorySession)}
+}
+
+// Load implements SessionStore.
+func (s *MemorySessionStore) Load(_ context.Context, id string) (map[string]any, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	sess, ok := s.sessions[id]
+	if !ok {
+		return nil, nil
+	}
+	if time.Now().After(sess.expires) {
+		delete(s.sessions, id)
+		return nil, nil
+	}
+	return maps.Clone(sess.values), nil
+}
+
+// Save implements SessionStore.
+func (s *MemorySessionStore) Save(_ context.Context, id string, values map[string]any, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sessions[id] = memorySession{values: maps.Clone(values), expires: time.Now().Add(ttl)}
+	return nil
+}
+
+// Delete implements SessionStore.
+func (s *MemorySessionStore) Delete(_ context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.sessions, id)
+	return nil
+}
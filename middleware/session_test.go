@@ -0,0 +1,98 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func sessionTestHandler(opts SessionOptions) http.Handler {
+	return Sessions(opts)(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		s := GetSession(req.Context())
+		switch req.URL.Path {
+		case "/login":
+			s.RenewID()
+			s.Set("user", "alice")
+		case "/logout":
+			s.Destroy()
+		}
+		user, _ := s.Get("user").(string)
+		_, _ = w.Write([]byte(user))
+	}))
+}
+
+func sessionRequest(t *testing.T, h http.Handler, path string, cookie *http.Cookie) (string, *http.Cookie) {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	if cookie != nil {
+		req.AddCookie(cookie)
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	var set *http.Cookie
+	if cs := rec.Result().Cookies(); len(cs) > 0 {
+		set = cs[0]
+	}
+	return rec.Body.String(), set
+}
+
+func TestSessions(t *testing.T) {
+	key := []byte(strings.Repeat("k", 32))
+	for name, opts := range map[string]SessionOptions{
+		"signed cookie":    {SigningKey: key},
+		"encrypted cookie": {SigningKey: key, EncryptionKey: []byte(strings.Repeat("e", 32))},
+		"store":            {SigningKey: key, Store: NewMemorySessionStore()},
+	} {
+		h := sessionTestHandler(opts)
+
+		body, cookie := sessionRequest(t, h, "/", nil)
+		if body != "" || cookie != nil {
+			t.Fatalf("%s: unchanged session set a cookie: %q %v", name, body, cookie)
+		}
+		_, cookie = sessionRequest(t, h, "/login", nil)
+		if cookie == nil || !cookie.HttpOnly || cookie.SameSite != http.SameSiteLaxMode {
+			t.Fatalf("%s: login cookie = %+v", name, cookie)
+		}
+		if opts.EncryptionKey != nil && strings.Contains(cookie.Value, "YWxpY2") { // base64 of "alic"
+			t.Fatalf("%s: value readable in cookie", name)
+		}
+		if body, _ := sessionRequest(t, h, "/", cookie); body != "alice" {
+			t.Fatalf("%s: session not loaded: %q", name, body)
+		}
+
+		tampered := *cookie
+		tampered.Value = "x" + cookie.Value[1:]
+		if cookie.Value[0] == 'x' {
+			tampered.Value = "y" + cookie.Value[1:]
+		}
+		if body, _ := sessionRequest(t, h, "/", &tampered); body != "" {
+			t.Fatalf("%s: tampered cookie accepted", name)
+		}
+
+		_, cleared := sessionRequest(t, h, "/logout", cookie)
+		if cleared == nil || cleared.MaxAge >= 0 {
+			t.Fatalf("%s: logout cookie = %+v", name, cleared)
+		}
+		if opts.Store != nil {
+			if body, _ := sessionRequest(t, h, "/", cookie); body != "" {
+				t.Fatalf("%s: destroyed session still loads", name)
+			}
+		}
+	}
+}
+
+func TestSessionRenewIDDeletesOldSession(t *testing.T) {
+	store := NewMemorySessionStore()
+	h := sessionTestHandler(SessionOptions{SigningKey: []byte("secret"), Store: store})
+	_, first := sessionRequest(t, h, "/login", nil)
+	_, second := sessionRequest(t, h, "/login", first)
+	if first.Value == second.Value {
+		t.Fatal("RenewID kept the session ID")
+	}
+	id, _ := (&sessionCodec{opts: &SessionOptions{Name: "session", SigningKey: []byte("secret")}}).verify(first.Value)
+	if v, _ := store.Load(context.Background(), string(id)); v != nil {
+		t.Fatal("old session still in store")
+	}
+}
@@ -0,0 +1,78 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+)
+
+// StripSlashes removes trailing slashes from the request path, so "/users/"
+// matches a route registered as "/users". saruta matches paths exactly, so
+// StripSlashes must run before routing: wrap the router with it, optionally
+// limited to a group of paths with Under:
+//
+//	handler := middleware.Under("/api", middleware.StripSlashes)(r)
+func StripSlashes(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if p, ok := trimSlashes(req.URL.Path); ok {
+			req.URL.Path = p
+			if req.URL.RawPath != "" {
+				req.URL.RawPath, _ = trimSlashes(req.URL.RawPath)
+			}
+		}
+		next.ServeHTTP(w, req)
+	})
+}
+
+// RedirectSlashes redirects requests whose path has trailing slashes to the
+// path without them, keeping the query string: 301 for GET and HEAD, 308 for
+// other methods. Like StripSlashes, it must wrap the router.
+func RedirectSlashes(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		p, ok := trimSlashes(req.URL.EscapedPath())
+		if !ok {
+			next.ServeHTTP(w, req)
+			return
+		}
+		// Collapse leading slashes so "//evil.example/" cannot become a
+		// protocol-relative redirect.
+		p = "/" + strings.TrimLeft(p, "/")
+		if req.URL.RawQuery != "" {
+			p += "?" + req.URL.RawQuery
+		}
+		code := http.StatusPermanentRedirect
+		if req.Method == http.MethodGet || req.Method == http.MethodHead {
+			code = http.StatusMovedPermanently
+		}
+		w.Header().Set("Location", p)
+		w.WriteHeader(code)
+	})
+}
+
+// Under applies mw only to requests whose path is prefix or below it, and
+// passes other requests straight to the wrapped handler. It lets groups of
+// routes opt into middleware that must run before routing.
+func Under(prefix string, mw func(http.Handler) http.Handler) func(http.Handler) http.Handler {
+	prefix = strings.TrimSuffix(prefix, "/")
+	return func(next http.Handler) http.Handler {
+		wrapped := mw(next)
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			p := req.URL.Path
+			if prefix == "" || p == prefix || strings.HasPrefix(p, prefix+"/") {
+				wrapped.ServeHTTP(w, req)
+				return
+			}
+			next.ServeHTTP(w, req)
+		})
+	}
+}
+
+func trimSlashes(p string) (string, bool) {
+	if len(p) <= 1 || p[len(p)-1] != '/' {
+		return p, false
+	}
+	t := strings.TrimRight(p, "/")
+	if t == "" {
+		t = "/"
+	}
+	return t, t != p
+}
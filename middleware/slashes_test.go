@@ -0,0 +1,54 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/catatsuy/saruta"
+)
+
+func TestStripSlashesUnder(t *testing.T) {
+	r := saruta.New()
+	r.Get("/api/users", func(w http.ResponseWriter, req *http.Request) { _, _ = w.Write([]byte("users")) })
+	r.Get("/web/about", func(w http.ResponseWriter, req *http.Request) { _, _ = w.Write([]byte("about")) })
+	r.MustCompile()
+	h := Under("/api", StripSlashes)(r)
+
+	for path, want := range map[string]int{
+		"/api/users":   http.StatusOK,
+		"/api/users/":  http.StatusOK,
+		"/api/users//": http.StatusOK,
+		"/web/about":   http.StatusOK,
+		"/web/about/":  http.StatusNotFound, // not opted in
+	} {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		if rec.Code != want {
+			t.Fatalf("%s: status %d, want %d", path, rec.Code, want)
+		}
+	}
+}
+
+func TestRedirectSlashes(t *testing.T) {
+	h := RedirectSlashes(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	for _, tc := range []struct {
+		method, target string
+		code           int
+		location       string
+	}{
+		{http.MethodGet, "/users/?page=2", http.StatusMovedPermanently, "/users?page=2"},
+		{http.MethodPost, "/users/", http.StatusPermanentRedirect, "/users"},
+		{http.MethodGet, "//evil.example/", http.StatusMovedPermanently, "/evil.example"},
+		{http.MethodGet, "/users", http.StatusNoContent, ""},
+		{http.MethodGet, "/", http.StatusNoContent, ""},
+	} {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(tc.method, tc.target, nil))
+		if rec.Code != tc.code || rec.Header().Get("Location") != tc.location {
+			t.Fatalf("%s %s: %d %q, want %d %q", tc.method, tc.target, rec.Code, rec.Header().Get("Location"), tc.code, tc.location)
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package middleware
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/catatsuy/saruta"
+)
+
+// MetaLatencyBudget is the route metadata key holding the latency budget
+// (time.Duration) checked by SlowRequests:
+//
+//	r.WithMeta(middleware.MetaLatencyBudget, 200*time.Millisecond).Get("/search", search)
+const MetaLatencyBudget = "latency_budget"
+
+// SlowRequest describes a request that exceeded its latency budget.
+type SlowRequest struct {
+	Method  string
+	Path    string
+	Pattern string
+	// Params are the matched path parameters in pattern order.
+	Params   []slog.Attr
+	Status   int
+	Duration time.Duration
+	Budget   time.Duration
+}
+
+// SlowRequestOptions configures SlowRequests.
+type SlowRequestOptions struct {
+	// Budget applies to routes without MetaLatencyBudget. Zero leaves them
+	// unchecked.
+	Budget time.Duration
+	// Logger receives a warning per slow request; slog.Default if nil.
+	// Ignored when OnSlow is set.
+	Logger *slog.Logger
+	// OnSlow, if set, is called instead of logging, after the handler
+	// returned, on the request goroutine.
+	OnSlow func(ctx context.Context, s SlowRequest)
+}
+
+// SlowRequests reports requests that take longer than their route's latency
+// budget: MetaLatencyBudget from the route metadata, or opts.Budget. Register
+// it with UseRoute; the budget is chosen once per route at Compile and
+// routes without one are left unwrapped, so the cost is a clock read per
+// checked request:
+//
+//	r.UseRoute(middleware.SlowRequests(middleware.SlowRequestOptions{Budget: time.Second}))
+//
+// The duration covers the handler and the middleware registered after
+// SlowRequests. The default report is a slog warning with the method, path,
+// pattern, path parameters, status, duration, budget and request ID.
+func SlowRequests(opts SlowRequestOptions) saruta.RouteMiddleware {
+	return func(route saruta.RouteInfo, next http.Handler) http.Handler {
+		budget := opts.Budget
+		if v, ok := route.Meta[MetaLatencyBudget].(time.Duration); ok {
+			budget = v
+		}
+		if budget <= 0 {
+			return next
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			start := time.Now()
+			sw := &statusWriter{ResponseWriter: w}
+			next.ServeHTTP(sw, req)
+			d := time.Since(start)
+			if d <= budget {
+				return
+			}
+			s := SlowRequest{
+				Method:   req.Method,
+				Path:     req.URL.Path,
+				Pattern:  req.Pattern,
+				Status:   sw.Status(),
+				Duration: d,
+				Budget:   budget,
+			}
+			for name, value := range saruta.Params(req) {
+				s.Params = append(s.Params, slog.String(name, value))
+			}
+			if opts.OnSlow != nil {
+				opts.OnSlow(req.Context(), s)
+				return
+			}
+			logSlowRequest(req.Context(), opts.Logger, s)
+		})
+	}
+}
+
+func logSlowRequest(ctx context.Context, l *slog.Logger, s SlowRequest) {
+	if l == nil {
+		l = slog.Default()
+	}
+	attrs := []slog.Attr{
+		slog.String("method", s.Method),
+		slog.String("path", s.Path),
+		slog.String("pattern", s.Pattern),
+		slog.Int("status", s.Status),
+		slog.Duration("duration", s.Duration),
+		slog.Duration("budget", s.Budget),
+	}
+	if len(s.Params) > 0 {
+		attrs = append(attrs, slog.Attr{Key: "params", Value: slog.GroupValue(s.Params...)})
+	}
+	if id := GetRequestID(ctx); id != "" {
+		attrs = append(attrs, slog.String("request_id", id))
+	}
+	l.LogAttrs(ctx, slog.LevelWarn, "slow request", attrs...)
+}
@@ -0,0 +1,70 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/catatsuy/saruta"
+)
+
+func TestSlowRequests(t *testing.T) {
+	var got []SlowRequest
+	r := saruta.New()
+	r.UseRoute(SlowRequests(SlowRequestOptions{
+		Budget: time.Hour,
+		OnSlow: func(ctx context.Context, s SlowRequest) { got = append(got, s) },
+	}))
+	slow := func(w http.ResponseWriter, req *http.Request) {
+		time.Sleep(5 * time.Millisecond)
+		w.WriteHeader(http.StatusAccepted)
+	}
+	r.WithMeta(MetaLatencyBudget, time.Millisecond).Get("/orgs/{org}/search", slow)
+	r.Get("/reports", slow)
+	r.MustCompile()
+
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/orgs/acme/search", nil))
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/reports", nil))
+
+	if len(got) != 1 {
+		t.Fatalf("slow requests = %+v, want 1", got)
+	}
+	s := got[0]
+	if s.Pattern != "/orgs/{org}/search" || s.Status != http.StatusAccepted || s.Budget != time.Millisecond || s.Duration < 5*time.Millisecond {
+		t.Fatalf("slow request = %+v", s)
+	}
+	if len(s.Params) != 1 || s.Params[0].Key != "org" || s.Params[0].Value.String() != "acme" {
+		t.Fatalf("params = %v", s.Params)
+	}
+}
+
+func TestSlowRequestsLog(t *testing.T) {
+	var buf bytes.Buffer
+	r := saruta.New()
+	r.UseRoute(SlowRequests(SlowRequestOptions{
+		Budget: time.Nanosecond,
+		Logger: slog.New(slog.NewJSONHandler(&buf, nil)),
+	}))
+	r.Get("/users/{id}", func(w http.ResponseWriter, req *http.Request) {
+		time.Sleep(time.Millisecond)
+	})
+	r.MustCompile()
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/users/7", nil))
+
+	var rec struct {
+		Level, Msg, Pattern string
+		Status              int
+		Params              map[string]string
+	}
+	if err := json.Unmarshal(buf.Bytes(), &rec); err != nil {
+		t.Fatalf("%v: %s", err, buf.String())
+	}
+	if rec.Level != "WARN" || rec.Msg != "slow request" || rec.Pattern != "/users/{id}" || rec.Status != 200 || rec.Params["id"] != "7" {
+		t.Fatalf("record = %+v", rec)
+	}
+}
@@ -0,0 +1,143 @@
+package middleware
+
+import (
+	"context"
+	"maps"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Timeout gives every request a deadline of d. The request context is
+// cancelled at the deadline; if the handler has not started the response by
+// then, Timeout answers 504 Gateway Timeout and later writes by the handler
+// fail with http.ErrHandlerTimeout.
+//
+// Unlike http.TimeoutHandler, responses are not buffered: once the handler
+// has written the header, the response streams as usual and Timeout waits
+// for the handler to return, relying on it to observe the cancelled context.
+// The handler runs on its own goroutine; a panic in it is re-raised on the
+// serving goroutine unless the 504 has already been sent. The response writer
+// supports http.Flusher but not http.Hijacker.
+func Timeout(d time.Duration) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			ctx, cancel := context.WithTimeout(req.Context(), d)
+			defer cancel()
+			req = req.WithContext(ctx)
+
+			tw := &timeoutWriter{w: w, h: make(http.Header), ctx: ctx}
+			done := make(chan struct{})
+			panicked := make(chan any, 1)
+			go func() {
+				defer func() {
+					if p := recover(); p != nil {
+						panicked <- p
+					}
+					close(done)
+				}()
+				next.ServeHTTP(tw, req)
+			}()
+
+			select {
+			case <-done:
+			case <-ctx.Done():
+				tw.mu.Lock()
+				if !tw.wroteHeader {
+					tw.timedOut = true
+					tw.mu.Unlock()
+					if ctx.Err() == context.DeadlineExceeded {
+						http.Error(w, http.StatusText(http.StatusGatewayTimeout), http.StatusGatewayTimeout)
+					}
+					return
+				}
+				tw.mu.Unlock()
+				<-done
+			}
+			select {
+			case p := <-panicked:
+				panic(p)
+			default:
+			}
+			tw.mu.Lock()
+			defer tw.mu.Unlock()
+			switch {
+			case tw.timedOut:
+				http.Error(w, http.StatusText(http.StatusGatewayTimeout), http.StatusGatewayTimeout)
+			case !tw.wroteHeader:
+				tw.writeHeaderLocked(http.StatusOK)
+			}
+		})
+	}
+}
+
+// timeoutWriter buffers header changes until the handler writes the header,
+// so that after a timeout the handler goroutine never touches w.
+type timeoutWriter struct {
+	w   http.ResponseWriter
+	h   http.Header
+	ctx context.Context
+
+	mu          sync.Mutex
+	wroteHeader bool
+	timedOut    bool
+}
+
+func (tw *timeoutWriter) Header() http.Header {
+	return tw.h
+}
+
+func (tw *timeoutWriter) WriteHeader(code int) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	if tw.expiredLocked() || tw.wroteHeader {
+		return
+	}
+	tw.writeHeaderLocked(code)
+}
+
+// expiredLocked reports whether the response is lost to the timeout. A
+// handler reacting to the deadline may get here before Timeout does, so a
+// passed deadline counts as a timeout until the header is written.
+func (tw *timeoutWriter) expiredLocked() bool {
+	if !tw.timedOut && !tw.wroteHeader && tw.ctx.Err() == context.DeadlineExceeded {
+		tw.timedOut = true
+	}
+	return tw.timedOut
+}
+
+func (tw *timeoutWriter) writeHeaderLocked(code int) {
+	if code < 200 {
+		// Informational responses may be followed by the final one.
+		maps.Copy(tw.w.Header(), tw.h)
+		tw.w.WriteHeader(code)
+		return
+	}
+	tw.wroteHeader = true
+	maps.Copy(tw.w.Header(), tw.h)
+	tw.w.WriteHeader(code)
+}
+
+func (tw *timeoutWriter) Write(p []byte) (int, error) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	if tw.expiredLocked() {
+		return 0, http.ErrHandlerTimeout
+	}
+	if !tw.wroteHeader {
+		tw.writeHeaderLocked(http.StatusOK)
+	}
+	return tw.w.Write(p)
+}
+
+func (tw *timeoutWriter) Flush() {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	if tw.expiredLocked() {
+		return
+	}
+	if !tw.wroteHeader {
+		tw.writeHeaderLocked(http.StatusOK)
+	}
+	_ = http.NewResponseController(tw.w).Flush()
+}
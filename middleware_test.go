@@ -0,0 +1,76 @@
+package saruta
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+)
+
+func TestRouterUseRoute(t *testing.T) {
+	var calls []string
+	var built []string
+	plain := func(name string) Middleware {
+		return func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				calls = append(calls, name)
+				next.ServeHTTP(w, req)
+			})
+		}
+	}
+	scoped := func(route RouteInfo, next http.Handler) http.Handler {
+		built = append(built, route.Method+" "+route.Pattern)
+		scope, _ := route.Meta["scope"].(string)
+		if scope == "" {
+			return next
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			calls = append(calls, "scope:"+scope+":"+req.PathValue(route.Params[0]))
+			if req.Header.Get("X-Scope") != scope {
+				http.Error(w, "forbidden", http.StatusForbidden)
+				return
+			}
+			next.ServeHTTP(w, req)
+		})
+	}
+
+	r := New()
+	r.Use(plain("first"))
+	r.UseRoute(scoped)
+	r.Use(plain("last"))
+	r.WithMeta("scope", "admin").Get("/admin/{id}", func(w http.ResponseWriter, req *http.Request) {})
+	r.Get("/public/{id}", func(w http.ResponseWriter, req *http.Request) {})
+	r.WithRoute(func(route RouteInfo, next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			calls = append(calls, "derived:"+route.Pattern)
+			next.ServeHTTP(w, req)
+		})
+	}).Get("/derived", func(w http.ResponseWriter, req *http.Request) {})
+	r.MustCompile()
+
+	slices.Sort(built)
+	if want := []string{"GET /admin/{id}", "GET /derived", "GET /public/{id}"}; !slices.Equal(built, want) {
+		t.Fatalf("route middleware built for %v, want %v", built, want)
+	}
+
+	serve := func(path, scope string) int {
+		calls = nil
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set("X-Scope", scope)
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+		return rec.Code
+	}
+	if code := serve("/admin/7", "admin"); code != http.StatusOK || !slices.Equal(calls, []string{"first", "scope:admin:7", "last"}) {
+		t.Fatalf("admin: status=%d calls=%v", code, calls)
+	}
+	if code := serve("/admin/7", ""); code != http.StatusForbidden {
+		t.Fatalf("admin without scope: status=%d", code)
+	}
+	if code := serve("/public/1", ""); code != http.StatusOK || !slices.Equal(calls, []string{"first", "last"}) {
+		t.Fatalf("public: status=%d calls=%v", code, calls)
+	}
+	if serve("/derived", ""); !slices.Equal(calls, []string{"first", "last", "derived:/derived"}) {
+		t.Fatalf("derived calls=%v", calls)
+	}
+}
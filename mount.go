@@ -0,0 +1,26 @@
+package saruta
+
+// MountPrecedence controls how a mount competes with routes for the same path.
+type MountPrecedence int
+
+const (
+	// MountAfterRoutes tries the mount only when no route path matches
+	// (default). A path that matches a route with a different method gets 405.
+	MountAfterRoutes MountPrecedence = iota
+	// MountBeforeMethodNotAllowed also tries the mount when a route path
+	// matches but its method does not, instead of answering 405.
+	MountBeforeMethodNotAllowed
+	// MountBeforeRoutes tries the mount before route lookup, so it wins over
+	// routes under its prefix.
+	MountBeforeRoutes
+)
+
+// MountOption configures a mount.
+type MountOption func(*registeredMount)
+
+// WithMountPrecedence sets the precedence of a mount relative to routes.
+func WithMountPrecedence(p MountPrecedence) MountOption {
+	return func(m *registeredMount) {
+		m.precedence = p
+	}
+}
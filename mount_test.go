@@ -0,0 +1,44 @@
+package saruta
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRouterMountPrecedence(t *testing.T) {
+	newRouter := func(p MountPrecedence) *Router {
+		r := New()
+		r.Get("/api/users", func(w http.ResponseWriter, req *http.Request) {
+			_, _ = w.Write([]byte("route"))
+		})
+		r.Mount("/api", http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			_, _ = w.Write([]byte("mount"))
+		}), WithMountPrecedence(p))
+		r.MustCompile()
+		return r
+	}
+
+	for _, tc := range []struct {
+		precedence MountPrecedence
+		method     string
+		wantCode   int
+		wantBody   string
+	}{
+		{precedence: MountAfterRoutes, method: http.MethodGet, wantCode: http.StatusOK, wantBody: "route"},
+		{precedence: MountAfterRoutes, method: http.MethodPost, wantCode: http.StatusMethodNotAllowed},
+		{precedence: MountBeforeMethodNotAllowed, method: http.MethodGet, wantCode: http.StatusOK, wantBody: "route"},
+		{precedence: MountBeforeMethodNotAllowed, method: http.MethodPost, wantCode: http.StatusOK, wantBody: "mount"},
+		{precedence: MountBeforeRoutes, method: http.MethodGet, wantCode: http.StatusOK, wantBody: "mount"},
+	} {
+		r := newRouter(tc.precedence)
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, httptest.NewRequest(tc.method, "/api/users", nil))
+		if rec.Code != tc.wantCode {
+			t.Fatalf("precedence %d %s status = %d, want %d", tc.precedence, tc.method, rec.Code, tc.wantCode)
+		}
+		if tc.wantBody != "" && rec.Body.String() != tc.wantBody {
+			t.Fatalf("precedence %d %s body = %q, want %q", tc.precedence, tc.method, rec.Body.String(), tc.wantBody)
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package saruta
+
+import (
+	"strings"
+	"sync/atomic"
+)
+
+type nearMissKind int
+
+const (
+	nearMissNone nearMissKind = iota
+	nearMissTrailingSlash
+	nearMissCase
+	nearMissMethod
+)
+
+// NearMissStats counts unmatched requests that were close to a registered route.
+type NearMissStats struct {
+	// NotFound is the total number of 404 responses served by the router.
+	NotFound uint64
+	// TrailingSlash counts 404s that would match with a trailing slash added or removed.
+	TrailingSlash uint64
+	// Case counts 404s that would match with the path lowercased.
+	Case uint64
+	// Method counts requests whose path matched but not the method (405 responses).
+	Method uint64
+}
+
+type nearMissCounters struct {
+	notFound      atomic.Uint64
+	trailingSlash atomic.Uint64
+	caseMismatch  atomic.Uint64
+	method        atomic.Uint64
+}
+
+// WithNearMissStats enables soft-404 tracking, exposed via Router.NearMisses.
+//
+// The extra lookups only run for requests that do not match a route.
+func WithNearMissStats() Option {
+	return func(r *Router) {
+		r.state.nearMiss = &nearMissCounters{}
+	}
+}
+
+// NearMisses returns a snapshot of the soft-404 counters.
+//
+// All counters are zero unless the router was created with WithNearMissStats.
+func (r *Router) NearMisses() NearMissStats {
+	c := r.state.nearMiss
+	if c == nil {
+		return NearMissStats{}
+	}
+	return NearMissStats{
+		NotFound:      c.notFound.Load(),
+		TrailingSlash: c.trailingSlash.Load(),
+		Case:          c.caseMismatch.Load(),
+		Method:        c.method.Load(),
+	}
+}
+
+func (c *nearMissCounters) recordNotFound(root *radixTree, method, path string) {
+	c.notFound.Add(1)
+	switch findNearMiss(root, method, path) {
+	case nearMissTrailingSlash:
+		c.trailingSlash.Add(1)
+	case nearMissCase:
+		c.caseMismatch.Add(1)
+	}
+}
+
+// findNearMiss reports how an unmatched path differs from a registered route.
+func findNearMiss(root *radixTree, method, path string) nearMissKind {
+	if path == "" || path[0] != '/' {
+		return nearMissNone
+	}
+	alt := path + "/"
+	if len(path) > 1 && path[len(path)-1] == '/' {
+		alt = path[:len(path)-1]
+	}
+	if routeExists(root, method, alt) {
+		return nearMissTrailingSlash
+	}
+	if lower := strings.ToLower(path); lower != path && routeExists(root, method, lower) {
+		return nearMissCase
+	}
+	return nearMissNone
+}
+
+func routeExists(root *radixTree, method, path string) bool {
+	matched, ok := root.matchRoute(path)
+	if !ok {
+		return false
+	}
+	_, ok = matched.leaf.handlers.get(method)
+	return ok
+}
@@ -0,0 +1,46 @@
+package saruta
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRouterNearMissStats(t *testing.T) {
+	h := func(w http.ResponseWriter, req *http.Request) {}
+	r := New(WithNearMissStats())
+	r.Get("/users", h)
+	r.Get("/teams/", h)
+	r.Get("/users/{id}/profile", h)
+	r.MustCompile()
+
+	for _, tc := range []struct {
+		method string
+		path   string
+	}{
+		{method: http.MethodGet, path: "/users/"},
+		{method: http.MethodGet, path: "/teams"},
+		{method: http.MethodGet, path: "/Users"},
+		{method: http.MethodGet, path: "/USERS/7/Profile"},
+		{method: http.MethodPost, path: "/users"},
+		{method: http.MethodGet, path: "/missing"},
+		{method: http.MethodGet, path: "/users"},
+	} {
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(tc.method, tc.path, nil))
+	}
+
+	want := NearMissStats{NotFound: 5, TrailingSlash: 2, Case: 2, Method: 1}
+	if got := r.NearMisses(); got != want {
+		t.Fatalf("near misses = %+v, want %+v", got, want)
+	}
+}
+
+func TestRouterNearMissStatsDisabled(t *testing.T) {
+	r := New()
+	r.Get("/users", func(w http.ResponseWriter, req *http.Request) {})
+	r.MustCompile()
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/users/", nil))
+	if got := r.NearMisses(); got != (NearMissStats{}) {
+		t.Fatalf("near misses = %+v, want zero", got)
+	}
+}
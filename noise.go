@@ -0,0 +1,89 @@
+package saruta
+
+import (
+	"net/http"
+	"strings"
+)
+
+// NoiseRule describes a noise path answered by WithNoiseShortCircuit.
+type NoiseRule struct {
+	// Path is an exact request path, or a prefix when it ends with "*".
+	Path string
+	// Handler answers matching requests. A nil Handler responds 404 with an
+	// empty body.
+	Handler http.Handler
+}
+
+type noiseFilter struct {
+	exact    map[string]http.Handler
+	prefixes []noisePrefix
+}
+
+type noisePrefix struct {
+	prefix  string
+	handler http.Handler
+}
+
+var noiseNotFound = http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+	w.WriteHeader(http.StatusNotFound)
+})
+
+var noiseNoContent = http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+	w.Header().Set("Cache-Control", "public, max-age=86400")
+	w.WriteHeader(http.StatusNoContent)
+})
+
+// DefaultNoiseRules returns the rules used by WithNoiseShortCircuit when
+// called without arguments: /favicon.ico gets a cacheable 204, and
+// /apple-touch-icon* and common scanner probes (/wp-login.php, /wp-admin*,
+// /xmlrpc.php, /.env*, /.git/*) get an empty 404.
+func DefaultNoiseRules() []NoiseRule {
+	return []NoiseRule{
+		{Path: "/favicon.ico", Handler: noiseNoContent},
+		{Path: "/apple-touch-icon*"},
+		{Path: "/wp-login.php"},
+		{Path: "/wp-admin*"},
+		{Path: "/xmlrpc.php"},
+		{Path: "/.env*"},
+		{Path: "/.git/*"},
+	}
+}
+
+// WithNoiseShortCircuit answers noise requests (favicons, scanner probes)
+// before rewrites, route matching and router middleware, keeping them out of
+// logs and metrics. Without arguments DefaultNoiseRules is used.
+//
+// Noise rules take precedence over registered routes, so do not list paths
+// the application serves itself.
+func WithNoiseShortCircuit(rules ...NoiseRule) Option {
+	return func(r *Router) {
+		if len(rules) == 0 {
+			rules = DefaultNoiseRules()
+		}
+		f := &noiseFilter{exact: make(map[string]http.Handler)}
+		for _, rule := range rules {
+			h := rule.Handler
+			if h == nil {
+				h = noiseNotFound
+			}
+			if prefix, ok := strings.CutSuffix(rule.Path, "*"); ok {
+				f.prefixes = append(f.prefixes, noisePrefix{prefix: prefix, handler: h})
+				continue
+			}
+			f.exact[rule.Path] = h
+		}
+		r.state.noise = f
+	}
+}
+
+func (f *noiseFilter) match(path string) http.Handler {
+	if h, ok := f.exact[path]; ok {
+		return h
+	}
+	for _, p := range f.prefixes {
+		if strings.HasPrefix(path, p.prefix) {
+			return p.handler
+		}
+	}
+	return nil
+}
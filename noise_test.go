@@ -0,0 +1,62 @@
+package saruta
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRouterNoiseShortCircuit(t *testing.T) {
+	r := New(WithNoiseShortCircuit(), WithNearMissStats())
+	r.Get("/{page}", func(w http.ResponseWriter, req *http.Request) {
+		_, _ = w.Write([]byte("page"))
+	})
+	r.MustCompile()
+
+	for _, tc := range []struct {
+		path string
+		code int
+	}{
+		{path: "/favicon.ico", code: http.StatusNoContent},
+		{path: "/apple-touch-icon-precomposed.png", code: http.StatusNotFound},
+		{path: "/wp-login.php", code: http.StatusNotFound},
+		{path: "/.env.production", code: http.StatusNotFound},
+		{path: "/about", code: http.StatusOK},
+	} {
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tc.path, nil))
+		if rec.Code != tc.code {
+			t.Fatalf("%s status = %d, want %d", tc.path, rec.Code, tc.code)
+		}
+		if tc.code != http.StatusOK && rec.Body.Len() != 0 {
+			t.Fatalf("%s body = %q, want empty", tc.path, rec.Body.String())
+		}
+	}
+	if got := r.NearMisses().NotFound; got != 0 {
+		t.Fatalf("noise counted as 404: %d", got)
+	}
+}
+
+func TestRouterNoiseShortCircuitCustomRules(t *testing.T) {
+	r := New(WithNoiseShortCircuit(NoiseRule{
+		Path: "/robots.txt",
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			_, _ = w.Write([]byte("User-agent: *\nDisallow: /\n"))
+		}),
+	}))
+	r.Get("/favicon.ico", func(w http.ResponseWriter, req *http.Request) {
+		_, _ = w.Write([]byte("icon"))
+	})
+	r.MustCompile()
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/robots.txt", nil))
+	if rec.Code != http.StatusOK || rec.Body.Len() == 0 {
+		t.Fatalf("robots status = %d body = %q", rec.Code, rec.Body.String())
+	}
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/favicon.ico", nil))
+	if got := rec.Body.String(); got != "icon" {
+		t.Fatalf("favicon body = %q, want route response", got)
+	}
+}
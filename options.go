@@ -0,0 +1,157 @@
+package saruta
+
+import (
+	"encoding/json"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Metadata keys used by automatic OPTIONS responses.
+const (
+	// MetaOptionsDoc marks routes whose automatic OPTIONS response carries a
+	// JSON description. It is set by OptionsDoc.
+	MetaOptionsDoc = "options_doc"
+	// MetaDoc holds a human-readable description of a route, included in
+	// OPTIONS documentation responses.
+	MetaDoc = "doc"
+	// MetaCORSMaxAge holds how long (time.Duration) preflight responses for
+	// a route may be cached. It is set by WithCORSMaxAge.
+	MetaCORSMaxAge = "cors_max_age"
+)
+
+// WithAutoOptions answers OPTIONS requests for matched paths that have no
+// explicit OPTIONS handler.
+//
+// The response is 204 with an Allow header listing the registered methods
+// plus OPTIONS. For routes registered through OptionsDoc the response is 200
+// with a JSON body describing the route instead. Allow headers on 405
+// responses also list OPTIONS.
+func WithAutoOptions() Option {
+	return func(r *Router) {
+		r.state.autoOptions = true
+	}
+}
+
+// OptionsDoc returns a derived router whose routes answer automatic OPTIONS
+// requests with a JSON description (methods, parameters with their
+// constraints, and metadata such as MetaDoc). It has no effect unless the
+// router was created with WithAutoOptions.
+func (r *Router) OptionsDoc() *Router {
+	return r.WithMeta(MetaOptionsDoc, true)
+}
+
+// WithCORSMaxAge returns a derived router whose routes let browsers and CDNs
+// cache automatic OPTIONS (preflight) responses for d.
+//
+// Automatic OPTIONS responses then carry Access-Control-Max-Age and
+// "Cache-Control: public, max-age=N", varied on the preflight request headers.
+// When routes sharing a path disagree, the shortest duration wins.
+func (r *Router) WithCORSMaxAge(d time.Duration) *Router {
+	return r.WithMeta(MetaCORSMaxAge, d)
+}
+
+// OptionsDocument is the JSON body of an OPTIONS documentation response.
+type OptionsDocument struct {
+	Pattern string                   `json:"pattern"`
+	Methods []string                 `json:"methods"`
+	Params  []OptionsParam           `json:"params,omitempty"`
+	Routes  map[string]OptionsMethod `json:"routes"`
+}
+
+// OptionsParam describes a path parameter in an OptionsDocument.
+type OptionsParam struct {
+	Name       string `json:"name"`
+	Constraint string `json:"constraint,omitempty"`
+	CatchAll   bool   `json:"catch_all,omitempty"`
+	Repeated   bool   `json:"repeated,omitempty"`
+}
+
+// OptionsMethod describes one method of a route in an OptionsDocument.
+type OptionsMethod struct {
+	Doc  string `json:"doc,omitempty"`
+	Meta Meta   `json:"meta,omitempty"`
+}
+
+func (r *Router) serveAutoOptions(w http.ResponseWriter, leaf *radixLeaf) {
+	allow := allowHeaderWithOptions(leaf.handlers)
+	w.Header().Set("Allow", allow)
+
+	documented := false
+	maxAge := time.Duration(-1)
+	for _, info := range leaf.routes {
+		if v, _ := info.Meta[MetaOptionsDoc].(bool); v {
+			documented = true
+		}
+		if d, ok := info.Meta[MetaCORSMaxAge].(time.Duration); ok && d >= 0 && (maxAge < 0 || d < maxAge) {
+			maxAge = d
+		}
+	}
+	if maxAge >= 0 {
+		secs := strconv.FormatInt(int64(maxAge/time.Second), 10)
+		h := w.Header()
+		h.Set("Access-Control-Max-Age", secs)
+		h.Set("Cache-Control", "public, max-age="+secs)
+		h.Add("Vary", "Origin")
+		h.Add("Vary", "Access-Control-Request-Method")
+		h.Add("Vary", "Access-Control-Request-Headers")
+	}
+	if !documented {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	doc := OptionsDocument{
+		Pattern: leaf.pattern,
+		Methods: strings.Split(allow, ", "),
+		Routes:  make(map[string]OptionsMethod, len(leaf.routes)),
+	}
+	if cp, err := compilePattern(leaf.pattern); err == nil {
+		doc.Params = cp.paramDocs()
+	}
+	for method, info := range leaf.routes {
+		m := OptionsMethod{}
+		for k, v := range info.Meta {
+			switch k {
+			case MetaOptionsDoc, MetaCORSMaxAge:
+			case MetaDoc:
+				m.Doc, _ = v.(string)
+			default:
+				if m.Meta == nil {
+					m.Meta = make(Meta)
+				}
+				m.Meta[k] = v
+			}
+		}
+		doc.Routes[method] = m
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(doc)
+}
+
+func allowHeaderWithOptions(handlers *methodTable) string {
+	methods := handlers.methods()
+	if _, ok := handlers.get(http.MethodOptions); !ok {
+		methods = append(methods, http.MethodOptions)
+		slices.Sort(methods)
+	}
+	return strings.Join(methods, ", ")
+}
+
+func (cp compiledPattern) paramDocs() []OptionsParam {
+	var params []OptionsParam
+	for _, seg := range cp.segments {
+		switch seg.kind {
+		case segmentCatchAll:
+			params = append(params, OptionsParam{Name: seg.name, Constraint: seg.expr, CatchAll: !seg.repeated, Repeated: seg.repeated})
+		case segmentParam:
+			for _, p := range seg.tmpl.params {
+				params = append(params, OptionsParam{Name: p.name, Constraint: p.expr})
+			}
+		}
+	}
+	return params
+}
@@ -0,0 +1,148 @@
+package saruta
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestRouterAutoOptions(t *testing.T) {
+	r := New(WithAutoOptions())
+	r.Get("/users", func(w http.ResponseWriter, req *http.Request) {})
+	r.Post("/users", func(w http.ResponseWriter, req *http.Request) {})
+	r.Options("/custom", func(w http.ResponseWriter, req *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	})
+	r.MustCompile()
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodOptions, "/users", nil))
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("OPTIONS status = %d, want %d", rec.Code, http.StatusNoContent)
+	}
+	if got := rec.Header().Get("Allow"); got != "GET, OPTIONS, POST" {
+		t.Fatalf("Allow = %q", got)
+	}
+	if rec.Body.Len() != 0 {
+		t.Fatalf("body = %q, want empty", rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodDelete, "/users", nil))
+	if rec.Code != http.StatusMethodNotAllowed || rec.Header().Get("Allow") != "GET, OPTIONS, POST" {
+		t.Fatalf("DELETE status = %d Allow = %q", rec.Code, rec.Header().Get("Allow"))
+	}
+
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodOptions, "/custom", nil))
+	if rec.Code != http.StatusTeapot {
+		t.Fatalf("explicit OPTIONS status = %d, want %d", rec.Code, http.StatusTeapot)
+	}
+
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodOptions, "/missing", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("missing OPTIONS status = %d, want %d", rec.Code, http.StatusNotFound)
+	}
+}
+
+func TestRouterOptionsWithoutAutoOptions(t *testing.T) {
+	r := New()
+	r.Get("/users", func(w http.ResponseWriter, req *http.Request) {})
+	r.MustCompile()
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodOptions, "/users", nil))
+	if rec.Code != http.StatusMethodNotAllowed || rec.Header().Get("Allow") != "GET" {
+		t.Fatalf("OPTIONS status = %d Allow = %q", rec.Code, rec.Header().Get("Allow"))
+	}
+}
+
+func TestRouterOptionsDoc(t *testing.T) {
+	r := New(WithAutoOptions())
+	api := r.OptionsDoc()
+	api.WithMeta(MetaDoc, "Show an image").WithMeta(MetaOwner, "media").
+		Get("/images/{id:[0-9]+}.{ext:[a-z]+}", func(w http.ResponseWriter, req *http.Request) {})
+	api.Get("/files/{path...}", func(w http.ResponseWriter, req *http.Request) {})
+	r.Get("/plain", func(w http.ResponseWriter, req *http.Request) {})
+	r.MustCompile()
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodOptions, "/images/1.png", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("OPTIONS status = %d, want %d", rec.Code, http.StatusOK)
+	}
+	if got := rec.Header().Get("Content-Type"); got != "application/json" {
+		t.Fatalf("Content-Type = %q", got)
+	}
+	var doc OptionsDocument
+	if err := json.Unmarshal(rec.Body.Bytes(), &doc); err != nil {
+		t.Fatal(err)
+	}
+	if doc.Pattern != "/images/{id:[0-9]+}.{ext:[a-z]+}" {
+		t.Fatalf("Pattern = %q", doc.Pattern)
+	}
+	if len(doc.Methods) != 2 || doc.Methods[0] != "GET" || doc.Methods[1] != "OPTIONS" {
+		t.Fatalf("Methods = %v", doc.Methods)
+	}
+	if len(doc.Params) != 2 || doc.Params[0] != (OptionsParam{Name: "id", Constraint: "[0-9]+"}) || doc.Params[1] != (OptionsParam{Name: "ext", Constraint: "[a-z]+"}) {
+		t.Fatalf("Params = %+v", doc.Params)
+	}
+	get := doc.Routes["GET"]
+	if get.Doc != "Show an image" || get.Meta[MetaOwner] != "media" {
+		t.Fatalf("GET = %+v", get)
+	}
+	if _, ok := get.Meta[MetaOptionsDoc]; ok {
+		t.Fatalf("GET meta leaks %s", MetaOptionsDoc)
+	}
+
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodOptions, "/files/a/b", nil))
+	doc = OptionsDocument{}
+	if err := json.Unmarshal(rec.Body.Bytes(), &doc); err != nil {
+		t.Fatal(err)
+	}
+	if len(doc.Params) != 1 || doc.Params[0] != (OptionsParam{Name: "path", CatchAll: true}) {
+		t.Fatalf("Params = %+v", doc.Params)
+	}
+
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodOptions, "/plain", nil))
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("undocumented OPTIONS status = %d, want %d", rec.Code, http.StatusNoContent)
+	}
+}
+
+func TestRouterAutoOptionsCORSMaxAge(t *testing.T) {
+	r := New(WithAutoOptions())
+	r.WithCORSMaxAge(10*time.Minute).Get("/items", func(w http.ResponseWriter, req *http.Request) {})
+	r.WithCORSMaxAge(time.Minute).Post("/items", func(w http.ResponseWriter, req *http.Request) {})
+	r.Get("/plain", func(w http.ResponseWriter, req *http.Request) {})
+	r.MustCompile()
+
+	req := httptest.NewRequest(http.MethodOptions, "/items", nil)
+	req.Header.Set("Origin", "https://example.com")
+	req.Header.Set("Access-Control-Request-Method", "POST")
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusNoContent)
+	}
+	if got := rec.Header().Get("Access-Control-Max-Age"); got != "60" {
+		t.Fatalf("Access-Control-Max-Age = %q, want 60", got)
+	}
+	if got := rec.Header().Get("Cache-Control"); got != "public, max-age=60" {
+		t.Fatalf("Cache-Control = %q", got)
+	}
+	if got := rec.Header().Values("Vary"); len(got) != 3 || got[0] != "Origin" {
+		t.Fatalf("Vary = %v", got)
+	}
+
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodOptions, "/plain", nil))
+	if rec.Header().Get("Access-Control-Max-Age") != "" || rec.Header().Get("Cache-Control") != "" {
+		t.Fatalf("plain route got caching headers: %v", rec.Header())
+	}
+}
@@ -0,0 +1,81 @@
+package saruta
+
+import (
+	"runtime"
+	"sync"
+)
+
+// parallelCompileMin is the number of routes from which Compile spreads
+// pattern compilation and tree construction over GOMAXPROCS goroutines.
+// Below it, starting goroutines costs more than it saves.
+const parallelCompileMin = 4096
+
+// parallelSubtreeMin is the smallest subtree, in routes, that buildRadix
+// hands to another goroutine.
+const parallelSubtreeMin = 256
+
+// compileWorkers returns the number of goroutines to use for n routes.
+func compileWorkers(n int) int {
+	if n < parallelCompileMin {
+		return 1
+	}
+	return max(1, min(runtime.GOMAXPROCS(0), n/parallelSubtreeMin))
+}
+
+// compilePatterns compiles the pattern of every route. errs[i] is the error
+// of routes[i], so that Compile still reports the first invalid route in
+// registration order.
+func compilePatterns(routes []registeredRoute) (cps []compiledPattern, errs []error) {
+	cps = make([]compiledPattern, len(routes))
+	errs = make([]error, len(routes))
+	workers := compileWorkers(len(routes))
+	if workers == 1 {
+		for i, rt := range routes {
+			cps[i], errs[i] = compilePattern(rt.pattern)
+		}
+		return cps, errs
+	}
+	chunk := (len(routes) + workers - 1) / workers
+	var wg sync.WaitGroup
+	for lo := 0; lo < len(routes); lo += chunk {
+		hi := min(lo+chunk, len(routes))
+		wg.Go(func() {
+			for i := lo; i < hi; i++ {
+				cps[i], errs[i] = compilePattern(routes[i].pattern)
+			}
+		})
+	}
+	wg.Wait()
+	return cps, errs
+}
+
+// radixBuilder bounds the goroutines buildRadixNode starts for large
+// subtrees. A nil *radixBuilder builds everything on the calling goroutine.
+type radixBuilder struct {
+	tokens chan struct{}
+}
+
+func newRadixBuilder(root *node) *radixBuilder {
+	workers := compileWorkers(root.size)
+	if workers == 1 {
+		return nil
+	}
+	return &radixBuilder{tokens: make(chan struct{}, workers-1)}
+}
+
+// build sets *dst to the radix form of src, on another goroutine tracked by
+// wg when src is large and a worker is free.
+func (b *radixBuilder) build(wg *sync.WaitGroup, src *node, dst **radixBuildNode) {
+	if b != nil && src.size >= parallelSubtreeMin {
+		select {
+		case b.tokens <- struct{}{}:
+			wg.Go(func() {
+				*dst = buildRadixNode(src, b)
+				<-b.tokens
+			})
+			return
+		default:
+		}
+	}
+	*dst = buildRadixNode(src, b)
+}
@@ -0,0 +1,72 @@
+package saruta
+
+import (
+	"fmt"
+	"net/http"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestParallelCompileMatchesSerial(t *testing.T) {
+	build := func(procs int) (*Router, string) {
+		defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(procs))
+		r := New()
+		h := func(w http.ResponseWriter, req *http.Request) {}
+		for i := range parallelCompileMin + 500 {
+			switch i % 3 {
+			case 0:
+				r.Get(fmt.Sprintf("/catalog/%d/items/{id}/v-%d", i%40, i), h)
+			case 1:
+				r.Post(fmt.Sprintf("/tenants/{tenant}/docs-%d/{rest...}", i), h)
+			default:
+				r.Get(fmt.Sprintf("/s/%d/%d", i%7, i), h)
+			}
+		}
+		r.MustCompile()
+		var b strings.Builder
+		if err := r.DumpTree(&b); err != nil {
+			t.Fatal(err)
+		}
+		return r, b.String()
+	}
+	_, serial := build(1)
+	r, parallel := build(4)
+	if serial != parallel {
+		t.Fatal("parallel compile built a different tree than the serial one")
+	}
+	for path, want := range map[string]string{
+		"/catalog/3/items/9/v-3": "/catalog/3/items/{id}/v-3",
+		"/tenants/acme/docs-4/a": "/tenants/{tenant}/docs-4/{rest...}",
+		"/s/5/5":                 "/s/5/5",
+	} {
+		method := http.MethodGet
+		if strings.HasPrefix(path, "/tenants") {
+			method = http.MethodPost
+		}
+		route, _, ok := r.Match(method, path)
+		if !ok || route.Pattern != want {
+			t.Errorf("Match(%s) = %q, %v", path, route.Pattern, ok)
+		}
+	}
+}
+
+func TestParallelCompileReportsFirstError(t *testing.T) {
+	defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(4))
+	r := New()
+	h := func(w http.ResponseWriter, req *http.Request) {}
+	for i := range parallelCompileMin * 2 {
+		switch i {
+		case 1000:
+			r.Get(fmt.Sprintf("/bad/%d/{", i), h)
+		case 7000:
+			r.Get("/worse/{}", h)
+		default:
+			r.Get(fmt.Sprintf("/ok/%d", i), h)
+		}
+	}
+	err := r.Compile()
+	if err == nil || !strings.Contains(err.Error(), "/bad/1000/{") {
+		t.Fatalf("Compile() = %v, want the error of /bad/1000/{", err)
+	}
+}
@@ -0,0 +1,162 @@
+package saruta
+
+import (
+	"errors"
+	"fmt"
+	"iter"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// PathValues returns the segments captured by a repeated parameter ({name+})
+// or a catch-all ({name...}): for "/tags/{tag+}" and "/tags/a/b/c" it returns
+// ["a" "b" "c"]. It returns nil when the value is empty.
+func PathValues(req *http.Request, name string) []string {
+	v := Param(req, name)
+	if v == "" {
+		return nil
+	}
+	return strings.Split(v, "/")
+}
+
+// Params yields the name and value of every path parameter of the matched
+// route, in pattern order, for code that does not know the names in advance
+// (generic logging, auditing, binding):
+//
+//	for name, value := range saruta.Params(req) {
+//		attrs = append(attrs, slog.String(name, value))
+//	}
+//
+// The names come from req.Pattern, so Params yields nothing before routing
+// or for requests that did not match a route.
+func Params(req *http.Request) iter.Seq2[string, string] {
+	return func(yield func(string, string) bool) {
+		pattern := req.Pattern
+		for {
+			i := strings.IndexByte(pattern, '{')
+			if i < 0 {
+				return
+			}
+			j := strings.IndexByte(pattern[i:], '}')
+			if j < 0 {
+				return
+			}
+			name, _, _ := strings.Cut(pattern[i+1:i+j], ":")
+			name = strings.TrimSuffix(strings.TrimSuffix(name, "..."), "+")
+			pattern = pattern[i+j+1:]
+			if !yield(name, Param(req, name)) {
+				return
+			}
+		}
+	}
+}
+
+// ErrMissingParam is wrapped by ParamError when the request has no value for
+// the parameter.
+var ErrMissingParam = errors.New("missing path parameter")
+
+// ParamError reports a path value that could not be parsed by ParamInt and
+// friends. Its message is safe to return to clients in a 400 response:
+//
+//	id, err := saruta.ParamInt(req, "id")
+//	if err != nil {
+//		http.Error(w, err.Error(), http.StatusBadRequest)
+//		return
+//	}
+type ParamError struct {
+	Name  string
+	Value string
+	Err   error
+}
+
+func (e *ParamError) Error() string {
+	if errors.Is(e.Err, ErrMissingParam) {
+		return fmt.Sprintf("path parameter %q is missing", e.Name)
+	}
+	return fmt.Sprintf("path parameter %q: invalid value %q", e.Name, e.Value)
+}
+
+func (e *ParamError) Unwrap() error {
+	return e.Err
+}
+
+func pathValue(req *http.Request, name string) (string, error) {
+	v := Param(req, name)
+	if v == "" {
+		return "", &ParamError{Name: name, Err: ErrMissingParam}
+	}
+	return v, nil
+}
+
+// ParamInt parses the path value name as a base-10 int.
+func ParamInt(req *http.Request, name string) (int, error) {
+	v, err := pathValue(req, name)
+	if err != nil {
+		return 0, err
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		return 0, &ParamError{Name: name, Value: v, Err: err}
+	}
+	return n, nil
+}
+
+// ParamInt64 parses the path value name as a base-10 int64.
+func ParamInt64(req *http.Request, name string) (int64, error) {
+	v, err := pathValue(req, name)
+	if err != nil {
+		return 0, err
+	}
+	n, err := strconv.ParseInt(v, 10, 64)
+	if err != nil {
+		return 0, &ParamError{Name: name, Value: v, Err: err}
+	}
+	return n, nil
+}
+
+// ParamBool parses the path value name with strconv.ParseBool.
+func ParamBool(req *http.Request, name string) (bool, error) {
+	v, err := pathValue(req, name)
+	if err != nil {
+		return false, err
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		return false, &ParamError{Name: name, Value: v, Err: err}
+	}
+	return b, nil
+}
+
+// ParamUUID validates the path value name as a UUID in the canonical
+// 8-4-4-4-12 hex form and returns it lowercased.
+func ParamUUID(req *http.Request, name string) (string, error) {
+	v, err := pathValue(req, name)
+	if err != nil {
+		return "", err
+	}
+	if !isUUID(v) {
+		return "", &ParamError{Name: name, Value: v, Err: errors.New("not a UUID")}
+	}
+	return strings.ToLower(v), nil
+}
+
+func isUUID(s string) bool {
+	if len(s) != 36 {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch i {
+		case 8, 13, 18, 23:
+			if c != '-' {
+				return false
+			}
+		default:
+			if !('0' <= c && c <= '9' || 'a' <= c && c <= 'f' || 'A' <= c && c <= 'F') {
+				return false
+			}
+		}
+	}
+	return true
+}
@@ -0,0 +1,141 @@
+package saruta
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestRouterRepeatedParam(t *testing.T) {
+	var got []string
+	r := New()
+	r.Get("/tags/{tag+:[a-z]+}", func(w http.ResponseWriter, req *http.Request) {
+		got = PathValues(req, "tag")
+	})
+	r.Get("/search/{facet+}", func(w http.ResponseWriter, req *http.Request) {
+		got = PathValues(req, "facet")
+	})
+	r.Get("/search/{facet}", func(w http.ResponseWriter, req *http.Request) {
+		got = []string{"single:" + req.PathValue("facet")}
+	})
+	r.MustCompile()
+
+	for _, tc := range []struct {
+		path string
+		code int
+		want []string
+	}{
+		{path: "/tags/a", code: http.StatusOK, want: []string{"a"}},
+		{path: "/tags/a/b/c", code: http.StatusOK, want: []string{"a", "b", "c"}},
+		{path: "/tags/a/B", code: http.StatusNotFound},
+		{path: "/tags/a//b", code: http.StatusNotFound},
+		{path: "/tags/", code: http.StatusNotFound},
+		{path: "/search/x", code: http.StatusOK, want: []string{"single:x"}},
+		{path: "/search/x/y", code: http.StatusOK, want: []string{"x", "y"}},
+	} {
+		got = nil
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tc.path, nil))
+		if rec.Code != tc.code {
+			t.Fatalf("GET %s status = %d, want %d", tc.path, rec.Code, tc.code)
+		}
+		if tc.code == http.StatusOK && !slices.Equal(got, tc.want) {
+			t.Fatalf("GET %s values = %q, want %q", tc.path, got, tc.want)
+		}
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	if v := PathValues(req, "missing"); v != nil {
+		t.Fatalf("PathValues(missing) = %q, want nil", v)
+	}
+}
+
+func TestRouterRepeatedParamConflict(t *testing.T) {
+	noop := func(w http.ResponseWriter, req *http.Request) {}
+	r := New()
+	r.Get("/tags/{tag+}", noop)
+	r.Get("/tags/{tag...}", noop)
+	err := r.Compile()
+	if err == nil || !strings.Contains(err.Error(), "{tag+}") {
+		t.Fatalf("Compile error = %v, want conflict naming {tag+}", err)
+	}
+}
+
+func TestParamAccessors(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.SetPathValue("id", "42")
+	req.SetPathValue("big", "9000000000")
+	req.SetPathValue("flag", "true")
+	req.SetPathValue("uuid", "123E4567-e89b-12d3-a456-426614174000")
+	req.SetPathValue("bad", "x1")
+
+	if n, err := ParamInt(req, "id"); err != nil || n != 42 {
+		t.Fatalf("ParamInt = %d, %v", n, err)
+	}
+	if n, err := ParamInt64(req, "big"); err != nil || n != 9000000000 {
+		t.Fatalf("ParamInt64 = %d, %v", n, err)
+	}
+	if b, err := ParamBool(req, "flag"); err != nil || !b {
+		t.Fatalf("ParamBool = %v, %v", b, err)
+	}
+	if u, err := ParamUUID(req, "uuid"); err != nil || u != "123e4567-e89b-12d3-a456-426614174000" {
+		t.Fatalf("ParamUUID = %q, %v", u, err)
+	}
+
+	_, err := ParamInt(req, "bad")
+	var pe *ParamError
+	if !errors.As(err, &pe) || pe.Name != "bad" || pe.Value != "x1" {
+		t.Fatalf("ParamInt(bad) err = %v", err)
+	}
+	if err.Error() != `path parameter "bad": invalid value "x1"` {
+		t.Fatalf("message = %q", err.Error())
+	}
+	if _, err := ParamUUID(req, "id"); err == nil {
+		t.Fatalf("ParamUUID(id) should fail")
+	}
+	if _, err := ParamBool(req, "bad"); err == nil {
+		t.Fatalf("ParamBool(bad) should fail")
+	}
+
+	_, err = ParamInt64(req, "missing")
+	if !errors.Is(err, ErrMissingParam) || err.Error() != `path parameter "missing" is missing` {
+		t.Fatalf("missing err = %v", err)
+	}
+}
+
+func TestParams(t *testing.T) {
+	type kv struct{ name, value string }
+	var got []kv
+	r := New()
+	r.Get("/orgs/{org}/repos/{repo:[a-z0-9-]+}/v{major}.{minor}/{path...}", func(w http.ResponseWriter, req *http.Request) {
+		for name, value := range Params(req) {
+			got = append(got, kv{name, value})
+		}
+	})
+	r.Get("/tags/{tag+}", func(w http.ResponseWriter, req *http.Request) {
+		for name, value := range Params(req) {
+			got = append(got, kv{name, value})
+			break
+		}
+	})
+	r.MustCompile()
+
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/orgs/acme/repos/web-app/v1.2/docs/a.md", nil))
+	want := []kv{{"org", "acme"}, {"repo", "web-app"}, {"major", "1"}, {"minor", "2"}, {"path", "docs/a.md"}}
+	if !slices.Equal(got, want) {
+		t.Fatalf("Params = %v, want %v", got, want)
+	}
+
+	got = nil
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/tags/a/b", nil))
+	if want := []kv{{"tag", "a/b"}}; !slices.Equal(got, want) {
+		t.Fatalf("Params = %v, want %v", got, want)
+	}
+
+	for name := range Params(httptest.NewRequest(http.MethodGet, "/", nil)) {
+		t.Fatalf("unrouted request yielded %q", name)
+	}
+}
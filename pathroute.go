@@ -0,0 +1,66 @@
+package saruta
+
+import "net/http"
+
+// PathRoute declares several methods for one pattern. It is created with
+// Router.Path.
+type PathRoute struct {
+	router  *Router
+	pattern string
+}
+
+// Path returns a builder that registers methods for pattern on r:
+//
+//	r.Path("/items/{id}").Get(show).Put(update).Delete(remove)
+//
+// Each call is a regular registration, so validation and conflict checks run
+// in Compile as usual.
+func (r *Router) Path(pattern string) *PathRoute {
+	return &PathRoute{router: r, pattern: pattern}
+}
+
+// Handle registers h for method.
+func (p *PathRoute) Handle(method string, h http.Handler) *PathRoute {
+	p.router.Handle(method, p.pattern, h)
+	return p
+}
+
+// HandleFunc registers h for method.
+func (p *PathRoute) HandleFunc(method string, h http.HandlerFunc) *PathRoute {
+	return p.Handle(method, h)
+}
+
+// Get registers a GET handler.
+func (p *PathRoute) Get(h http.HandlerFunc) *PathRoute {
+	return p.HandleFunc(http.MethodGet, h)
+}
+
+// Post registers a POST handler.
+func (p *PathRoute) Post(h http.HandlerFunc) *PathRoute {
+	return p.HandleFunc(http.MethodPost, h)
+}
+
+// Put registers a PUT handler.
+func (p *PathRoute) Put(h http.HandlerFunc) *PathRoute {
+	return p.HandleFunc(http.MethodPut, h)
+}
+
+// Patch registers a PATCH handler.
+func (p *PathRoute) Patch(h http.HandlerFunc) *PathRoute {
+	return p.HandleFunc(http.MethodPatch, h)
+}
+
+// Delete registers a DELETE handler.
+func (p *PathRoute) Delete(h http.HandlerFunc) *PathRoute {
+	return p.HandleFunc(http.MethodDelete, h)
+}
+
+// Head registers a HEAD handler.
+func (p *PathRoute) Head(h http.HandlerFunc) *PathRoute {
+	return p.HandleFunc(http.MethodHead, h)
+}
+
+// Options registers an OPTIONS handler.
+func (p *PathRoute) Options(h http.HandlerFunc) *PathRoute {
+	return p.HandleFunc(http.MethodOptions, h)
+}
@@ -0,0 +1,36 @@
+package saruta
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRouterPathBuilder(t *testing.T) {
+	method := func(w http.ResponseWriter, req *http.Request) {
+		_, _ = w.Write([]byte(req.Method + ":" + req.PathValue("id")))
+	}
+	r := New()
+	r.Path("/items/{id}").Get(method).Put(method).Delete(method)
+	r.MustCompile()
+
+	for _, m := range []string{http.MethodGet, http.MethodPut, http.MethodDelete} {
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, httptest.NewRequest(m, "/items/5", nil))
+		if got, want := rec.Body.String(), m+":5"; got != want {
+			t.Fatalf("%s body = %q, want %q", m, got, want)
+		}
+	}
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/items/5", nil))
+	if got, want := rec.Header().Get("Allow"), "DELETE, GET, PUT"; got != want {
+		t.Fatalf("Allow = %q, want %q", got, want)
+	}
+
+	r = New()
+	r.Path("/items/{id}").Get(method).Get(method)
+	if err := r.Compile(); err == nil {
+		t.Fatalf("expected duplicate route error")
+	}
+}
@@ -22,14 +22,15 @@ const (
 )
 
 type segment struct {
-	kind    segmentKind
-	literal string
-	name    string
-	expr    string
-	prefix  string
-	suffix  string
-	matcher segmentMatcher
-	tmpl    *segmentTemplate
+	kind     segmentKind
+	literal  string
+	name     string
+	expr     string
+	prefix   string
+	suffix   string
+	matcher  segmentMatcher
+	tmpl     *segmentTemplate
+	repeated bool // catch-all collecting one or more segments ({name+})
 }
 
 type segmentTemplate struct {
@@ -87,6 +88,21 @@ func compilePattern(pattern string) (compiledPattern, error) {
 	return compiledPattern{segments: segments}, nil
 }
 
+func (cp compiledPattern) paramNames() []string {
+	var names []string
+	for _, seg := range cp.segments {
+		switch seg.kind {
+		case segmentCatchAll:
+			names = append(names, seg.name)
+		case segmentParam:
+			for _, p := range seg.tmpl.params {
+				names = append(names, p.name)
+			}
+		}
+	}
+	return names
+}
+
 func parseSegment(raw string) (segment, error) {
 	if raw == "" {
 		return segment{kind: segmentStatic, literal: ""}, nil
@@ -116,7 +132,7 @@ func parseSegment(raw string) (segment, error) {
 			if body == "" {
 				return segment{}, fmt.Errorf("empty parameter name")
 			}
-			if strings.HasSuffix(body, "...") {
+			if strings.HasSuffix(body, "...") || isRepeatedParam(body) {
 				if len(params) > 0 || i != 0 || j != len(raw)-1 {
 					return segment{}, fmt.Errorf("catch-all cannot have static prefix/suffix in segment")
 				}
@@ -156,6 +172,27 @@ func parseSegment(raw string) (segment, error) {
 }
 
 func parseParamBody(body, prefix, suffix string) (segment, error) {
+	if isRepeatedParam(body) {
+		if prefix != "" || suffix != "" {
+			return segment{}, fmt.Errorf("repeated parameter cannot have static prefix/suffix in segment")
+		}
+		name, expr, hasExpr := strings.Cut(body, ":")
+		param := strings.TrimSuffix(name, "+")
+		if hasExpr {
+			param += ":" + expr
+		}
+		p, err := parseSegmentParam(param)
+		if err != nil {
+			return segment{}, err
+		}
+		return segment{
+			kind:     segmentCatchAll,
+			name:     p.name,
+			expr:     p.expr,
+			matcher:  &repeatedMatcher{each: p.matcher},
+			repeated: true,
+		}, nil
+	}
 	if strings.HasSuffix(body, "...") {
 		if prefix != "" || suffix != "" {
 			return segment{}, fmt.Errorf("catch-all cannot have static prefix/suffix in segment")
@@ -259,6 +296,26 @@ func compileSegmentExpr(expr string) (segmentMatcher, error) {
 	return newByteClassMatcher(classBytes, minLen), nil
 }
 
+// matcherInterner shares equal byte-class matchers between the edges of a
+// compiled tree. Each constraint compiles to its own 256-entry table; with
+// interning, [0-9]+ on a thousand routes (or spelled \d+ on some of them)
+// is stored once.
+type matcherInterner map[byteClassMatcher]*byteClassMatcher
+
+func (mi matcherInterner) intern(m segmentMatcher) segmentMatcher {
+	switch m := m.(type) {
+	case *byteClassMatcher:
+		if shared, ok := mi[*m]; ok {
+			return shared
+		}
+		mi[*m] = m
+		return m
+	case *repeatedMatcher:
+		m.each = mi.intern(m.each)
+	}
+	return m
+}
+
 func newByteClassMatcher(chars []byte, minLen int) *byteClassMatcher {
 	m := &byteClassMatcher{minLen: minLen}
 	for _, c := range chars {
@@ -335,3 +392,32 @@ func splitPathSegments(path string) []string {
 	}
 	return strings.Split(path[1:], "/")
 }
+
+// isRepeatedParam reports whether a parameter body declares a repeated
+// parameter: {name+} or {name+:expr}.
+func isRepeatedParam(body string) bool {
+	name, _, _ := strings.Cut(body, ":")
+	return strings.HasSuffix(name, "+")
+}
+
+// repeatedMatcher matches the rest of a path made of one or more non-empty
+// segments, each accepted by each (any segment when each is nil).
+type repeatedMatcher struct {
+	each segmentMatcher
+}
+
+func (m *repeatedMatcher) Match(rest string) bool {
+	if rest == "" {
+		return false
+	}
+	for {
+		seg, tail, more := strings.Cut(rest, "/")
+		if seg == "" || (m.each != nil && !m.each.Match(seg)) {
+			return false
+		}
+		if !more {
+			return true
+		}
+		rest = tail
+	}
+}
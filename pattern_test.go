@@ -15,6 +15,8 @@ func TestCompilePatternValid(t *testing.T) {
 		{pattern: `/image/{id:[a-z0-9]+}.{ext:[a-z]+}`, kinds: []segmentKind{segmentStatic, segmentParam}},
 		{pattern: `/assets/pre-{id:[0-9]+}-v1`, kinds: []segmentKind{segmentStatic, segmentParam}},
 		{pattern: "/files/{path...}", kinds: []segmentKind{segmentStatic, segmentCatchAll}},
+		{pattern: "/tags/{tag+}", kinds: []segmentKind{segmentStatic, segmentCatchAll}},
+		{pattern: "/tags/{tag+:[a-z]+}", kinds: []segmentKind{segmentStatic, segmentCatchAll}},
 		{pattern: "/users/", kinds: []segmentKind{segmentStatic, segmentStatic}},
 	}
 	for _, tc := range tests {
@@ -48,6 +50,11 @@ func TestCompilePatternInvalid(t *testing.T) {
 		"/api/{id:[0-9]+}{x}",
 		"/image/{id:[a-z0-9]+}{ext:[a-z]+}",
 		"/api/x{id...}.json",
+		"/tags/{tag+}/x",
+		"/tags/x{tag+}",
+		"/tags/{+}",
+		"/tags/{tag+:}",
+		"/tags/{tag+:[a-z}",
 	}
 	for _, pattern := range tests {
 		if _, err := compilePattern(pattern); err == nil {
@@ -0,0 +1,95 @@
+package saruta
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Protocol is a set of HTTP protocol versions.
+type Protocol uint8
+
+// Protocol versions, combined with | in ProtocolPolicy.Allowed.
+const (
+	ProtoHTTP1 Protocol = 1 << iota
+	ProtoHTTP2
+	ProtoHTTP3
+)
+
+func (p Protocol) String() string {
+	var names []string
+	for _, v := range []struct {
+		p    Protocol
+		name string
+	}{{ProtoHTTP1, "h1"}, {ProtoHTTP2, "h2"}, {ProtoHTTP3, "h3"}} {
+		if p&v.p != 0 {
+			names = append(names, v.name)
+		}
+	}
+	return strings.Join(names, ",")
+}
+
+func requestProtocol(req *http.Request) Protocol {
+	switch req.ProtoMajor {
+	case 2:
+		return ProtoHTTP2
+	case 3:
+		return ProtoHTTP3
+	default:
+		return ProtoHTTP1
+	}
+}
+
+// ProtocolPolicy restricts how a route may be reached. See WithProtocols.
+type ProtocolPolicy struct {
+	// Allowed lists the accepted protocol versions; zero accepts any.
+	Allowed Protocol
+	// TLS rejects requests that did not arrive over TLS (req.TLS is nil).
+	TLS bool
+	// Status is the response status for rejected requests. Zero means 505 for
+	// a protocol version outside Allowed and 403 for a request without TLS.
+	Status int
+}
+
+// MetaProtocols holds the ProtocolPolicy set by WithProtocols.
+const MetaProtocols = "protocols"
+
+// WithProtocols returns a derived router whose routes reject requests that
+// violate p with a problem+json response, before router middleware and the
+// handler run:
+//
+//	admin := r.WithProtocols(saruta.ProtocolPolicy{TLS: true})
+//	hooks := r.WithProtocols(saruta.ProtocolPolicy{Allowed: saruta.ProtoHTTP2 | saruta.ProtoHTTP3})
+//
+// The TLS check relies on req.TLS. Behind a TLS-terminating proxy every
+// request looks like plain HTTP, so enforce TLS at the proxy instead.
+func (r *Router) WithProtocols(p ProtocolPolicy) *Router {
+	return r.WithMeta(MetaProtocols, p)
+}
+
+// RequireTLS is shorthand for WithProtocols(ProtocolPolicy{TLS: true}).
+func (r *Router) RequireTLS() *Router {
+	return r.WithProtocols(ProtocolPolicy{TLS: true})
+}
+
+func enforceProtocols(p ProtocolPolicy, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if p.TLS && req.TLS == nil {
+			writeProblem(w, policyStatus(p, http.StatusForbidden), "this endpoint requires TLS")
+			return
+		}
+		if proto := requestProtocol(req); p.Allowed != 0 && p.Allowed&proto == 0 {
+			writeProblem(w, policyStatus(p, http.StatusHTTPVersionNotSupported),
+				fmt.Sprintf("protocol %s is not accepted; use %s", proto, p.Allowed))
+			return
+		}
+		next.ServeHTTP(w, req)
+	})
+}
+
+func policyStatus(p ProtocolPolicy, def int) int {
+	if p.Status != 0 {
+		return p.Status
+	}
+	return def
+}
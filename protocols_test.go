@@ -0,0 +1,78 @@
+package saruta
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRouterWithProtocols(t *testing.T) {
+	ok := func(w http.ResponseWriter, req *http.Request) { w.Write([]byte("ok")) }
+	r := New()
+	r.RequireTLS().Get("/admin", ok)
+	r.WithProtocols(ProtocolPolicy{Allowed: ProtoHTTP2 | ProtoHTTP3}).Get("/hooks", ok)
+	r.WithProtocols(ProtocolPolicy{TLS: true, Allowed: ProtoHTTP2, Status: http.StatusNotFound}).Get("/hidden", ok)
+	r.Get("/open", ok)
+	r.MustCompile()
+
+	for _, tc := range []struct {
+		path  string
+		major int
+		tls   bool
+		code  int
+	}{
+		{path: "/admin", major: 1, code: http.StatusForbidden},
+		{path: "/admin", major: 1, tls: true, code: http.StatusOK},
+		{path: "/hooks", major: 1, tls: true, code: http.StatusHTTPVersionNotSupported},
+		{path: "/hooks", major: 2, code: http.StatusOK},
+		{path: "/hooks", major: 3, code: http.StatusOK},
+		{path: "/hidden", major: 2, code: http.StatusNotFound},
+		{path: "/hidden", major: 1, tls: true, code: http.StatusNotFound},
+		{path: "/hidden", major: 2, tls: true, code: http.StatusOK},
+		{path: "/open", major: 1, code: http.StatusOK},
+	} {
+		req := httptest.NewRequest(http.MethodGet, tc.path, nil)
+		req.ProtoMajor = tc.major
+		if !tc.tls {
+			req.TLS = nil
+		} else {
+			req.TLS = &tls.ConnectionState{}
+		}
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+		if rec.Code != tc.code {
+			t.Fatalf("%s (HTTP/%d, tls=%v): status=%d, want %d", tc.path, tc.major, tc.tls, rec.Code, tc.code)
+		}
+		if tc.code != http.StatusOK && rec.Header().Get("Content-Type") != "application/problem+json" {
+			t.Fatalf("%s: Content-Type=%q", tc.path, rec.Header().Get("Content-Type"))
+		}
+	}
+}
+
+func TestProtocolString(t *testing.T) {
+	if got := (ProtoHTTP1 | ProtoHTTP3).String(); got != "h1,h3" {
+		t.Fatalf("String() = %q", got)
+	}
+}
+
+func TestRouterWithProtocolsBeforeMiddleware(t *testing.T) {
+	ran := false
+	r := New()
+	r.Use(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			ran = true
+			next.ServeHTTP(w, req)
+		})
+	})
+	r.RequireTLS().Get("/admin", func(w http.ResponseWriter, req *http.Request) {})
+	r.MustCompile()
+
+	req := httptest.NewRequest(http.MethodGet, "/admin", nil)
+	req.TLS = nil
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	if rec.Code != http.StatusForbidden || ran {
+		t.Fatalf("status = %d, middleware ran = %v, want 403 without middleware", rec.Code, ran)
+	}
+}
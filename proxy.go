@@ -0,0 +1,187 @@
+package saruta
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// RewriteRule configures how Proxy forwards requests upstream.
+type RewriteRule struct {
+	// Path is the upstream path, appended to the target's path. Placeholders
+	// ({id} or {rest...}) are replaced with the values captured by the
+	// pattern, escaped as in the request; requests whose values hold a "."
+	// or ".." segment are answered with 400. Empty forwards the request path
+	// unchanged.
+	Path string
+	// PreserveHost sends the incoming Host header instead of the target's.
+	PreserveHost bool
+	// Forwarded sets X-Forwarded-For, X-Forwarded-Host and
+	// X-Forwarded-Proto on the upstream request. Without it, incoming
+	// X-Forwarded-* headers are dropped.
+	Forwarded bool
+	// SetHeaders are set on the upstream request, replacing incoming values.
+	SetHeaders map[string]string
+	// RemoveHeaders are deleted from the upstream request.
+	RemoveHeaders []string
+	// FlushInterval is httputil.ReverseProxy.FlushInterval: zero buffers
+	// response bodies, a negative value flushes after every write. Streaming
+	// responses (text/event-stream, or without Content-Length) are flushed
+	// immediately either way.
+	FlushInterval time.Duration
+	// Transport performs upstream requests; nil uses http.DefaultTransport.
+	Transport http.RoundTripper
+	// ErrorHandler answers requests the upstream could not serve. nil
+	// answers 502 with a problem+json body.
+	ErrorHandler func(w http.ResponseWriter, req *http.Request, err error)
+}
+
+type proxyRule struct {
+	target string
+	rule   RewriteRule
+}
+
+// proxyMethods are the methods Proxy registers.
+var proxyMethods = []string{
+	http.MethodGet,
+	http.MethodHead,
+	http.MethodPost,
+	http.MethodPut,
+	http.MethodPatch,
+	http.MethodDelete,
+	http.MethodOptions,
+}
+
+// Proxy registers a reverse proxy from pattern to the absolute URL target,
+// built on httputil.ReverseProxy:
+//
+//	r.Proxy("/api/{rest...}", "http://backend:8080", saruta.RewriteRule{
+//		Path: "/v2/{rest...}",
+//	})
+//
+// Unlike a mount, the upstream path can be rebuilt from the values the
+// pattern captures. The proxy answers GET, HEAD, POST, PUT, PATCH, DELETE
+// and OPTIONS, runs the middleware of r like any route, and streams request
+// and response bodies. The query string is forwarded, merged with the
+// target's. Validation of target and rule.Path is deferred until Compile.
+func (r *Router) Proxy(pattern, target string, rule RewriteRule) {
+	r.checkFrozen("Proxy")
+	pr := &proxyRule{target: target, rule: rule}
+	source := callerSource()
+	for _, method := range proxyMethods {
+		r.state.routes = append(r.state.routes, registeredRoute{
+			method:     method,
+			pattern:    r.prefix + pattern,
+			proxy:      pr,
+			middleware: append([]middlewareEntry(nil), r.middleware...),
+			meta:       r.meta,
+			source:     source,
+		})
+	}
+}
+
+func (pr *proxyRule) compile(pattern string, cp compiledPattern) (http.Handler, error) {
+	target, err := url.Parse(pr.target)
+	if err != nil {
+		return nil, fmt.Errorf("invalid proxy %s: %w", pattern, err)
+	}
+	if (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+		return nil, fmt.Errorf("invalid proxy %s: target %q must be an absolute http or https URL", pattern, pr.target)
+	}
+	var tmpl *pathTemplate
+	if pr.rule.Path != "" {
+		if pr.rule.Path[0] != '/' || strings.ContainsAny(pr.rule.Path, "?#") {
+			return nil, fmt.Errorf("invalid proxy %s: path %q must be a path", pattern, pr.rule.Path)
+		}
+		if tmpl, err = parsePathTemplate(pr.rule.Path, cp); err != nil {
+			return nil, fmt.Errorf("invalid proxy %s: %w", pattern, err)
+		}
+	}
+
+	rule := pr.rule
+	errorHandler := rule.ErrorHandler
+	if errorHandler == nil {
+		errorHandler = func(w http.ResponseWriter, req *http.Request, err error) {
+			writeProblem(w, http.StatusBadGateway, "upstream request failed")
+		}
+	}
+	base := strings.TrimSuffix(target.EscapedPath(), "/")
+	rp := &httputil.ReverseProxy{
+		Rewrite: func(p *httputil.ProxyRequest) {
+			p.SetURL(target)
+			if tmpl != nil {
+				escaped, _ := proxyPath(tmpl, base, p.In)
+				p.Out.URL.Path, _ = url.PathUnescape(escaped)
+				p.Out.URL.RawPath = escaped
+			}
+			if rule.PreserveHost {
+				p.Out.Host = p.In.Host
+			}
+			if rule.Forwarded {
+				p.SetXForwarded()
+			}
+			for k, v := range rule.SetHeaders {
+				p.Out.Header.Set(k, v)
+			}
+			for _, k := range rule.RemoveHeaders {
+				p.Out.Header.Del(k)
+			}
+		},
+		Transport:     rule.Transport,
+		FlushInterval: rule.FlushInterval,
+		ErrorHandler:  errorHandler,
+	}
+	if tmpl == nil {
+		return rp, nil
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if _, ok := proxyPath(tmpl, base, req); !ok {
+			writeProblem(w, http.StatusBadRequest, "path parameter contains a dot segment")
+			return
+		}
+		rp.ServeHTTP(w, req)
+	}), nil
+}
+
+// proxyPath returns the escaped upstream path for req, and false if a
+// captured value holds a "." or ".." segment, which the upstream would
+// resolve against the rule's path.
+func proxyPath(tmpl *pathTemplate, base string, req *http.Request) (string, bool) {
+	ok := true
+	escaped := tmpl.expand(func(name string) string {
+		v := escapedPathValue(req, Param(req, name))
+		for seg := range strings.SplitSeq(v, "/") {
+			if seg, _ = url.PathUnescape(seg); seg == "." || seg == ".." {
+				ok = false
+			}
+		}
+		return v
+	}, nil)
+	return base + escaped, ok
+}
+
+// escapedPathValue returns the escaped form of v, a value captured from
+// req.URL.Path. A catch-all value is cut from the escaped request path, so
+// that an encoded slash in it stays encoded instead of splitting a segment.
+func escapedPathValue(req *http.Request, v string) string {
+	if strings.Contains(v, "/") && req.URL.RawPath != "" && strings.HasSuffix(req.URL.Path, v) {
+		raw := req.URL.EscapedPath()
+		i := 0
+		for skip := len(req.URL.Path) - len(v); skip > 0 && i < len(raw); skip-- {
+			if raw[i] == '%' {
+				i += 3
+			} else {
+				i++
+			}
+		}
+		if i <= len(raw) {
+			if u, err := url.PathUnescape(raw[i:]); err == nil && u == v {
+				return raw[i:]
+			}
+		}
+	}
+	return escapePathValue(v)
+}
@@ -0,0 +1,174 @@
+package saruta
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestProxy(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		fmt.Fprintf(w, "%s %s?%s host=%s xff=%q tenant=%q cookie=%q",
+			req.Method, req.URL.EscapedPath(), req.URL.RawQuery, req.Host,
+			req.Header.Get("X-Forwarded-For"), req.Header.Get("X-Tenant"), req.Header.Get("Cookie"))
+	}))
+	defer upstream.Close()
+	upstreamHost := strings.TrimPrefix(upstream.URL, "http://")
+
+	r := New()
+	r.Use(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			w.Header().Set("X-Mw", "1")
+			next.ServeHTTP(w, req)
+		})
+	})
+	r.Proxy("/api/{rest...}", upstream.URL+"/base?v=1", RewriteRule{
+		Path:          "/v2/{rest...}",
+		SetHeaders:    map[string]string{"X-Tenant": "acme"},
+		RemoveHeaders: []string{"Cookie"},
+	})
+	r.Proxy("/users/{id}/avatar", upstream.URL, RewriteRule{
+		Path:         "/avatars/{id}.png",
+		PreserveHost: true,
+		Forwarded:    true,
+	})
+	r.Proxy("/raw/{rest...}", upstream.URL, RewriteRule{})
+	r.MustCompile()
+
+	tests := []struct {
+		method, target, want string
+	}{
+		{http.MethodGet, "/api/items/7?q=x", "GET /base/v2/items/7?v=1&q=x host=" + upstreamHost + ` xff="" tenant="acme" cookie=""`},
+		{http.MethodPost, "/api/a%20b/c", "POST /base/v2/a%20b/c?v=1 host=" + upstreamHost + ` xff="" tenant="acme" cookie=""`},
+		{http.MethodGet, "/users/42/avatar", `GET /avatars/42.png? host=example.com xff="192.0.2.1" tenant="" cookie="c=1"`},
+		{http.MethodDelete, "/raw/x/y", "DELETE /raw/x/y? host=" + upstreamHost + ` xff="" tenant="" cookie="c=1"`},
+	}
+	for _, tc := range tests {
+		req := httptest.NewRequest(tc.method, tc.target, nil)
+		req.Header.Set("Cookie", "c=1")
+		req.Header.Set("X-Forwarded-For", "203.0.113.9")
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+		if rec.Code != http.StatusOK || rec.Body.String() != tc.want {
+			t.Errorf("%s %s = %d %q, want %q", tc.method, tc.target, rec.Code, rec.Body, tc.want)
+		}
+		if rec.Header().Get("X-Mw") != "1" {
+			t.Errorf("%s %s: middleware did not run", tc.method, tc.target)
+		}
+	}
+}
+
+func TestProxyPathValues(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		io.WriteString(w, req.URL.EscapedPath())
+	}))
+	defer upstream.Close()
+
+	r := New()
+	r.Proxy("/api/{rest...}", upstream.URL, RewriteRule{Path: "/v2/{rest...}"})
+	r.Proxy("/files/{name}", upstream.URL, RewriteRule{Path: "/v2/files/{name}"})
+	r.MustCompile()
+
+	tests := []struct {
+		target string
+		code   int
+		want   string
+	}{
+		{"/api/a%2Fb/c", http.StatusOK, "/v2/a%2Fb/c"},
+		{"/api/a%20b/c", http.StatusOK, "/v2/a%20b/c"},
+		{"/api/..x/y.", http.StatusOK, "/v2/..x/y."},
+		{"/api/%2e%2e/admin", http.StatusBadRequest, ""},
+		{"/api/x/../../admin", http.StatusBadRequest, ""},
+		{"/api/./admin", http.StatusBadRequest, ""},
+		{"/files/%2E%2E", http.StatusBadRequest, ""},
+	}
+	for _, tc := range tests {
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tc.target, nil))
+		if rec.Code != tc.code {
+			t.Errorf("GET %s status = %d, want %d", tc.target, rec.Code, tc.code)
+			continue
+		}
+		if tc.code == http.StatusOK && rec.Body.String() != tc.want {
+			t.Errorf("GET %s upstream path = %q, want %q", tc.target, rec.Body, tc.want)
+		}
+	}
+}
+
+func TestProxyStreams(t *testing.T) {
+	next := make(chan string)
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		for msg := range next {
+			fmt.Fprintf(w, "data: %s\n", msg)
+			w.(http.Flusher).Flush()
+		}
+	}))
+	defer upstream.Close()
+
+	r := New()
+	r.Proxy("/events", upstream.URL, RewriteRule{})
+	r.MustCompile()
+	srv := httptest.NewServer(r)
+	defer srv.Close()
+
+	// Headers reach the client only once the upstream flushes, so queue the
+	// first event before waiting for the response.
+	go func() { next <- "one" }()
+	res, err := http.Get(srv.URL + "/events")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+	lines := bufio.NewScanner(res.Body)
+	for i, msg := range []string{"one", "two"} {
+		if i > 0 {
+			next <- msg
+		}
+		if !lines.Scan() || lines.Text() != "data: "+msg {
+			t.Fatalf("line %q, want event %q", lines.Text(), msg)
+		}
+	}
+	close(next)
+	if rest, _ := io.ReadAll(res.Body); len(rest) != 0 {
+		t.Errorf("trailing data %q", rest)
+	}
+}
+
+func TestProxyUpstreamError(t *testing.T) {
+	upstream := httptest.NewServer(http.NotFoundHandler())
+	upstream.Close()
+
+	r := New()
+	r.Proxy("/down", upstream.URL, RewriteRule{})
+	r.MustCompile()
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/down", nil))
+	if rec.Code != http.StatusBadGateway || rec.Header().Get("Content-Type") != "application/problem+json" {
+		t.Errorf("status %d, Content-Type %q", rec.Code, rec.Header().Get("Content-Type"))
+	}
+}
+
+func TestProxyCompileErrors(t *testing.T) {
+	tests := []struct {
+		target string
+		rule   RewriteRule
+		want   string
+	}{
+		{"backend:8080", RewriteRule{}, "absolute http or https URL"},
+		{"ftp://backend", RewriteRule{}, "absolute http or https URL"},
+		{"http://backend", RewriteRule{Path: "v2/{rest...}"}, "must be a path"},
+		{"http://backend", RewriteRule{Path: "/v2/{other...}"}, "not captured"},
+	}
+	for _, tc := range tests {
+		r := New()
+		r.Proxy("/api/{rest...}", tc.target, tc.rule)
+		if err := r.Compile(); err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("Proxy(%q, %+v): Compile error %v, want %q", tc.target, tc.rule, err, tc.want)
+		}
+	}
+}
@@ -2,9 +2,11 @@ package saruta
 
 import (
 	"fmt"
+	"math/bits"
 	"net/http"
 	"sort"
 	"strings"
+	"sync"
 )
 
 type node struct {
@@ -13,17 +15,30 @@ type node struct {
 	catchAllChild  *paramEdge
 
 	handlers map[string]http.Handler
-	mount    http.Handler
+	routes   map[string]*RouteInfo
+	origins  map[string]string // method -> registration, for duplicate errors
+	pattern  string
+	mount    *mountEntry
+	rewrite  *pathTemplate
+	size     int // paths inserted at or below this node
 }
 
 type paramEdge struct {
-	name    string
-	expr    string
-	prefix  string
-	suffix  string
-	matcher segmentMatcher
-	tmpl    *segmentTemplate
-	next    *node
+	name     string
+	expr     string
+	prefix   string
+	suffix   string
+	matcher  segmentMatcher
+	tmpl     *segmentTemplate
+	repeated bool
+	next     *node
+	origin   string // registration that created the edge, for conflict errors
+}
+
+type mountEntry struct {
+	handler    http.Handler
+	precedence MountPrecedence
+	source     string
 }
 
 type pathParam struct {
@@ -32,32 +47,185 @@ type pathParam struct {
 }
 
 type routeMatch struct {
-	leaf       *radixNode
-	params     [8]pathParam
-	paramCount int
+	leaf   *radixLeaf
+	params paramStore
+}
+
+// inlineParams is the number of path values captured without allocating.
+const inlineParams = 8
+
+// paramStore holds the path values captured while matching: the first
+// inlineParams inline, any further ones in a slice taken from overflowPool,
+// so common routes match without allocating and deep ones still match.
+type paramStore struct {
+	inline   [inlineParams]pathParam
+	n        int
+	overflow *[]pathParam
+}
+
+var overflowPool = sync.Pool{
+	New: func() any {
+		s := make([]pathParam, 0, inlineParams)
+		return &s
+	},
+}
+
+func (ps *paramStore) push(p pathParam) {
+	if ps.n < inlineParams {
+		ps.inline[ps.n] = p
+	} else {
+		if ps.overflow == nil {
+			ps.overflow = overflowPool.Get().(*[]pathParam)
+		}
+		*ps.overflow = append((*ps.overflow)[:ps.n-inlineParams], p)
+	}
+	ps.n++
+}
+
+// truncate drops the values after the first n, undoing pushes when the
+// matcher backtracks.
+func (ps *paramStore) truncate(n int) {
+	ps.n = n
+}
+
+func (ps *paramStore) len() int {
+	return ps.n
+}
+
+func (ps *paramStore) at(i int) pathParam {
+	if i < inlineParams {
+		return ps.inline[i]
+	}
+	return (*ps.overflow)[i-inlineParams]
+}
+
+func (ps *paramStore) get(name string) string {
+	for i := range ps.n {
+		if p := ps.at(i); p.name == name {
+			return p.value
+		}
+	}
+	return ""
+}
+
+// release returns the overflow slice to the pool. ps must not be read
+// afterwards.
+func (ps *paramStore) release() {
+	if ps.overflow == nil {
+		return
+	}
+	clear(*ps.overflow)
+	*ps.overflow = (*ps.overflow)[:0]
+	overflowPool.Put(ps.overflow)
+	ps.overflow = nil
+	ps.n = min(ps.n, inlineParams)
+}
+
+// radixTree is the compiled routing tree laid out by layoutRadix. Nodes and
+// edges link to each other by index into its slices rather than by pointer,
+// so nodes, edges, firsts and indexes hold no pointers at all and the garbage
+// collector skips them. The root is nodes[0].
+type radixTree struct {
+	nodes []radixNode
+	edges []radixStaticEdge
+	// firsts holds the first label byte of each edge, parallel to edges.
+	// Nodes with at most compactEdgeLimit edges scan it; larger ones look the
+	// byte up in their indexes entry (edge+1; 0 means none) instead.
+	firsts  []byte
+	indexes [][256]uint16
+	params  []radixParamEdge
+	leaves  []radixLeaf
+	tables  []methodTable
+	// labels holds every distinct static edge label once, back to back; see
+	// radixStaticEdge.
+	labels string
 }
 
 type radixNode struct {
-	staticEdges     []radixStaticEdge
-	staticEdgeIndex [256]uint16 // index+1; 0 means none
-	paramChild      *radixParamEdge
-	catchAllChild   *radixParamEdge
-	handlers        map[string]http.Handler
-	mount           http.Handler
+	edges    int32 // first static edge in edges and firsts
+	numEdges int32
+	index    int32 // indexes entry, or -1
+	param    int32 // params entry, or -1
+	catchAll int32 // params entry, or -1
+	leaf     int32 // leaves entry; nodes serving nothing share entry 0
 }
 
+// radixStaticEdge is labels[labelStart:labelEnd] leading to nodes[next].
 type radixStaticEdge struct {
-	label string
-	next  *radixNode
+	labelStart int32
+	labelEnd   int32
+	next       int32
 }
 
 type radixParamEdge struct {
-	name    string
-	prefix  string
-	suffix  string
-	matcher segmentMatcher
-	tmpl    *segmentTemplate
-	next    *radixNode
+	name     string
+	expr     string
+	prefix   string
+	suffix   string
+	matcher  segmentMatcher
+	tmpl     *segmentTemplate
+	repeated bool
+	next     int32
+}
+
+// radixLeaf is what a node serves: the routes ending there, a mount or a
+// rewrite.
+type radixLeaf struct {
+	handlers *methodTable
+	routes   map[string]*RouteInfo
+	pattern  string
+	mount    *mountEntry
+	rewrite  *pathTemplate
+}
+
+func (l *radixLeaf) empty() bool {
+	return l.handlers == nil && l.routes == nil && l.pattern == "" && l.mount == nil && l.rewrite == nil
+}
+
+// radixBuildNode is a node of the pointer-linked tree buildRadixNode
+// assembles and layoutRadix flattens into a radixTree.
+type radixBuildNode struct {
+	staticEdges   []radixBuildEdge
+	paramChild    *radixParamEdge
+	paramNext     *radixBuildNode
+	catchAllChild *radixParamEdge
+	catchAllNext  *radixBuildNode
+	leaf          radixLeaf
+}
+
+type radixBuildEdge struct {
+	label string
+	next  *radixBuildNode
+}
+
+func (t *radixTree) node(i int32) *radixNode {
+	return &t.nodes[i]
+}
+
+func (t *radixTree) leaf(n *radixNode) *radixLeaf {
+	return &t.leaves[n.leaf]
+}
+
+func (t *radixTree) staticEdges(n *radixNode) []radixStaticEdge {
+	return t.edges[n.edges : n.edges+n.numEdges]
+}
+
+func (t *radixTree) label(e radixStaticEdge) string {
+	return t.labels[e.labelStart:e.labelEnd]
+}
+
+func (t *radixTree) paramChild(n *radixNode) *radixParamEdge {
+	if n.param < 0 {
+		return nil
+	}
+	return &t.params[n.param]
+}
+
+func (t *radixTree) catchAllChild(n *radixNode) *radixParamEdge {
+	if n.catchAll < 0 {
+		return nil
+	}
+	return &t.params[n.catchAll]
 }
 
 func newNode() *node {
@@ -67,7 +235,56 @@ func newNode() *node {
 }
 
 func (n *node) insertRoute(method, pattern string, cp compiledPattern, h http.Handler) error {
+	return n.insertRouteInfo(method, pattern, pattern, cp, h, nil, "")
+}
+
+// insertRouteInfo inserts a route at pattern. leafPattern is what requests
+// matching it get as req.Pattern: the route's pattern, also for an alias.
+// source is the registration call site and only used to name both sides of
+// a conflict.
+func (n *node) insertRouteInfo(method, pattern, leafPattern string, cp compiledPattern, h http.Handler, info *RouteInfo, source string) error {
+	origin := describeRoute(method, pattern, source)
+	cur, err := n.insertPath(origin, cp)
+	if err != nil {
+		return err
+	}
+	if cur.handlers == nil {
+		cur.handlers = make(map[string]http.Handler)
+		cur.origins = make(map[string]string)
+		cur.pattern = leafPattern
+	}
+	if _, exists := cur.handlers[method]; exists {
+		return fmt.Errorf("duplicate route: %s is already registered by %s", origin, cur.origins[method])
+	}
+	cur.handlers[method] = h
+	cur.origins[method] = origin
+	if info != nil {
+		if cur.routes == nil {
+			cur.routes = make(map[string]*RouteInfo)
+		}
+		cur.routes[method] = info
+	}
+	return nil
+}
+
+func (n *node) insertRewrite(pattern string, cp compiledPattern, tmpl *pathTemplate) error {
+	cur, err := n.insertPath("rewrite "+pattern, cp)
+	if err != nil {
+		return err
+	}
+	if cur.rewrite != nil {
+		return fmt.Errorf("duplicate rewrite: %s", pattern)
+	}
+	cur.rewrite = tmpl
+	return nil
+}
+
+// insertPath creates the nodes for cp. origin describes the registration
+// (see describeRoute) and is recorded on new parameter edges so that a later
+// conflicting registration can name it.
+func (n *node) insertPath(origin string, cp compiledPattern) (*node, error) {
 	cur := n
+	cur.size++
 	for _, seg := range cp.segments {
 		switch seg.kind {
 		case segmentStatic:
@@ -87,38 +304,35 @@ func (n *node) insertRoute(method, pattern string, cp compiledPattern, h http.Ha
 					matcher: seg.matcher,
 					tmpl:    seg.tmpl,
 					next:    newNode(),
+					origin:  origin,
 				}
-			} else if !sameSegmentTemplate(cur.paramChild.tmpl, seg.tmpl) {
-				return fmt.Errorf("route conflict: %s %s conflicts with existing parameter {%s}", method, pattern, cur.paramChild.name)
+			} else if pe := cur.paramChild; !sameSegmentTemplate(pe.tmpl, seg.tmpl) {
+				return nil, fmt.Errorf("route conflict: %s conflicts with parameter %s of %s", origin, pe.label(), pe.origin)
 			}
 			cur = cur.paramChild.next
 		case segmentCatchAll:
 			if cur.catchAllChild == nil {
 				cur.catchAllChild = &paramEdge{
-					name:    seg.name,
-					expr:    seg.expr,
-					matcher: seg.matcher,
-					next:    newNode(),
+					name:     seg.name,
+					expr:     seg.expr,
+					matcher:  seg.matcher,
+					repeated: seg.repeated,
+					next:     newNode(),
+					origin:   origin,
 				}
-			} else if cur.catchAllChild.name != seg.name {
-				return fmt.Errorf("route conflict: %s %s conflicts with existing catch-all {%s...}", method, pattern, cur.catchAllChild.name)
+			} else if ce := cur.catchAllChild; ce.name != seg.name || ce.repeated != seg.repeated || ce.expr != seg.expr {
+				return nil, fmt.Errorf("route conflict: %s conflicts with catch-all %s of %s", origin, catchAllLabel(ce.name, ce.expr, ce.repeated), ce.origin)
 			}
 			cur = cur.catchAllChild.next
 		default:
-			return fmt.Errorf("unknown segment kind")
+			return nil, fmt.Errorf("unknown segment kind")
 		}
+		cur.size++
 	}
-	if cur.handlers == nil {
-		cur.handlers = make(map[string]http.Handler)
-	}
-	if _, exists := cur.handlers[method]; exists {
-		return fmt.Errorf("duplicate route: %s %s", method, pattern)
-	}
-	cur.handlers[method] = h
-	return nil
+	return cur, nil
 }
 
-func (n *node) insertMount(prefix string, cp compiledPattern, h http.Handler) error {
+func (n *node) insertMount(prefix string, cp compiledPattern, m *mountEntry) error {
 	cur := n
 	for _, seg := range cp.segments {
 		if seg.kind != segmentStatic {
@@ -132,20 +346,12 @@ func (n *node) insertMount(prefix string, cp compiledPattern, h http.Handler) er
 		cur = next
 	}
 	if cur.mount != nil {
-		return fmt.Errorf("duplicate mount: %s", prefix)
+		return fmt.Errorf("duplicate mount: %s conflicts with %s", describeRoute("mount", prefix, m.source), describeRoute("mount", prefix, cur.mount.source))
 	}
-	cur.mount = h
+	cur.mount = m
 	return nil
 }
 
-func storeParam(params *[8]pathParam, count int, p pathParam) (int, bool) {
-	if count >= len(params) {
-		return count, false
-	}
-	params[count] = p
-	return count + 1, true
-}
-
 func (pe *paramEdge) matchSegment(seg string) (string, bool) {
 	if pe.tmpl != nil && len(pe.tmpl.params) > 1 {
 		return "", false
@@ -178,32 +384,33 @@ func matchParamSegment(seg, prefix, suffix string, matcher segmentMatcher) (stri
 	return value, true
 }
 
-func allowHeaderValue(handlers map[string]http.Handler) string {
-	if len(handlers) == 0 {
-		return ""
-	}
-	methods := make([]string, 0, len(handlers))
-	for method := range handlers {
-		methods = append(methods, method)
-	}
-	sort.Strings(methods)
-	return strings.Join(methods, ", ")
+func allowHeaderValue(handlers *methodTable) string {
+	return strings.Join(handlers.methods(), ", ")
 }
 
-func buildRadix(root *node) *radixNode {
+func buildRadix(root *node) *radixTree {
 	if root == nil {
-		return &radixNode{}
+		return layoutRadix(&radixBuildNode{})
 	}
-	rt := buildRadixNode(root)
-	finalizeRadix(rt)
-	return rt
+	rt := buildRadixNode(root, newRadixBuilder(root))
+	sortRadix(rt)
+	return layoutRadix(rt)
 }
 
-func buildRadixNode(src *node) *radixNode {
-	dst := &radixNode{
-		handlers: src.handlers,
-		mount:    src.mount,
-	}
+// buildRadixNode converts the subtree at src. With a non-nil b, large child
+// subtrees are converted concurrently; edges are inserted once all are done,
+// and sortRadix makes the result independent of the order.
+func buildRadixNode(src *node, b *radixBuilder) *radixBuildNode {
+	dst := &radixBuildNode{
+		leaf: radixLeaf{
+			handlers: newMethodTable(src.handlers),
+			routes:   src.routes,
+			pattern:  src.pattern,
+			mount:    src.mount,
+			rewrite:  src.rewrite,
+		},
+	}
+	var wg sync.WaitGroup
 	if src.paramChild != nil {
 		dst.paramChild = &radixParamEdge{
 			name:    src.paramChild.name,
@@ -211,23 +418,30 @@ func buildRadixNode(src *node) *radixNode {
 			suffix:  src.paramChild.suffix,
 			matcher: src.paramChild.matcher,
 			tmpl:    src.paramChild.tmpl,
-			next:    buildRadixNode(src.paramChild.next),
 		}
+		b.build(&wg, src.paramChild.next, &dst.paramNext)
 	}
 	if src.catchAllChild != nil {
 		dst.catchAllChild = &radixParamEdge{
-			name:    src.catchAllChild.name,
-			matcher: src.catchAllChild.matcher,
-			next:    buildRadixNode(src.catchAllChild.next),
+			name:     src.catchAllChild.name,
+			expr:     src.catchAllChild.expr,
+			matcher:  src.catchAllChild.matcher,
+			repeated: src.catchAllChild.repeated,
 		}
+		b.build(&wg, src.catchAllChild.next, &dst.catchAllNext)
 	}
 
-	if len(src.staticChildren) == 0 {
-		return dst
-	}
+	edges := make([]radixBuildEdge, len(src.staticChildren))
+	i := 0
 	for seg, child := range src.staticChildren {
 		label, end := compressStaticChain(seg, child)
-		insertRadixStaticEdge(dst, label, buildRadixNode(end))
+		edges[i].label = label
+		b.build(&wg, end, &edges[i].next)
+		i++
+	}
+	wg.Wait()
+	for _, e := range edges {
+		insertRadixStaticEdge(dst, e.label, e.next)
 	}
 	return dst
 }
@@ -236,7 +450,7 @@ func compressStaticChain(firstSeg string, child *node) (string, *node) {
 	label := "/" + firstSeg
 	cur := child
 	for {
-		if cur == nil || cur.handlers != nil || cur.mount != nil || cur.paramChild != nil || cur.catchAllChild != nil || len(cur.staticChildren) != 1 {
+		if cur == nil || cur.handlers != nil || cur.mount != nil || cur.rewrite != nil || cur.paramChild != nil || cur.catchAllChild != nil || len(cur.staticChildren) != 1 {
 			return label, cur
 		}
 		var nextSeg string
@@ -250,54 +464,152 @@ func compressStaticChain(firstSeg string, child *node) (string, *node) {
 	}
 }
 
-func (n *radixNode) matchRoute(path string) (routeMatch, bool) {
-	var params [8]pathParam
+func (t *radixTree) matchRoute(path string) (routeMatch, bool) {
+	return t.matchRouteTrace(path, nil)
+}
+
+// matchRouteTrace is matchRoute with an optional trace collector (nil on the
+// serving path).
+func (t *radixTree) matchRouteTrace(path string, tr *matchTracer) (routeMatch, bool) {
+	var m routeMatch
 	if path == "/" {
-		return routeMatch{leaf: n, params: params, paramCount: 0}, true
+		m.leaf = t.leaf(&t.nodes[0])
+		return m, true
 	}
-	leaf, count, ok := n.matchPath(path, 0, &params, 0)
+	leaf, ok := t.matchPath(path, 0, &m.params, tr)
 	if !ok {
+		m.params.release()
 		return routeMatch{}, false
 	}
-	return routeMatch{leaf: leaf, params: params, paramCount: count}, true
+	m.leaf = t.leaf(leaf)
+	return m, true
 }
 
-func (n *radixNode) matchPath(path string, pos int, params *[8]pathParam, paramCount int) (*radixNode, int, bool) {
-	if pos == len(path) {
-		return n, paramCount, true
-	}
+// matchStackSize is the number of backtracking frames matchPath keeps on the
+// goroutine stack; deeper trees grow the stack on the heap.
+const matchStackSize = 16
+
+// matchFrame is node n being matched at pos. next is the alternative to try
+// when the frame is on top of the stack; once a child frame is exhausted it
+// also tells which edge led there.
+type matchFrame struct {
+	n     int32
+	next  uint8
+	pos   int
+	saved int // params.len() before the param edge was taken
+}
+
+const (
+	tryStatic uint8 = iota
+	tryParam
+	tryCatchAll
+	exhausted
+)
 
-	if pos < len(path) {
-		if edge := n.staticEdgeFor(path[pos]); edge != nil && strings.HasPrefix(path[pos:], edge.label) {
-			if leaf, count, ok := edge.next.matchPath(path, pos+len(edge.label), params, paramCount); ok {
-				return leaf, count, true
+// matchPath matches path[pos:] below the root, pushing captured values onto
+// params. It walks the tree depth first (static, then param, then catch-all
+// edges) with an explicit stack instead of recursion. On failure params is
+// left as it was on entry.
+func (t *radixTree) matchPath(path string, pos int, params *paramStore, tr *matchTracer) (*radixNode, bool) {
+	var buf [matchStackSize]matchFrame
+	stack := append(buf[:0], matchFrame{pos: pos})
+	for len(stack) > 0 {
+		f := &stack[len(stack)-1]
+		cur, pos := &t.nodes[f.n], f.pos
+		switch f.next {
+		case tryStatic:
+			if pos == len(path) {
+				return cur, true
+			}
+			f.next = tryParam
+			if edge := t.staticEdgeFor(cur, path[pos]); edge != nil {
+				label := t.label(*edge)
+				if strings.HasPrefix(path[pos:], label) {
+					if tr != nil {
+						tr.step(pos, "static", label, path[pos:], true, "")
+					}
+					stack = append(stack, matchFrame{n: edge.next, pos: pos + len(label)})
+					continue
+				}
+				if tr != nil {
+					tr.step(pos, "static", label, path[pos:], false, "label does not match")
+				}
 			}
-		}
-	}
 
-	if pe := n.paramChild; pe != nil {
-		if seg, nextPos, ok := nextSegmentAt(path, pos); ok {
-			nextCount, ok := pe.storeSegmentParams(seg, params, paramCount)
-			if ok {
-				if leaf, count, ok := pe.next.matchPath(path, nextPos, params, nextCount); ok {
-					return leaf, count, true
+		case tryParam:
+			f.next = tryCatchAll
+			pe := t.paramChild(cur)
+			if pe == nil {
+				continue
+			}
+			seg, nextPos, ok := nextSegmentAt(path, pos)
+			if !ok {
+				if tr != nil {
+					tr.step(pos, "param", pe.label(), path[pos:], false, "no segment boundary at this position")
 				}
+				continue
+			}
+			f.saved = params.len()
+			if pe.storeSegmentParams(seg, params) {
+				if tr != nil {
+					tr.step(pos, "param", pe.label(), seg, true, "")
+				}
+				stack = append(stack, matchFrame{n: pe.next, pos: nextPos})
+				continue
+			}
+			params.truncate(f.saved)
+			if tr != nil {
+				tr.step(pos, "param", pe.label(), seg, false, "segment rejected by pattern or constraint")
 			}
-		}
-	}
 
-	if pe := n.catchAllChild; pe != nil {
-		if rest, ok := catchAllAt(path, pos); ok {
+		case tryCatchAll:
+			f.next = exhausted
+			pe := t.catchAllChild(cur)
+			if pe == nil {
+				continue
+			}
+			rest, ok := catchAllAt(path, pos)
+			if !ok {
+				if tr != nil {
+					tr.step(pos, "catch-all", pe.catchAllLabel(), path[pos:], false, "no segment boundary at this position")
+				}
+				continue
+			}
 			if value, ok := pe.matchSegment(rest); ok {
-				nextCount, ok := storeParam(params, paramCount, pathParam{name: pe.name, value: value})
-				if ok {
-					return pe.next, nextCount, true
+				params.push(pathParam{name: pe.name, value: value})
+				if tr != nil {
+					tr.step(pos, "catch-all", pe.catchAllLabel(), rest, true, "")
+				}
+				return &t.nodes[pe.next], true
+			}
+			if tr != nil {
+				tr.step(pos, "catch-all", pe.catchAllLabel(), rest, false, "value rejected by constraint")
+			}
+
+		default:
+			// Every alternative below cur failed: pop it and undo the edge
+			// of the parent that led here.
+			stack = stack[:len(stack)-1]
+			if len(stack) == 0 {
+				break
+			}
+			parent := &stack[len(stack)-1]
+			switch parent.next {
+			case tryParam:
+				if tr != nil {
+					edge := t.staticEdgeFor(&t.nodes[parent.n], path[parent.pos])
+					tr.step(parent.pos, "static", t.label(*edge), path[parent.pos:], false, "no route below this edge")
+				}
+			case tryCatchAll:
+				params.truncate(parent.saved)
+				if tr != nil {
+					seg, _, _ := nextSegmentAt(path, parent.pos)
+					tr.step(parent.pos, "param", t.paramChild(&t.nodes[parent.n]).label(), seg, false, "no route below this edge")
 				}
 			}
 		}
 	}
-
-	return nil, 0, false
+	return nil, false
 }
 
 func nextSegmentAt(path string, pos int) (seg string, nextPos int, ok bool) {
@@ -320,30 +632,31 @@ func catchAllAt(path string, pos int) (string, bool) {
 	return path[pos+1:], true
 }
 
-func (n *radixNode) findMount(path string) http.Handler {
-	cur := n
+func (t *radixTree) findMount(path string) *mountEntry {
+	cur := &t.nodes[0]
 	pos := 0
-	var candidate http.Handler
-	if cur.mount != nil {
-		candidate = cur.mount
-	}
+	candidate := t.leaf(cur).mount
 	for {
 		if pos == len(path) {
 			return candidate
 		}
-		edge := cur.staticEdgeFor(path[pos])
-		if edge == nil || !strings.HasPrefix(path[pos:], edge.label) {
+		edge := t.staticEdgeFor(cur, path[pos])
+		if edge == nil {
 			return candidate
 		}
-		cur = edge.next
-		pos += len(edge.label)
-		if cur.mount != nil && (pos == len(path) || (pos < len(path) && path[pos] == '/')) {
-			candidate = cur.mount
+		label := t.label(*edge)
+		if !strings.HasPrefix(path[pos:], label) {
+			return candidate
+		}
+		cur = &t.nodes[edge.next]
+		pos += len(label)
+		if m := t.leaf(cur).mount; m != nil && (pos == len(path) || (pos < len(path) && path[pos] == '/')) {
+			candidate = m
 		}
 	}
 }
 
-func insertRadixStaticEdge(n *radixNode, label string, child *radixNode) {
+func insertRadixStaticEdge(n *radixBuildNode, label string, child *radixBuildNode) {
 	for i := range n.staticEdges {
 		existing := &n.staticEdges[i]
 		common := longestCommonPrefix(existing.label, label)
@@ -359,10 +672,10 @@ func insertRadixStaticEdge(n *radixNode, label string, child *radixNode) {
 			return
 		}
 		// Split existing edge.
-		split := &radixNode{}
+		split := &radixBuildNode{}
 		remainingExisting := existing.label[common:]
 		remainingNew := label[common:]
-		split.staticEdges = append(split.staticEdges, radixStaticEdge{
+		split.staticEdges = append(split.staticEdges, radixBuildEdge{
 			label: remainingExisting,
 			next:  existing.next,
 		})
@@ -376,27 +689,32 @@ func insertRadixStaticEdge(n *radixNode, label string, child *radixNode) {
 		insertRadixStaticEdge(split, remainingNew, child)
 		return
 	}
-	n.staticEdges = append(n.staticEdges, radixStaticEdge{label: label, next: child})
+	n.staticEdges = append(n.staticEdges, radixBuildEdge{label: label, next: child})
 }
 
-func mergeRadixSubtree(dst, src *radixNode) *radixNode {
+func mergeRadixSubtree(dst, src *radixBuildNode) *radixBuildNode {
 	if dst == nil {
 		return src
 	}
 	if src == nil {
 		return dst
 	}
-	if dst.handlers == nil {
-		dst.handlers = src.handlers
+	if dst.leaf.handlers == nil {
+		dst.leaf.handlers = src.leaf.handlers
+		dst.leaf.routes = src.leaf.routes
+		dst.leaf.pattern = src.leaf.pattern
 	}
-	if dst.mount == nil {
-		dst.mount = src.mount
+	if dst.leaf.mount == nil {
+		dst.leaf.mount = src.leaf.mount
+	}
+	if dst.leaf.rewrite == nil {
+		dst.leaf.rewrite = src.leaf.rewrite
 	}
 	if dst.paramChild == nil {
-		dst.paramChild = src.paramChild
+		dst.paramChild, dst.paramNext = src.paramChild, src.paramNext
 	}
 	if dst.catchAllChild == nil {
-		dst.catchAllChild = src.catchAllChild
+		dst.catchAllChild, dst.catchAllNext = src.catchAllChild, src.catchAllNext
 	}
 	for _, e := range src.staticEdges {
 		insertRadixStaticEdge(dst, e.label, e.next)
@@ -404,16 +722,33 @@ func mergeRadixSubtree(dst, src *radixNode) *radixNode {
 	return dst
 }
 
+// longestCommonPrefix compares eight bytes at a time and locates the first
+// difference in a word from its lowest set bit. Matching keeps
+// strings.HasPrefix: it compiles to the runtime's vectorized memequal, which
+// BenchmarkLabelPrefix shows is already faster than word loops in Go.
 func longestCommonPrefix(a, b string) int {
 	n := min(len(b), len(a))
 	i := 0
+	for ; i+8 <= n; i += 8 {
+		if x := load64(a, i) ^ load64(b, i); x != 0 {
+			return i + bits.TrailingZeros64(x)/8
+		}
+	}
 	for i < n && a[i] == b[i] {
 		i++
 	}
 	return i
 }
 
-func finalizeRadix(n *radixNode) {
+// load64 reads s[i:i+8] as a little-endian word; the compiler merges the byte
+// loads into one.
+func load64(s string, i int) uint64 {
+	s = s[i : i+8]
+	return uint64(s[0]) | uint64(s[1])<<8 | uint64(s[2])<<16 | uint64(s[3])<<24 |
+		uint64(s[4])<<32 | uint64(s[5])<<40 | uint64(s[6])<<48 | uint64(s[7])<<56
+}
+
+func sortRadix(n *radixBuildNode) {
 	if n == nil {
 		return
 	}
@@ -423,48 +758,51 @@ func finalizeRadix(n *radixNode) {
 		})
 	}
 	for i := range n.staticEdges {
-		edge := &n.staticEdges[i]
-		if edge.label != "" {
-			n.staticEdgeIndex[edge.label[0]] = uint16(i + 1)
-		}
-		finalizeRadix(edge.next)
-	}
-	if n.paramChild != nil {
-		finalizeRadix(n.paramChild.next)
-	}
-	if n.catchAllChild != nil {
-		finalizeRadix(n.catchAllChild.next)
+		sortRadix(n.staticEdges[i].next)
 	}
+	sortRadix(n.paramNext)
+	sortRadix(n.catchAllNext)
 }
 
-func (n *radixNode) staticEdgeFor(first byte) *radixStaticEdge {
-	idx := n.staticEdgeIndex[first]
-	if idx == 0 {
-		return nil
+func (t *radixTree) staticEdgeFor(n *radixNode, first byte) *radixStaticEdge {
+	if n.index >= 0 {
+		idx := t.indexes[n.index][first]
+		if idx == 0 {
+			return nil
+		}
+		return &t.edges[n.edges+int32(idx)-1]
+	}
+	for i, b := range t.firsts[n.edges : n.edges+n.numEdges] {
+		if b == first {
+			return &t.edges[int(n.edges)+i]
+		}
 	}
-	return &n.staticEdges[int(idx)-1]
+	return nil
 }
 
-func (pe *radixParamEdge) storeSegmentParams(seg string, params *[8]pathParam, count int) (int, bool) {
+// storeSegmentParams matches seg against the edge and pushes the captured
+// values. On failure the caller truncates params.
+func (pe *radixParamEdge) storeSegmentParams(seg string, params *paramStore) bool {
 	if pe.tmpl == nil || len(pe.tmpl.params) <= 1 {
 		value, ok := pe.matchSegment(seg)
 		if !ok {
-			return count, false
+			return false
 		}
-		return storeParam(params, count, pathParam{name: pe.name, value: value})
+		params.push(pathParam{name: pe.name, value: value})
+		return true
 	}
-	return matchTemplateAndStore(pe.tmpl, seg, params, count)
+	return matchTemplateAndStore(pe.tmpl, seg, params)
 }
 
-func matchTemplateAndStore(tmpl *segmentTemplate, seg string, params *[8]pathParam, count int) (int, bool) {
+func matchTemplateAndStore(tmpl *segmentTemplate, seg string, params *paramStore) bool {
 	if tmpl == nil {
-		return count, false
+		return false
 	}
 	pos := 0
 	for i, p := range tmpl.params {
 		prefix := tmpl.literals[i]
 		if !strings.HasPrefix(seg[pos:], prefix) {
-			return count, false
+			return false
 		}
 		pos += len(prefix)
 
@@ -472,27 +810,27 @@ func matchTemplateAndStore(tmpl *segmentTemplate, seg string, params *[8]pathPar
 		var value string
 		if i == len(tmpl.params)-1 {
 			if !strings.HasSuffix(seg, nextLit) {
-				return count, false
+				return false
 			}
 			end := len(seg) - len(nextLit)
 			if end < pos {
-				return count, false
+				return false
 			}
 			value = seg[pos:end]
 			if p.matcher != nil && !p.matcher.Match(value) {
-				return count, false
+				return false
 			}
 			pos = end
 		} else {
 			if nextLit == "" {
-				return count, false
+				return false
 			}
 			searchStart := pos
 			matched := false
 			for {
 				rel := strings.Index(seg[searchStart:], nextLit)
 				if rel < 0 {
-					return count, false
+					return false
 				}
 				end := searchStart + rel
 				value = seg[pos:end]
@@ -504,20 +842,16 @@ func matchTemplateAndStore(tmpl *segmentTemplate, seg string, params *[8]pathPar
 				searchStart = end + 1
 			}
 			if !matched {
-				return count, false
+				return false
 			}
 		}
-		var ok bool
-		count, ok = storeParam(params, count, pathParam{name: p.name, value: value})
-		if !ok {
-			return count, false
-		}
+		params.push(pathParam{name: p.name, value: value})
 	}
 	if pos != len(seg)-len(tmpl.literals[len(tmpl.literals)-1]) {
 		// last literal should be consumed by suffix check
-		return count, false
+		return false
 	}
-	return count, true
+	return true
 }
 
 func sameSegmentTemplate(a, b *segmentTemplate) bool {
@@ -1,7 +1,11 @@
 package saruta
 
 import (
+	"fmt"
 	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
 	"testing"
 )
 
@@ -99,26 +103,104 @@ func TestTriePriorityStaticParamCatchAll(t *testing.T) {
 	if !ok {
 		t.Fatalf("expected match")
 	}
-	if _, ok := m.leaf.handlers[http.MethodGet]; !ok {
+	if _, ok := m.leaf.handlers.get(http.MethodGet); !ok {
 		t.Fatalf("expected GET handler")
 	}
-	if m.paramCount != 0 {
-		t.Fatalf("params count = %d, want none", m.paramCount)
+	if m.params.len() != 0 {
+		t.Fatalf("params count = %d, want none", m.params.len())
 	}
 
 	m, ok = rt.matchRoute("/users/42")
 	if !ok {
 		t.Fatalf("expected match for param")
 	}
-	if m.paramCount != 1 || m.params[0].name != "id" || m.params[0].value != "42" {
-		t.Fatalf("params = %#v", m.params[:m.paramCount])
+	if m.params.len() != 1 || m.params.at(0).name != "id" || m.params.at(0).value != "42" {
+		t.Fatalf("params = %#v", m.params)
 	}
 
 	m, ok = rt.matchRoute("/users/a/b")
 	if !ok {
 		t.Fatalf("expected catch-all match")
 	}
-	if m.paramCount != 1 || m.params[0].name != "rest" || m.params[0].value != "a/b" {
-		t.Fatalf("params = %#v", m.params[:m.paramCount])
+	if m.params.len() != 1 || m.params.at(0).name != "rest" || m.params.at(0).value != "a/b" {
+		t.Fatalf("params = %#v", m.params)
+	}
+}
+
+func TestRouterManyParams(t *testing.T) {
+	const pattern = "/a/{p1}/{p2}/{p3}/{p4}/{p5}/{p6}/{p7}/{p8}/{p9}/x{p10}y/{rest...}"
+	var got []string
+	r := New()
+	r.Get(pattern, func(w http.ResponseWriter, req *http.Request) {
+		for i := 1; i <= 10; i++ {
+			got = append(got, req.PathValue(fmt.Sprintf("p%d", i)))
+		}
+		got = append(got, req.PathValue("rest"))
+	})
+	// A sibling that fails deep in the path forces backtracking across the
+	// inline/overflow boundary.
+	r.Get("/a/{p1}/{p2}/{p3}/{p4}/{p5}/{p6}/{p7}/{p8}/{p9}/static/only", func(w http.ResponseWriter, req *http.Request) {})
+	r.MustCompile()
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/a/1/2/3/4/5/6/7/8/9/x10y/r/s", nil))
+	want := []string{"1", "2", "3", "4", "5", "6", "7", "8", "9", "10", "r/s"}
+	if rec.Code != http.StatusOK || !slices.Equal(got, want) {
+		t.Fatalf("status %d, values %q, want %q", rec.Code, got, want)
+	}
+
+	var ps ParamBuffer
+	if _, ok := r.Lookup(http.MethodGet, "/a/1/2/3/4/5/6/7/8/9/x10y/r", &ps); !ok || ps.Len() != 11 || ps.Get("p10") != "10" || ps.Get("rest") != "r" {
+		t.Fatalf("Lookup: ok=%v len=%d p10=%q rest=%q", ok, ps.Len(), ps.Get("p10"), ps.Get("rest"))
+	}
+	if name, value := ps.At(9); name != "p10" || value != "10" {
+		t.Fatalf("At(9) = %q, %q", name, value)
+	}
+	ps.Reset()
+	if ps.Len() != 0 {
+		t.Fatalf("Len after Reset = %d", ps.Len())
+	}
+
+	if route, params, ok := r.Match(http.MethodGet, "/a/1/2/3/4/5/6/7/8/9/static/only"); !ok || params["p9"] != "9" || len(params) != 9 || route.Pattern == pattern {
+		t.Fatalf("Match = %v %v %v", route.Pattern, params, ok)
+	}
+}
+
+func TestMatchBacktracksBeyondStack(t *testing.T) {
+	const depth = 2 * matchStackSize
+	var static, param strings.Builder
+	for i := range depth {
+		static.WriteString("/s")
+		fmt.Fprintf(&param, "/{p%d}", i)
+	}
+	r := New()
+	r.Get(static.String()+"/y", func(w http.ResponseWriter, req *http.Request) {})
+	r.Get(param.String()+"/x", func(w http.ResponseWriter, req *http.Request) {})
+	r.MustCompile()
+
+	// The static chain fails at its last segment; the parameter chain is then
+	// followed, and for /z unwound, twice as deep as the preallocated stack.
+	route, params, ok := r.Match(http.MethodGet, static.String()+"/x")
+	if !ok || route.Pattern != param.String()+"/x" || len(params) != depth || params[fmt.Sprintf("p%d", depth-1)] != "s" {
+		t.Fatalf("Match = %q, %d params, %v", route.Pattern, len(params), ok)
+	}
+	if _, _, ok := r.Match(http.MethodGet, static.String()+"/z"); ok {
+		t.Fatal("unexpected match for /z")
+	}
+}
+
+func TestLongestCommonPrefix(t *testing.T) {
+	base := "/api/v1/organizations/settings"
+	for i := 0; i <= len(base); i++ {
+		for _, tail := range []string{"", "x", "/more/segments/here"} {
+			a := base + tail
+			b := base[:i] + "#" + base[i:]
+			if got := longestCommonPrefix(a, b); got != i {
+				t.Fatalf("longestCommonPrefix(%q, %q) = %d, want %d", a, b, got, i)
+			}
+			if got := longestCommonPrefix(a, a[:i]); got != i {
+				t.Fatalf("longestCommonPrefix(%q, %q) = %d, want %d", a, a[:i], got, i)
+			}
+		}
 	}
 }
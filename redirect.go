@@ -0,0 +1,133 @@
+package saruta
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+type redirectRule struct {
+	target string
+	code   int
+}
+
+// pathTemplate is a target string with {name} / {name...} placeholders that
+// are filled from captured path values.
+type pathTemplate struct {
+	literals []string
+	names    []string
+}
+
+// Redirect registers a declarative redirect from pattern to target.
+//
+// Placeholders in target ({id} or {rest...}) are replaced with the values
+// captured by pattern, and the original query string is preserved unless
+// target has its own. The redirect answers GET and HEAD requests. Validation
+// of the target and status code is deferred until Compile.
+func (r *Router) Redirect(pattern, target string, code int) {
+	r.checkFrozen("Redirect")
+	rule := &redirectRule{target: target, code: code}
+	source := callerSource()
+	for _, method := range []string{http.MethodGet, http.MethodHead} {
+		r.state.routes = append(r.state.routes, registeredRoute{
+			method:     method,
+			pattern:    r.prefix + pattern,
+			redirect:   rule,
+			middleware: append([]middlewareEntry(nil), r.middleware...),
+			meta:       r.meta,
+			source:     source,
+		})
+	}
+}
+
+func (rr *redirectRule) compile(pattern string, cp compiledPattern) (http.Handler, error) {
+	switch rr.code {
+	case http.StatusMovedPermanently, http.StatusFound, http.StatusSeeOther, http.StatusTemporaryRedirect, http.StatusPermanentRedirect:
+	default:
+		return nil, fmt.Errorf("invalid redirect %s: unsupported status code %d", pattern, rr.code)
+	}
+	tmpl, err := parsePathTemplate(rr.target, cp)
+	if err != nil {
+		return nil, fmt.Errorf("invalid redirect %s: %w", pattern, err)
+	}
+	code := rr.code
+	hasQuery := strings.Contains(rr.target, "?")
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		loc := tmpl.expand(func(name string) string { return Param(req, name) }, escapePathValue)
+		loc = localLocation(loc)
+		if !hasQuery && req.URL.RawQuery != "" {
+			loc += "?" + req.URL.RawQuery
+		}
+		w.Header().Set("Location", loc)
+		w.WriteHeader(code)
+	}), nil
+}
+
+func parsePathTemplate(target string, cp compiledPattern) (*pathTemplate, error) {
+	if target == "" {
+		return nil, fmt.Errorf("empty target")
+	}
+	known := make(map[string]bool)
+	for _, name := range cp.paramNames() {
+		known[name] = true
+	}
+
+	tmpl := &pathTemplate{}
+	last := 0
+	for i := 0; i < len(target); i++ {
+		switch target[i] {
+		case '}':
+			return nil, fmt.Errorf("invalid target syntax %q", target)
+		case '{':
+			j := strings.IndexByte(target[i+1:], '}')
+			if j < 0 {
+				return nil, fmt.Errorf("invalid target syntax %q", target)
+			}
+			j = i + 1 + j
+			name := strings.TrimSuffix(target[i+1:j], "...")
+			if err := validateParamName(name); err != nil {
+				return nil, err
+			}
+			if !known[name] {
+				return nil, fmt.Errorf("target parameter {%s} is not captured by the pattern", name)
+			}
+			tmpl.literals = append(tmpl.literals, target[last:i])
+			tmpl.names = append(tmpl.names, name)
+			i = j
+			last = j + 1
+		}
+	}
+	tmpl.literals = append(tmpl.literals, target[last:])
+	return tmpl, nil
+}
+
+func (t *pathTemplate) expand(value func(string) string, escape func(string) string) string {
+	var b strings.Builder
+	for i, name := range t.names {
+		b.WriteString(t.literals[i])
+		v := value(name)
+		if escape != nil {
+			v = escape(v)
+		}
+		b.WriteString(v)
+	}
+	b.WriteString(t.literals[len(t.literals)-1])
+	return b.String()
+}
+
+// escapePathValue escapes a captured value for use in a URL path while
+// keeping the slashes captured by catch-all parameters.
+func escapePathValue(v string) string {
+	return strings.ReplaceAll(url.PathEscape(v), "%2F", "/")
+}
+
+// localLocation collapses the leading slashes of a path-relative location,
+// so that a captured value such as "/evil.com" cannot turn "/{rest}" into
+// the protocol-relative "//evil.com". Browsers also read "/\" as "//".
+func localLocation(loc string) string {
+	if len(loc) < 2 || loc[0] != '/' || (loc[1] != '/' && loc[1] != '\\') {
+		return loc
+	}
+	return "/" + strings.TrimLeft(loc, "/\\")
+}
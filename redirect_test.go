@@ -0,0 +1,87 @@
+package saruta
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRouterRedirect(t *testing.T) {
+	r := New()
+	r.Redirect("/old/{id}", "/new/{id}", http.StatusMovedPermanently)
+	r.Redirect("/docs/{path...}", "https://docs.example.com/{path}", http.StatusFound)
+	r.Redirect("/search", "/find?q=all", http.StatusSeeOther)
+	r.MustCompile()
+
+	for _, tc := range []struct {
+		method string
+		path   string
+		code   int
+		want   string
+	}{
+		{method: http.MethodGet, path: "/old/42", code: http.StatusMovedPermanently, want: "/new/42"},
+		{method: http.MethodHead, path: "/old/42?x=1", code: http.StatusMovedPermanently, want: "/new/42?x=1"},
+		{method: http.MethodGet, path: "/old/a%20b", code: http.StatusMovedPermanently, want: "/new/a%20b"},
+		{method: http.MethodGet, path: "/docs/a/b.html", code: http.StatusFound, want: "https://docs.example.com/a/b.html"},
+		{method: http.MethodGet, path: "/search?x=1", code: http.StatusSeeOther, want: "/find?q=all"},
+	} {
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, httptest.NewRequest(tc.method, tc.path, nil))
+		if rec.Code != tc.code {
+			t.Fatalf("%s %s status = %d, want %d", tc.method, tc.path, rec.Code, tc.code)
+		}
+		if got := rec.Header().Get("Location"); got != tc.want {
+			t.Fatalf("%s %s Location = %q, want %q", tc.method, tc.path, got, tc.want)
+		}
+	}
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/old/42", nil))
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("POST status = %d, want %d", rec.Code, http.StatusMethodNotAllowed)
+	}
+}
+
+func TestRouterRedirectStaysLocal(t *testing.T) {
+	r := New()
+	r.Redirect("/old/{rest...}", "/{rest}", http.StatusMovedPermanently)
+	r.Redirect("/back/{rest...}", "/\\{rest}", http.StatusMovedPermanently)
+	r.MustCompile()
+
+	for _, tc := range []struct {
+		path string
+		want string
+	}{
+		{path: "/old/a/b", want: "/a/b"},
+		{path: "/old//evil.com", want: "/evil.com"},
+		{path: "/old///evil.com/x?q=1", want: "/evil.com/x?q=1"},
+		{path: "/old/%5Cevil.com", want: "/%5Cevil.com"},
+		{path: "/back/evil.com", want: "/evil.com"},
+	} {
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tc.path, nil))
+		if got := rec.Header().Get("Location"); got != tc.want {
+			t.Errorf("GET %s Location = %q, want %q", tc.path, got, tc.want)
+		}
+	}
+}
+
+func TestRouterRedirectCompileErrors(t *testing.T) {
+	for _, tc := range []struct {
+		pattern string
+		target  string
+		code    int
+	}{
+		{pattern: "/old/{id}", target: "/new/{name}", code: http.StatusMovedPermanently},
+		{pattern: "/old/{id}", target: "/new/{id", code: http.StatusMovedPermanently},
+		{pattern: "/old/{id}", target: "", code: http.StatusMovedPermanently},
+		{pattern: "/old/{id}", target: "/new/{id}", code: http.StatusOK},
+		{pattern: "old", target: "/new", code: http.StatusFound},
+	} {
+		r := New()
+		r.Redirect(tc.pattern, tc.target, tc.code)
+		if err := r.Compile(); err == nil {
+			t.Fatalf("%s -> %s (%d): expected compile error", tc.pattern, tc.target, tc.code)
+		}
+	}
+}
@@ -0,0 +1,220 @@
+package saruta
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"unsafe"
+)
+
+// Compile warning kinds.
+const (
+	// WarnShadowedMount: an unconstrained catch-all route covers a mount
+	// prefix, so the mount only sees methods the route does not handle.
+	WarnShadowedMount = "shadowed_mount"
+	// WarnCaseOnly: two route patterns differ only in letter case.
+	WarnCaseOnly = "case_only"
+	// WarnUnreachableParam was reported for routes with more than eight
+	// parameters.
+	//
+	// Deprecated: routes can capture any number of parameters and this
+	// warning is no longer reported.
+	WarnUnreachableParam = "unreachable_param"
+)
+
+// CompileWarning is a suspicious but valid setup found by Compile.
+type CompileWarning struct {
+	Kind    string `json:"kind"`
+	Pattern string `json:"pattern"`
+	Message string `json:"message"`
+}
+
+func (w CompileWarning) String() string {
+	return w.Message
+}
+
+// CompileReport describes the outcome of a successful Compile.
+type CompileReport struct {
+	// Routes is the final route table, sorted as by Routes.
+	Routes   []RouteInfo      `json:"routes"`
+	Tree     TreeStats        `json:"tree"`
+	Warnings []CompileWarning `json:"warnings,omitempty"`
+}
+
+// TreeStats describes the shape of the compiled radix tree.
+type TreeStats struct {
+	Nodes         int `json:"nodes"`
+	StaticEdges   int `json:"static_edges"`
+	ParamEdges    int `json:"param_edges"`
+	CatchAllEdges int `json:"catch_all_edges"`
+	// MaxDepth is the largest number of edges from the root to a node.
+	MaxDepth int `json:"max_depth"`
+	// LongestStaticEdge is the length in bytes of the longest static edge
+	// label. Chains of single-child static nodes are compressed into one
+	// edge, so a label spanning several segments shows compression at work.
+	LongestStaticEdge int `json:"longest_static_edge"`
+	// EstimatedBytes approximates the memory held by the tree itself: nodes,
+	// edges, labels and handler tables, excluding the handlers, middleware
+	// and matchers they point to.
+	EstimatedBytes int `json:"estimated_bytes"`
+}
+
+// Stats summarizes the compiled router for capacity planning: the number of
+// routes (one per method and pattern) and the shape and approximate size of
+// the radix tree.
+type Stats struct {
+	Routes int `json:"routes"`
+	TreeStats
+}
+
+// Stats returns the statistics of the most recent successful Compile, or the
+// zero value if the router has not been compiled.
+func (r *Router) Stats() Stats {
+	rep := r.CompileReport()
+	return Stats{Routes: len(rep.Routes), TreeStats: rep.Tree}
+}
+
+// Per-entry overhead of a Go map bucket slot, used for the handler tables.
+const mapEntryOverhead = 16
+
+func treeStats(t *radixTree, n *radixNode, depth int, st *TreeStats) {
+	st.Nodes++
+	st.MaxDepth = max(st.MaxDepth, depth)
+	st.EstimatedBytes += int(unsafe.Sizeof(*n)) + int(n.numEdges)
+	if n.index >= 0 {
+		st.EstimatedBytes += int(unsafe.Sizeof(t.indexes[n.index]))
+	}
+	if n.leaf != 0 {
+		leaf := t.leaf(n)
+		st.EstimatedBytes += int(unsafe.Sizeof(*leaf)) + len(leaf.pattern)
+		if leaf.handlers != nil {
+			st.EstimatedBytes += int(unsafe.Sizeof(*leaf.handlers))
+			st.EstimatedBytes += len(leaf.handlers.custom) * (int(unsafe.Sizeof("")) + int(unsafe.Sizeof(http.Handler(nil))) + mapEntryOverhead)
+		}
+		st.EstimatedBytes += len(leaf.routes) * (int(unsafe.Sizeof("")) + int(unsafe.Sizeof((*RouteInfo)(nil))) + mapEntryOverhead)
+	}
+	for _, e := range t.staticEdges(n) {
+		label := t.label(e)
+		st.StaticEdges++
+		st.LongestStaticEdge = max(st.LongestStaticEdge, len(label))
+		st.EstimatedBytes += int(unsafe.Sizeof(e)) + len(label)
+		treeStats(t, t.node(e.next), depth+1, st)
+	}
+	if pe := t.paramChild(n); pe != nil {
+		st.ParamEdges++
+		st.EstimatedBytes += paramEdgeBytes(pe)
+		treeStats(t, t.node(pe.next), depth+1, st)
+	}
+	if pe := t.catchAllChild(n); pe != nil {
+		st.CatchAllEdges++
+		st.EstimatedBytes += paramEdgeBytes(pe)
+		treeStats(t, t.node(pe.next), depth+1, st)
+	}
+}
+
+func paramEdgeBytes(pe *radixParamEdge) int {
+	return int(unsafe.Sizeof(*pe)) + len(pe.name) + len(pe.expr) + len(pe.prefix) + len(pe.suffix)
+}
+
+// OnCompile registers fn to be called after every successful Compile, in
+// registration order, with the report of that compile. Hooks run
+// synchronously on the goroutine calling Compile, after the new tree is
+// being served; use them to refresh metrics gauges, log the route table or
+// export API descriptions on each (re)compile.
+func (r *Router) OnCompile(fn func(CompileReport)) {
+	r.checkFrozen("OnCompile")
+	r.state.onCompile = append(r.state.onCompile, fn)
+}
+
+// Err returns the warnings joined into one error, or nil if there are none,
+// for setups (CI, tests) that treat warnings as failures:
+//
+//	r.MustCompile()
+//	if err := r.CompileReport().Err(); err != nil {
+//		t.Fatal(err)
+//	}
+func (rep CompileReport) Err() error {
+	errs := make([]error, len(rep.Warnings))
+	for i, w := range rep.Warnings {
+		errs[i] = errors.New(w.Message)
+	}
+	return errors.Join(errs...)
+}
+
+// CompileReport returns the report of the most recent successful Compile. It
+// is empty if r has not been compiled.
+func (r *Router) CompileReport() CompileReport {
+	c := r.state.current.Load()
+	if c == nil {
+		return CompileReport{}
+	}
+	return c.report
+}
+
+func compileWarnings(reg registrations) []CompileWarning {
+	var warnings []CompileWarning
+	seen := make(map[string]bool, len(reg.routes))
+	lower := make(map[string]string, len(reg.routes))
+	for _, rt := range reg.routes {
+		if seen[rt.pattern] {
+			continue
+		}
+		seen[rt.pattern] = true
+		cp, err := compilePattern(rt.pattern)
+		if err != nil {
+			continue
+		}
+
+		key := strings.ToLower(rt.pattern)
+		if other, ok := lower[key]; ok {
+			warnings = append(warnings, CompileWarning{
+				Kind:    WarnCaseOnly,
+				Pattern: rt.pattern,
+				Message: fmt.Sprintf("routes %s and %s differ only in case", other, rt.pattern),
+			})
+		} else {
+			lower[key] = rt.pattern
+		}
+
+		prefix, ok := catchAllPrefix(cp)
+		if !ok {
+			continue
+		}
+		for _, mt := range reg.mounts {
+			if mt.precedence == MountBeforeRoutes {
+				continue
+			}
+			mp := strings.TrimSuffix(mt.prefix, "/")
+			if mp == prefix || strings.HasPrefix(mp, prefix+"/") {
+				warnings = append(warnings, CompileWarning{
+					Kind:    WarnShadowedMount,
+					Pattern: mt.prefix,
+					Message: fmt.Sprintf("mount %s is shadowed by catch-all route %s %s", mt.prefix, rt.method, rt.pattern),
+				})
+			}
+		}
+	}
+	return warnings
+}
+
+// catchAllPrefix returns the static path in front of an unconstrained
+// catch-all that ends cp ("" for /{path...}).
+func catchAllPrefix(cp compiledPattern) (string, bool) {
+	n := len(cp.segments)
+	if n == 0 {
+		return "", false
+	}
+	last := cp.segments[n-1]
+	if last.kind != segmentCatchAll || last.expr != "" {
+		return "", false
+	}
+	var b strings.Builder
+	for _, seg := range cp.segments[:n-1] {
+		if seg.kind != segmentStatic {
+			return "", false
+		}
+		b.WriteString("/" + seg.literal)
+	}
+	return b.String(), true
+}
@@ -0,0 +1,138 @@
+package saruta
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"unsafe"
+)
+
+func TestCompileReportWarnings(t *testing.T) {
+	noop := func(w http.ResponseWriter, req *http.Request) {}
+	r := New()
+	if rep := r.CompileReport(); len(rep.Warnings) != 0 {
+		t.Fatalf("report before Compile = %+v", rep)
+	}
+	r.Get("/assets/{path...}", noop)
+	r.Mount("/assets/legacy", http.NotFoundHandler())
+	r.Mount("/assets/first", http.NotFoundHandler(), WithMountPrecedence(MountBeforeRoutes))
+	r.Mount("/other", http.NotFoundHandler())
+	r.Get("/Users", noop)
+	r.Get("/users", noop)
+	r.Post("/users", noop)
+	r.Get("/many/{a}/{b}/{c}/{d}/{e}/{f}/{g}/{h}/{i}", noop)
+	r.MustCompile()
+
+	rep := r.CompileReport()
+	got := map[string]string{}
+	for _, w := range rep.Warnings {
+		if _, dup := got[w.Kind+" "+w.Pattern]; dup {
+			t.Fatalf("duplicate warning %+v", w)
+		}
+		got[w.Kind+" "+w.Pattern] = w.Message
+	}
+	want := map[string]string{
+		WarnShadowedMount + " /assets/legacy": "mount /assets/legacy is shadowed by catch-all route GET /assets/{path...}",
+		WarnCaseOnly + " /users":              "routes /Users and /users differ only in case",
+	}
+	if len(got) != len(want) {
+		t.Fatalf("warnings = %v", rep.Warnings)
+	}
+	for k, msg := range want {
+		if got[k] != msg {
+			t.Fatalf("warning %s = %q, want %q", k, got[k], msg)
+		}
+	}
+
+	err := rep.Err()
+	if err == nil || !strings.Contains(err.Error(), "differ only in case") {
+		t.Fatalf("Err() = %v", err)
+	}
+}
+
+func TestCompileReportClean(t *testing.T) {
+	r := New()
+	r.Get("/{path...}", func(w http.ResponseWriter, req *http.Request) {})
+	r.Get("/users/{id:[0-9]+}", func(w http.ResponseWriter, req *http.Request) {})
+	r.MustCompile()
+	if err := r.CompileReport().Err(); err != nil {
+		t.Fatalf("Err() = %v, want nil", err)
+	}
+}
+
+func TestRouterOnCompile(t *testing.T) {
+	noop := func(w http.ResponseWriter, req *http.Request) {}
+	r := New()
+	r.WithMeta("team", "accounts").Get("/users/{id}", noop)
+	r.Get("/users", noop)
+	r.Get("/files/{path...}", noop)
+
+	var reports []CompileReport
+	r.OnCompile(func(rep CompileReport) { reports = append(reports, rep) })
+	r.MustCompile()
+
+	if len(reports) != 1 {
+		t.Fatalf("hook called %d times, want 1", len(reports))
+	}
+	rep := reports[0]
+	var patterns []string
+	for _, rt := range rep.Routes {
+		patterns = append(patterns, rt.Method+" "+rt.Pattern)
+	}
+	if got, want := strings.Join(patterns, ","), "GET /files/{path...},GET /users,GET /users/{id}"; got != want {
+		t.Fatalf("routes = %s, want %s", got, want)
+	}
+	if rep.Routes[2].Meta["team"] != "accounts" {
+		t.Fatalf("meta = %v", rep.Routes[2].Meta)
+	}
+	if rep.Tree.Nodes == 0 || rep.Tree.ParamEdges != 1 || rep.Tree.CatchAllEdges != 1 || rep.Tree.MaxDepth < 2 {
+		t.Fatalf("tree stats = %+v", rep.Tree)
+	}
+
+	r.Get("/health", noop)
+	r.Get("/a/{x}", noop)
+	r.Get("/a/{y}", noop) // conflict: the failed compile must not call hooks
+	if err := r.Compile(); err == nil {
+		t.Fatal("expected compile error")
+	}
+	if len(reports) != 1 {
+		t.Fatalf("hook called after failed compile")
+	}
+	if !r.Remove(http.MethodGet, "/a/{y}") {
+		t.Fatal("Remove failed")
+	}
+	r.MustCompile()
+	if len(reports) != 2 || len(reports[1].Routes) != 5 {
+		t.Fatalf("reports = %d, routes = %d", len(reports), len(reports[len(reports)-1].Routes))
+	}
+}
+
+func TestRouterStats(t *testing.T) {
+	r := New()
+	if got := r.Stats(); got != (Stats{}) {
+		t.Fatalf("uncompiled Stats = %+v, want zero", got)
+	}
+	noop := func(w http.ResponseWriter, req *http.Request) {}
+	r.Get("/api/v1/users", noop)
+	r.Post("/api/v1/users", noop)
+	r.Get("/api/v1/users/{id}", noop)
+	r.Get("/files/{path...}", noop)
+	r.MustCompile()
+
+	st := r.Stats()
+	if st.Routes != 4 {
+		t.Fatalf("Routes = %d, want 4", st.Routes)
+	}
+	if st.TreeStats != r.CompileReport().Tree {
+		t.Fatalf("TreeStats = %+v, want %+v", st.TreeStats, r.CompileReport().Tree)
+	}
+	if st.ParamEdges != 1 || st.CatchAllEdges != 1 {
+		t.Fatalf("param/catch-all edges = %d/%d, want 1/1", st.ParamEdges, st.CatchAllEdges)
+	}
+	if st.LongestStaticEdge < len("api/v1/users") {
+		t.Fatalf("LongestStaticEdge = %d, want the compressed /api/v1/users chain", st.LongestStaticEdge)
+	}
+	if st.EstimatedBytes <= st.Nodes*int(unsafe.Sizeof(radixNode{}))+st.StaticEdges*int(unsafe.Sizeof(radixStaticEdge{})) {
+		t.Fatalf("EstimatedBytes = %d for %d nodes", st.EstimatedBytes, st.Nodes)
+	}
+}
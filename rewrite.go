@@ -0,0 +1,73 @@
+package saruta
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+type registeredRewrite struct {
+	pattern string
+	target  string
+}
+
+// Rewrite registers an internal rewrite rule.
+//
+// Requests whose path matches pattern are routed as if their path were target,
+// without an external redirect. Placeholders in target ({rest} or {rest...})
+// are replaced with the values captured by pattern. Rewrites run once, before
+// route and mount lookup, and update req.URL.Path. Validation is deferred
+// until Compile.
+func (r *Router) Rewrite(pattern, target string) {
+	r.checkFrozen("Rewrite")
+	r.state.rewrites = append(r.state.rewrites, registeredRewrite{
+		pattern: r.prefix + pattern,
+		target:  r.prefix + target,
+	})
+}
+
+func compileRewrites(rewrites []registeredRewrite) (*radixTree, error) {
+	if len(rewrites) == 0 {
+		return nil, nil
+	}
+	root := newNode()
+	for _, rw := range rewrites {
+		cp, err := compilePattern(rw.pattern)
+		if err != nil {
+			return nil, err
+		}
+		if rw.target == "" || rw.target[0] != '/' || strings.ContainsAny(rw.target, "?#") {
+			return nil, fmt.Errorf("invalid rewrite %s: target %q must be a path", rw.pattern, rw.target)
+		}
+		tmpl, err := parsePathTemplate(rw.target, cp)
+		if err != nil {
+			return nil, fmt.Errorf("invalid rewrite %s: %w", rw.pattern, err)
+		}
+		if err := root.insertRewrite(rw.pattern, cp, tmpl); err != nil {
+			return nil, err
+		}
+	}
+	return buildRadix(root), nil
+}
+
+// rewritePath applies the first matching rewrite rule to req and returns the
+// path to route.
+func rewritePath(rewrites *radixTree, req *http.Request, path string) string {
+	next, ok := rewriteTarget(rewrites, path)
+	if !ok {
+		return path
+	}
+	req.URL.Path = next
+	req.URL.RawPath = ""
+	return next
+}
+
+// rewriteTarget returns the rewritten path for path, if a rewrite matches.
+func rewriteTarget(rewrites *radixTree, path string) (string, bool) {
+	matched, ok := rewrites.matchRoute(path)
+	if !ok || matched.leaf.rewrite == nil {
+		return "", false
+	}
+	defer matched.params.release()
+	return matched.leaf.rewrite.expand(matched.params.get, nil), true
+}
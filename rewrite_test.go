@@ -0,0 +1,70 @@
+package saruta
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRouterRewrite(t *testing.T) {
+	r := New()
+	r.Rewrite("/v1/{rest...}", "/v2/{rest}")
+	r.Rewrite("/legacy/users/{id}", "/users/{id}")
+	r.Get("/v2/items/{id}", func(w http.ResponseWriter, req *http.Request) {
+		_, _ = w.Write([]byte(req.URL.Path + " id=" + req.PathValue("id")))
+	})
+	r.Get("/users/{id}", func(w http.ResponseWriter, req *http.Request) {
+		_, _ = w.Write([]byte("user=" + req.PathValue("id")))
+	})
+	r.Mount("/v2/static", http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		_, _ = w.Write([]byte("mount:" + req.URL.Path))
+	}))
+	r.MustCompile()
+
+	for _, tc := range []struct {
+		path string
+		want string
+	}{
+		{path: "/v1/items/9", want: "/v2/items/9 id=9"},
+		{path: "/v2/items/9", want: "/v2/items/9 id=9"},
+		{path: "/legacy/users/3", want: "user=3"},
+		{path: "/v1/static/app.js", want: "mount:/v2/static/app.js"},
+	} {
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tc.path, nil))
+		if got := rec.Body.String(); got != tc.want {
+			t.Fatalf("%s body = %q, want %q", tc.path, got, tc.want)
+		}
+	}
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v1", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("/v1 status = %d, want %d", rec.Code, http.StatusNotFound)
+	}
+}
+
+func TestRouterRewriteCompileErrors(t *testing.T) {
+	for _, tc := range []struct {
+		pattern string
+		target  string
+	}{
+		{pattern: "/v1/{rest...}", target: "/v2/{other}"},
+		{pattern: "/v1/{rest...}", target: "v2/{rest}"},
+		{pattern: "/v1/{rest...}", target: "/v2/{rest}?x=1"},
+		{pattern: "v1", target: "/v2"},
+	} {
+		r := New()
+		r.Rewrite(tc.pattern, tc.target)
+		if err := r.Compile(); err == nil {
+			t.Fatalf("%s -> %s: expected compile error", tc.pattern, tc.target)
+		}
+	}
+
+	r := New()
+	r.Rewrite("/a/{x}", "/b/{x}")
+	r.Rewrite("/a/{x}", "/c/{x}")
+	if err := r.Compile(); err == nil {
+		t.Fatalf("expected duplicate rewrite error")
+	}
+}
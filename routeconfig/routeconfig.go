@@ -0,0 +1,243 @@
+// Package routeconfig registers saruta routes described as data: a JSON (or
+// YAML) file lists patterns, methods and the names of handlers and
+// middleware, which are looked up in a Registry filled by the application.
+//
+//	reg := routeconfig.NewRegistry()
+//	reg.Handler("users.show", showUser)
+//	reg.Middleware("auth", requireAuth)
+//	if err := routeconfig.Load(r, data, reg); err != nil { ... }
+//	r.MustCompile()
+//
+// Load only checks that the file is well formed and that every name is
+// registered; patterns and methods are validated by Compile like any other
+// route.
+package routeconfig
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"math"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/catatsuy/saruta"
+	"github.com/catatsuy/saruta/middleware"
+)
+
+// File is a route file:
+//
+//	{
+//	  "routes": [
+//	    {
+//	      "pattern": "/users/{id}",
+//	      "methods": ["GET", "HEAD"],
+//	      "handler": "users.show",
+//	      "middleware": ["auth"],
+//	      "meta": {"owner": "accounts"}
+//	    }
+//	  ]
+//	}
+type File struct {
+	Routes []Route `json:"routes" yaml:"routes"`
+}
+
+// Route is one entry of a File. Middleware runs in the listed order, after
+// the router's own middleware. Meta is attached as with Router.WithMeta.
+//
+// The metadata keys saruta and its middleware read are converted to the
+// types they expect: sizes and priorities (max_body_size, drain_priority)
+// from whole numbers, durations (cors_max_age, latency_budget) from strings
+// such as "10m" or numbers of seconds, and lists (early_hints, scopes) from
+// arrays of strings. A value that does not convert fails Register, as do
+// keys whose values cannot be written in a file (protocols, deprecated).
+type Route struct {
+	Pattern    string         `json:"pattern" yaml:"pattern"`
+	Methods    []string       `json:"methods" yaml:"methods"`
+	Handler    string         `json:"handler" yaml:"handler"`
+	Middleware []string       `json:"middleware,omitempty" yaml:"middleware,omitempty"`
+	Meta       map[string]any `json:"meta,omitempty" yaml:"meta,omitempty"`
+}
+
+// Registry maps the names used in a File to handlers and middleware.
+// Fill it before loading; it is not safe for concurrent registration.
+type Registry struct {
+	handlers   map[string]http.Handler
+	middleware map[string]saruta.Middleware
+}
+
+// NewRegistry returns an empty Registry.
+func NewRegistry() *Registry {
+	return &Registry{
+		handlers:   make(map[string]http.Handler),
+		middleware: make(map[string]saruta.Middleware),
+	}
+}
+
+// Handler registers h as name.
+func (g *Registry) Handler(name string, h http.Handler) {
+	g.handlers[name] = h
+}
+
+// HandlerFunc registers h as name.
+func (g *Registry) HandlerFunc(name string, h http.HandlerFunc) {
+	g.Handler(name, h)
+}
+
+// Middleware registers mw as name.
+func (g *Registry) Middleware(name string, mw saruta.Middleware) {
+	g.middleware[name] = mw
+}
+
+// Parse decodes a JSON route file. Unknown fields are rejected, so that a
+// misspelled key fails instead of being silently ignored.
+func Parse(data []byte) (*File, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	var f File
+	if err := dec.Decode(&f); err != nil {
+		return nil, fmt.Errorf("routeconfig: %w", err)
+	}
+	return &f, nil
+}
+
+// Decode decodes a route file with unmarshal, such as yaml.Unmarshal from a
+// YAML package of the application's choice; this package depends on the
+// standard library only.
+func Decode(data []byte, unmarshal func([]byte, any) error) (*File, error) {
+	var f File
+	if err := unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("routeconfig: %w", err)
+	}
+	return &f, nil
+}
+
+// Load parses the JSON route file data and registers its routes on r.
+func Load(r *saruta.Router, data []byte, reg *Registry) error {
+	f, err := Parse(data)
+	if err != nil {
+		return err
+	}
+	return f.Register(r, reg)
+}
+
+// Register registers the routes of f on r. It checks every route before
+// registering any, so a file with an unknown name leaves r unchanged.
+func (f *File) Register(r *saruta.Router, reg *Registry) error {
+	type resolved struct {
+		route   Route
+		handler http.Handler
+		mws     []saruta.Middleware
+	}
+	routes := make([]resolved, 0, len(f.Routes))
+	for i, rt := range f.Routes {
+		if len(rt.Methods) == 0 {
+			return fmt.Errorf("routeconfig: route %d (%s): no methods", i, rt.Pattern)
+		}
+		meta := make(map[string]any, len(rt.Meta))
+		for k, v := range rt.Meta {
+			tv, err := typedMeta(k, v)
+			if err != nil {
+				return fmt.Errorf("routeconfig: route %d (%s): meta %q: %w", i, rt.Pattern, k, err)
+			}
+			meta[k] = tv
+		}
+		rt.Meta = meta
+		h, ok := reg.handlers[rt.Handler]
+		if !ok {
+			return fmt.Errorf("routeconfig: route %d (%s): unknown handler %q", i, rt.Pattern, rt.Handler)
+		}
+		mws := make([]saruta.Middleware, 0, len(rt.Middleware))
+		for _, name := range rt.Middleware {
+			mw, ok := reg.middleware[name]
+			if !ok {
+				return fmt.Errorf("routeconfig: route %d (%s): unknown middleware %q", i, rt.Pattern, name)
+			}
+			mws = append(mws, mw)
+		}
+		routes = append(routes, resolved{route: rt, handler: h, mws: mws})
+	}
+
+	for _, rt := range routes {
+		sub := r.With(rt.mws...)
+		for _, k := range slices.Sorted(maps.Keys(rt.route.Meta)) {
+			sub = sub.WithMeta(k, rt.route.Meta[k])
+		}
+		for _, method := range rt.route.Methods {
+			sub.Handle(method, rt.route.Pattern, rt.handler)
+		}
+	}
+	return nil
+}
+
+// typedMeta converts the value of a known metadata key from its decoded
+// form to the type its reader expects. Other keys are returned unchanged.
+func typedMeta(key string, v any) (any, error) {
+	switch key {
+	case saruta.MetaMaxBodySize:
+		return metaInt(v)
+	case saruta.MetaDrainPriority:
+		n, err := metaInt(v)
+		return int(n), err
+	case saruta.MetaCORSMaxAge, middleware.MetaLatencyBudget:
+		return metaDuration(v)
+	case saruta.MetaEarlyHints, middleware.MetaScopes:
+		return metaStrings(v)
+	case saruta.MetaProtocols, saruta.MetaDeprecated:
+		return nil, fmt.Errorf("must be set in code")
+	}
+	return v, nil
+}
+
+func metaInt(v any) (int64, error) {
+	switch n := v.(type) {
+	case int:
+		return int64(n), nil
+	case int64:
+		return n, nil
+	case uint64:
+		if n <= math.MaxInt64 {
+			return int64(n), nil
+		}
+	case float64:
+		if n == math.Trunc(n) && math.Abs(n) < 1<<63 {
+			return int64(n), nil
+		}
+	}
+	return 0, fmt.Errorf("%v is not a whole number", v)
+}
+
+func metaDuration(v any) (time.Duration, error) {
+	if s, ok := v.(string); ok {
+		return time.ParseDuration(s)
+	}
+	switch n := v.(type) {
+	case int, int64, uint64, float64:
+		secs, err := metaInt(n)
+		if err != nil || secs > math.MaxInt64/int64(time.Second) || secs < math.MinInt64/int64(time.Second) {
+			break
+		}
+		return time.Duration(secs) * time.Second, nil
+	}
+	return 0, fmt.Errorf("%v is not a duration or a number of seconds", v)
+}
+
+func metaStrings(v any) ([]string, error) {
+	switch list := v.(type) {
+	case []string:
+		return list, nil
+	case []any:
+		out := make([]string, len(list))
+		for i, e := range list {
+			s, ok := e.(string)
+			if !ok {
+				return nil, fmt.Errorf("element %d (%v) is not a string", i, e)
+			}
+			out[i] = s
+		}
+		return out, nil
+	}
+	return nil, fmt.Errorf("%v is not a list of strings", v)
+}
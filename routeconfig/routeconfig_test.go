@@ -0,0 +1,171 @@
+package routeconfig_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/catatsuy/saruta"
+	"github.com/catatsuy/saruta/routeconfig"
+)
+
+const routes = `{
+  "routes": [
+    {"pattern": "/users/{id}", "methods": ["GET", "HEAD"], "handler": "users.show", "middleware": ["tag"], "meta": {"owner": "accounts"}},
+    {"pattern": "/health", "methods": ["GET"], "handler": "health"}
+  ]
+}`
+
+func newRegistry() *routeconfig.Registry {
+	reg := routeconfig.NewRegistry()
+	reg.HandlerFunc("users.show", func(w http.ResponseWriter, req *http.Request) {
+		w.Write([]byte("user " + req.PathValue("id")))
+	})
+	reg.HandlerFunc("health", func(w http.ResponseWriter, req *http.Request) {
+		w.Write([]byte("ok"))
+	})
+	reg.Middleware("tag", func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			w.Header().Set("X-Tag", "1")
+			next.ServeHTTP(w, req)
+		})
+	})
+	return reg
+}
+
+func TestLoad(t *testing.T) {
+	r := saruta.New()
+	if err := routeconfig.Load(r, []byte(routes), newRegistry()); err != nil {
+		t.Fatal(err)
+	}
+	r.MustCompile()
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/users/42", nil))
+	if rec.Body.String() != "user 42" || rec.Header().Get("X-Tag") != "1" {
+		t.Errorf("GET /users/42 = %q, X-Tag %q", rec.Body, rec.Header().Get("X-Tag"))
+	}
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/health", nil))
+	if rec.Body.String() != "ok" || rec.Header().Get("X-Tag") != "" {
+		t.Errorf("GET /health = %q, X-Tag %q", rec.Body, rec.Header().Get("X-Tag"))
+	}
+
+	var methods []string
+	for _, info := range r.Routes() {
+		if info.Pattern == "/users/{id}" {
+			methods = append(methods, info.Method)
+			if info.Meta["owner"] != "accounts" {
+				t.Errorf("%s %s meta = %v", info.Method, info.Pattern, info.Meta)
+			}
+		}
+	}
+	if strings.Join(methods, ",") != "GET,HEAD" {
+		t.Errorf("methods = %v", methods)
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	tests := []struct {
+		name, data, want string
+	}{
+		{"syntax", `{"routes": [`, "unexpected EOF"},
+		{"unknown field", `{"routes": [{"path": "/x"}]}`, `unknown field "path"`},
+		{"no methods", `{"routes": [{"pattern": "/x", "handler": "health"}]}`, "no methods"},
+		{"unknown handler", `{"routes": [{"pattern": "/x", "methods": ["GET"], "handler": "nope"}]}`, `unknown handler "nope"`},
+		{"unknown middleware", `{"routes": [{"pattern": "/x", "methods": ["GET"], "handler": "health", "middleware": ["nope"]}]}`, `unknown middleware "nope"`},
+	}
+	for _, tc := range tests {
+		r := saruta.New()
+		err := routeconfig.Load(r, []byte(tc.data), newRegistry())
+		if err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("%s: err = %v, want %q", tc.name, err, tc.want)
+		}
+		if len(r.Routes()) != 0 {
+			t.Errorf("%s: registered %d routes", tc.name, len(r.Routes()))
+		}
+	}
+}
+
+func TestLoadTypedMeta(t *testing.T) {
+	data := `{"routes": [{"pattern": "/upload", "methods": ["POST"], "handler": "health", "meta": {
+		"max_body_size": 4,
+		"drain_priority": 2,
+		"cors_max_age": "10m",
+		"latency_budget": 2,
+		"early_hints": ["</app.css>; rel=preload; as=style"],
+		"scopes": ["files:write"],
+		"owner": "storage"
+	}}]}`
+	r := saruta.New()
+	if err := routeconfig.Load(r, []byte(data), newRegistry()); err != nil {
+		t.Fatal(err)
+	}
+	r.MustCompile()
+
+	want := saruta.Meta{
+		"max_body_size":  int64(4),
+		"drain_priority": 2,
+		"cors_max_age":   10 * time.Minute,
+		"latency_budget": 2 * time.Second,
+		"early_hints":    []string{"</app.css>; rel=preload; as=style"},
+		"scopes":         []string{"files:write"},
+		"owner":          "storage",
+	}
+	if got := r.Routes()[0].Meta; !reflect.DeepEqual(got, want) {
+		t.Errorf("meta = %#v, want %#v", got, want)
+	}
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/upload", strings.NewReader("too large")))
+	if rec.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusRequestEntityTooLarge)
+	}
+
+	for _, meta := range []string{
+		`{"max_body_size": "1MB"}`,
+		`{"max_body_size": 1.5}`,
+		`{"cors_max_age": "soon"}`,
+		`{"early_hints": "</app.css>"}`,
+		`{"scopes": ["a", 1]}`,
+		`{"protocols": {}}`,
+	} {
+		data := `{"routes": [{"pattern": "/x", "methods": ["GET"], "handler": "health", "meta": ` + meta + `}]}`
+		r := saruta.New()
+		if err := routeconfig.Load(r, []byte(data), newRegistry()); err == nil || !strings.Contains(err.Error(), "meta") {
+			t.Errorf("meta %s: err = %v, want a meta error", meta, err)
+		}
+		if len(r.Routes()) != 0 {
+			t.Errorf("meta %s: registered %d routes", meta, len(r.Routes()))
+		}
+	}
+}
+
+func TestLoadInvalidPatternFailsCompile(t *testing.T) {
+	r := saruta.New()
+	data := `{"routes": [{"pattern": "/users/{id", "methods": ["GET"], "handler": "health"}]}`
+	if err := routeconfig.Load(r, []byte(data), newRegistry()); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Compile(); err == nil {
+		t.Error("Compile succeeded for an invalid pattern")
+	}
+}
+
+func TestDecode(t *testing.T) {
+	f, err := routeconfig.Decode([]byte(routes), json.Unmarshal)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(f.Routes) != 2 || f.Routes[0].Handler != "users.show" {
+		t.Fatalf("routes = %+v", f.Routes)
+	}
+	r := saruta.New()
+	if err := f.Register(r, newRegistry()); err != nil {
+		t.Fatal(err)
+	}
+	r.MustCompile()
+}
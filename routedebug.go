@@ -0,0 +1,183 @@
+//go:build !tinygo
+
+package saruta
+
+import (
+	"cmp"
+	_ "embed"
+	"fmt"
+	"html/template"
+	"net/http"
+	"reflect"
+	"runtime"
+	"slices"
+	"strings"
+)
+
+// MountRouteDebug registers GET pattern (typically "/debug/routes") serving
+// the compiled route table: one row per pattern with its methods, the names
+// of the middleware wrapping it, and the mounts. Browsers get an HTML page;
+// clients asking for application/json (or ?format=json) get JSON.
+//
+// With ?path=/users/1&method=GET the response also includes TraceMatch for
+// that request, and the page has a form to fill those in. The table exposes
+// the application's structure, so the endpoint is guarded by mw as for
+// MountDebug:
+//
+//	r.MountRouteDebug("/debug/routes", middleware.IPFilter("127.0.0.0/8"))
+//
+// Without mw it answers only loopback clients. To serve it to everyone, pass
+// middleware that lets every request through.
+func (r *Router) MountRouteDebug(pattern string, mw ...Middleware) {
+	r.checkFrozen("MountRouteDebug")
+	if len(mw) == 0 {
+		mw = []Middleware{loopbackOnly}
+	}
+	r.With(mw...).handleBound(http.MethodGet, pattern, func(r *Router) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			q := req.URL.Query()
+			table := r.routeTable()
+			if path := q.Get("path"); path != "" {
+				method := cmp.Or(q.Get("method"), http.MethodGet)
+				trace := TraceMatch(r, path, strings.ToUpper(method))
+				table.Match = &trace
+			}
+			if q.Get("format") == "json" || prefersJSON(req) {
+				writeDebugJSON(w, table)
+				return
+			}
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			w.Header().Set("Content-Security-Policy", "default-src 'none'; style-src 'unsafe-inline'; form-action 'self'")
+			if err := routeTableTemplate.Execute(w, table); err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+			}
+		})
+	})
+}
+
+type routeTableRow struct {
+	Pattern    string   `json:"pattern"`
+	Methods    []string `json:"methods,omitempty"`
+	Summary    string   `json:"summary,omitempty"`
+	Tags       []string `json:"tags,omitempty"`
+	Aliases    []string `json:"aliases,omitempty"`
+	Middleware []string `json:"middleware,omitempty"`
+	Mount      bool     `json:"mount,omitempty"`
+	Handler    string   `json:"handler,omitempty"`
+}
+
+type routeTable struct {
+	Routes []routeTableRow `json:"routes"`
+	Match  *MatchTrace     `json:"match,omitempty"`
+}
+
+// routeTable builds the table from the registrations of the current
+// compiled tree. Routes sharing a pattern, summary (see Doc) and middleware
+// chain are merged into one row.
+func (r *Router) routeTable() routeTable {
+	c := r.state.current.Load()
+	if c == nil {
+		panic("saruta: router is not compiled; call Compile or MustCompile before serving")
+	}
+	aliases := make(map[string][]string)
+	for _, ra := range c.reg.aliases {
+		aliases[ra.pattern] = append(aliases[ra.pattern], ra.aliases...)
+	}
+	table := routeTable{Routes: []routeTableRow{}}
+	index := make(map[string]int)
+	for _, rt := range c.reg.routes {
+		names := middlewareNames(rt.middleware)
+		doc, _ := rt.meta[MetaAPIDoc].(APIDoc)
+		key := rt.pattern + "\x00" + doc.Summary + "\x00" + strings.Join(names, "\x00")
+		i, ok := index[key]
+		if !ok {
+			i = len(table.Routes)
+			index[key] = i
+			table.Routes = append(table.Routes, routeTableRow{
+				Pattern:    rt.pattern,
+				Summary:    doc.Summary,
+				Tags:       doc.Tags,
+				Aliases:    aliases[rt.pattern],
+				Middleware: names,
+			})
+		}
+		if row := &table.Routes[i]; !slices.Contains(row.Methods, rt.method) {
+			row.Methods = append(row.Methods, rt.method)
+		}
+	}
+	for _, mt := range c.reg.mounts {
+		table.Routes = append(table.Routes, routeTableRow{Pattern: mt.prefix, Mount: true, Handler: fmt.Sprintf("%T", mt.handler)})
+	}
+	for i := range table.Routes {
+		slices.Sort(table.Routes[i].Methods)
+	}
+	slices.SortStableFunc(table.Routes, func(a, b routeTableRow) int {
+		return strings.Compare(a.Pattern, b.Pattern)
+	})
+	return table
+}
+
+// middlewareNames returns the function names of a middleware chain, outermost
+// first, shortened to package.Func.
+func middlewareNames(entries []middlewareEntry) []string {
+	if len(entries) == 0 {
+		return nil
+	}
+	names := make([]string, len(entries))
+	for i, e := range entries {
+		var fn any = e.mw
+		if e.route != nil {
+			fn = e.route
+		}
+		names[i] = funcName(fn)
+	}
+	return names
+}
+
+func funcName(fn any) string {
+	v := reflect.ValueOf(fn)
+	if v.Kind() != reflect.Func || v.IsNil() {
+		return "?"
+	}
+	f := runtime.FuncForPC(v.Pointer())
+	if f == nil {
+		return "?"
+	}
+	name := strings.TrimSuffix(f.Name(), "-fm")
+	if i := strings.LastIndexByte(name, '/'); i >= 0 {
+		name = name[i+1:]
+	}
+	// Drop the closure suffixes (".func1", ".func2.1") of middleware
+	// constructors so that Logger(l) shows as middleware.Logger.
+	for {
+		i := strings.LastIndexByte(name, '.')
+		if i < 0 || !isClosureSuffix(name[i+1:]) {
+			break
+		}
+		name = name[:i]
+	}
+	return name
+}
+
+func isClosureSuffix(s string) bool {
+	s = strings.TrimPrefix(s, "func")
+	if s == "" {
+		return false
+	}
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+func prefersJSON(req *http.Request) bool {
+	accept := req.Header.Get("Accept")
+	return strings.Contains(accept, "application/json") && !strings.Contains(accept, "text/html")
+}
+
+//go:embed routedebug.html
+var routeTableHTML string
+
+var routeTableTemplate = template.Must(template.New("routes").Parse(routeTableHTML))
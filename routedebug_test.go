@@ -0,0 +1,115 @@
+//go:build !tinygo
+
+package saruta
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func debugTestMiddleware(next http.Handler) http.Handler { return next }
+
+func debugTestHeader(name string) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			w.Header().Set(name, "1")
+			next.ServeHTTP(w, req)
+		})
+	}
+}
+
+func TestRouterMountRouteDebug(t *testing.T) {
+	noop := func(w http.ResponseWriter, req *http.Request) {}
+	r := New()
+	r.Use(debugTestMiddleware)
+	r.Get("/users/{id}", noop)
+	r.Put("/users/{id}", noop)
+	r.With(debugTestHeader("X-Admin")).Delete("/users/{id}", noop)
+	r.Mount("/static", http.NotFoundHandler())
+	r.MountRouteDebug("/debug/routes")
+	r.MustCompile()
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, loopbackRequest("/debug/routes?format=json&path=/users/1&method=post"))
+	var table routeTable
+	if err := json.Unmarshal(rec.Body.Bytes(), &table); err != nil {
+		t.Fatal(err)
+	}
+
+	var users []routeTableRow
+	var mount bool
+	for _, row := range table.Routes {
+		switch row.Pattern {
+		case "/users/{id}":
+			users = append(users, row)
+		case "/static":
+			mount = row.Mount && row.Handler == "http.HandlerFunc"
+		}
+	}
+	if !mount {
+		t.Fatalf("mount row missing: %+v", table.Routes)
+	}
+	if len(users) != 2 {
+		t.Fatalf("users rows = %+v, want 2", users)
+	}
+	if !slices.Equal(users[0].Methods, []string{"GET", "PUT"}) || !slices.Equal(users[0].Middleware, []string{"saruta.debugTestMiddleware"}) {
+		t.Fatalf("first row = %+v", users[0])
+	}
+	if !slices.Equal(users[1].Methods, []string{"DELETE"}) || !slices.Equal(users[1].Middleware, []string{"saruta.debugTestMiddleware", "saruta.debugTestHeader"}) {
+		t.Fatalf("second row = %+v", users[1])
+	}
+	if table.Match == nil || table.Match.Result != "method not allowed" || table.Match.Pattern != "/users/{id}" {
+		t.Fatalf("match = %+v", table.Match)
+	}
+
+	req := loopbackRequest("/debug/routes?path=/users/<b>")
+	req.Header.Set("Accept", "text/html,application/xhtml+xml")
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	body := rec.Body.String()
+	if ct := rec.Header().Get("Content-Type"); ct != "text/html; charset=utf-8" {
+		t.Fatalf("Content-Type = %q", ct)
+	}
+	for _, want := range []string{"<code>/users/{id}</code>", "saruta.debugTestHeader", "mount <code>http.HandlerFunc</code>", "/users/&lt;b&gt;", ">matched<"} {
+		if !strings.Contains(body, want) {
+			t.Errorf("page does not contain %q", want)
+		}
+	}
+
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/debug/routes", nil))
+	if rec.Code != http.StatusForbidden {
+		t.Fatalf("remote client: status = %d, want 403", rec.Code)
+	}
+}
+
+func TestRouteDebugShowsDocSummaries(t *testing.T) {
+	noop := func(w http.ResponseWriter, req *http.Request) {}
+	r := New()
+	users := r.Doc("", DocTags("users"))
+	users.Doc("Create a user", DocRequest(&docTestCreateUser{})).Post("/users", noop)
+	users.Doc("List users", DocResponse(http.StatusOK, []docTestUser{})).Get("/users", noop)
+	r.MountRouteDebug("/debug/routes")
+	r.MustCompile()
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, loopbackRequest("/debug/routes?format=json"))
+	var table routeTable
+	if err := json.Unmarshal(rec.Body.Bytes(), &table); err != nil {
+		t.Fatal(err)
+	}
+	var summaries []string
+	for _, row := range table.Routes {
+		if row.Pattern == "/users" {
+			summaries = append(summaries, row.Summary)
+		}
+	}
+	slices.Sort(summaries)
+	if !slices.Equal(summaries, []string{"Create a user", "List users"}) {
+		t.Fatalf("debug page summaries = %q", summaries)
+	}
+}
@@ -2,36 +2,90 @@ package saruta
 
 import (
 	"fmt"
+	"maps"
 	"net/http"
+	"slices"
+	"strings"
+	"sync/atomic"
 )
 
 type Router struct {
 	state      *routerState
-	middleware []Middleware
+	middleware []middlewareEntry
+	meta       Meta
+	prefix     string
 }
 
 type routerState struct {
-	root             *radixNode
+	current          atomic.Pointer[compiledState]
 	notFound         http.Handler
 	methodNotAllowed http.Handler
+	errorScopes      []registeredErrorScope
 
-	routes []registeredRoute
-	mounts []registeredMount
+	routes     []registeredRoute
+	mounts     []registeredMount
+	aliases    []registeredAlias
+	rewrites   []registeredRewrite
+	subRouters []registeredSubRouter
 
-	compiled          bool
+	nearMiss *nearMissCounters
+	noise    *noiseFilter
+
+	frozen            bool
 	panicOnCompileErr bool
+	copyParams        bool
+	autoOptions       bool
+	errorMiddleware   bool
+	drain             *drainTracker
+	onCompile         []func(CompileReport)
+	onRegister        []func(RouteInfo)
+	basePath          string
+	vars              *varCounters
+	suggest           int
+	matchCache        int
+	noPathValues      bool
+	no405             bool
+}
+
+// compiledState is the immutable result of Compile. ServeHTTP loads it once
+// per request, so Compile can replace it while requests are in flight.
+type compiledState struct {
+	root             *radixTree
+	rewriteRoot      *radixTree
+	mountsFirst      bool
+	notFound         http.Handler
+	methodNotAllowed http.Handler
+	// routeNotFound is notFound without the WithMiddlewareOnErrors chain, for
+	// route handlers (StaticFS) whose middleware already ran.
+	routeNotFound http.Handler
+	errorScopes   []errorScope
+	report        CompileReport
+	// reg is the flattened registration set the tree was built from.
+	reg     registrations
+	suggest *suggester
+	cache   *matchCache
+	// no405 makes a path match with the wrong method a 404.
+	no405 bool
 }
 
 type registeredRoute struct {
 	method     string
 	pattern    string
 	handler    http.Handler
-	middleware []Middleware
+	middleware []middlewareEntry
+	meta       Meta
+	redirect   *redirectRule
+	proxy      *proxyRule
+	source     string // registration call site, for conflict errors
+	// rebind builds handler for another router; see handleBound.
+	rebind func(*Router) http.Handler
 }
 
 type registeredMount struct {
-	prefix  string
-	handler http.Handler
+	prefix     string
+	handler    http.Handler
+	precedence MountPrecedence
+	source     string
 }
 
 type Option func(*Router)
@@ -43,13 +97,65 @@ func WithPanicOnCompileError() Option {
 	}
 }
 
+// WithCopyParams makes the router copy path parameter values before setting
+// them on the request.
+//
+// By default, values passed to req.SetPathValue are substrings of
+// req.URL.Path and share its memory, which avoids allocations. Handlers that
+// retain parameter values beyond the request keep the whole path alive; this
+// option trades one allocation per parameter for independent values.
+func WithCopyParams() Option {
+	return func(r *Router) {
+		r.state.copyParams = true
+	}
+}
+
+// WithoutMethodNotAllowed turns off 405 responses: a request whose path
+// matches a route but whose method does not is handled like an unmatched
+// path (mounts, then the NotFound handler), without building an Allow
+// header. Gateways that never send Allow save the leaf inspection, and
+// scanners probing methods see plain 404s. Automatic OPTIONS responses
+// (WithAutoOptions) are still served.
+func WithoutMethodNotAllowed() Option {
+	return func(r *Router) {
+		r.state.no405 = true
+	}
+}
+
+// WithMiddlewareOnErrors makes router middleware wrap the NotFound and
+// MethodNotAllowed handlers (and the built-in 404 / 405 responses), so
+// request logging and metrics also see error traffic.
+//
+// The chain is the middleware of the router Compile is called on, at the
+// time of the call; route-aware middleware receives a zero RouteInfo.
+func WithMiddlewareOnErrors() Option {
+	return func(r *Router) {
+		r.state.errorMiddleware = true
+	}
+}
+
+// WithBasePath prefixes every pattern, mount, alias and rewrite registered on
+// the router with path, as if all registration happened inside
+// r.Route(path, ...). It lets the same service be served at the root or
+// behind an ingress that forwards a path prefix, by configuration only.
+//
+// Redirect targets are used as given. Remove and Replace take patterns
+// without the base path. A trailing slash is ignored; other validation is
+// deferred until Compile.
+func WithBasePath(path string) Option {
+	return func(r *Router) {
+		r.state.basePath = strings.TrimSuffix(path, "/")
+		r.prefix = r.state.basePath
+	}
+}
+
 // New creates a new Router.
 //
 // Register routes with Get/Post/Handle, then call Compile or MustCompile
 // before serving requests.
 func New(opts ...Option) *Router {
 	r := &Router{
-		state: &routerState{},
+		state: &routerState{drain: &drainTracker{levels: make(map[int]*drainLevel)}},
 	}
 	for _, opt := range opts {
 		if opt != nil {
@@ -63,13 +169,48 @@ func New(opts ...Option) *Router {
 //
 // Validation and conflict detection are deferred until Compile.
 func (r *Router) Handle(method, pattern string, h http.Handler) {
+	r.checkFrozen("Handle")
 	r.state.routes = append(r.state.routes, registeredRoute{
 		method:     method,
-		pattern:    pattern,
+		pattern:    r.prefix + pattern,
 		handler:    h,
-		middleware: append([]Middleware(nil), r.middleware...),
+		middleware: append([]middlewareEntry(nil), r.middleware...),
+		meta:       r.meta,
+		source:     callerSource(),
 	})
-	r.state.compiled = false
+	r.notifyRegister(RouteInfo{Method: method, Pattern: r.prefix + pattern, Meta: r.meta})
+}
+
+// Remove unregisters the route for method and pattern, reporting whether one
+// was registered. Like registration, it takes effect at the next Compile.
+func (r *Router) Remove(method, pattern string) bool {
+	r.checkFrozen("Remove")
+	pattern = r.prefix + pattern
+	n := len(r.state.routes)
+	r.state.routes = slices.DeleteFunc(r.state.routes, func(rt registeredRoute) bool {
+		return rt.method == method && rt.pattern == pattern
+	})
+	return len(r.state.routes) != n
+}
+
+// Replace swaps the handler of the route registered for method and pattern,
+// reporting whether one was registered. The middleware and metadata the route
+// was registered with are kept. Like registration, it takes effect at the
+// next Compile.
+func (r *Router) Replace(method, pattern string, h http.Handler) bool {
+	r.checkFrozen("Replace")
+	pattern = r.prefix + pattern
+	i := slices.IndexFunc(r.state.routes, func(rt registeredRoute) bool {
+		return rt.method == method && rt.pattern == pattern
+	})
+	if i < 0 {
+		return false
+	}
+	r.state.routes[i].handler = h
+	r.state.routes[i].redirect = nil
+	r.state.routes[i].proxy = nil
+	r.state.routes[i].rebind = nil
+	return true
 }
 
 // HandleFunc is like Handle but accepts http.HandlerFunc.
@@ -114,18 +255,49 @@ func (r *Router) Options(pattern string, h http.HandlerFunc) {
 
 // Use appends router-level middleware for subsequent route registrations.
 func (r *Router) Use(mw ...Middleware) {
-	r.middleware = append(r.middleware, mw...)
+	r.checkFrozen("Use")
+	r.middleware = append(r.middleware, plainEntries(mw)...)
+}
+
+// UseWithErrors is like Use, but the middleware also wraps the NotFound and
+// MethodNotAllowed handlers, with or without WithMiddlewareOnErrors. Use it
+// for middleware every response needs, such as a request ID or a recoverer:
+//
+//	r.UseWithErrors(middleware.RequestID, middleware.Recoverer)
+func (r *Router) UseWithErrors(mw ...Middleware) {
+	r.checkFrozen("UseWithErrors")
+	for _, m := range plainEntries(mw) {
+		m.onErrors = true
+		r.middleware = append(r.middleware, m)
+	}
+}
+
+// UseRoute appends route-aware middleware to the router; see RouteMiddleware.
+func (r *Router) UseRoute(mw ...RouteMiddleware) {
+	r.checkFrozen("UseRoute")
+	r.middleware = append(r.middleware, routeEntries(mw)...)
 }
 
 // With returns a derived router sharing the same route set and compile target,
 // but with additional middleware applied to routes registered via the derived router.
 func (r *Router) With(mw ...Middleware) *Router {
-	combined := make([]Middleware, 0, len(r.middleware)+len(mw))
+	return r.with(plainEntries(mw))
+}
+
+// WithRoute is like With for route-aware middleware.
+func (r *Router) WithRoute(mw ...RouteMiddleware) *Router {
+	return r.with(routeEntries(mw))
+}
+
+func (r *Router) with(mw []middlewareEntry) *Router {
+	combined := make([]middlewareEntry, 0, len(r.middleware)+len(mw))
 	combined = append(combined, r.middleware...)
 	combined = append(combined, mw...)
 	return &Router{
 		state:      r.state,
 		middleware: combined,
+		meta:       r.meta,
+		prefix:     r.prefix,
 	}
 }
 
@@ -137,40 +309,144 @@ func (r *Router) Group(fn func(r *Router)) {
 	fn(r.With())
 }
 
+// Route calls fn with a derived router whose patterns are prefixed with prefix.
+//
+// Everything registered inside fn, including nested Route, Group and Mount
+// calls, is placed under prefix, and middleware added inside fn is scoped to
+// it:
+//
+//	r.Route("/api/v1", func(api *saruta.Router) {
+//		api.Get("/users", listUsers) // GET /api/v1/users
+//	})
+//
+// A trailing slash in prefix is ignored, so a route registered as "/" inside
+// fn serves "{prefix}/". Redirect targets are used as given.
+func (r *Router) Route(prefix string, fn func(r *Router)) {
+	if fn == nil {
+		return
+	}
+	fn(r.WithPrefix(prefix))
+}
+
+// PrefixGroup is Group with a path prefix; it is equivalent to Route.
+func (r *Router) PrefixGroup(prefix string, fn func(r *Router)) {
+	r.Route(prefix, fn)
+}
+
+// WithPrefix returns a derived router like With whose patterns are also
+// prefixed with prefix. Prefixes compose, so
+// r.WithPrefix("/api").WithPrefix("/v1") registers under "/api/v1".
+func (r *Router) WithPrefix(prefix string) *Router {
+	d := r.With()
+	d.prefix = r.prefix + strings.TrimSuffix(prefix, "/")
+	return d
+}
+
 // Mount delegates a static path prefix to another handler.
 //
 // Prefix validation happens in Compile. Mounted handlers receive the original
-// request path (no path stripping).
-func (r *Router) Mount(prefix string, h http.Handler) {
-	r.state.mounts = append(r.state.mounts, registeredMount{
-		prefix:  prefix,
+// request path (no path stripping). By default routes take precedence over
+// mounts; see WithMountPrecedence.
+func (r *Router) Mount(prefix string, h http.Handler, opts ...MountOption) {
+	r.checkFrozen("Mount")
+	mt := registeredMount{
+		prefix:  r.prefix + prefix,
 		handler: h,
-	})
-	r.state.compiled = false
+		source:  callerSource(),
+	}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(&mt)
+		}
+	}
+	r.state.mounts = append(r.state.mounts, mt)
+	r.notifyRegister(RouteInfo{Pattern: mt.prefix, Meta: r.meta, Mount: true})
 }
 
 // Compile validates registered routes and builds the runtime radix tree.
+// Suspicious but valid setups are reported as warnings by CompileReport.
+//
+// Compile may be called again after routes are added or removed, including
+// while the router is serving: the new tree is swapped in atomically, requests
+// already in flight finish on the previous one, and a failed Compile keeps the
+// previous tree. Registration and Compile themselves must not run
+// concurrently with each other.
+//
+// Tables of several thousand routes are compiled on up to GOMAXPROCS
+// goroutines: patterns are parsed and large subtrees converted in parallel.
+// Middleware constructors and hooks still run on the calling goroutine.
 func (r *Router) Compile() error {
 	root := newNode()
 
-	for _, rt := range r.state.routes {
+	if _, err := normalizePrefix(r.state.basePath); err != nil {
+		return r.compileError(fmt.Errorf("invalid base path: %w", err))
+	}
+	reg, err := r.state.collect()
+	if err != nil {
+		return r.compileError(err)
+	}
+	aliases, err := compileAliases(reg.aliases)
+	if err != nil {
+		return r.compileError(err)
+	}
+	aliased := make(map[string]bool, len(aliases))
+	routes := make([]RouteInfo, 0, len(reg.routes))
+
+	cps, cpErrs := compilePatterns(reg.routes)
+	for i, rt := range reg.routes {
 		if rt.method == "" {
 			return r.compileError(fmt.Errorf("invalid method: empty"))
 		}
-		if rt.handler == nil {
+		if rt.handler == nil && rt.redirect == nil && rt.proxy == nil {
 			return r.compileError(fmt.Errorf("invalid handler: nil"))
 		}
-		cp, err := compilePattern(rt.pattern)
+		cp, err := cps[i], cpErrs[i]
 		if err != nil {
 			return r.compileError(err)
 		}
-		h := chainMiddlewares(rt.handler, rt.middleware)
-		if err := root.insertRoute(rt.method, rt.pattern, cp, h); err != nil {
+		h := rt.handler
+		if rt.redirect != nil {
+			h, err = rt.redirect.compile(rt.pattern, cp)
+			if err != nil {
+				return r.compileError(err)
+			}
+		}
+		if rt.proxy != nil {
+			h, err = rt.proxy.compile(rt.pattern, cp)
+			if err != nil {
+				return r.compileError(err)
+			}
+		}
+		h = wrapRouteFeatures(r.state, rt, h)
+		info := newRouteInfo(rt, cp, aliases[rt.pattern])
+		route := *info
+		route.Meta = maps.Clone(info.Meta)
+		routes = append(routes, route)
+		if h, err = chainRoute(rt, h, route); err != nil {
 			return r.compileError(err)
 		}
+		h = wrapRouteGuards(rt, h)
+		if r.state.vars != nil {
+			h = r.state.vars.countRoute(rt.method+" "+rt.pattern, h)
+		}
+		if err := root.insertRouteInfo(rt.method, rt.pattern, rt.pattern, cp, h, info, rt.source); err != nil {
+			return r.compileError(err)
+		}
+		for _, alias := range aliases[rt.pattern] {
+			if err := root.insertRouteInfo(rt.method, alias.pattern, rt.pattern, alias.cp, h, info, rt.source); err != nil {
+				return r.compileError(err)
+			}
+		}
+		aliased[rt.pattern] = true
+	}
+	for _, ra := range reg.aliases {
+		if !aliased[ra.pattern] {
+			return r.compileError(fmt.Errorf("invalid alias: no routes registered for %s", ra.pattern))
+		}
 	}
 
-	for _, mt := range r.state.mounts {
+	mountsFirst := false
+	for _, mt := range reg.mounts {
 		if mt.handler == nil {
 			return r.compileError(fmt.Errorf("invalid handler: nil"))
 		}
@@ -183,16 +459,91 @@ func (r *Router) Compile() error {
 				return r.compileError(fmt.Errorf("invalid mount prefix %q: prefix must be a static path", mt.prefix))
 			}
 		}
-		if err := root.insertMount(mt.prefix, cp, mt.handler); err != nil {
+		if err := root.insertMount(mt.prefix, cp, &mountEntry{handler: mt.handler, precedence: mt.precedence, source: mt.source}); err != nil {
 			return r.compileError(err)
 		}
+		if mt.precedence == MountBeforeRoutes {
+			mountsFirst = true
+		}
 	}
 
-	r.state.root = buildRadix(root)
-	r.state.compiled = true
+	rewriteRoot, err := compileRewrites(reg.rewrites)
+	if err != nil {
+		return r.compileError(err)
+	}
+
+	// The error handlers, with their middleware, are composed once here so
+	// that serving a 404 or 405 only dispatches.
+	notFound, methodNotAllowed := r.state.notFound, r.state.methodNotAllowed
+	if notFound == nil {
+		notFound = http.HandlerFunc(defaultNotFound)
+	}
+	if methodNotAllowed == nil {
+		methodNotAllowed = http.HandlerFunc(defaultMethodNotAllowed)
+	}
+	errorMiddleware := r.state.errorEntries(r.middleware)
+	notFound = chainMiddlewares(notFound, errorMiddleware, RouteInfo{})
+	methodNotAllowed = chainMiddlewares(methodNotAllowed, errorMiddleware, RouteInfo{})
+	errorScopes, err := r.state.compileErrorScopes(notFound, methodNotAllowed)
+	if err != nil {
+		return r.compileError(err)
+	}
+
+	sortRouteInfos(routes)
+	var suggest *suggester
+	if r.state.suggest > 0 {
+		suggest = newSuggester(r.state.suggest, routes)
+	}
+	var cache *matchCache
+	if r.state.matchCache > 0 {
+		cache = newMatchCache(r.state.matchCache)
+	}
+	report := CompileReport{Routes: routes, Warnings: compileWarnings(reg)}
+	radix := buildRadix(root)
+	treeStats(radix, radix.node(0), 0, &report.Tree)
+
+	r.state.current.Store(&compiledState{
+		root:             radix,
+		rewriteRoot:      rewriteRoot,
+		mountsFirst:      mountsFirst,
+		notFound:         notFound,
+		methodNotAllowed: methodNotAllowed,
+		routeNotFound:    r.state.notFound,
+		errorScopes:      errorScopes,
+		report:           report,
+		reg:              reg,
+		suggest:          suggest,
+		cache:            cache,
+		no405:            r.state.no405,
+	})
+	for _, fn := range r.state.onCompile {
+		fn(report)
+	}
 	return nil
 }
 
+// Freeze makes the route set immutable: from now on Handle (and the method
+// helpers built on it), Use, Mount, MountRouter, Alias, Redirect, Rewrite,
+// Remove, Replace, NotFound and MethodNotAllowed panic, on r and on every
+// router derived from it. Compile remains allowed, so Freeze can be called
+// before or after it. This turns a late registration (an init-order bug that
+// would otherwise only take effect at some later Compile, or never) into an
+// immediate failure.
+func (r *Router) Freeze() {
+	r.state.frozen = true
+}
+
+// Frozen reports whether Freeze has been called.
+func (r *Router) Frozen() bool {
+	return r.state.frozen
+}
+
+func (r *Router) checkFrozen(op string) {
+	if r.state.frozen {
+		panic("saruta: " + op + " called on a frozen router")
+	}
+}
+
 // MustCompile is like Compile but panics on error.
 func (r *Router) MustCompile() {
 	if err := r.Compile(); err != nil {
@@ -202,23 +553,46 @@ func (r *Router) MustCompile() {
 
 // NotFound sets the handler used when no route matches.
 //
-// Router middleware added with Use is not applied to this handler.
+// Called on a group with a prefix (Route, WithPrefix), it applies only to
+// paths under that prefix; the most specific group wins and the router-wide
+// handler is the fallback. Router middleware added with Use is not applied to
+// this handler unless the router was created with WithMiddlewareOnErrors, in
+// which case a group handler gets the group's middleware; middleware added
+// with UseWithErrors always is. Like routes, it takes effect at the next
+// Compile.
 func (r *Router) NotFound(h http.Handler) {
+	r.checkFrozen("NotFound")
+	if r.prefix != r.state.basePath {
+		r.setErrorScope(func(s *registeredErrorScope) { s.notFound = h })
+		return
+	}
 	r.state.notFound = h
 }
 
 // MethodNotAllowed sets the handler used when the path matches but the method does not.
 //
-// Router middleware added with Use is not applied to this handler.
+// Like NotFound, it can be scoped to the paths of a group with a prefix.
+// Router middleware added with Use is not applied to this handler unless the
+// router was created with WithMiddlewareOnErrors; middleware added with
+// UseWithErrors always is. Like routes, it takes effect at the next Compile.
 func (r *Router) MethodNotAllowed(h http.Handler) {
+	r.checkFrozen("MethodNotAllowed")
+	if r.prefix != r.state.basePath {
+		r.setErrorScope(func(s *registeredErrorScope) { s.methodNotAllowed = h })
+		return
+	}
 	r.state.methodNotAllowed = h
 }
 
 // ServeHTTP implements http.Handler.
 //
-// The router must be compiled before it is used.
+// The router must be compiled before it is used; it serves the tree of the
+// most recent successful Compile. When a route matches, its
+// path values and req.Pattern (the registered pattern) are set before
+// middleware runs.
 func (r *Router) ServeHTTP(w http.ResponseWriter, req *http.Request) {
-	if !r.state.compiled || r.state.root == nil {
+	c := r.state.current.Load()
+	if c == nil {
 		panic("saruta: router is not compiled; call Compile or MustCompile before serving")
 	}
 	if req == nil || req.URL == nil {
@@ -230,48 +604,138 @@ func (r *Router) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 		r.serveNotFound(w, req)
 		return
 	}
+	if r.state.noise != nil {
+		if h := r.state.noise.match(path); h != nil {
+			h.ServeHTTP(w, req)
+			return
+		}
+	}
+	if c.rewriteRoot != nil {
+		path = rewritePath(c.rewriteRoot, req, path)
+	}
+
+	if c.mountsFirst {
+		if m := c.root.findMount(path); m != nil && m.precedence == MountBeforeRoutes {
+			m.handler.ServeHTTP(w, req)
+			return
+		}
+	}
 
-	if matched, ok := r.state.root.matchRoute(path); ok {
-		if h, ok := matched.leaf.handlers[req.Method]; ok {
-			for i := 0; i < matched.paramCount; i++ {
-				p := matched.params[i]
+	if matched, ok := c.matchRoute(path); ok {
+		if h, ok := matched.leaf.handlers.get(req.Method); ok {
+			if r.state.noPathValues && matched.params.len() > 0 {
+				req.Pattern = matched.leaf.pattern
+				serveMatchedParams(w, req, h, &matched.params)
+				return
+			}
+			for i := range matched.params.len() {
+				p := matched.params.at(i)
+				if r.state.copyParams {
+					p.value = strings.Clone(p.value)
+				}
 				req.SetPathValue(p.name, p.value)
 			}
+			matched.params.release()
+			req.Pattern = matched.leaf.pattern
 			h.ServeHTTP(w, req)
 			return
 		}
-		if len(matched.leaf.handlers) > 0 {
-			allow := allowHeaderValue(matched.leaf.handlers)
-			if allow != "" {
-				w.Header().Set("Allow", allow)
+		matched.params.release()
+		if r.state.autoOptions && req.Method == http.MethodOptions && matched.leaf.handlers.len() > 0 {
+			r.serveAutoOptions(w, matched.leaf)
+			return
+		}
+		if !c.no405 && matched.leaf.handlers.len() > 0 {
+			if m := c.root.findMount(path); m != nil && m.precedence != MountAfterRoutes {
+				m.handler.ServeHTTP(w, req)
+				return
+			}
+			if r.state.nearMiss != nil {
+				r.state.nearMiss.method.Add(1)
 			}
+			w.Header()["Allow"] = matched.leaf.handlers.allowHeader(r.state.autoOptions)
 			r.serveMethodNotAllowed(w, req)
 			return
 		}
 	}
 
-	if h := r.state.root.findMount(path); h != nil {
-		h.ServeHTTP(w, req)
+	if m := c.root.findMount(path); m != nil {
+		m.handler.ServeHTTP(w, req)
 		return
 	}
 
+	if r.state.nearMiss != nil {
+		r.state.nearMiss.recordNotFound(c.root, req.Method, path)
+	}
+	if c.suggest != nil {
+		req = c.suggest.attach(req, path)
+	}
 	r.serveNotFound(w, req)
 }
 
 func (r *Router) serveNotFound(w http.ResponseWriter, req *http.Request) {
-	if r.state.notFound != nil {
-		r.state.notFound.ServeHTTP(w, req)
+	if v := r.state.vars; v != nil {
+		v.notFound.Add(1)
+	}
+	c := r.state.current.Load()
+	if sc := c.errorScope(req); sc != nil && sc.notFound != nil {
+		sc.notFound.ServeHTTP(w, req)
+		return
+	}
+	c.notFound.ServeHTTP(w, req)
+}
+
+func (r *Router) serveRouteNotFound(w http.ResponseWriter, req *http.Request) {
+	c := r.state.current.Load()
+	if sc := c.errorScope(req); sc != nil && sc.routeNotFound != nil {
+		sc.routeNotFound.ServeHTTP(w, req)
+		return
+	}
+	if c != nil && c.routeNotFound != nil {
+		c.routeNotFound.ServeHTTP(w, req)
 		return
 	}
-	http.NotFound(w, req)
+	defaultNotFound(w, req)
 }
 
 func (r *Router) serveMethodNotAllowed(w http.ResponseWriter, req *http.Request) {
-	if r.state.methodNotAllowed != nil {
-		r.state.methodNotAllowed.ServeHTTP(w, req)
+	if v := r.state.vars; v != nil {
+		v.methodNotAllowed.Add(1)
+	}
+	c := r.state.current.Load()
+	if sc := c.errorScope(req); sc != nil && sc.methodNotAllowed != nil {
+		sc.methodNotAllowed.ServeHTTP(w, req)
 		return
 	}
-	http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+	c.methodNotAllowed.ServeHTTP(w, req)
+}
+
+var (
+	notFoundBody         = []byte("404 page not found\n")
+	methodNotAllowedBody = []byte(http.StatusText(http.StatusMethodNotAllowed) + "\n")
+	plainTextHeader      = []string{"text/plain; charset=utf-8"}
+	nosniffHeader        = []string{"nosniff"}
+)
+
+// defaultNotFound writes the same response as http.NotFound.
+func defaultNotFound(w http.ResponseWriter, req *http.Request) {
+	writeErrorText(w, http.StatusNotFound, notFoundBody)
+}
+
+func defaultMethodNotAllowed(w http.ResponseWriter, req *http.Request) {
+	writeErrorText(w, http.StatusMethodNotAllowed, methodNotAllowedBody)
+}
+
+// writeErrorText writes the response of http.Error without allocating: the
+// header values are shared slices assigned to the map, where Header.Set
+// would allocate one per call, and the body is written as is.
+func writeErrorText(w http.ResponseWriter, status int, body []byte) {
+	h := w.Header()
+	h.Del("Content-Length")
+	h["Content-Type"] = plainTextHeader[:1:1]
+	h["X-Content-Type-Options"] = nosniffHeader[:1:1]
+	w.WriteHeader(status)
+	_, _ = w.Write(body)
 }
 
 func (r *Router) compileError(err error) error {
@@ -5,7 +5,11 @@ import (
 	"net/http/httptest"
 	"reflect"
 	"slices"
+	"strconv"
+	"sync"
 	"testing"
+	"testing/fstest"
+	"unsafe"
 )
 
 func TestRouterStaticAndNotFound(t *testing.T) {
@@ -304,6 +308,83 @@ func TestRouterCustomErrorHandlersAndNoUseMiddlewareApplied(t *testing.T) {
 	}
 }
 
+func TestRouterMiddlewareOnErrors(t *testing.T) {
+	var seen []string
+	r := New(WithMiddlewareOnErrors())
+	r.Use(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			seen = append(seen, req.Method+" "+req.URL.Path)
+			w.Header().Set("X-Use", "1")
+			next.ServeHTTP(w, req)
+		})
+	})
+	r.Get("/users", func(w http.ResponseWriter, req *http.Request) {})
+	r.StaticFS("/static", fstest.MapFS{})
+	r.MustCompile()
+
+	for _, tc := range []struct {
+		method, path string
+		code         int
+	}{
+		{method: http.MethodGet, path: "/missing", code: http.StatusNotFound},
+		{method: http.MethodPost, path: "/users", code: http.StatusMethodNotAllowed},
+		{method: http.MethodGet, path: "/static/none.css", code: http.StatusNotFound},
+	} {
+		seen = nil
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, httptest.NewRequest(tc.method, tc.path, nil))
+		if rec.Code != tc.code || rec.Header().Get("X-Use") != "1" {
+			t.Fatalf("%s %s: status=%d X-Use=%q", tc.method, tc.path, rec.Code, rec.Header().Get("X-Use"))
+		}
+		if len(seen) != 1 {
+			t.Fatalf("%s %s: middleware ran %d times", tc.method, tc.path, len(seen))
+		}
+	}
+}
+
+func TestRouterUseWithErrors(t *testing.T) {
+	var seen, plain int
+	r := New()
+	r.UseWithErrors(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			seen++
+			next.ServeHTTP(w, req)
+		})
+	})
+	r.Use(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			plain++
+			next.ServeHTTP(w, req)
+		})
+	})
+	r.Get("/users", func(w http.ResponseWriter, req *http.Request) {})
+	r.StaticFS("/static", fstest.MapFS{})
+	r.Route("/api", func(api *Router) {
+		api.NotFound(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			w.WriteHeader(http.StatusTeapot)
+		}))
+	})
+	r.MustCompile()
+
+	for _, tc := range []struct {
+		method, path string
+		code, plain  int
+	}{
+		{method: http.MethodGet, path: "/users", code: http.StatusOK, plain: 1},
+		{method: http.MethodGet, path: "/missing", code: http.StatusNotFound},
+		{method: http.MethodPost, path: "/users", code: http.StatusMethodNotAllowed},
+		{method: http.MethodGet, path: "/static/none.css", code: http.StatusNotFound, plain: 1},
+		{method: http.MethodGet, path: "/api/missing", code: http.StatusTeapot},
+	} {
+		seen, plain = 0, 0
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, httptest.NewRequest(tc.method, tc.path, nil))
+		if rec.Code != tc.code || seen != 1 || plain != tc.plain {
+			t.Fatalf("%s %s: status=%d UseWithErrors ran %d times, Use ran %d times", tc.method, tc.path, rec.Code, seen, plain)
+		}
+	}
+}
+
 func TestRouterMount(t *testing.T) {
 	r := New()
 	sub := http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
@@ -431,6 +512,169 @@ func TestRouterServeHTTPPanicsBeforeCompile(t *testing.T) {
 	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/ok", nil))
 }
 
+func TestRouterRecompileWhileServing(t *testing.T) {
+	r := New()
+	r.Get("/stable", func(w http.ResponseWriter, req *http.Request) {})
+	r.MustCompile()
+
+	stop := make(chan struct{})
+	var wg sync.WaitGroup
+	for range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				rec := httptest.NewRecorder()
+				r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/stable", nil))
+				if rec.Code != http.StatusOK {
+					t.Errorf("GET /stable status=%d, want 200", rec.Code)
+					return
+				}
+				r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/dyn/1", nil))
+			}
+		}()
+	}
+
+	for i := range 50 {
+		pattern := "/dyn/" + strconv.Itoa(i%3)
+		if i%2 == 0 {
+			r.Get(pattern, func(w http.ResponseWriter, req *http.Request) {})
+		} else {
+			r.Remove(http.MethodGet, pattern)
+		}
+		if err := r.Compile(); err != nil {
+			t.Fatalf("Compile: %v", err)
+		}
+	}
+	close(stop)
+	wg.Wait()
+}
+
+func TestRouterRemove(t *testing.T) {
+	r := New()
+	api := r.WithPrefix("/api")
+	api.Get("/users", func(w http.ResponseWriter, req *http.Request) {})
+	api.Post("/users", func(w http.ResponseWriter, req *http.Request) {})
+	r.MustCompile()
+
+	if !api.Remove(http.MethodGet, "/users") {
+		t.Fatalf("Remove returned false for a registered route")
+	}
+	if r.Remove(http.MethodGet, "/users") {
+		t.Fatalf("Remove returned true for an unknown route")
+	}
+
+	// The previous tree serves until the next Compile.
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/users", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("before recompile status=%d, want 200", rec.Code)
+	}
+
+	r.MustCompile()
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/users", nil))
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("after recompile status=%d, want 405", rec.Code)
+	}
+}
+
+func TestRouterReplace(t *testing.T) {
+	r := New()
+	var calls []string
+	api := r.WithPrefix("/api").With(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			calls = append(calls, "mw")
+			next.ServeHTTP(w, req)
+		})
+	}).WithMeta(MetaOwner, "users")
+	api.Get("/users/{id}", func(w http.ResponseWriter, req *http.Request) {
+		w.Write([]byte("real"))
+	})
+	r.MustCompile()
+
+	if r.Replace(http.MethodPost, "/api/users/{id}", http.NotFoundHandler()) {
+		t.Fatalf("Replace returned true for an unknown route")
+	}
+	stub := http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Write([]byte("stub " + req.PathValue("id")))
+	})
+	if !api.Replace(http.MethodGet, "/users/{id}", stub) {
+		t.Fatalf("Replace returned false for a registered route")
+	}
+	r.MustCompile()
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/users/7", nil))
+	if rec.Body.String() != "stub 7" {
+		t.Fatalf("body = %q, want stub 7", rec.Body.String())
+	}
+	if !slices.Equal(calls, []string{"mw"}) {
+		t.Fatalf("middleware calls = %v", calls)
+	}
+	if routes := r.Routes(); len(routes) != 1 || routes[0].Meta[MetaOwner] != "users" {
+		t.Fatalf("routes = %+v", routes)
+	}
+}
+
+func TestRouterFreeze(t *testing.T) {
+	noop := func(w http.ResponseWriter, req *http.Request) {}
+	r := New()
+	api := r.WithPrefix("/api")
+	api.Get("/users", noop)
+	r.MustCompile()
+	r.Freeze()
+	if !r.Frozen() || !api.Frozen() {
+		t.Fatalf("Frozen() = false after Freeze")
+	}
+
+	for name, fn := range map[string]func(){
+		"Get":      func() { api.Get("/late", noop) },
+		"Use":      func() { r.Use(func(next http.Handler) http.Handler { return next }) },
+		"Mount":    func() { r.Mount("/static", http.NotFoundHandler()) },
+		"Remove":   func() { api.Remove(http.MethodGet, "/users") },
+		"NotFound": func() { r.NotFound(http.NotFoundHandler()) },
+		"Rewrite":  func() { r.Rewrite("/old", "/api/users") },
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Fatalf("%s on a frozen router did not panic", name)
+				}
+			}()
+			fn()
+		}()
+	}
+
+	r.MustCompile()
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/users", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status=%d, want 200", rec.Code)
+	}
+}
+
+func TestRouterFailedRecompileKeepsPreviousTree(t *testing.T) {
+	r := New()
+	r.Get("/ok", func(w http.ResponseWriter, req *http.Request) {})
+	r.MustCompile()
+
+	r.Get("invalid", func(w http.ResponseWriter, req *http.Request) {})
+	if err := r.Compile(); err == nil {
+		t.Fatalf("expected compile error")
+	}
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/ok", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status=%d, want 200", rec.Code)
+	}
+}
+
 func stringsLower(s string) string {
 	b := make([]byte, len(s))
 	for i := range s {
@@ -442,3 +686,309 @@ func stringsLower(s string) string {
 	}
 	return string(b)
 }
+
+func TestRouterCopyParams(t *testing.T) {
+	sharesPath := func(req *http.Request, value string) bool {
+		path := req.URL.Path
+		start := uintptr(unsafe.Pointer(unsafe.StringData(path)))
+		p := uintptr(unsafe.Pointer(unsafe.StringData(value)))
+		return p >= start && p < start+uintptr(len(path))
+	}
+
+	for _, tc := range []struct {
+		opts  []Option
+		share bool
+	}{
+		{opts: nil, share: true},
+		{opts: []Option{WithCopyParams()}, share: false},
+	} {
+		var shared bool
+		r := New(tc.opts...)
+		r.Get("/users/{id}", func(w http.ResponseWriter, req *http.Request) {
+			shared = sharesPath(req, req.PathValue("id"))
+		})
+		r.MustCompile()
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/users/12345", nil))
+		if shared != tc.share {
+			t.Fatalf("opts=%d shared = %v, want %v", len(tc.opts), shared, tc.share)
+		}
+	}
+}
+
+func TestRouterSetsRequestPattern(t *testing.T) {
+	var got string
+	h := func(w http.ResponseWriter, req *http.Request) { got = req.Pattern }
+	sub := New()
+	sub.Get("/items/{id}", h)
+	r := New()
+	r.Get("/users/{id}", h)
+	r.Alias("/users/{id}", "/u/{id}")
+	r.MountRouter("/api", sub)
+	r.MustCompile()
+
+	for _, tc := range []struct {
+		path string
+		want string
+	}{
+		{path: "/users/1", want: "/users/{id}"},
+		{path: "/u/1", want: "/users/{id}"},
+		{path: "/api/items/1", want: "/api/items/{id}"},
+	} {
+		got = ""
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, tc.path, nil))
+		if got != tc.want {
+			t.Fatalf("%s pattern = %q, want %q", tc.path, got, tc.want)
+		}
+	}
+}
+
+func TestRouterRoutePrefix(t *testing.T) {
+	var calls []string
+	mw := func(name string) Middleware {
+		return func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				calls = append(calls, name)
+				next.ServeHTTP(w, req)
+			})
+		}
+	}
+	h := func(w http.ResponseWriter, req *http.Request) {
+		_, _ = w.Write([]byte(req.Pattern))
+	}
+
+	r := New()
+	r.Route("/api", func(api *Router) {
+		api.Use(mw("api"))
+		api.Get("/", h)
+		api.Route("/v1/", func(v1 *Router) {
+			v1.Get("/users/{id}", h)
+			v1.Group(func(g *Router) {
+				g.Use(mw("group"))
+				g.Get("/teams", h)
+			})
+		})
+		api.Mount("/static", http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			_, _ = w.Write([]byte("mount"))
+		}))
+	})
+	r.Get("/health", h)
+	r.MustCompile()
+
+	for _, tc := range []struct {
+		path  string
+		body  string
+		calls []string
+	}{
+		{path: "/api/", body: "/api/", calls: []string{"api"}},
+		{path: "/api/v1/users/1", body: "/api/v1/users/{id}", calls: []string{"api"}},
+		{path: "/api/v1/teams", body: "/api/v1/teams", calls: []string{"api", "group"}},
+		{path: "/api/static/app.js", body: "mount"},
+		{path: "/health", body: "/health"},
+	} {
+		calls = nil
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tc.path, nil))
+		if rec.Body.String() != tc.body {
+			t.Fatalf("%s body = %q, want %q", tc.path, rec.Body.String(), tc.body)
+		}
+		if !reflect.DeepEqual(calls, tc.calls) {
+			t.Fatalf("%s calls = %#v, want %#v", tc.path, calls, tc.calls)
+		}
+	}
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/users/1", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("unprefixed status = %d, want %d", rec.Code, http.StatusNotFound)
+	}
+}
+
+func TestRouterPrefixGroupNested(t *testing.T) {
+	var calls []string
+	mw := func(name string) Middleware {
+		return func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				calls = append(calls, name)
+				next.ServeHTTP(w, req)
+			})
+		}
+	}
+	h := func(w http.ResponseWriter, req *http.Request) {
+		_, _ = w.Write([]byte(req.Pattern))
+	}
+
+	r := New()
+	r.Use(mw("root"))
+	r.PrefixGroup("/a", func(a *Router) {
+		a.Use(mw("a"))
+		a.Get("/x", h)
+		a.PrefixGroup("/b", func(b *Router) {
+			b.Use(mw("b"))
+			b.Get("/x", h)
+			b.With(mw("inline")).WithPrefix("/c").Get("/x", h)
+		})
+		a.Get("/y", h)
+	})
+	r.WithPrefix("/d").WithPrefix("/e/").Get("/x", h)
+	r.MustCompile()
+
+	for _, tc := range []struct {
+		path  string
+		calls []string
+	}{
+		{path: "/a/x", calls: []string{"root", "a"}},
+		{path: "/a/b/x", calls: []string{"root", "a", "b"}},
+		{path: "/a/b/c/x", calls: []string{"root", "a", "b", "inline"}},
+		{path: "/a/y", calls: []string{"root", "a"}},
+		{path: "/d/e/x", calls: []string{"root"}},
+	} {
+		calls = nil
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tc.path, nil))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("%s status = %d", tc.path, rec.Code)
+		}
+		if !reflect.DeepEqual(calls, tc.calls) {
+			t.Fatalf("%s calls = %#v, want %#v", tc.path, calls, tc.calls)
+		}
+	}
+}
+
+func TestRouterWithBasePath(t *testing.T) {
+	r := New(WithBasePath("/service-a/"))
+	r.Get("/users/{id}", func(w http.ResponseWriter, req *http.Request) {
+		_, _ = w.Write([]byte("user " + req.PathValue("id")))
+	})
+	r.Route("/admin", func(admin *Router) {
+		admin.Get("/", func(w http.ResponseWriter, req *http.Request) {
+			_, _ = w.Write([]byte("admin"))
+		})
+	})
+	r.Mount("/static", http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		_, _ = w.Write([]byte("static " + req.URL.Path))
+	}))
+	r.MustCompile()
+
+	for path, want := range map[string]string{
+		"/service-a/users/7":     "user 7",
+		"/service-a/admin/":      "admin",
+		"/service-a/static/a.js": "static /service-a/static/a.js",
+	} {
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		if rec.Code != http.StatusOK || rec.Body.String() != want {
+			t.Fatalf("%s: %d %q, want %q", path, rec.Code, rec.Body.String(), want)
+		}
+	}
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/users/7", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("unprefixed path: status %d, want 404", rec.Code)
+	}
+	if !r.Remove(http.MethodGet, "/users/{id}") {
+		t.Fatal("Remove with a pattern relative to the base path failed")
+	}
+
+	bad := New(WithBasePath("service-a"))
+	bad.Get("/x", func(w http.ResponseWriter, req *http.Request) {})
+	if err := bad.Compile(); err == nil {
+		t.Fatal("expected invalid base path error")
+	}
+}
+
+func TestWithoutMethodNotAllowed(t *testing.T) {
+	r := New(WithoutMethodNotAllowed(), WithAutoOptions())
+	r.Get("/users", func(w http.ResponseWriter, req *http.Request) {})
+	r.Get("/files/{name}", func(w http.ResponseWriter, req *http.Request) {})
+	r.Mount("/files", http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	}))
+	r.NotFound(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.WriteHeader(http.StatusGone)
+	}))
+	r.MustCompile()
+
+	for _, tc := range []struct {
+		method, path string
+		want         int
+	}{
+		{http.MethodGet, "/users", http.StatusOK},
+		{http.MethodPost, "/users", http.StatusGone},
+		{http.MethodOptions, "/users", http.StatusNoContent},
+		{http.MethodDelete, "/files/a", http.StatusTeapot}, // falls through to the mount
+	} {
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, httptest.NewRequest(tc.method, tc.path, nil))
+		if rec.Code != tc.want {
+			t.Errorf("%s %s: status %d, want %d", tc.method, tc.path, rec.Code, tc.want)
+		}
+		if tc.method == http.MethodPost && rec.Header().Get("Allow") != "" {
+			t.Errorf("%s %s: Allow %q", tc.method, tc.path, rec.Header().Get("Allow"))
+		}
+	}
+
+	var ps ParamBuffer
+	if h, flags := r.Compiled().Lookup(http.MethodPost, "/users", &ps); h != nil || flags != 0 || ps.Allow != "" {
+		t.Errorf("Lookup = %v, %v, Allow %q; want a miss", h != nil, flags, ps.Allow)
+	}
+	if tr := TraceMatch(r, "/users", http.MethodPost); tr.Result != "not found" {
+		t.Errorf("TraceMatch result = %q", tr.Result)
+	}
+}
+
+func TestErrorResponsesDoNotAllocate(t *testing.T) {
+	for _, errorMiddleware := range []bool{false, true} {
+		var opts []Option
+		if errorMiddleware {
+			opts = append(opts, WithMiddlewareOnErrors())
+		}
+		r := New(opts...)
+		r.Use(func(next http.Handler) http.Handler { return next })
+		r.Get("/users", func(w http.ResponseWriter, req *http.Request) {})
+		r.MustCompile()
+
+		w := &discardResponseWriter{}
+		for _, req := range []*http.Request{
+			httptest.NewRequest(http.MethodGet, "/missing", nil),
+			httptest.NewRequest(http.MethodPost, "/users", nil),
+		} {
+			allocs := testing.AllocsPerRun(100, func() {
+				clear(w.Header())
+				r.ServeHTTP(w, req)
+			})
+			if allocs != 0 {
+				t.Errorf("errorMiddleware=%v %s %s: %v allocs, want 0", errorMiddleware, req.Method, req.URL.Path, allocs)
+			}
+		}
+	}
+}
+
+func TestDefaultErrorResponsesMatchHTTPError(t *testing.T) {
+	r := New()
+	r.Get("/users", func(w http.ResponseWriter, req *http.Request) {})
+	r.MustCompile()
+
+	tests := []struct {
+		method, path string
+		want         func(http.ResponseWriter)
+	}{
+		{http.MethodGet, "/missing", func(w http.ResponseWriter) { http.NotFound(w, nil) }},
+		{http.MethodPost, "/users", func(w http.ResponseWriter) {
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		}},
+	}
+	for _, tc := range tests {
+		got := httptest.NewRecorder()
+		r.ServeHTTP(got, httptest.NewRequest(tc.method, tc.path, nil))
+		want := httptest.NewRecorder()
+		tc.want(want)
+		if got.Code != want.Code || got.Body.String() != want.Body.String() {
+			t.Errorf("%s %s: %d %q, want %d %q", tc.method, tc.path, got.Code, got.Body, want.Code, want.Body)
+		}
+		for _, k := range []string{"Content-Type", "X-Content-Type-Options"} {
+			if got.Header().Get(k) != want.Header().Get(k) {
+				t.Errorf("%s %s: %s = %q, want %q", tc.method, tc.path, k, got.Header().Get(k), want.Header().Get(k))
+			}
+		}
+	}
+}
@@ -0,0 +1,28 @@
+package serve
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// DefaultAltSvcMaxAge is how long clients may remember the HTTP/3 endpoint
+// advertised by AdvertiseHTTP3 when maxAge is zero.
+const DefaultAltSvcMaxAge = 24 * time.Hour
+
+// AdvertiseHTTP3 returns a handler that adds an Alt-Svc header announcing
+// HTTP/3 on UDP port to every response served over HTTP/1.x or HTTP/2, then
+// calls h. Wrap the handler of the TCP listeners with it; requests that
+// already arrived over HTTP/3 are passed through unchanged.
+func AdvertiseHTTP3(port int, maxAge time.Duration, h http.Handler) http.Handler {
+	if maxAge <= 0 {
+		maxAge = DefaultAltSvcMaxAge
+	}
+	value := fmt.Sprintf(`h3=":%d"; ma=%d`, port, int64(maxAge/time.Second))
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.ProtoMajor < 3 {
+			w.Header().Add("Alt-Svc", value)
+		}
+		h.ServeHTTP(w, req)
+	})
+}
@@ -0,0 +1,37 @@
+package serve_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/catatsuy/saruta/serve"
+)
+
+func TestAdvertiseHTTP3(t *testing.T) {
+	h := serve.AdvertiseHTTP3(8443, time.Hour, newRouter())
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/users/1", nil))
+	if got := rec.Header().Get("Alt-Svc"); got != `h3=":8443"; ma=3600` {
+		t.Fatalf("Alt-Svc = %q", got)
+	}
+	if rec.Body.String() != "user 1 " {
+		t.Fatalf("body = %q", rec.Body.String())
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/users/1", nil)
+	req.Proto, req.ProtoMajor, req.ProtoMinor = "HTTP/3.0", 3, 0
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if got := rec.Header().Get("Alt-Svc"); got != "" {
+		t.Fatalf("Alt-Svc over HTTP/3 = %q, want none", got)
+	}
+
+	rec = httptest.NewRecorder()
+	serve.AdvertiseHTTP3(443, 0, newRouter()).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	if got := rec.Header().Get("Alt-Svc"); got != `h3=":443"; ma=86400` {
+		t.Fatalf("default Alt-Svc = %q", got)
+	}
+}
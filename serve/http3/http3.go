@@ -0,0 +1,61 @@
+//go:build http3
+
+// Package http3 serves a handler, typically a compiled saruta router, over
+// HTTP/3 (QUIC) next to HTTP/1.1 and HTTP/2, advertising the UDP endpoint
+// with Alt-Svc on TCP responses.
+//
+// It lives in its own module, behind the http3 build tag, so that the
+// quic-go dependency stays out of the root module:
+//
+//	go build -tags http3
+package http3
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/catatsuy/saruta/serve"
+	qhttp3 "github.com/quic-go/quic-go/http3"
+)
+
+// ListenAndServeTLS serves h on addr over TCP (HTTP/1.1, HTTP/2) and UDP
+// (HTTP/3) with the same certificate. TCP responses carry an Alt-Svc header
+// that lets clients switch to HTTP/3. It returns when either listener fails,
+// after closing the other one.
+func ListenAndServeTLS(addr, certFile, keyFile string, h http.Handler) error {
+	return ListenAndServeTLSMaxAge(addr, certFile, keyFile, 0, h)
+}
+
+// ListenAndServeTLSMaxAge is like ListenAndServeTLS with the Alt-Svc max age
+// set explicitly (see serve.AdvertiseHTTP3).
+func ListenAndServeTLSMaxAge(addr, certFile, keyFile string, maxAge time.Duration, h http.Handler) error {
+	port, err := portOf(addr)
+	if err != nil {
+		return err
+	}
+	h3 := &qhttp3.Server{Addr: addr, Handler: h}
+	tcp := &http.Server{Addr: addr, Handler: serve.AdvertiseHTTP3(port, maxAge, h)}
+
+	errc := make(chan error, 2)
+	go func() { errc <- h3.ListenAndServeTLS(certFile, keyFile) }()
+	go func() { errc <- tcp.ListenAndServeTLS(certFile, keyFile) }()
+	err = <-errc
+	return errors.Join(err, h3.Close(), tcp.Close())
+}
+
+// portOf returns the port of a listen address, 443 when it has none.
+func portOf(addr string) (int, error) {
+	if addr == "" {
+		return 443, nil
+	}
+	_, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return 0, err
+	}
+	if port == "" {
+		return 443, nil
+	}
+	return net.LookupPort("udp", port)
+}
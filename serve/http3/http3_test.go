@@ -0,0 +1,22 @@
+//go:build http3
+
+package http3
+
+import "testing"
+
+func TestPortOf(t *testing.T) {
+	for addr, want := range map[string]int{
+		"":            443,
+		":8443":       8443,
+		"localhost:":  443,
+		"[::1]:https": 443,
+	} {
+		got, err := portOf(addr)
+		if err != nil || got != want {
+			t.Fatalf("portOf(%q) = %d, %v, want %d", addr, got, err, want)
+		}
+	}
+	if _, err := portOf("no-port"); err == nil {
+		t.Fatalf("portOf without a port separator should fail")
+	}
+}
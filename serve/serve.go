@@ -0,0 +1,108 @@
+// Package serve runs an http.Handler, typically a compiled saruta router,
+// under CGI or FastCGI for shared hosting and legacy infrastructure.
+//
+//	r.MustCompile()
+//	if err := serve.CGI(r); err != nil {
+//		log.Fatal(err)
+//	}
+//
+// Under both protocols the request path is the original REQUEST_URI, which
+// includes the script location when the web server does not rewrite URLs
+// (/cgi-bin/app.cgi/users/42). The helpers strip that script name so routes
+// are registered as /users/{id} either way.
+package serve
+
+import (
+	"net"
+	"net/http"
+	"net/http/cgi"
+	"net/http/fcgi"
+	"os"
+	"strings"
+)
+
+// Option configures CGI and FastCGI.
+type Option func(*config)
+
+type config struct {
+	scriptName string
+	set        bool
+}
+
+// WithScriptName sets the script location stripped from request paths. CGI
+// defaults to the SCRIPT_NAME environment variable; FastCGI has no default,
+// since the FastCGI request does not expose SCRIPT_NAME.
+func WithScriptName(name string) Option {
+	return func(c *config) {
+		c.scriptName = name
+		c.set = true
+	}
+}
+
+func newConfig(opts []Option) *config {
+	c := &config{}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(c)
+		}
+	}
+	return c
+}
+
+// CGI serves the current request from the CGI environment with h.
+func CGI(h http.Handler, opts ...Option) error {
+	c := newConfig(opts)
+	if !c.set {
+		c.scriptName = os.Getenv("SCRIPT_NAME")
+	}
+	return cgi.Serve(StripScriptName(c.scriptName, h))
+}
+
+// FastCGI accepts FastCGI connections on l and serves them with h. If l is
+// nil, requests are read from standard input, as when the process is spawned
+// by the web server.
+func FastCGI(l net.Listener, h http.Handler, opts ...Option) error {
+	c := newConfig(opts)
+	return fcgi.Serve(l, StripScriptName(c.scriptName, h))
+}
+
+// StripScriptName returns a handler that removes the script location name
+// from the request path before calling h, so /cgi-bin/app.cgi/users/42 is
+// routed as /users/42 and /cgi-bin/app.cgi as /. Paths outside name (URLs the
+// web server already rewrote) are passed through unchanged, as is every
+// request when name is empty or "/".
+func StripScriptName(name string, h http.Handler) http.Handler {
+	name = strings.TrimSuffix(name, "/")
+	if name == "" {
+		return h
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		path, ok := trimScript(req.URL.Path, name)
+		if !ok {
+			h.ServeHTTP(w, req)
+			return
+		}
+		req2 := new(http.Request)
+		*req2 = *req
+		u := *req.URL
+		u.Path = path
+		u.RawPath = ""
+		if raw, ok := trimScript(req.URL.RawPath, name); ok {
+			u.RawPath = raw
+		}
+		req2.URL = &u
+		req2.RequestURI = u.RequestURI()
+		h.ServeHTTP(w, req2)
+	})
+}
+
+func trimScript(path, name string) (string, bool) {
+	rest, ok := strings.CutPrefix(path, name)
+	if !ok || (rest != "" && rest[0] != '/') {
+		return "", false
+	}
+	if rest == "" {
+		rest = "/"
+	}
+	return rest, true
+}
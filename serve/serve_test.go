@@ -0,0 +1,106 @@
+package serve_test
+
+import (
+	"net/http"
+	"net/http/cgi"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/catatsuy/saruta"
+	"github.com/catatsuy/saruta/serve"
+)
+
+func newRouter() *saruta.Router {
+	r := saruta.New()
+	r.Get("/", func(w http.ResponseWriter, req *http.Request) {
+		w.Write([]byte("index"))
+	})
+	r.Get("/users/{id}", func(w http.ResponseWriter, req *http.Request) {
+		w.Write([]byte("user " + req.PathValue("id") + " " + req.URL.RawQuery))
+	})
+	r.Get("/files/{path...}", func(w http.ResponseWriter, req *http.Request) {
+		w.Write([]byte("file " + req.PathValue("path")))
+	})
+	r.MustCompile()
+	return r
+}
+
+func TestStripScriptName(t *testing.T) {
+	h := serve.StripScriptName("/cgi-bin/app.cgi", newRouter())
+
+	for _, tc := range []struct {
+		requestURI string
+		code       int
+		body       string
+	}{
+		{requestURI: "/cgi-bin/app.cgi/users/42?x=1", code: http.StatusOK, body: "user 42 x=1"},
+		{requestURI: "/cgi-bin/app.cgi", code: http.StatusOK, body: "index"},
+		{requestURI: "/cgi-bin/app.cgi/", code: http.StatusOK, body: "index"},
+		{requestURI: "/cgi-bin/app.cgi/files/a%2Fb/c.txt", code: http.StatusOK, body: "file a/b/c.txt"},
+		// Rewritten by the web server: no script name in the path.
+		{requestURI: "/users/7", code: http.StatusOK, body: "user 7 "},
+		// Not on a segment boundary.
+		{requestURI: "/cgi-bin/app.cgix/users/7", code: http.StatusNotFound},
+	} {
+		req, err := cgi.RequestFromMap(map[string]string{
+			"REQUEST_METHOD":  "GET",
+			"SERVER_PROTOCOL": "HTTP/1.1",
+			"HTTP_HOST":       "example.com",
+			"REQUEST_URI":     tc.requestURI,
+		})
+		if err != nil {
+			t.Fatalf("RequestFromMap(%s): %v", tc.requestURI, err)
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		if rec.Code != tc.code {
+			t.Fatalf("%s: status=%d, want %d", tc.requestURI, rec.Code, tc.code)
+		}
+		if tc.body != "" && rec.Body.String() != tc.body {
+			t.Fatalf("%s: body=%q, want %q", tc.requestURI, rec.Body.String(), tc.body)
+		}
+	}
+}
+
+func TestStripScriptNameEmpty(t *testing.T) {
+	r := newRouter()
+	for _, name := range []string{"", "/"} {
+		rec := httptest.NewRecorder()
+		serve.StripScriptName(name, r).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/users/1", nil))
+		if rec.Body.String() != "user 1 " {
+			t.Fatalf("StripScriptName(%q) body=%q", name, rec.Body.String())
+		}
+	}
+}
+
+// TestCGIChild is the CGI program run by TestCGI; it does nothing unless
+// started by the CGI host.
+func TestCGIChild(t *testing.T) {
+	if os.Getenv("SARUTA_CGI_CHILD") != "1" {
+		t.Skip("not a CGI child")
+	}
+	if err := serve.CGI(newRouter()); err != nil {
+		t.Fatal(err)
+	}
+	os.Exit(0)
+}
+
+func TestCGI(t *testing.T) {
+	host := &cgi.Handler{
+		Path: os.Args[0],
+		Root: "/cgi-bin/app.cgi",
+		Args: []string{"-test.run=^TestCGIChild$"},
+		Env:  []string{"SARUTA_CGI_CHILD=1"},
+	}
+	for path, want := range map[string]string{
+		"/cgi-bin/app.cgi/users/42?q=go": "user 42 q=go",
+		"/cgi-bin/app.cgi":               "index",
+	} {
+		rec := httptest.NewRecorder()
+		host.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		if rec.Code != http.StatusOK || rec.Body.String() != want {
+			t.Fatalf("%s: status=%d body=%q, want %q", path, rec.Code, rec.Body.String(), want)
+		}
+	}
+}
@@ -0,0 +1,128 @@
+package saruta
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// ToServeMux registers the routes and mounts of the most recent Compile on a
+// new http.ServeMux, for libraries that insist on a standard mux. Handlers
+// are registered with their middleware, so groups and Use carry over.
+//
+// Only what ServeMux patterns can express is copied: plain {name} and
+// {name...} segments. Routes with constraints, prefixes or suffixes around
+// a parameter, several parameters in a segment or repeated parameters are
+// skipped, as are MountBeforeRoutes mounts and routes ServeMux rejects as
+// conflicting; the returned error lists them, and the mux holds the rest.
+// Rewrites, noise rules and router-level error handlers are not copied.
+//
+// Some behavior follows ServeMux rather than saruta: GET routes also answer
+// HEAD, mounts also take requests with another method for a route path
+// (like MountBeforeMethodNotAllowed), req.Pattern includes the method, and
+// requests are matched on the unescaped segments of the path.
+func (r *Router) ToServeMux() (*http.ServeMux, error) {
+	c := r.state.current.Load()
+	if c == nil {
+		return nil, fmt.Errorf("saruta: router is not compiled; call Compile before ToServeMux")
+	}
+	mux := http.NewServeMux()
+	var errs []error
+	if c.rewriteRoot != nil {
+		errs = append(errs, fmt.Errorf("saruta: ToServeMux: rewrites are not supported"))
+	}
+	exportServeMux(mux, c.root, c.root.node(0), "", true, &errs)
+	return mux, errors.Join(errs...)
+}
+
+// exportServeMux registers the leaves below n. path is the pattern text
+// leading to n, rebuilt from the edges, so that an alias leaf is registered
+// under its own path rather than the pattern of the route it aliases. static
+// reports that path has no parameters; mounts only sit below static edges.
+func exportServeMux(mux *http.ServeMux, t *radixTree, n *radixNode, path string, static bool, errs *[]error) {
+	leaf := t.leaf(n)
+	if leaf.mount != nil && static {
+		if err := serveMuxMount(mux, path, leaf.mount); err != nil {
+			*errs = append(*errs, err)
+		}
+	}
+	if leaf.handlers != nil {
+		own := path
+		if own == "" {
+			own = "/"
+		}
+		pattern, err := serveMuxPattern(own)
+		for _, method := range leaf.handlers.methods() {
+			if err == nil {
+				h, _ := leaf.handlers.get(method)
+				err = serveMuxHandle(mux, method+" "+pattern, h)
+			}
+			if err != nil {
+				*errs = append(*errs, fmt.Errorf("saruta: ToServeMux: %s %s: %w", method, own, err))
+			}
+		}
+	}
+	for _, e := range t.staticEdges(n) {
+		exportServeMux(mux, t, t.node(e.next), path+t.label(e), static, errs)
+	}
+	if pe := t.paramChild(n); pe != nil {
+		exportServeMux(mux, t, t.node(pe.next), path+"/"+paramLabel(pe.name, pe.prefix, pe.suffix, pe.tmpl), false, errs)
+	}
+	if pe := t.catchAllChild(n); pe != nil {
+		exportServeMux(mux, t, t.node(pe.next), path+"/"+catchAllLabel(pe.name, pe.expr, pe.repeated), false, errs)
+	}
+}
+
+// serveMuxPattern returns the ServeMux form of a saruta pattern, without
+// the method. A trailing slash gets {$}, since ServeMux would otherwise
+// treat the pattern as a prefix.
+func serveMuxPattern(pattern string) (string, error) {
+	cp, err := compilePattern(pattern)
+	if err != nil {
+		return "", err
+	}
+	for _, seg := range cp.segments {
+		switch seg.kind {
+		case segmentParam:
+			if len(seg.tmpl.params) != 1 || seg.prefix != "" || seg.suffix != "" || seg.expr != "" {
+				return "", fmt.Errorf("segment %q has no ServeMux equivalent", "{"+seg.name+"}")
+			}
+		case segmentCatchAll:
+			if seg.repeated || seg.expr != "" {
+				return "", fmt.Errorf("catch-all %q has no ServeMux equivalent", seg.name)
+			}
+		}
+	}
+	if strings.HasSuffix(pattern, "/") {
+		pattern += "{$}"
+	}
+	return pattern, nil
+}
+
+func serveMuxMount(mux *http.ServeMux, prefix string, m *mountEntry) error {
+	if m.precedence == MountBeforeRoutes {
+		return fmt.Errorf("saruta: ToServeMux: mount %s: MountBeforeRoutes has no ServeMux equivalent", prefix)
+	}
+	if prefix != "" {
+		if err := serveMuxHandle(mux, prefix, m.handler); err != nil {
+			return fmt.Errorf("saruta: ToServeMux: mount %s: %w", prefix, err)
+		}
+	}
+	if err := serveMuxHandle(mux, prefix+"/", m.handler); err != nil {
+		return fmt.Errorf("saruta: ToServeMux: mount %s: %w", prefix, err)
+	}
+	return nil
+}
+
+// serveMuxHandle is mux.Handle, returning the panic ServeMux raises for a
+// conflicting pattern as an error.
+func serveMuxHandle(mux *http.ServeMux, pattern string, h http.Handler) (err error) {
+	defer func() {
+		if v := recover(); v != nil {
+			err = fmt.Errorf("%v", v)
+		}
+	}()
+	mux.Handle(pattern, h)
+	return nil
+}
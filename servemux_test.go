@@ -0,0 +1,113 @@
+package saruta
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestToServeMux(t *testing.T) {
+	r := New()
+	r.Use(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			w.Header().Set("X-Mw", "1")
+			next.ServeHTTP(w, req)
+		})
+	})
+	echo := func(w http.ResponseWriter, req *http.Request) {
+		w.Write([]byte(req.Pattern + " " + req.PathValue("id") + req.PathValue("path")))
+	}
+	r.Get("/", echo)
+	r.Get("/users/{id}", echo)
+	r.Post("/users/", echo)
+	r.Get("/files/{path...}", echo)
+	r.Get("/items/{id:[0-9]+}", echo)
+	r.Get("/img/{id}.png", echo)
+	r.Mount("/legacy", http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Write([]byte("legacy " + req.URL.Path))
+	}))
+	r.MustCompile()
+
+	mux, err := r.ToServeMux()
+	if err == nil {
+		t.Fatal("ToServeMux reported no skipped routes")
+	}
+	for _, want := range []string{"GET /items/{id:[0-9]+}", "GET /img/{id}.png"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q does not mention %s", err, want)
+		}
+	}
+
+	tests := []struct {
+		method, path string
+		code         int
+		body         string
+	}{
+		{http.MethodGet, "/", 200, "GET /{$} "},
+		{http.MethodGet, "/users/42", 200, "GET /users/{id} 42"},
+		{http.MethodPost, "/users/", 200, "POST /users/{$} "},
+		{http.MethodPost, "/users/x/y", 404, ""},
+		{http.MethodGet, "/files/a/b", 200, "GET /files/{path...} a/b"},
+		{http.MethodDelete, "/users/42", 405, ""},
+		{http.MethodGet, "/items/1", 404, ""},
+		{http.MethodGet, "/legacy", 200, "legacy /legacy"},
+		{http.MethodPut, "/legacy/x", 200, "legacy /legacy/x"},
+	}
+	for _, tc := range tests {
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest(tc.method, tc.path, nil))
+		if rec.Code != tc.code {
+			t.Errorf("%s %s: status %d, want %d", tc.method, tc.path, rec.Code, tc.code)
+			continue
+		}
+		if tc.code == 200 && rec.Body.String() != tc.body {
+			t.Errorf("%s %s: body %q, want %q", tc.method, tc.path, rec.Body, tc.body)
+		}
+		if tc.code == 200 && !strings.HasPrefix(tc.path, "/legacy") && rec.Header().Get("X-Mw") != "1" {
+			t.Errorf("%s %s: middleware did not run", tc.method, tc.path)
+		}
+	}
+}
+
+func TestToServeMuxErrors(t *testing.T) {
+	if _, err := New().ToServeMux(); err == nil {
+		t.Error("ToServeMux on an uncompiled router succeeded")
+	}
+
+	r := New()
+	r.Get("/{a}/b", func(w http.ResponseWriter, req *http.Request) {})
+	r.Get("/a/{b}", func(w http.ResponseWriter, req *http.Request) {})
+	r.Mount("/first", http.NotFoundHandler(), WithMountPrecedence(MountBeforeRoutes))
+	r.MustCompile()
+	mux, err := r.ToServeMux()
+	if mux == nil || err == nil {
+		t.Fatalf("ToServeMux = %v, %v; want a mux and an error", mux, err)
+	}
+	for _, want := range []string{"conflicts", "MountBeforeRoutes"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q does not mention %s", err, want)
+		}
+	}
+}
+
+func TestToServeMuxAlias(t *testing.T) {
+	r := New()
+	r.Get("/users/{id}", func(w http.ResponseWriter, req *http.Request) {
+		w.Write([]byte(req.PathValue("id")))
+	})
+	r.Alias("/users/{id}", "/u/{id}")
+	r.MustCompile()
+
+	mux, err := r.ToServeMux()
+	if err != nil {
+		t.Fatalf("ToServeMux: %v", err)
+	}
+	for _, path := range []string{"/users/7", "/u/7"} {
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		if rec.Code != http.StatusOK || rec.Body.String() != "7" {
+			t.Errorf("GET %s: %d %q, want 200 \"7\"", path, rec.Code, rec.Body)
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package saruta
+
+import (
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// packageDir is the directory of this package's source files, used to skip
+// its own frames when recording where a route was registered.
+var packageDir = func() string {
+	_, file, _, _ := runtime.Caller(0)
+	return filepath.Dir(file)
+}()
+
+// callerSource returns "dir/file.go:line" for the first caller outside this
+// package (test files count as outside), or "" if it cannot be determined.
+// Helpers such as Get, Health and MountDebug are thereby attributed to the
+// application code that called them.
+func callerSource() string {
+	var pcs [16]uintptr
+	n := runtime.Callers(2, pcs[:])
+	frames := runtime.CallersFrames(pcs[:n])
+	for {
+		f, more := frames.Next()
+		if f.File != "" && (filepath.Dir(f.File) != packageDir || strings.HasSuffix(f.File, "_test.go")) {
+			dir, file := filepath.Split(f.File)
+			return filepath.Base(dir) + "/" + file + ":" + strconv.Itoa(f.Line)
+		}
+		if !more {
+			return ""
+		}
+	}
+}
+
+// describeRoute names a registration in conflict errors, e.g.
+// "GET /users/{id} (api/routes.go:42)".
+func describeRoute(method, pattern, source string) string {
+	s := method + " " + pattern
+	if source != "" {
+		s += " (" + source + ")"
+	}
+	return s
+}
@@ -0,0 +1,62 @@
+package saruta
+
+import (
+	"net/http"
+	"regexp"
+	"testing"
+)
+
+func TestCompileConflictNamesBothRoutes(t *testing.T) {
+	noop := func(w http.ResponseWriter, req *http.Request) {}
+	tests := []struct {
+		name     string
+		register func(r *Router)
+		want     string
+	}{
+		{
+			name: "param",
+			register: func(r *Router) {
+				r.Get("/users/{id}", noop)
+				r.Route("/users", func(r *Router) { r.Delete("/{name}", noop) })
+			},
+			want: `^route conflict: DELETE /users/\{name\} \([^/ ]+/source_test\.go:\d+\) conflicts with parameter \{id\} of GET /users/\{id\} \([^/ ]+/source_test\.go:\d+\)$`,
+		},
+		{
+			name: "catch-all",
+			register: func(r *Router) {
+				r.Get("/files/{path...}", noop)
+				r.Get("/files/{rest...}", noop)
+			},
+			want: `^route conflict: GET /files/\{rest\.\.\.\} \([^/ ]+/source_test\.go:\d+\) conflicts with catch-all \{path\.\.\.\} of GET /files/\{path\.\.\.\} \([^/ ]+/source_test\.go:\d+\)$`,
+		},
+		{
+			name: "duplicate",
+			register: func(r *Router) {
+				r.Get("/users", noop)
+				r.HandleFunc(http.MethodGet, "/users", noop)
+			},
+			want: `^duplicate route: GET /users \([^/ ]+/source_test\.go:\d+\) is already registered by GET /users \([^/ ]+/source_test\.go:\d+\)$`,
+		},
+		{
+			name: "mount",
+			register: func(r *Router) {
+				r.Mount("/static", http.NotFoundHandler())
+				r.Mount("/static", http.NotFoundHandler())
+			},
+			want: `^duplicate mount: mount /static \([^/ ]+/source_test\.go:\d+\) conflicts with mount /static \([^/ ]+/source_test\.go:\d+\)$`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := New()
+			tt.register(r)
+			err := r.Compile()
+			if err == nil {
+				t.Fatal("expected compile error")
+			}
+			if !regexp.MustCompile(tt.want).MatchString(err.Error()) {
+				t.Fatalf("error = %q, want match for %s", err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,276 @@
+package saruta
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"path"
+	"strings"
+	"sync"
+)
+
+type staticConfig struct {
+	contentHash bool
+	etag        func(name string, info fs.FileInfo) string
+	exclude     []string
+}
+
+// StaticOption configures StaticFS and SPA.
+type StaticOption func(*staticConfig)
+
+// WithContentHash enables content hashing: responses carry a strong ETag
+// derived from the file contents, and StaticFiles.Path returns versioned URLs
+// that are served with immutable cache headers.
+//
+// Hashes are computed on first use and cached, so the file system must not
+// change while serving (embed.FS never does).
+func WithContentHash() StaticOption {
+	return func(c *staticConfig) {
+		c.contentHash = true
+	}
+}
+
+// WithETagFunc sets the function that computes the ETag of a file. fn returns
+// the complete header value including quotes (and the W/ prefix for weak
+// tags), or "" to send no ETag. It takes precedence over the content hash ETag
+// from WithContentHash; versioned URLs keep working.
+func WithETagFunc(fn func(name string, info fs.FileInfo) string) StaticOption {
+	return func(c *staticConfig) {
+		c.etag = fn
+	}
+}
+
+// ModTimeETag is an ETag function for WithETagFunc that derives a weak ETag
+// from the file's size and ModTime. Use WithContentHash for embed.FS, whose
+// ModTimes are all zero.
+func ModTimeETag(name string, info fs.FileInfo) string {
+	return fmt.Sprintf(`W/"%x-%x"`, info.ModTime().UnixNano(), info.Size())
+}
+
+// WithSPAExclude keeps SPA from answering paths under the given prefixes
+// (for example "/api/"), so unknown API paths get a real 404 instead of the
+// index document. It has no effect on StaticFS.
+func WithSPAExclude(prefixes ...string) StaticOption {
+	return func(c *staticConfig) {
+		c.exclude = append(c.exclude, prefixes...)
+	}
+}
+
+// StaticFiles serves files registered with StaticFS.
+type StaticFiles struct {
+	fsys     fs.FS
+	prefix   string
+	cfg      staticConfig
+	notFound http.Handler
+	hashes   sync.Map // name -> hex content hash
+}
+
+// StaticFS serves the files of fsys under prefix (GET and HEAD), typically
+// fs.Sub of an embed.FS:
+//
+//	r.StaticFS("/assets", fs.Sub(embedded, "public"))
+//
+// Files are served with http.ServeContent, so Range, If-Range,
+// If-Modified-Since and If-None-Match (see WithContentHash and WithETagFunc)
+// are handled. Directories serve their index.html and are never listed. Files with a zero
+// ModTime (as in embed.FS) are served without Last-Modified instead of with
+// the Unix epoch. Missing files use the router's NotFound handler.
+func (r *Router) StaticFS(prefix string, fsys fs.FS, opts ...StaticOption) *StaticFiles {
+	prefix = strings.TrimSuffix(prefix, "/")
+	s := &StaticFiles{
+		fsys:     fsys,
+		prefix:   r.prefix + prefix,
+		notFound: http.HandlerFunc(r.serveRouteNotFound),
+	}
+	for _, opt := range opts {
+		opt(&s.cfg)
+	}
+	bind := func(br *Router) http.Handler {
+		bs := s
+		if br.state != r.state {
+			bs = &StaticFiles{fsys: s.fsys, prefix: s.prefix, cfg: s.cfg, notFound: http.HandlerFunc(br.serveRouteNotFound)}
+		}
+		return http.HandlerFunc(bs.ServeHTTP)
+	}
+	r.handleBound(http.MethodGet, prefix+"/{path...}", bind)
+	r.handleBound(http.MethodHead, prefix+"/{path...}", bind)
+	return s
+}
+
+// SPA serves a single-page application from fsys under prefix.
+//
+// SPA is installed as the router's NotFound handler, so registered routes and
+// mounts always win. GET and HEAD requests under prefix that match no route
+// are served the file from fsys when it exists; otherwise paths without a file
+// extension (client-side routes) get index with "Cache-Control: no-cache".
+// Missing assets (paths with an extension), other methods and paths excluded
+// with WithSPAExclude fall through to the NotFound handler set before SPA.
+func (r *Router) SPA(prefix string, fsys fs.FS, index string, opts ...StaticOption) *StaticFiles {
+	prefix = r.prefix + strings.TrimSuffix(prefix, "/")
+	fallback := r.state.notFound
+	if fallback == nil {
+		fallback = http.HandlerFunc(http.NotFound)
+	}
+	s := &StaticFiles{
+		fsys:     fsys,
+		prefix:   prefix,
+		notFound: fallback,
+	}
+	for _, opt := range opts {
+		opt(&s.cfg)
+	}
+	index = strings.TrimPrefix(index, "/")
+
+	r.NotFound(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		name, ok := s.spaName(req)
+		if !ok {
+			fallback.ServeHTTP(w, req)
+			return
+		}
+		if s.serveFile(w, req, name) {
+			return
+		}
+		if path.Ext(name) != "" {
+			fallback.ServeHTTP(w, req)
+			return
+		}
+		w.Header().Set("Cache-Control", "no-cache")
+		if !s.serveFile(w, req, index) {
+			w.Header().Del("Cache-Control")
+			fallback.ServeHTTP(w, req)
+		}
+	}))
+	return s
+}
+
+// spaName returns the file name for req, or false when SPA must not answer it.
+func (s *StaticFiles) spaName(req *http.Request) (string, bool) {
+	if req.Method != http.MethodGet && req.Method != http.MethodHead {
+		return "", false
+	}
+	p := req.URL.Path
+	for _, ex := range s.cfg.exclude {
+		if strings.HasPrefix(p, ex) {
+			return "", false
+		}
+	}
+	if p != s.prefix && !strings.HasPrefix(p, s.prefix+"/") {
+		return "", false
+	}
+	return strings.TrimPrefix(strings.TrimPrefix(p, s.prefix), "/"), true
+}
+
+// ServeHTTP serves the file named by the path value "path".
+func (s *StaticFiles) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if !s.serveFile(w, req, Param(req, "path")) {
+		s.notFound.ServeHTTP(w, req)
+	}
+}
+
+// Path returns the URL path for the file name. With WithContentHash the path
+// carries a version query (?v=<hash>); requests for the current version are
+// served with "Cache-Control: public, max-age=31536000, immutable".
+func (s *StaticFiles) Path(name string) string {
+	name = strings.TrimPrefix(name, "/")
+	p := s.prefix + "/" + name
+	if !s.cfg.contentHash {
+		return p
+	}
+	hash, err := s.hash(name)
+	if err != nil {
+		return p
+	}
+	return p + "?v=" + hash
+}
+
+// serveFile reports whether name was found and served.
+func (s *StaticFiles) serveFile(w http.ResponseWriter, req *http.Request, name string) bool {
+	name = strings.TrimSuffix(name, "/")
+	if name == "" {
+		name = "."
+	}
+	if !fs.ValidPath(name) {
+		return false
+	}
+	f, info, name, err := s.open(name)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+
+	content, ok := f.(io.ReadSeeker)
+	if !ok {
+		data, err := io.ReadAll(f)
+		if err != nil {
+			return false
+		}
+		content = bytes.NewReader(data)
+	}
+	if s.cfg.contentHash {
+		if hash, err := s.hash(name); err == nil {
+			w.Header().Set("ETag", `"`+hash+`"`)
+			if req.URL.Query().Get("v") == hash {
+				w.Header().Set("Cache-Control", "public, max-age=31536000, immutable")
+			}
+		}
+	}
+	if s.cfg.etag != nil {
+		if etag := s.cfg.etag(name, info); etag != "" {
+			w.Header().Set("ETag", etag)
+		} else {
+			w.Header().Del("ETag")
+		}
+	}
+	http.ServeContent(w, req, info.Name(), info.ModTime(), content)
+	return true
+}
+
+// open opens name, resolving directories to their index.html.
+func (s *StaticFiles) open(name string) (fs.File, fs.FileInfo, string, error) {
+	f, err := s.fsys.Open(name)
+	if err != nil {
+		return nil, nil, "", err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, nil, "", err
+	}
+	if !info.IsDir() {
+		return f, info, name, nil
+	}
+	f.Close()
+	name = path.Join(name, "index.html")
+	f, err = s.fsys.Open(name)
+	if err != nil {
+		return nil, nil, "", err
+	}
+	info, err = f.Stat()
+	if err != nil || info.IsDir() {
+		f.Close()
+		return nil, nil, "", fs.ErrNotExist
+	}
+	return f, info, name, nil
+}
+
+func (s *StaticFiles) hash(name string) (string, error) {
+	if v, ok := s.hashes.Load(name); ok {
+		return v.(string), nil
+	}
+	f, err := s.fsys.Open(name)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	sum := hex.EncodeToString(h.Sum(nil)[:8])
+	s.hashes.Store(name, sum)
+	return sum, nil
+}
@@ -0,0 +1,229 @@
+package saruta
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+func TestRouterStaticFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"app.js":          {Data: []byte("console.log(1)")},
+		"docs/index.html": {Data: []byte("<h1>docs</h1>")},
+		"old.txt":         {Data: []byte("old"), ModTime: time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)},
+	}
+	r := New()
+	r.NotFound(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	}))
+	r.StaticFS("/assets/", fsys)
+	r.MustCompile()
+
+	for _, tc := range []struct {
+		method string
+		path   string
+		code   int
+		body   string
+	}{
+		{method: http.MethodGet, path: "/assets/app.js", code: http.StatusOK, body: "console.log(1)"},
+		{method: http.MethodHead, path: "/assets/app.js", code: http.StatusOK},
+		{method: http.MethodGet, path: "/assets/docs/", code: http.StatusOK, body: "<h1>docs</h1>"},
+		{method: http.MethodGet, path: "/assets/docs", code: http.StatusOK, body: "<h1>docs</h1>"},
+		{method: http.MethodGet, path: "/assets/", code: http.StatusTeapot},
+		{method: http.MethodGet, path: "/assets/missing.js", code: http.StatusTeapot},
+		{method: http.MethodGet, path: "/assets/../static_test.go", code: http.StatusTeapot},
+		{method: http.MethodPost, path: "/assets/app.js", code: http.StatusMethodNotAllowed},
+	} {
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, httptest.NewRequest(tc.method, tc.path, nil))
+		if rec.Code != tc.code {
+			t.Fatalf("%s %s status = %d, want %d", tc.method, tc.path, rec.Code, tc.code)
+		}
+		if tc.body != "" && rec.Body.String() != tc.body {
+			t.Fatalf("%s %s body = %q, want %q", tc.method, tc.path, rec.Body.String(), tc.body)
+		}
+	}
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/assets/app.js", nil))
+	if got := rec.Header().Get("Last-Modified"); got != "" {
+		t.Fatalf("zero ModTime Last-Modified = %q, want empty", got)
+	}
+	if got := rec.Header().Get("Content-Type"); !strings.HasPrefix(got, "text/javascript") {
+		t.Fatalf("Content-Type = %q", got)
+	}
+
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/assets/old.txt", nil))
+	if got := rec.Header().Get("Last-Modified"); got != "Thu, 02 Jan 2020 03:04:05 GMT" {
+		t.Fatalf("Last-Modified = %q", got)
+	}
+}
+
+func TestRouterStaticFSContentHash(t *testing.T) {
+	fsys := fstest.MapFS{"app.js": {Data: []byte("console.log(1)")}}
+	r := New()
+	assets := r.WithPrefix("/static").StaticFS("/assets", fsys, WithContentHash())
+	r.MustCompile()
+
+	p := assets.Path("app.js")
+	if !strings.HasPrefix(p, "/static/assets/app.js?v=") {
+		t.Fatalf("Path = %q", p)
+	}
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, p, nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d", rec.Code)
+	}
+	etag := rec.Header().Get("ETag")
+	if etag != `"`+strings.TrimPrefix(p, "/static/assets/app.js?v=")+`"` {
+		t.Fatalf("ETag = %q", etag)
+	}
+	if got := rec.Header().Get("Cache-Control"); got != "public, max-age=31536000, immutable" {
+		t.Fatalf("Cache-Control = %q", got)
+	}
+
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/static/assets/app.js?v=stale", nil))
+	if got := rec.Header().Get("Cache-Control"); got != "" {
+		t.Fatalf("stale version Cache-Control = %q, want empty", got)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/static/assets/app.js", nil)
+	req.Header.Set("If-None-Match", etag)
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	if rec.Code != http.StatusNotModified {
+		t.Fatalf("If-None-Match status = %d, want %d", rec.Code, http.StatusNotModified)
+	}
+}
+
+func TestRouterSPA(t *testing.T) {
+	fsys := fstest.MapFS{
+		"index.html":     {Data: []byte("<app>")},
+		"assets/app.js":  {Data: []byte("js")},
+		"robots.txt":     {Data: []byte("robots")},
+		"docs/guide.txt": {Data: []byte("guide")},
+	}
+	r := New()
+	r.Get("/api/users", func(w http.ResponseWriter, req *http.Request) {
+		w.Write([]byte("users"))
+	})
+	r.SPA("/", fsys, "index.html", WithSPAExclude("/api/"))
+	r.MustCompile()
+
+	for _, tc := range []struct {
+		method string
+		path   string
+		code   int
+		body   string
+	}{
+		{method: http.MethodGet, path: "/", code: http.StatusOK, body: "<app>"},
+		{method: http.MethodGet, path: "/assets/app.js", code: http.StatusOK, body: "js"},
+		{method: http.MethodGet, path: "/robots.txt", code: http.StatusOK, body: "robots"},
+		{method: http.MethodGet, path: "/users/42/edit", code: http.StatusOK, body: "<app>"},
+		{method: http.MethodHead, path: "/settings", code: http.StatusOK},
+		{method: http.MethodGet, path: "/api/users", code: http.StatusOK, body: "users"},
+		{method: http.MethodGet, path: "/api/unknown", code: http.StatusNotFound},
+		{method: http.MethodPost, path: "/api/users", code: http.StatusMethodNotAllowed},
+		{method: http.MethodGet, path: "/assets/missing.js", code: http.StatusNotFound},
+		{method: http.MethodPost, path: "/settings", code: http.StatusNotFound},
+	} {
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, httptest.NewRequest(tc.method, tc.path, nil))
+		if rec.Code != tc.code {
+			t.Fatalf("%s %s status = %d, want %d", tc.method, tc.path, rec.Code, tc.code)
+		}
+		if tc.body != "" && rec.Body.String() != tc.body {
+			t.Fatalf("%s %s body = %q, want %q", tc.method, tc.path, rec.Body.String(), tc.body)
+		}
+	}
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/users/42", nil))
+	if got := rec.Header().Get("Cache-Control"); got != "no-cache" {
+		t.Fatalf("fallback Cache-Control = %q, want no-cache", got)
+	}
+}
+
+func TestRouterSPAPrefixKeepsNotFound(t *testing.T) {
+	fsys := fstest.MapFS{"index.html": {Data: []byte("<app>")}}
+	r := New()
+	r.NotFound(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	}))
+	r.SPA("/app", fsys, "index.html")
+	r.MustCompile()
+
+	for path, want := range map[string]int{
+		"/app":         http.StatusOK,
+		"/app/a/b":     http.StatusOK,
+		"/other":       http.StatusTeapot,
+		"/application": http.StatusTeapot,
+	} {
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		if rec.Code != want {
+			t.Fatalf("GET %s status = %d, want %d", path, rec.Code, want)
+		}
+	}
+}
+
+func TestRouterStaticFSConditionalAndRange(t *testing.T) {
+	mod := time.Date(2024, 5, 6, 7, 8, 9, 0, time.UTC)
+	fsys := fstest.MapFS{"video.bin": {Data: []byte("0123456789"), ModTime: mod}}
+	r := New()
+	r.StaticFS("/media", fsys, WithETagFunc(ModTimeETag))
+	r.MustCompile()
+
+	serve := func(header map[string]string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/media/video.bin", nil)
+		for k, v := range header {
+			req.Header.Set(k, v)
+		}
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := serve(nil)
+	etag := rec.Header().Get("ETag")
+	if rec.Code != http.StatusOK || !strings.HasPrefix(etag, `W/"`) {
+		t.Fatalf("status = %d ETag = %q", rec.Code, etag)
+	}
+	if got := rec.Header().Get("Accept-Ranges"); got != "bytes" {
+		t.Fatalf("Accept-Ranges = %q", got)
+	}
+
+	rec = serve(map[string]string{"Range": "bytes=2-5"})
+	if rec.Code != http.StatusPartialContent || rec.Body.String() != "2345" {
+		t.Fatalf("Range status = %d body = %q", rec.Code, rec.Body.String())
+	}
+	if got := rec.Header().Get("Content-Range"); got != "bytes 2-5/10" {
+		t.Fatalf("Content-Range = %q", got)
+	}
+
+	rec = serve(map[string]string{"If-None-Match": etag})
+	if rec.Code != http.StatusNotModified {
+		t.Fatalf("If-None-Match status = %d, want %d", rec.Code, http.StatusNotModified)
+	}
+
+	rec = serve(map[string]string{"If-Modified-Since": mod.Format(http.TimeFormat)})
+	if rec.Code != http.StatusNotModified {
+		t.Fatalf("If-Modified-Since status = %d, want %d", rec.Code, http.StatusNotModified)
+	}
+
+	rec = serve(map[string]string{"Range": "bytes=0-1", "If-Range": mod.Add(-time.Hour).Format(http.TimeFormat)})
+	if rec.Code != http.StatusOK || rec.Body.String() != "0123456789" {
+		t.Fatalf("stale If-Range status = %d body = %q", rec.Code, rec.Body.String())
+	}
+
+	rec = serve(map[string]string{"Range": "bytes=20-30"})
+	if rec.Code != http.StatusRequestedRangeNotSatisfiable {
+		t.Fatalf("bad Range status = %d, want %d", rec.Code, http.StatusRequestedRangeNotSatisfiable)
+	}
+}
@@ -0,0 +1,110 @@
+//go:build !tinygo
+
+package saruta
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"sync"
+)
+
+// MetaStreaming marks routes registered through Router.Streaming.
+const MetaStreaming = "streaming"
+
+// ErrBodyReadBeforeHandler is returned by request body reads performed by
+// middleware of a streaming route.
+var ErrBodyReadBeforeHandler = errors.New("saruta: request body read before the streaming handler")
+
+var bodyReaders sync.Map // code pointer -> struct{}
+
+// MarkBodyReader declares that mw reads or buffers the request body and
+// returns mw unchanged.
+//
+// Compile rejects streaming routes whose middleware chain contains a marked
+// middleware. Marking applies to the function, so every middleware created
+// by the same function literal is treated as a body reader.
+func MarkBodyReader(mw Middleware) Middleware {
+	if mw != nil {
+		bodyReaders.Store(reflect.ValueOf(mw).Pointer(), struct{}{})
+	}
+	return mw
+}
+
+func isBodyReader(mw Middleware) bool {
+	if mw == nil {
+		return false
+	}
+	_, ok := bodyReaders.Load(reflect.ValueOf(mw).Pointer())
+	return ok
+}
+
+// Streaming returns a derived router whose routes are pass-through upload
+// endpoints.
+//
+// The router never buffers request bodies. For streaming routes it also
+// enforces that middleware leaves the body alone: Compile fails if the chain
+// contains middleware marked with MarkBodyReader, and at runtime any body
+// read that happens before the handler fails with ErrBodyReadBeforeHandler.
+func (r *Router) Streaming() *Router {
+	return r.WithMeta(MetaStreaming, true)
+}
+
+func init() {
+	chainRoute = chainStreamingRoute
+}
+
+// chainStreamingRoute is chainRoute for builds that support streaming routes.
+func chainStreamingRoute(rt registeredRoute, h http.Handler, route RouteInfo) (http.Handler, error) {
+	if streaming, _ := rt.meta[MetaStreaming].(bool); !streaming {
+		return chainMiddlewares(h, rt.middleware, route), nil
+	}
+	if err := checkStreamingRoute(rt); err != nil {
+		return nil, err
+	}
+	return guardStreamingBody(h, rt.middleware, route), nil
+}
+
+// checkStreamingRoute rejects a streaming route whose middleware reads the
+// request body.
+func checkStreamingRoute(rt registeredRoute) error {
+	for _, m := range rt.middleware {
+		if isBodyReader(m.mw) {
+			return fmt.Errorf("invalid streaming route %s %s: middleware reads the request body", rt.method, rt.pattern)
+		}
+	}
+	return nil
+}
+
+// guardStreamingBody wraps a streaming route handler chain so that body reads
+// fail until the route handler itself runs.
+func guardStreamingBody(handler http.Handler, mws []middlewareEntry, route RouteInfo) http.Handler {
+	inner := http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if g, ok := req.Body.(*guardedBody); ok {
+			g.open = true
+			req.Body = g.ReadCloser
+		}
+		handler.ServeHTTP(w, req)
+	})
+	chained := chainMiddlewares(inner, mws, route)
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Body != nil && req.Body != http.NoBody {
+			req.Body = &guardedBody{ReadCloser: req.Body}
+		}
+		chained.ServeHTTP(w, req)
+	})
+}
+
+type guardedBody struct {
+	io.ReadCloser
+	open bool
+}
+
+func (b *guardedBody) Read(p []byte) (int, error) {
+	if !b.open {
+		return 0, ErrBodyReadBeforeHandler
+	}
+	return b.ReadCloser.Read(p)
+}
@@ -0,0 +1,73 @@
+//go:build !tinygo
+
+package saruta
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestRouterStreamingRoutePassesBodyThrough(t *testing.T) {
+	r := New()
+	r.Use(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			w.Header().Set("X-Logged", "1")
+			next.ServeHTTP(w, req)
+		})
+	})
+	r.Streaming().Post("/upload", func(w http.ResponseWriter, req *http.Request) {
+		n, err := io.Copy(io.Discard, req.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		_, _ = io.WriteString(w, strconv.FormatInt(n, 10))
+	})
+	r.MustCompile()
+
+	pr, pw := io.Pipe()
+	go func() {
+		for i := 0; i < 4; i++ {
+			_, _ = pw.Write([]byte("chunk"))
+		}
+		_ = pw.Close()
+	}()
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/upload", pr))
+	if rec.Code != http.StatusOK || rec.Body.String() != "20" {
+		t.Fatalf("status = %d body = %q", rec.Code, rec.Body.String())
+	}
+}
+
+func TestRouterStreamingRouteRejectsBodyReadingMiddleware(t *testing.T) {
+	var readErr error
+	reader := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			_, readErr = io.ReadAll(req.Body)
+			next.ServeHTTP(w, req)
+		})
+	}
+
+	r := New()
+	r.Streaming().With(reader).Post("/upload", func(w http.ResponseWriter, req *http.Request) {})
+	r.MustCompile()
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/upload", strings.NewReader("data")))
+	if !errors.Is(readErr, ErrBodyReadBeforeHandler) {
+		t.Fatalf("read error = %v, want %v", readErr, ErrBodyReadBeforeHandler)
+	}
+
+	r = New()
+	r.Use(MarkBodyReader(reader))
+	r.Post("/form", func(w http.ResponseWriter, req *http.Request) {})
+	r.MustCompile()
+
+	r.Streaming().Post("/upload", func(w http.ResponseWriter, req *http.Request) {})
+	if err := r.Compile(); err == nil {
+		t.Fatalf("expected compile error for body-reading middleware on streaming route")
+	}
+}
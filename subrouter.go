@@ -0,0 +1,133 @@
+package saruta
+
+import (
+	"fmt"
+	"maps"
+	"strings"
+)
+
+type registeredSubRouter struct {
+	prefix     string
+	router     *Router
+	middleware []middlewareEntry
+	meta       Meta
+}
+
+// registrations is the flattened registration set of a router, including
+// everything merged in from sub-routers.
+type registrations struct {
+	routes   []registeredRoute
+	mounts   []registeredMount
+	aliases  []registeredAlias
+	rewrites []registeredRewrite
+}
+
+// MountRouter merges the routes of sub into r under prefix at compile time.
+//
+// Unlike Mount, the sub-router's routes become part of r's tree, so 405
+// responses, Allow headers and path parameters in prefix work as for routes
+// registered on r directly. Middleware of r at the time of the call runs
+// before the sub-router's own middleware. The sub-router's NotFound and
+// MethodNotAllowed handlers are not used. Validation is deferred until Compile.
+func (r *Router) MountRouter(prefix string, sub *Router) {
+	r.checkFrozen("MountRouter")
+	r.state.subRouters = append(r.state.subRouters, registeredSubRouter{
+		prefix:     r.prefix + prefix,
+		router:     sub,
+		middleware: append([]middlewareEntry(nil), r.middleware...),
+		meta:       r.meta,
+	})
+}
+
+func (s *routerState) collect() (registrations, error) {
+	return s.collectFrom(map[*routerState]bool{})
+}
+
+func (s *routerState) collectFrom(visiting map[*routerState]bool) (registrations, error) {
+	if visiting[s] {
+		return registrations{}, fmt.Errorf("invalid sub-router: router is mounted into itself")
+	}
+	visiting[s] = true
+	defer delete(visiting, s)
+
+	reg := registrations{
+		routes:   s.routes,
+		mounts:   s.mounts,
+		aliases:  s.aliases,
+		rewrites: s.rewrites,
+	}
+	if len(s.subRouters) == 0 {
+		return reg, nil
+	}
+	reg.routes = append([]registeredRoute(nil), s.routes...)
+	reg.mounts = append([]registeredMount(nil), s.mounts...)
+	reg.aliases = append([]registeredAlias(nil), s.aliases...)
+	reg.rewrites = append([]registeredRewrite(nil), s.rewrites...)
+
+	for _, sr := range s.subRouters {
+		if sr.router == nil {
+			return registrations{}, fmt.Errorf("invalid sub-router: nil")
+		}
+		prefix, err := normalizePrefix(sr.prefix)
+		if err != nil {
+			return registrations{}, err
+		}
+		sub, err := sr.router.state.collectFrom(visiting)
+		if err != nil {
+			return registrations{}, err
+		}
+		for _, rt := range sub.routes {
+			rt.pattern = prefix + rt.pattern
+			rt.middleware = append(append([]middlewareEntry(nil), sr.middleware...), rt.middleware...)
+			rt.meta = mergeMeta(sr.meta, rt.meta)
+			reg.routes = append(reg.routes, rt)
+		}
+		for _, mt := range sub.mounts {
+			mt.prefix = prefix + mt.prefix
+			reg.mounts = append(reg.mounts, mt)
+		}
+		for _, ra := range sub.aliases {
+			aliases := make([]string, len(ra.aliases))
+			for i, a := range ra.aliases {
+				aliases[i] = prefix + a
+			}
+			reg.aliases = append(reg.aliases, registeredAlias{pattern: prefix + ra.pattern, aliases: aliases})
+		}
+		for _, rw := range sub.rewrites {
+			reg.rewrites = append(reg.rewrites, registeredRewrite{pattern: prefix + rw.pattern, target: prefix + rw.target})
+		}
+	}
+	return reg, nil
+}
+
+// normalizePrefix validates a path prefix. "/" and "" both mean no prefix.
+func normalizePrefix(prefix string) (string, error) {
+	if prefix == "" || prefix == "/" {
+		return "", nil
+	}
+	if prefix[0] != '/' || strings.HasSuffix(prefix, "/") {
+		return "", fmt.Errorf("invalid prefix %q: must start with '/' and not end with '/'", prefix)
+	}
+	cp, err := compilePattern(prefix)
+	if err != nil {
+		return "", err
+	}
+	for _, seg := range cp.segments {
+		if seg.kind == segmentCatchAll {
+			return "", fmt.Errorf("invalid prefix %q: catch-all is not allowed", prefix)
+		}
+	}
+	return prefix, nil
+}
+
+func mergeMeta(parent, child Meta) Meta {
+	if len(parent) == 0 {
+		return child
+	}
+	if len(child) == 0 {
+		return parent
+	}
+	out := maps.Clone(parent)
+	maps.Copy(out, child)
+	return out
+}
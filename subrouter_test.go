@@ -0,0 +1,102 @@
+package saruta
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestRouterMountRouter(t *testing.T) {
+	var calls []string
+	mw := func(name string) Middleware {
+		return func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				calls = append(calls, name)
+				next.ServeHTTP(w, req)
+			})
+		}
+	}
+
+	api := New()
+	api.Use(mw("api"))
+	api.Get("/users/{id}", func(w http.ResponseWriter, req *http.Request) {
+		_, _ = w.Write([]byte(req.PathValue("org") + ":" + req.PathValue("id")))
+	})
+	api.Post("/users/{id}", func(w http.ResponseWriter, req *http.Request) {})
+
+	r := New()
+	r.Use(mw("root"))
+	r.MountRouter("/orgs/{org}", api)
+	r.MustCompile()
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/orgs/acme/users/7", nil))
+	if got, want := rec.Body.String(), "acme:7"; got != want {
+		t.Fatalf("body = %q, want %q", got, want)
+	}
+	if want := []string{"root", "api"}; !reflect.DeepEqual(calls, want) {
+		t.Fatalf("calls = %#v, want %#v", calls, want)
+	}
+
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodDelete, "/orgs/acme/users/7", nil))
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusMethodNotAllowed)
+	}
+	if got, want := rec.Header().Get("Allow"), "GET, POST"; got != want {
+		t.Fatalf("Allow = %q, want %q", got, want)
+	}
+
+	routes := r.Routes()
+	if len(routes) != 2 || routes[0].Pattern != "/orgs/{org}/users/{id}" {
+		t.Fatalf("routes = %#v", routes)
+	}
+}
+
+func TestRouterMountRouterNested(t *testing.T) {
+	v1 := New()
+	v1.Get("/ping", func(w http.ResponseWriter, req *http.Request) {
+		_, _ = w.Write([]byte("pong"))
+	})
+	api := New()
+	api.MountRouter("/v1", v1)
+	r := New()
+	r.MountRouter("/api", api)
+	r.MustCompile()
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/ping", nil))
+	if got, want := rec.Body.String(), "pong"; got != want {
+		t.Fatalf("body = %q, want %q", got, want)
+	}
+}
+
+func TestRouterMountRouterErrors(t *testing.T) {
+	h := func(w http.ResponseWriter, req *http.Request) {}
+
+	for _, prefix := range []string{"api", "/api/", "/files/{rest...}"} {
+		sub := New()
+		sub.Get("/x", h)
+		r := New()
+		r.MountRouter(prefix, sub)
+		if err := r.Compile(); err == nil {
+			t.Fatalf("%q: expected compile error", prefix)
+		}
+	}
+
+	r := New()
+	r.Get("/api/x", h)
+	sub := New()
+	sub.Get("/x", h)
+	r.MountRouter("/api", sub)
+	if err := r.Compile(); err == nil {
+		t.Fatalf("expected duplicate route error")
+	}
+
+	r = New()
+	r.MountRouter("/self", r)
+	if err := r.Compile(); err == nil {
+		t.Fatalf("expected cycle error")
+	}
+}
@@ -0,0 +1,168 @@
+package saruta
+
+import (
+	"cmp"
+	"context"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// WithSuggestions makes the router look for the registered routes closest to
+// an unmatched path and hand up to n of them to the NotFound handler, which
+// reads them with Suggestions to build "did you mean" error bodies.
+//
+// Closeness is the edit distance over static segments; parameters match any
+// segment and a catch-all the rest of the path. The search runs only for 404s,
+// scanning every pattern, so it is meant for developer-facing APIs rather than
+// paths hammered by scanners.
+func WithSuggestions(n int) Option {
+	return func(r *Router) {
+		r.state.suggest = max(n, 0)
+	}
+}
+
+type suggestionsKey struct{}
+
+// Suggestions returns the registered patterns closest to the path of an
+// unmatched request, best first, when the router was created with
+// WithSuggestions. It returns nil outside a NotFound handler or when nothing
+// is close.
+//
+//	r.NotFound(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+//		writeJSON(w, 404, map[string]any{
+//			"error":        "not found",
+//			"did_you_mean": saruta.Suggestions(req.Context()),
+//		})
+//	}))
+func Suggestions(ctx context.Context) []string {
+	s, _ := ctx.Value(suggestionsKey{}).([]string)
+	return s
+}
+
+// suggester holds the patterns considered by WithSuggestions, split into
+// segments once at Compile.
+type suggester struct {
+	n        int
+	patterns []suggestPattern
+}
+
+type suggestPattern struct {
+	pattern  string
+	segments []segment
+	static   int // total length of the static segments
+}
+
+func newSuggester(n int, routes []RouteInfo) *suggester {
+	s := &suggester{n: n}
+	seen := make(map[string]bool)
+	add := func(pattern string) {
+		if seen[pattern] {
+			return
+		}
+		seen[pattern] = true
+		cp, err := compilePattern(pattern)
+		if err != nil {
+			return
+		}
+		sp := suggestPattern{pattern: pattern, segments: cp.segments}
+		for _, seg := range cp.segments {
+			if seg.kind == segmentStatic {
+				sp.static += len(seg.literal)
+			}
+		}
+		s.patterns = append(s.patterns, sp)
+	}
+	for _, rt := range routes {
+		add(rt.Pattern)
+		for _, a := range rt.Aliases {
+			add(a)
+		}
+	}
+	return s
+}
+
+// attach returns req carrying the suggestions for path, or req itself when
+// there are none.
+func (s *suggester) attach(req *http.Request, path string) *http.Request {
+	if list := s.suggest(path); len(list) > 0 {
+		return req.WithContext(context.WithValue(req.Context(), suggestionsKey{}, list))
+	}
+	return req
+}
+
+func (s *suggester) suggest(path string) []string {
+	type scored struct {
+		pattern string
+		dist    int
+	}
+	segs := splitPathSegments(path)
+	var found []scored
+	for _, sp := range s.patterns {
+		// Allow roughly one typo per three static characters, and at least one.
+		limit := max(1, sp.static/3)
+		if d := sp.distance(segs, limit); d <= limit {
+			found = append(found, scored{sp.pattern, d})
+		}
+	}
+	slices.SortFunc(found, func(a, b scored) int {
+		return cmp.Or(cmp.Compare(a.dist, b.dist), strings.Compare(a.pattern, b.pattern))
+	})
+	out := make([]string, 0, min(len(found), s.n))
+	for _, f := range found[:min(len(found), s.n)] {
+		out = append(out, f.pattern)
+	}
+	return out
+}
+
+// distance returns the edit distance between the pattern and the path
+// segments, giving up (returning limit+1) once it exceeds limit.
+func (sp *suggestPattern) distance(segs []string, limit int) int {
+	d := 0
+	for i, seg := range sp.segments {
+		if seg.kind == segmentCatchAll {
+			if i >= len(segs) && seg.repeated {
+				d++
+			}
+			return d
+		}
+		if i >= len(segs) {
+			d += 1 + len(seg.literal)
+		} else if seg.kind == segmentStatic {
+			d += editDistance(seg.literal, segs[i])
+		} else if segs[i] == "" {
+			d++
+		}
+		if d > limit {
+			return limit + 1
+		}
+	}
+	for _, extra := range segs[min(len(sp.segments), len(segs)):] {
+		d += 1 + len(extra)
+	}
+	return d
+}
+
+// editDistance is the Levenshtein distance between a and b in bytes.
+func editDistance(a, b string) int {
+	if a == b {
+		return 0
+	}
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
@@ -0,0 +1,74 @@
+package saruta
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+)
+
+func TestRouterSuggestions(t *testing.T) {
+	noop := func(w http.ResponseWriter, req *http.Request) {}
+	r := New(WithSuggestions(2))
+	r.Get("/users/{id}", noop)
+	r.Get("/users/{id}/orders", noop)
+	r.Get("/organizations", noop)
+	r.Get("/files/{path...}", noop)
+	var got []string
+	r.NotFound(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		got = Suggestions(req.Context())
+		http.NotFound(w, req)
+	}))
+	r.MustCompile()
+
+	tests := []struct {
+		path string
+		want []string
+	}{
+		{"/user/42", []string{"/users/{id}"}},
+		{"/users/42/order", []string{"/users/{id}/orders"}},
+		{"/organisations", []string{"/organizations"}},
+		{"/fils/a/b", []string{"/files/{path...}"}},
+		{"/completely/unrelated/path", nil},
+	}
+	for _, tt := range tests {
+		got = nil
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.path, nil))
+		if rec.Code != http.StatusNotFound {
+			t.Fatalf("%s: status = %d", tt.path, rec.Code)
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("%s: Suggestions = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+
+	plain := New()
+	plain.Get("/users/{id}", noop)
+	plain.NotFound(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		got = Suggestions(req.Context())
+	}))
+	plain.MustCompile()
+	got = []string{"sentinel"}
+	plain.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/user/1", nil))
+	if got != nil {
+		t.Fatalf("suggestions without WithSuggestions = %q", got)
+	}
+}
+
+func TestEditDistance(t *testing.T) {
+	for _, tt := range []struct {
+		a, b string
+		want int
+	}{
+		{"", "", 0},
+		{"users", "users", 0},
+		{"users", "user", 1},
+		{"kitten", "sitting", 3},
+		{"", "abc", 3},
+	} {
+		if got := editDistance(tt.a, tt.b); got != tt.want {
+			t.Errorf("editDistance(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
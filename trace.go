@@ -0,0 +1,190 @@
+package saruta
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// MatchStep is one edge tried while matching a path.
+type MatchStep struct {
+	// Pos is the byte offset in the path where the edge was tried.
+	Pos int `json:"pos"`
+	// Kind is "static", "param" or "catch-all".
+	Kind string `json:"kind"`
+	// Label is the static label or the parameter pattern of the edge.
+	Label string `json:"label"`
+	// Input is the remaining path (static, catch-all) or the segment (param)
+	// the edge was tested against.
+	Input string `json:"input"`
+	OK    bool   `json:"ok"`
+	// Reason explains a failed step.
+	Reason string `json:"reason,omitempty"`
+}
+
+// MatchTrace explains how the router handles a request. See TraceMatch.
+type MatchTrace struct {
+	Method string `json:"method"`
+	Path   string `json:"path"`
+	// RewrittenPath is the path after Rewrite rules, if one applied.
+	RewrittenPath string      `json:"rewritten_path,omitempty"`
+	Steps         []MatchStep `json:"steps"`
+	// Pattern is the pattern of the node the path ended at, if any.
+	Pattern string `json:"pattern,omitempty"`
+	// Allow lists the methods registered for that node.
+	Allow []string `json:"allow,omitempty"`
+	// Result is "matched", "method not allowed", "mount", "noise" or
+	// "not found".
+	Result string `json:"result"`
+}
+
+// Explain is TraceMatch as a method, for call sites that already hold the
+// router: r.Explain("GET", "/users/abc"). Print the result to read the
+// trace; see MatchTrace.String.
+func (r *Router) Explain(method, path string) MatchTrace {
+	return TraceMatch(r, path, method)
+}
+
+// String renders the trace one step per line, indented under the outcome:
+//
+//	GET /users/abc: not found
+//	  @0 static /users on "/users/abc": ok
+//	  @6 static /me on "/abc": label does not match
+//	  @6 param {id:[0-9]+} on "abc": segment rejected by pattern or constraint
+//	  @0 static /users on "/users/abc": no route below this edge
+//
+// A failed step with the reason "no route below this edge" is a backtrack:
+// the edge matched but nothing under it did, so the matcher tried the next
+// edge at the same position.
+func (t MatchTrace) String() string {
+	var b strings.Builder
+	b.WriteString(t.Method + " " + t.Path + ": " + t.Result)
+	if t.Pattern != "" {
+		b.WriteString(" (" + t.Pattern + ")")
+	}
+	if t.RewrittenPath != "" {
+		b.WriteString("\n  rewritten to " + t.RewrittenPath)
+	}
+	for _, st := range t.Steps {
+		fmt.Fprintf(&b, "\n  @%d %s %s on %q: ", st.Pos, st.Kind, st.Label, st.Input)
+		if st.OK {
+			b.WriteString("ok")
+		} else {
+			b.WriteString(st.Reason)
+		}
+	}
+	return b.String()
+}
+
+// TraceMatch reports the edges tried, the constraints evaluated and why each
+// branch failed while routing method + path, along with the final outcome.
+// No handler runs. It is meant for debugging 404s in development and backs
+// the explain endpoint of MountDebug; it allocates and is much slower than
+// serving. TraceMatch panics if r is not compiled.
+func TraceMatch(r *Router, path, method string) MatchTrace {
+	c := r.state.current.Load()
+	if c == nil {
+		panic("saruta: router is not compiled; call Compile or MustCompile before tracing")
+	}
+	t := MatchTrace{Method: method, Path: path}
+	if path == "" || path[0] != '/' {
+		t.Result = "not found"
+		return t
+	}
+	if r.state.noise != nil && r.state.noise.match(path) != nil {
+		t.Result = "noise"
+		return t
+	}
+	if c.rewriteRoot != nil {
+		if next, ok := rewriteTarget(c.rewriteRoot, path); ok {
+			t.RewrittenPath = next
+			path = next
+		}
+	}
+	if c.mountsFirst {
+		if m := c.root.findMount(path); m != nil && m.precedence == MountBeforeRoutes {
+			t.Result = "mount"
+			return t
+		}
+	}
+
+	tr := &matchTracer{}
+	matched, ok := c.root.matchRouteTrace(path, tr)
+	t.Steps = tr.steps
+	if ok && matched.leaf.handlers.len() > 0 {
+		_, methodOK := matched.leaf.handlers.get(method)
+		methodOK = methodOK || r.state.autoOptions && method == http.MethodOptions
+		if methodOK || !c.no405 {
+			t.Pattern = matched.leaf.pattern
+			allow := allowHeaderValue(matched.leaf.handlers)
+			if r.state.autoOptions {
+				allow = allowHeaderWithOptions(matched.leaf.handlers)
+			}
+			t.Allow = strings.Split(allow, ", ")
+			if methodOK {
+				t.Result = "matched"
+				return t
+			}
+			if m := c.root.findMount(path); m != nil && m.precedence != MountAfterRoutes {
+				t.Result = "mount"
+				return t
+			}
+			t.Result = "method not allowed"
+			return t
+		}
+	}
+	if c.root.findMount(path) != nil {
+		t.Result = "mount"
+		return t
+	}
+	t.Result = "not found"
+	return t
+}
+
+type matchTracer struct {
+	steps []MatchStep
+}
+
+func (tr *matchTracer) step(pos int, kind, label, input string, ok bool, reason string) {
+	tr.steps = append(tr.steps, MatchStep{Pos: pos, Kind: kind, Label: label, Input: input, OK: ok, Reason: reason})
+}
+
+// label reconstructs the pattern segment of a parameter edge.
+func (pe *radixParamEdge) label() string {
+	return paramLabel(pe.name, pe.prefix, pe.suffix, pe.tmpl)
+}
+
+func (pe *paramEdge) label() string {
+	return paramLabel(pe.name, pe.prefix, pe.suffix, pe.tmpl)
+}
+
+func paramLabel(name, prefix, suffix string, tmpl *segmentTemplate) string {
+	if tmpl == nil {
+		return prefix + "{" + name + "}" + suffix
+	}
+	var b strings.Builder
+	for i, p := range tmpl.params {
+		b.WriteString(tmpl.literals[i])
+		b.WriteString("{" + p.name)
+		if p.expr != "" {
+			b.WriteString(":" + p.expr)
+		}
+		b.WriteString("}")
+	}
+	b.WriteString(tmpl.literals[len(tmpl.literals)-1])
+	return b.String()
+}
+
+func (pe *radixParamEdge) catchAllLabel() string {
+	return catchAllLabel(pe.name, pe.expr, pe.repeated)
+}
+
+func catchAllLabel(name, expr string, repeated bool) string {
+	if !repeated {
+		return "{" + name + "...}"
+	}
+	if expr != "" {
+		return "{" + name + "+:" + expr + "}"
+	}
+	return "{" + name + "+}"
+}
@@ -0,0 +1,83 @@
+package saruta
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestTraceMatch(t *testing.T) {
+	noop := func(w http.ResponseWriter, req *http.Request) {}
+	r := New()
+	r.Get("/users/{id:[0-9]+}", noop)
+	r.Get("/users/me", noop)
+	r.Post("/items/{id}.{ext:[a-z]+}", noop)
+	r.Get("/files/{path...}", noop)
+	r.Rewrite("/people/{id}", "/users/{id}")
+	r.Mount("/legacy", http.NotFoundHandler())
+	r.MustCompile()
+
+	tr := TraceMatch(r, "/users/abc", http.MethodGet)
+	if tr.Result != "not found" {
+		t.Fatalf("result = %q, want not found", tr.Result)
+	}
+	var rejected *MatchStep
+	for i := range tr.Steps {
+		if tr.Steps[i].Kind == "param" && !tr.Steps[i].OK {
+			rejected = &tr.Steps[i]
+		}
+	}
+	if rejected == nil || rejected.Label != "{id:[0-9]+}" || rejected.Input != "abc" || rejected.Reason == "" {
+		t.Fatalf("steps = %+v, want rejected {id:[0-9]+} step", tr.Steps)
+	}
+
+	tr = TraceMatch(r, "/users/42", http.MethodGet)
+	if tr.Result != "matched" || tr.Pattern != "/users/{id:[0-9]+}" {
+		t.Fatalf("trace = %+v", tr)
+	}
+	last := tr.Steps[len(tr.Steps)-1]
+	if !last.OK || last.Kind != "param" || last.Input != "42" {
+		t.Fatalf("last step = %+v", last)
+	}
+
+	tr = TraceMatch(r, "/items/1.png", http.MethodGet)
+	if tr.Result != "method not allowed" || len(tr.Allow) != 1 || tr.Allow[0] != "POST" {
+		t.Fatalf("trace = %+v", tr)
+	}
+	if last := tr.Steps[len(tr.Steps)-1]; last.Label != "{id}.{ext:[a-z]+}" {
+		t.Fatalf("template label = %q", last.Label)
+	}
+
+	tr = TraceMatch(r, "/people/7", http.MethodGet)
+	if tr.RewrittenPath != "/users/7" || tr.Result != "matched" {
+		t.Fatalf("trace = %+v", tr)
+	}
+
+	tr = TraceMatch(r, "/files/a/b", http.MethodGet)
+	if last := tr.Steps[len(tr.Steps)-1]; tr.Result != "matched" || last.Kind != "catch-all" || last.Input != "a/b" {
+		t.Fatalf("trace = %+v", tr)
+	}
+
+	if tr := TraceMatch(r, "/legacy/x", http.MethodGet); tr.Result != "mount" {
+		t.Fatalf("result = %q, want mount", tr.Result)
+	}
+}
+
+func TestRouterExplain(t *testing.T) {
+	noop := func(w http.ResponseWriter, req *http.Request) {}
+	r := New()
+	r.Get("/users/{id:[0-9]+}", noop)
+	r.Get("/users/me", noop)
+	r.MustCompile()
+
+	if got := r.Explain(http.MethodGet, "/users/42"); got.Result != "matched" || got.Pattern != "/users/{id:[0-9]+}" {
+		t.Fatalf("Explain = %+v", got)
+	}
+	want := `GET /users/abc: not found
+  @0 static /users on "/users/abc": ok
+  @6 static /me on "/abc": label does not match
+  @6 param {id:[0-9]+} on "abc": segment rejected by pattern or constraint
+  @0 static /users on "/users/abc": no route below this edge`
+	if got := r.Explain(http.MethodGet, "/users/abc").String(); got != want {
+		t.Fatalf("String =\n%s\nwant\n%s", got, want)
+	}
+}
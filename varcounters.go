@@ -0,0 +1,58 @@
+package saruta
+
+import (
+	"net/http"
+	"sync"
+	"sync/atomic"
+)
+
+// varCounters holds the request counters served by MountDebugVars.
+type varCounters struct {
+	notFound         atomic.Uint64
+	methodNotAllowed atomic.Uint64
+
+	mu     sync.Mutex
+	routes map[string]*atomic.Uint64 // "METHOD pattern" -> hits, kept across recompiles
+}
+
+func newVarCounters() *varCounters {
+	return &varCounters{routes: make(map[string]*atomic.Uint64)}
+}
+
+// countRoute wraps h to count requests served by the route named key.
+func (v *varCounters) countRoute(key string, h http.Handler) http.Handler {
+	v.mu.Lock()
+	c := v.routes[key]
+	if c == nil {
+		c = new(atomic.Uint64)
+		v.routes[key] = c
+	}
+	v.mu.Unlock()
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		c.Add(1)
+		h.ServeHTTP(w, req)
+	})
+}
+
+type routerVars struct {
+	Matches          uint64            `json:"matches"`
+	NotFound         uint64            `json:"not_found"`
+	MethodNotAllowed uint64            `json:"method_not_allowed"`
+	Routes           map[string]uint64 `json:"routes"`
+}
+
+func (v *varCounters) snapshot() routerVars {
+	s := routerVars{
+		NotFound:         v.notFound.Load(),
+		MethodNotAllowed: v.methodNotAllowed.Load(),
+	}
+	v.mu.Lock()
+	s.Routes = make(map[string]uint64, len(v.routes))
+	for k, c := range v.routes {
+		n := c.Load()
+		s.Routes[k] = n
+		s.Matches += n
+	}
+	v.mu.Unlock()
+	return s
+}